@@ -0,0 +1,408 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/air/gadgets"
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// VERSION identifies the version of the export format.  This should be
+// incremented whenever the format changes in a way which is not backwards
+// compatible.
+const VERSION = 3
+
+// Document is a self-contained description of an AIR schema, intended to be
+// consumed by a prover.  Columns are identified throughout by their index in
+// the Columns array.  Observe that property assertions are not included, since
+// these cannot be enforced by the prover.
+type Document struct {
+	// Version of the export format used.
+	Version uint `json:"version"`
+	// Field over which all constraints are defined.
+	Field string `json:"field"`
+	// Modules (i.e. tables) making up the schema.
+	Modules []string `json:"modules"`
+	// Columns of the schema, in order of their column index.
+	Columns []Column `json:"columns"`
+	// Assignments determining the values of computed columns, in the order in
+	// which they should be applied.
+	Assignments []Assignment `json:"assignments"`
+	// Constraints which must be enforced by the prover.
+	Constraints []Constraint `json:"constraints"`
+}
+
+// Column describes a single column of the schema.
+type Column struct {
+	// Index of the module containing this column.
+	Module uint `json:"module"`
+	// Name of this column (which is unique within its module).
+	Name string `json:"name"`
+	// Type of this column (e.g. "u8" or "field").  Observe that only types
+	// enforced through range constraints are guaranteed to hold.
+	Type string `json:"type"`
+	// Length multiplier for this column relative to its module.
+	Multiplier uint `json:"multiplier"`
+	// Determines whether this column is computed (i.e. filled by an assignment)
+	// or must be provided by the user.
+	Computed bool `json:"computed"`
+	// Determines whether this column is fixed, meaning its values depend only
+	// on the height of its module (e.g. a "selector").  A fixed column is not
+	// constrained and, hence, its values must not be taken from the prover.
+	// Instead, they must be determined independently (e.g. as part of the
+	// verification key).
+	Fixed bool `json:"fixed"`
+}
+
+// Assignment describes how the values of one or more computed columns are
+// determined.  The kind of assignment determines which fields are used, where
+// the kinds are: "computed", "interleave", "permute", "lexicographic-sort",
+// "decompose-bytes", "decompose-bits", "and-bytes", "and-table", "selector" and
+// "multiplicity".  A selector is a fixed column (see Column) which is one on the
+// rows of a given domain, and zero otherwise.  A multiplicity counts, for each target row of a lookup, the
+// number of source rows matching it.  A permutation of selected source rows
+// declares its target selector as the last of its targets.
+type Assignment struct {
+	Kind string `json:"kind"`
+	// Columns being assigned.
+	Targets []uint `json:"targets"`
+	// Columns from which targets are computed (all kinds except "computed").
+	Sources []uint `json:"sources,omitempty"`
+	// Sorting directions, where true means ascending ("permute" and
+	// "lexicographic-sort" only).
+	Signs []bool `json:"signs,omitempty"`
+	// Bitwidth of the delta column ("lexicographic-sort" only).
+	BitWidth uint `json:"bitwidth,omitempty"`
+	// Computation determining the value on each row ("computed" only).
+	Expr *Expr `json:"expr,omitempty"`
+	// Rows on which the target is one ("selector" only).
+	Domain []Window `json:"domain,omitempty"`
+	// Target columns of the lookup being counted, where the source columns are
+	// given in Sources ("multiplicity" only).
+	Lookup []uint `json:"lookup,omitempty"`
+	// Columns which select the participating source and target rows of the
+	// lookup being counted or the permutation.  When absent, every row
+	// participates ("multiplicity" and "permute" only).
+	SourceSelector *uint `json:"source_selector,omitempty"`
+	TargetSelector *uint `json:"target_selector,omitempty"`
+	// Column determining which rows are sorted, such that only rows whose
+	// preceding row is selected are sorted.  When absent, every row is sorted
+	// ("lexicographic-sort" only).
+	Selector *uint `json:"selector,omitempty"`
+}
+
+// Window describes the rows from a given start row up to (and including) a
+// given end row, at intervals of a given step.  Negative rows are taken from
+// the end of the module.
+type Window struct {
+	Start int  `json:"start"`
+	End   int  `json:"end"`
+	Step  uint `json:"step"`
+}
+
+// Expr describes a computation over the columns of a schema.  Computations are
+// not restricted to polynomials, though expressions given for constraints use
+// only "col", "const", "add", "sub" and "mul".  The operator
+// determines which fields are used, where the operators are: "col", "const",
+// "add", "sub", "mul", "exp", "norm", "inv" and the bitwise operators "band",
+// "bor", "bxor", "bnot", "shl" and "shr".
+type Expr struct {
+	Op    string  `json:"op"`
+	Args  []*Expr `json:"args,omitempty"`
+	Col   *uint   `json:"col,omitempty"`
+	Shift int     `json:"shift,omitempty"`
+	Val   string  `json:"val,omitempty"`
+	Pow   uint64  `json:"pow,omitempty"`
+	Width uint    `json:"width,omitempty"`
+}
+
+// Constraint describes a single constraint to be enforced by the prover.  The
+// kind of constraint determines which fields are used, where the kinds are:
+// "vanishing", "lookup", "logup", "range" and "permutation".  A "logup" is a
+// lookup which is proven using a log-derivative argument and, hence, requires
+// a multiplicity column in the target module.
+type Constraint struct {
+	Kind   string `json:"kind"`
+	Handle string `json:"handle,omitempty"`
+	// Enclosing module ("vanishing" only).
+	Module uint `json:"module"`
+	// Row on which this constraint applies, where negative rows are taken from
+	// the end of the module.  When absent, the constraint applies on all rows
+	// ("vanishing" only).
+	Domain *int `json:"domain,omitempty"`
+	// Polynomial which must evaluate to zero ("vanishing" only).
+	Polynomial Polynomial `json:"polynomial,omitempty"`
+	// Expression which must evaluate to zero, given instead of a polynomial
+	// when multiplying it out would give too many terms ("vanishing" only).
+	Expr *Expr `json:"expr,omitempty"`
+	// Source and target columns ("lookup", "logup" and "permutation" only).
+	Sources []uint `json:"sources,omitempty"`
+	Targets []uint `json:"targets,omitempty"`
+	// Columns which select the participating source and target rows.  When
+	// absent, every row participates ("lookup", "logup" and "permutation"
+	// only).
+	SourceSelector *uint `json:"source_selector,omitempty"`
+	TargetSelector *uint `json:"target_selector,omitempty"`
+	// Column holding the multiplicity of each target row ("logup" only).
+	Multiplicity *uint `json:"multiplicity,omitempty"`
+	// Column being bounded ("range" only).
+	Column *uint `json:"column,omitempty"`
+	// Exclusive upper bound on values, given as a (decimal) field element
+	// ("range" only).
+	Bound string `json:"bound,omitempty"`
+}
+
+// Export an AIR schema into a document suitable for consumption by a prover.
+func Export(schema *air.Schema) *Document {
+	doc := &Document{Version: VERSION, Field: "bls12-377"}
+	//
+	for i := schema.Modules(); i.HasNext(); {
+		mod := i.Next()
+		doc.Modules = append(doc.Modules, mod.Name())
+	}
+	//
+	ninputs := schema.InputColumns().Count()
+	//
+	for i := schema.Columns(); i.HasNext(); {
+		col := i.Next()
+		computed := uint(len(doc.Columns)) >= ninputs
+		doc.Columns = append(doc.Columns, Column{col.Context().Module(), col.Name(), exportType(col.Type()),
+			col.Context().LengthMultiplier(), computed, false})
+	}
+	// Columns for each assignment are allocated in order, after the inputs.
+	index := ninputs
+	//
+	for i := schema.Assignments(); i.HasNext(); {
+		ith := i.Next()
+		n := ith.Columns().Count()
+		exported := exportAssignment(ith, index, n)
+		// Selectors determine fixed columns
+		for _, target := range exported.Targets {
+			doc.Columns[target].Fixed = exported.Kind == "selector"
+		}
+		//
+		doc.Assignments = append(doc.Assignments, exported)
+		index += n
+	}
+	//
+	for i := schema.Constraints(); i.HasNext(); {
+		doc.Constraints = append(doc.Constraints, exportConstraint(i.Next()))
+	}
+	//
+	return doc
+}
+
+// ToJsonString exports an AIR schema into a JSON document suitable for
+// consumption by a prover.
+func ToJsonString(schema *air.Schema) string {
+	bytes, err := json.MarshalIndent(Export(schema), "", " ")
+	// Sanity check
+	if err != nil {
+		panic(err)
+	}
+	//
+	return string(bytes)
+}
+
+func exportAssignment(a sc.Assignment, index uint, n uint) Assignment {
+	targets := make([]uint, n)
+	for i := range targets {
+		targets[i] = index + uint(i)
+	}
+	//
+	switch a := a.(type) {
+	case *assignment.ComputedColumn[air.Expr]:
+		return Assignment{Kind: "computed", Targets: targets, Expr: exportAirExpr(a.Expr())}
+	case *assignment.ComputedColumn[*gadgets.Inverse]:
+		inverse := &Expr{Op: "inv", Args: []*Expr{exportAirExpr(a.Expr().Expr)}}
+		return Assignment{Kind: "computed", Targets: targets, Expr: inverse}
+	case *assignment.ComputedColumn[*gadgets.DomainSelector]:
+		return Assignment{Kind: "selector", Targets: targets, Domain: exportWindows(a.Expr().Domain())}
+	case *assignment.ComputedColumn[mir.Expr]:
+		return Assignment{Kind: "computed", Targets: targets, Expr: exportMirExpr(a.Expr())}
+	case *assignment.Interleaving:
+		return Assignment{Kind: "interleave", Targets: targets, Sources: a.Sources()}
+	case *assignment.SortedPermutation:
+		var targetSelector *uint
+		//
+		if a.TargetSelector().HasValue() {
+			targetSelector = &targets[n-1]
+		}
+		//
+		return Assignment{Kind: "permute", Targets: targets, Sources: a.Sources(), Signs: a.Signs(),
+			SourceSelector: exportColumn(a.Selector()), TargetSelector: targetSelector}
+	case *assignment.LexicographicSort:
+		return Assignment{Kind: "lexicographic-sort", Targets: targets, Sources: a.Sources(), Signs: a.Signs(),
+			BitWidth: a.BitWidth(), Selector: exportColumn(a.Selector())}
+	case *assignment.ByteDecomposition:
+		return Assignment{Kind: "decompose-bytes", Targets: targets, Sources: []uint{a.Source()}}
+	case *assignment.BitDecomposition:
+		return Assignment{Kind: "decompose-bits", Targets: targets, Sources: []uint{a.Source()}}
+	case *assignment.ByteAnd:
+		sources := append(append([]uint{}, a.Lhs()...), a.Rhs()...)
+		//
+		return Assignment{Kind: "and-bytes", Targets: targets, Sources: sources}
+	case *assignment.ByteAndTable:
+		return Assignment{Kind: "and-table", Targets: targets}
+	case *assignment.LookupMultiplicity:
+		return Assignment{Kind: "multiplicity", Targets: targets, Sources: a.Sources(), Lookup: a.Targets(),
+			SourceSelector: exportColumn(a.SourceSelector()), TargetSelector: exportColumn(a.TargetSelector())}
+	default:
+		panic(fmt.Sprintf("unknown AIR assignment encountered (%T)", a))
+	}
+}
+
+func exportWindows(domain *constraint.Domain) []Window {
+	windows := make([]Window, len(domain.Windows()))
+	//
+	for i, w := range domain.Windows() {
+		windows[i] = Window{w.Start, w.End, w.Step}
+	}
+	//
+	return windows
+}
+
+// Export the domain of a vanishing constraint.  Observe that lowering to the AIR
+// introduces selector columns for all domains other than the first or last row.
+// Hence, any other domain is unexpected here.
+func exportDomain(domain *constraint.Domain) *int {
+	if domain == nil {
+		return nil
+	} else if row, ok := domain.Row(); ok {
+		return &row
+	}
+	//
+	panic(fmt.Sprintf("unsupported domain %s", domain))
+}
+
+func exportConstraint(c sc.Constraint) Constraint {
+	switch c := c.(type) {
+	case air.VanishingConstraint:
+		exported := Constraint{Kind: "vanishing", Handle: c.Handle(), Module: c.Context().Module(),
+			Domain: exportDomain(c.Domain())}
+		// Fall back to the expression itself when it is too large to multiply
+		// out.
+		if poly, ok := NewPolynomial(c.Constraint().Expr); ok {
+			exported.Polynomial = poly
+		} else {
+			exported.Expr = exportAirExpr(c.Constraint().Expr)
+		}
+		//
+		return exported
+	case air.LookupConstraint:
+		return Constraint{Kind: "lookup", Handle: c.Handle(), Module: c.SourceContext().Module(),
+			Sources: exportColumns(c.Sources()), Targets: exportColumns(c.Targets()),
+			SourceSelector: exportSelector(c.SourceSelector()), TargetSelector: exportSelector(c.TargetSelector())}
+	case air.LogDerivativeLookupConstraint:
+		multiplicity := c.Multiplicity().Column
+		//
+		return Constraint{Kind: "logup", Handle: c.Handle(), Module: c.SourceContext().Module(),
+			Sources: exportColumns(c.Sources()), Targets: exportColumns(c.Targets()),
+			SourceSelector: exportSelector(c.SourceSelector()), TargetSelector: exportSelector(c.TargetSelector()),
+			Multiplicity: &multiplicity}
+	case air.RangeConstraint:
+		bound := c.Bound()
+		//
+		return Constraint{Kind: "range", Handle: c.Handle(), Module: c.Context().Module(),
+			Column: exportColumn(util.Some(c.Target().Column)), Bound: bound.String()}
+	case *constraint.PermutationConstraint:
+		return Constraint{Kind: "permutation", Sources: c.Sources(), Targets: c.Targets(),
+			SourceSelector: exportColumn(c.SourceSelector()), TargetSelector: exportColumn(c.TargetSelector())}
+	default:
+		panic(fmt.Sprintf("unknown AIR constraint encountered (%T)", c))
+	}
+}
+
+// Export the columns accessed by a lookup, which are never shifted at the AIR
+// level.
+func exportColumns(accesses []*air.ColumnAccess) []uint {
+	columns := make([]uint, len(accesses))
+	for i, a := range accesses {
+		columns[i] = a.Column
+	}
+	//
+	return columns
+}
+
+// Export the (optional) selector column of a lookup.
+func exportSelector(selector util.Option[*air.ColumnAccess]) *uint {
+	if !selector.HasValue() {
+		return nil
+	}
+	//
+	return exportColumn(util.Some(selector.Unwrap().Column))
+}
+
+// Export an (optional) column index.
+func exportColumn(column util.Option[uint]) *uint {
+	if !column.HasValue() {
+		return nil
+	}
+	//
+	index := column.Unwrap()
+	//
+	return &index
+}
+
+func exportType(t sc.Type) string {
+	if t.AsUint() != nil {
+		return fmt.Sprintf("u%d", t.AsUint().BitWidth())
+	}
+	//
+	return "field"
+}
+
+func exportAirExpr(e air.Expr) *Expr {
+	switch e := e.(type) {
+	case *air.ColumnAccess:
+		return &Expr{Op: "col", Col: exportColumn(util.Some(e.Column)), Shift: e.Shift}
+	case *air.Constant:
+		return &Expr{Op: "const", Val: e.Value.String()}
+	case *air.Add:
+		return &Expr{Op: "add", Args: exportAll(e.Args, exportAirExpr)}
+	case *air.Sub:
+		return &Expr{Op: "sub", Args: exportAll(e.Args, exportAirExpr)}
+	case *air.Mul:
+		return &Expr{Op: "mul", Args: exportAll(e.Args, exportAirExpr)}
+	default:
+		panic(fmt.Sprintf("unknown AIR expression encountered (%T)", e))
+	}
+}
+
+func exportMirExpr(e mir.Expr) *Expr {
+	switch e := e.(type) {
+	case *mir.ColumnAccess:
+		return &Expr{Op: "col", Col: exportColumn(util.Some(e.Column)), Shift: e.Shift}
+	case *mir.Constant:
+		return &Expr{Op: "const", Val: e.Value.String()}
+	case *mir.Add:
+		return &Expr{Op: "add", Args: exportAll(e.Args, exportMirExpr)}
+	case *mir.Sub:
+		return &Expr{Op: "sub", Args: exportAll(e.Args, exportMirExpr)}
+	case *mir.Mul:
+		return &Expr{Op: "mul", Args: exportAll(e.Args, exportMirExpr)}
+	case *mir.Exp:
+		return &Expr{Op: "exp", Args: []*Expr{exportMirExpr(e.Arg)}, Pow: e.Pow}
+	case *mir.Normalise:
+		return &Expr{Op: "norm", Args: []*Expr{exportMirExpr(e.Arg)}}
+	case *mir.Bitwise:
+		return &Expr{Op: e.Op.String(), Args: exportAll(e.Args, exportMirExpr), Width: e.Width}
+	default:
+		panic(fmt.Sprintf("unknown MIR expression encountered (%T)", e))
+	}
+}
+
+func exportAll[E any](args []E, export func(E) *Expr) []*Expr {
+	exprs := make([]*Expr, len(args))
+	for i, arg := range args {
+		exprs[i] = export(arg)
+	}
+	//
+	return exprs
+}
@@ -0,0 +1,278 @@
+package export
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+)
+
+// MAX_POLYNOMIAL_TERMS determines the maximum number of terms permitted when
+// multiplying out an expression into its canonical form.  Since multiplying out
+// products of sums can give rise to exponentially many terms, expressions
+// exceeding this are not converted.
+const MAX_POLYNOMIAL_TERMS = 256
+
+// Factor represents a column access (at a given shift) raised to a given
+// power, such as X[k-1]^2.
+type Factor struct {
+	// Index of the column being accessed.
+	Column uint `json:"col"`
+	// Relative row being accessed (e.g. -1 for the previous row).
+	Shift int `json:"shift"`
+	// Power to which this access is raised (which is always at least one).
+	Power uint `json:"pow"`
+}
+
+// Term represents a single monomial within a polynomial, such as 3*X*Y[k+1].
+// A term without any factors is simply a constant.
+type Term struct {
+	// Coefficient of this term, given as a (decimal) field element.  Observe
+	// that negative coefficients are represented by their field equivalents.
+	Coefficient string `json:"coeff"`
+	// Factors making up this term, sorted by column and then shift.
+	Factors []Factor `json:"factors"`
+}
+
+// Polynomial represents the canonical form of an AIR expression, namely a sum
+// of monomials.  Terms are sorted by degree and then lexicographically by their
+// factors, such that structurally different expressions which are nevertheless
+// equivalent (e.g. "(* X (+ Y 1))" and "(+ X (* Y X))") have the same
+// canonical form.
+type Polynomial []Term
+
+// NewPolynomial constructs the canonical form of a given AIR expression.  In
+// essence, this is done by multiplying out all products of sums, and then
+// combining like terms.  If at any point this gives rise to more than
+// MAX_POLYNOMIAL_TERMS terms, then conversion fails.
+func NewPolynomial(e air.Expr) (Polynomial, bool) {
+	poly, ok := expand(e)
+	//
+	if !ok {
+		return nil, false
+	}
+	//
+	terms := make([]Term, 0, len(poly))
+	// Convert all non-zero terms
+	for _, m := range poly {
+		if !m.coeff.IsZero() {
+			terms = append(terms, Term{m.coeff.String(), m.factors})
+		}
+	}
+	//
+	slices.SortFunc(terms, compareTerms)
+	//
+	return terms, true
+}
+
+// Degree returns the degree of this polynomial.  That is, the maximum degree of
+// any term within the polynomial.
+func (p Polynomial) Degree() uint {
+	degree := uint(0)
+	//
+	for _, t := range p {
+		degree = max(degree, t.Degree())
+	}
+	//
+	return degree
+}
+
+// Degree returns the degree of this term, which is the sum of the powers of its
+// factors.
+func (t *Term) Degree() uint {
+	degree := uint(0)
+	//
+	for _, f := range t.Factors {
+		degree += f.Power
+	}
+	//
+	return degree
+}
+
+// ============================================================================
+// Expansion
+// ============================================================================
+
+// Monomial in the process of being constructed.
+type monomial struct {
+	coeff   fr.Element
+	factors []Factor
+}
+
+// A sum of monomials, indexed by a unique key for their factors (so like terms
+// can be combined).
+type sum map[string]*monomial
+
+// Expand a given expression into a sum of monomials, returning false if this
+// exceeds MAX_POLYNOMIAL_TERMS terms.
+func expand(e air.Expr) (sum, bool) {
+	switch e := e.(type) {
+	case *air.Constant:
+		return constant(e.Value), true
+	case *air.ColumnAccess:
+		var one fr.Element
+		//
+		one.SetOne()
+		//
+		return singleton(one, []Factor{{e.Column, e.Shift, 1}}), true
+	case *air.Add:
+		return expandSum(e.Args, false)
+	case *air.Sub:
+		return expandSum(e.Args, true)
+	case *air.Mul:
+		return expandProduct(e.Args)
+	default:
+		panic(fmt.Sprintf("unknown AIR expression encountered (%T)", e))
+	}
+}
+
+// Expand a sum (or subtraction) of expressions.
+func expandSum(args []air.Expr, subtract bool) (sum, bool) {
+	poly, ok := expand(args[0])
+	//
+	for i := 1; ok && i < len(args); i++ {
+		var ith sum
+		//
+		if ith, ok = expand(args[i]); ok {
+			for _, m := range ith {
+				coeff := m.coeff
+				//
+				if subtract {
+					coeff.Neg(&coeff)
+				}
+				//
+				poly.add(coeff, m.factors)
+			}
+			//
+			ok = len(poly) <= MAX_POLYNOMIAL_TERMS
+		}
+	}
+	//
+	return poly, ok
+}
+
+// Expand a product of expressions by multiplying them out.
+func expandProduct(args []air.Expr) (sum, bool) {
+	poly, ok := expand(args[0])
+	//
+	for i := 1; ok && i < len(args); i++ {
+		var ith sum
+		//
+		if ith, ok = expand(args[i]); ok {
+			poly = poly.mul(ith)
+			ok = len(poly) <= MAX_POLYNOMIAL_TERMS
+		}
+	}
+	//
+	return poly, ok
+}
+
+func constant(val fr.Element) sum {
+	return singleton(val, nil)
+}
+
+func singleton(coeff fr.Element, factors []Factor) sum {
+	poly := make(sum)
+	poly.add(coeff, factors)
+	//
+	return poly
+}
+
+// Add a given monomial into this sum, combining it with any like term.
+func (p sum) add(coeff fr.Element, factors []Factor) {
+	key := keyOf(factors)
+	//
+	if m, ok := p[key]; ok {
+		m.coeff.Add(&m.coeff, &coeff)
+	} else {
+		p[key] = &monomial{coeff, factors}
+	}
+}
+
+// Multiply two sums together, producing a new sum.
+func (p sum) mul(q sum) sum {
+	result := make(sum)
+	//
+	for _, l := range p {
+		for _, r := range q {
+			var coeff fr.Element
+			//
+			coeff.Mul(&l.coeff, &r.coeff)
+			//
+			if !coeff.IsZero() {
+				result.add(coeff, mulFactors(l.factors, r.factors))
+			}
+		}
+	}
+	//
+	return result
+}
+
+// Multiply two (sorted) lists of factors together, producing a sorted list of
+// factors where accesses to the same column and shift are combined.
+func mulFactors(lhs []Factor, rhs []Factor) []Factor {
+	factors := make([]Factor, 0, len(lhs)+len(rhs))
+	i, j := 0, 0
+	//
+	for i < len(lhs) && j < len(rhs) {
+		c := compareFactors(lhs[i], rhs[j])
+		//
+		switch {
+		case c < 0:
+			factors = append(factors, lhs[i])
+			i++
+		case c > 0:
+			factors = append(factors, rhs[j])
+			j++
+		default:
+			factors = append(factors, Factor{lhs[i].Column, lhs[i].Shift, lhs[i].Power + rhs[j].Power})
+			i, j = i+1, j+1
+		}
+	}
+	//
+	factors = append(factors, lhs[i:]...)
+	//
+	return append(factors, rhs[j:]...)
+}
+
+func keyOf(factors []Factor) string {
+	var builder strings.Builder
+	//
+	for _, f := range factors {
+		builder.WriteString(fmt.Sprintf("%d:%d^%d;", f.Column, f.Shift, f.Power))
+	}
+	//
+	return builder.String()
+}
+
+// Compare two factors by column, and then by shift (ignoring power).
+func compareFactors(lhs Factor, rhs Factor) int {
+	if lhs.Column != rhs.Column {
+		return cmp.Compare(lhs.Column, rhs.Column)
+	}
+	//
+	return cmp.Compare(lhs.Shift, rhs.Shift)
+}
+
+// Compare two terms by degree, and then lexicographically by factors.
+func compareTerms(lhs Term, rhs Term) int {
+	if ld, rd := lhs.Degree(), rhs.Degree(); ld != rd {
+		return cmp.Compare(ld, rd)
+	}
+	//
+	for i := 0; i < min(len(lhs.Factors), len(rhs.Factors)); i++ {
+		l, r := lhs.Factors[i], rhs.Factors[i]
+		//
+		if c := compareFactors(l, r); c != 0 {
+			return c
+		} else if l.Power != r.Power {
+			// Higher powers first, since this means fewer factors overall.
+			return cmp.Compare(r.Power, l.Power)
+		}
+	}
+	//
+	return cmp.Compare(len(lhs.Factors), len(rhs.Factors))
+}
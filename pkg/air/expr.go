@@ -1,6 +1,10 @@
 package air
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/trace"
@@ -33,6 +37,11 @@ type Expr interface {
 	// so, the constant is returned; otherwise, nil is returned.  NOTE: this
 	// does not perform any form of simplification to determine this.
 	AsConstant() *fr.Element
+
+	// Degree returns the degree of the polynomial represented by this
+	// expression.  For example, a column access has degree 1, whilst the
+	// product of two column accesses has degree 2.
+	Degree() uint
 }
 
 // ============================================================================
@@ -82,6 +91,10 @@ func (p *Add) Equate(other Expr) Expr { return &Sub{Args: []Expr{p, other}} }
 // direction (right).
 func (p *Add) Bounds() util.Bounds { return util.BoundsForArray(p.Args) }
 
+// Degree returns the degree of this sum, which is the maximum degree of any
+// term.
+func (p *Add) Degree() uint { return maxDegree(p.Args) }
+
 // AsConstant determines whether or not this is a constant expression.  If
 // so, the constant is returned; otherwise, nil is returned.  NOTE: this
 // does not perform any form of simplification to determine this.
@@ -134,6 +147,10 @@ func (p *Sub) Equate(other Expr) Expr { return &Sub{Args: []Expr{p, other}} }
 // direction (right).
 func (p *Sub) Bounds() util.Bounds { return util.BoundsForArray(p.Args) }
 
+// Degree returns the degree of this subtraction, which is the maximum degree
+// of any term.
+func (p *Sub) Degree() uint { return maxDegree(p.Args) }
+
 // AsConstant determines whether or not this is a constant expression.  If
 // so, the constant is returned; otherwise, nil is returned.  NOTE: this
 // does not perform any form of simplification to determine this.
@@ -186,6 +203,18 @@ func (p *Mul) Equate(other Expr) Expr { return &Sub{Args: []Expr{p, other}} }
 // direction (right).
 func (p *Mul) Bounds() util.Bounds { return util.BoundsForArray(p.Args) }
 
+// Degree returns the degree of this product, which is the sum of the degrees
+// of its factors.
+func (p *Mul) Degree() uint {
+	degree := uint(0)
+	//
+	for _, arg := range p.Args {
+		degree += arg.Degree()
+	}
+	//
+	return degree
+}
+
 // AsConstant determines whether or not this is a constant expression.  If
 // so, the constant is returned; otherwise, nil is returned.  NOTE: this
 // does not perform any form of simplification to determine this.
@@ -245,6 +274,9 @@ func (p *Constant) Equate(other Expr) Expr { return &Sub{Args: []Expr{p, other}}
 // direction (right).  A constant has zero shift.
 func (p *Constant) Bounds() util.Bounds { return util.EMPTY_BOUND }
 
+// Degree returns the degree of this constant, which is always 0.
+func (p *Constant) Degree() uint { return 0 }
+
 // AsConstant determines whether or not this is a constant expression.  If
 // so, the constant is returned; otherwise, nil is returned.  NOTE: this
 // does not perform any form of simplification to determine this.
@@ -320,3 +352,57 @@ func (p *ColumnAccess) Bounds() util.Bounds {
 // so, the constant is returned; otherwise, nil is returned.  NOTE: this
 // does not perform any form of simplification to determine this.
 func (p *ColumnAccess) AsConstant() *fr.Element { return nil }
+
+// Degree returns the degree of this column access, which is always 1.
+func (p *ColumnAccess) Degree() uint { return 1 }
+
+// Determine the maximum degree of any expression in a given array.
+func maxDegree(exprs []Expr) uint {
+	degree := uint(0)
+	//
+	for _, e := range exprs {
+		degree = max(degree, e.Degree())
+	}
+	//
+	return degree
+}
+
+// ============================================================================
+// Canonical Keys
+// ============================================================================
+
+// CanonicalKey returns a key which identifies a given expression up to the
+// ordering of operands for commutative operators (i.e. addition and
+// multiplication).  Thus, two expressions with the same key always evaluate to
+// the same value.  Unlike the Lisp representation of an expression, columns are
+// identified by index (rather than name) and, hence, keys are unique.
+func CanonicalKey(e Expr) string {
+	switch e := e.(type) {
+	case *Add:
+		return canonicalNaryKey("+", true, e.Args)
+	case *Sub:
+		return canonicalNaryKey("-", false, e.Args)
+	case *Mul:
+		return canonicalNaryKey("*", true, e.Args)
+	case *Constant:
+		return e.Value.String()
+	case *ColumnAccess:
+		return fmt.Sprintf("#%d@%d", e.Column, e.Shift)
+	}
+	// Should be unreachable
+	panic(fmt.Sprintf("unknown expression: %v", e))
+}
+
+func canonicalNaryKey(op string, commutative bool, args []Expr) string {
+	keys := make([]string, len(args))
+	//
+	for i, arg := range args {
+		keys[i] = CanonicalKey(arg)
+	}
+	// Operand order is irrelevant for commutative operators
+	if commutative {
+		slices.Sort(keys)
+	}
+	//
+	return fmt.Sprintf("(%s %s)", op, strings.Join(keys, " "))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/air"
+	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/schema/assignment"
 )
 
@@ -30,30 +31,55 @@ func ApplyBinaryGadget(col uint, schema *air.Schema) {
 // number of bits.  This is implemented using a *byte decomposition* which adds
 // n columns and a vanishing constraint (where n*8 >= nbits).
 func ApplyBitwidthGadget(col uint, nbits uint, schema *air.Schema) {
-	if nbits%8 != 0 {
-		panic("asymmetric bitwidth constraints not yet supported")
-	} else if nbits == 0 {
-		panic("zero bitwidth constraint encountered")
+	name := schema.Columns().Nth(col).Name()
+	//
+	applyByteDecomposition(name, fmt.Sprintf("%s:u%d", name, nbits), col, nbits, schema)
+}
+
+// BitDecompositionOf returns the index of the first bit column of an existing
+// decomposition of a given column into a given number of bits (if one exists).
+// Observe that such a decomposition ensures all values in the column fit within
+// that number of bits.
+func BitDecompositionOf(col uint, nbits uint, schema *air.Schema) (uint, bool) {
+	column := schema.Columns().Nth(col)
+	//
+	return sc.ColumnIndexOf(schema, column.Context().Module(), fmt.Sprintf("%s:u%d:0", column.Name(), nbits))
+}
+
+// ApplyBitDecompositionGadget decomposes all values in a given column into
+// their individual bits, returning the index of the first (i.e. least
+// significant) bit column.  This adds n binary columns and a vanishing
+// constraint ensuring they recompose to the original value which, as a side
+// effect, also ensures all values in the column fit within n bits.  Since this
+// is relatively expensive, a decomposition is shared between all uses of the
+// same column at the same bitwidth.
+func ApplyBitDecompositionGadget(col uint, nbits uint, schema *air.Schema) uint {
+	if nbits == 0 {
+		panic("zero bit decomposition encountered")
 	}
 	// Identify target column
 	column := schema.Columns().Nth(col)
-	// Calculate how many bytes required.
-	n := nbits / 8
-	es := make([]air.Expr, n)
-	fr256 := fr.NewElement(256)
-	name := column.Name()
-	coefficient := fr.NewElement(1)
+	// Determine prefix for bit columns
+	prefix := fmt.Sprintf("%s:u%d", column.Name(), nbits)
+	// Check whether decomposition already exists
+	if index, ok := BitDecompositionOf(col, nbits, schema); ok {
+		return index
+	}
 	// Add decomposition assignment
 	index := schema.AddAssignment(
-		assignment.NewByteDecomposition(name, column.Context(), col, n))
+		assignment.NewBitDecomposition(prefix, column.Context(), col, nbits))
+	//
+	es := make([]air.Expr, nbits)
+	fr2 := fr.NewElement(2)
+	coefficient := fr.NewElement(1)
 	// Construct Columns
-	for i := uint(0); i < n; i++ {
+	for i := uint(0); i < nbits; i++ {
 		// Create Column + Constraint
 		es[i] = air.NewColumnAccess(index+i, 0).Mul(air.NewConst(coefficient))
 
-		schema.AddRangeConstraint(index+i, fr256)
+		ApplyBinaryGadget(index+i, schema)
 		// Update coefficient
-		coefficient.Mul(&coefficient, &fr256)
+		coefficient.Mul(&coefficient, &fr2)
 	}
 	// Construct (X:0 * 1) + ... + (X:n * 2^n)
 	sum := &air.Add{Args: es}
@@ -61,5 +87,7 @@ func ApplyBitwidthGadget(col uint, nbits uint, schema *air.Schema) {
 	X := air.NewColumnAccess(col, 0)
 	eq := X.Equate(sum)
 	// Construct column name
-	schema.AddVanishingConstraint(fmt.Sprintf("%s:u%d", name, nbits), column.Context(), nil, eq)
+	schema.AddVanishingConstraint(fmt.Sprintf("%s:bits", prefix), column.Context(), nil, eq)
+	//
+	return index
 }
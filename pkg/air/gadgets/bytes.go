@@ -0,0 +1,191 @@
+package gadgets
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// BYTE_AND_MODULE is the name of the module holding the table of byte
+// conjunctions (see ApplyByteAndTableGadget).  Observe this cannot clash with a
+// module declared in a source file.
+const BYTE_AND_MODULE = ":band"
+
+// ByteDecompositionOf returns the index of the first byte column of an existing
+// decomposition of a given column for a given number of bits (if one exists).
+// Observe that such a decomposition ensures all values in the column fit within
+// that number of bits.
+func ByteDecompositionOf(col uint, nbits uint, schema *air.Schema) (uint, bool) {
+	column := schema.Columns().Nth(col)
+	//
+	return sc.ColumnIndexOf(schema, column.Context().Module(), fmt.Sprintf("%s:u%d:0", column.Name(), nbits))
+}
+
+// ApplyByteDecompositionGadget decomposes all values in a given column into
+// their bytes, returning the index of the first (i.e. least significant) byte
+// column.  This adds n columns, each of which is range constrained, and a
+// vanishing constraint ensuring they recompose to the original value (where
+// n*8 >= nbits).  When nbits is not a multiple of eight, the most significant
+// byte is further restricted such that, overall, all values in the column fit
+// within nbits.  A decomposition is shared between all uses of the same column
+// at the same bitwidth.
+func ApplyByteDecompositionGadget(col uint, nbits uint, schema *air.Schema) uint {
+	// Check whether decomposition already exists
+	if index, ok := ByteDecompositionOf(col, nbits, schema); ok {
+		return index
+	}
+	//
+	name := fmt.Sprintf("%s:u%d", schema.Columns().Nth(col).Name(), nbits)
+	//
+	return applyByteDecomposition(name, name, col, nbits, schema)
+}
+
+// Decompose all values in a given column into bytes, where the byte columns
+// are named using a given prefix, and the recomposition constraint using a
+// given handle.
+func applyByteDecomposition(prefix string, handle string, col uint, nbits uint, schema *air.Schema) uint {
+	if nbits == 0 {
+		panic("zero bitwidth constraint encountered")
+	}
+	// Identify target column
+	column := schema.Columns().Nth(col)
+	// Calculate how many bytes required.
+	n := (nbits + 7) / 8
+	es := make([]air.Expr, n)
+	fr256 := fr.NewElement(256)
+	coefficient := fr.NewElement(1)
+	// Add decomposition assignment
+	index := schema.AddAssignment(
+		assignment.NewByteDecomposition(prefix, column.Context(), col, n))
+	// Construct Columns
+	for i := uint(0); i < n; i++ {
+		bound := fr256
+		// Restrict most significant byte (if applicable)
+		if i == n-1 && nbits%8 != 0 {
+			bound = fr.NewElement(1 << (nbits % 8))
+		}
+		// Create Column + Constraint
+		es[i] = air.NewColumnAccess(index+i, 0).Mul(air.NewConst(coefficient))
+
+		schema.AddRangeConstraint(index+i, bound)
+		// Update coefficient
+		coefficient.Mul(&coefficient, &fr256)
+	}
+	// Construct (X:0 * 1) + ... + (X:n * 2^n)
+	sum := &air.Add{Args: es}
+	// Construct X == (X:0 * 1) + ... + (X:n * 2^n)
+	X := air.NewColumnAccess(col, 0)
+	eq := X.Equate(sum)
+	// Construct column name
+	schema.AddVanishingConstraint(handle, column.Context(), nil, eq)
+	//
+	return index
+}
+
+// ApplyByteAndGadget adds computed columns holding the bitwise conjunction of
+// two columns, whose values must fit within a given number of bits.  Both
+// columns are decomposed into bytes (see ApplyByteDecompositionGadget), and the
+// conjunction of each pair of bytes is then held in a computed column.  The
+// index of the first (i.e. least significant) byte of the result is returned.
+// Observe that the result bytes are not constrained here; rather, they are
+// constrained by a table of byte conjunctions, which can only be added once all
+// conjunctions are known (see ApplyByteAndTableGadget).
+func ApplyByteAndGadget(lhs uint, rhs uint, nbits uint, schema *air.Schema) uint {
+	lhsColumn := schema.Columns().Nth(lhs)
+	rhsColumn := schema.Columns().Nth(rhs)
+	context := lhsColumn.Context()
+	// Determine prefix for result columns
+	prefix := fmt.Sprintf("%s&%s:u%d", lhsColumn.Name(), rhsColumn.Name(), nbits)
+	// Check whether conjunction already exists
+	if index, ok := sc.ColumnIndexOf(schema, context.Module(), prefix+":0"); ok {
+		return index
+	}
+	// Decompose operands
+	lhsBytes := byteColumns(ApplyByteDecompositionGadget(lhs, nbits, schema), nbits)
+	rhsBytes := byteColumns(ApplyByteDecompositionGadget(rhs, nbits, schema), nbits)
+	// Add conjunction assignment
+	return schema.AddAssignment(assignment.NewByteAnd(prefix, context, lhsBytes, rhsBytes))
+}
+
+// Determine the byte columns of a byte decomposition for a given number of
+// bits.
+func byteColumns(index uint, nbits uint) []uint {
+	columns := make([]uint, (nbits+7)/8)
+	for i := range columns {
+		columns[i] = index + uint(i)
+	}
+	//
+	return columns
+}
+
+// ApplyByteAndTableGadget constrains the results of every byte conjunction in
+// a given schema (see ApplyByteAndGadget), by looking up each triple of bytes in
+// a table of byte conjunctions.  This table is held in a dedicated module and
+// contains only those pairs of bytes actually conjoined in a given trace.
+// Nevertheless, every row of the table is itself constrained to hold a valid
+// conjunction, by decomposing its bytes into bits.  Since the table depends
+// upon every conjunction, it must be added only once lowering is otherwise
+// complete.  Lookups into the table are log-derivative lookups (if requested).
+func ApplyByteAndTableGadget(logDerivative bool, schema *air.Schema) {
+	var (
+		conjunctions []*assignment.ByteAnd
+		indices      []uint
+		lhs, rhs     []uint
+		index        = schema.InputColumns().Count()
+	)
+	// Identify all conjunctions
+	for iter := schema.Assignments(); iter.HasNext(); {
+		ith := iter.Next()
+		//
+		if c, ok := ith.(*assignment.ByteAnd); ok {
+			conjunctions = append(conjunctions, c)
+			indices = append(indices, index)
+			lhs = append(lhs, c.Lhs()...)
+			rhs = append(rhs, c.Rhs()...)
+		}
+		//
+		index += ith.Columns().Count()
+	}
+	// Check whether table is required
+	if len(conjunctions) == 0 {
+		return
+	}
+	// Add table
+	context := trace.NewContext(schema.AddModule(BYTE_AND_MODULE), 1)
+	table := schema.AddAssignment(assignment.NewByteAndTable(context, lhs, rhs))
+	targets := []uint{table, table + 1, table + 2}
+	// Constrain table, such that and == (lhs:0 * rhs:0 * 1) + ... + (lhs:7 *
+	// rhs:7 * 2^7).
+	lhsBits := ApplyBitDecompositionGadget(table, 8, schema)
+	rhsBits := ApplyBitDecompositionGadget(table+1, 8, schema)
+	es := make([]air.Expr, 8)
+	//
+	for i := uint(0); i < 8; i++ {
+		bit := air.NewColumnAccess(lhsBits+i, 0).Mul(air.NewColumnAccess(rhsBits+i, 0))
+		es[i] = bit.Mul(air.NewConst64(1 << i))
+	}
+	//
+	schema.AddVanishingConstraint("and", context, nil, air.NewColumnAccess(table+2, 0).Equate(&air.Add{Args: es}))
+	// Lookup every triple of bytes in the table
+	none := util.None[uint]()
+	//
+	for i, c := range conjunctions {
+		for j := range c.Lhs() {
+			handle := schema.Columns().Nth(indices[i] + uint(j)).Name()
+			sources := []uint{c.Lhs()[j], c.Rhs()[j], indices[i] + uint(j)}
+			//
+			if logDerivative {
+				multiplicity := ApplyMultiplicityGadget(handle, context, sources, targets, none, none, schema)
+				schema.AddLogDerivativeLookupConstraint(handle, c.Context(), context, sources, targets, none, none,
+					multiplicity)
+			} else {
+				schema.AddLookupConstraint(handle, c.Context(), context, sources, targets, none, none)
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/consensys/go-corset/pkg/air"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // ApplyColumnSortGadget adds sorting constraints for a column where the
@@ -18,8 +19,9 @@ import (
 //
 // This gadget does not attempt to sort the column data during trace expansion,
 // and assumes the data either comes sorted or is sorted by some other
-// computation.
-func ApplyColumnSortGadget(col uint, sign bool, bitwidth uint, schema *air.Schema) {
+// computation.  When a selector column is given, sorting is only enforced on
+// rows whose preceding row is selected.
+func ApplyColumnSortGadget(col uint, sign bool, bitwidth uint, selector util.Option[uint], schema *air.Schema) {
 	var deltaName string
 	// Identify target column
 	column := schema.Columns().Nth(col)
@@ -37,12 +39,16 @@ func ApplyColumnSortGadget(col uint, sign bool, bitwidth uint, schema *air.Schem
 		Xdiff = Xkm1.Sub(Xk)
 		deltaName = fmt.Sprintf("-%s", name)
 	}
+	// Account for selector
+	if selector.HasValue() {
+		Xdiff = air.NewColumnAccess(selector.Unwrap(), -1).Mul(Xdiff)
+	}
 	// Look up column
 	deltaIndex, ok := sc.ColumnIndexOf(schema, column.Context().Module(), deltaName)
 	// Add new column (if it does not already exist)
 	if !ok {
 		deltaIndex = schema.AddAssignment(
-			assignment.NewComputedColumn(column.Context(), deltaName, Xdiff))
+			assignment.NewComputedColumn(column.Context(), deltaName, &sc.FieldType{}, Xdiff))
 	}
 	// Add necessary bitwidth constraints
 	ApplyBitwidthGadget(deltaIndex, bitwidth, schema)
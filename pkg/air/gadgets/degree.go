@@ -0,0 +1,192 @@
+package gadgets
+
+import (
+	"fmt"
+
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// ApplyDegreeBoundGadget ensures every vanishing constraint from a given index
+// onwards has degree at most maxDegree.  This is done by replacing factors
+// within high-degree products with computed columns (see Expand), each of which
+// holds the value of the factor it replaces.  Since each computed column is
+// itself defined by a constraint of degree at most maxDegree, the resulting
+// schema is equivalent to the original.  Observe that the maximum degree must
+// be at least two, since the product of two columns cannot be reduced.
+// Finally, in rare cases (see expandAnchored), reducing a constraint would
+// change the rows on which it is checked.  Such constraints cannot be bounded
+// soundly and, hence, an error is returned for the first one encountered.
+// Since this is determined before any computed columns are added, the schema is
+// left unchanged by a constraint which cannot be bounded.
+func ApplyDegreeBoundGadget(start uint, maxDegree uint, schema *air.Schema) error {
+	if maxDegree < 2 {
+		panic(fmt.Sprintf("invalid maximum degree (%d)", maxDegree))
+	}
+	// Determines the reduced expression, without adding any computed columns.
+	// This is possible since only the shifts of column accesses introduced by
+	// expandAnchored affect the rows on which a constraint is checked.
+	dryrun := degreeReducer{maxDegree, func(trace.Context, air.Expr) uint { return 0 }}
+	// Determines the reduced expression, adding computed columns as necessary.
+	reducer := degreeReducer{maxDegree, func(ctx trace.Context, e air.Expr) uint {
+		return Expand(ctx, e, schema)
+	}}
+	// NOTE: constraints added by Expand are within the bound by construction
+	// and, hence, are not revisited.
+	for i, n := start, schema.Constraints().Count(); i < n; i++ {
+		if vc, ok := schema.Constraints().Nth(i).(air.VanishingConstraint); ok {
+			expr := vc.Constraint().Expr
+			//
+			if expr.Degree() <= maxDegree {
+				continue
+			} else if dryrun.reduce(vc.Context(), expr).Bounds() != expr.Bounds() {
+				// Rows on which constraint is checked would change.
+				return fmt.Errorf("constraint %s cannot be bounded to degree %d", vc.Handle(), maxDegree)
+			}
+			//
+			schema.ReplaceVanishingConstraint(i, reducer.reduce(vc.Context(), expr))
+		}
+	}
+	//
+	return nil
+}
+
+// A degree reducer reduces the degree of expressions by replacing factors with
+// accesses to columns holding their values.
+type degreeReducer struct {
+	maxDegree uint
+	// Determines the column holding the value of a given expression.
+	expand func(trace.Context, air.Expr) uint
+}
+
+// Reduce the degree of a given expression to at most maxDegree, introducing
+// computed columns as necessary.
+func (p *degreeReducer) reduce(ctx trace.Context, e air.Expr) air.Expr {
+	if e.Degree() <= p.maxDegree {
+		return e
+	}
+	//
+	switch e := e.(type) {
+	case *air.Add:
+		return &air.Add{Args: p.reduceAll(ctx, e.Args)}
+	case *air.Sub:
+		return &air.Sub{Args: p.reduceAll(ctx, e.Args)}
+	case *air.Mul:
+		return p.reduceProduct(ctx, p.reduceAll(ctx, e.Args))
+	}
+	// Should be unreachable, since constants and column accesses have degree
+	// at most 1.
+	panic(fmt.Sprintf("unknown expression: %v", e))
+}
+
+func (p *degreeReducer) reduceAll(ctx trace.Context, exprs []air.Expr) []air.Expr {
+	nexprs := make([]air.Expr, len(exprs))
+	//
+	for i, e := range exprs {
+		nexprs[i] = p.reduce(ctx, e)
+	}
+	//
+	return nexprs
+}
+
+// Reduce the degree of a product whose factors each have degree at most
+// maxDegree.  Whilst the product exceeds the bound, the factor of highest
+// degree is replaced by a computed column.  When every factor has degree at
+// most one, the leading factors are instead grouped together into a single
+// computed column.  Either way, the degree of the product strictly decreases.
+func (p *degreeReducer) reduceProduct(ctx trace.Context, factors []air.Expr) air.Expr {
+	for degree := (&air.Mul{Args: factors}).Degree(); degree > p.maxDegree; {
+		largest := 0
+		// Identify factor of highest degree
+		for i, f := range factors {
+			if f.Degree() > factors[largest].Degree() {
+				largest = i
+			}
+		}
+		//
+		if d := factors[largest].Degree(); d > 1 {
+			factors[largest] = p.expandAnchored(ctx, factors[largest])
+			degree = degree - d + 1
+		} else {
+			// Group leading factors into a product of degree maxDegree.
+			group, rest := splitFactors(factors, p.maxDegree)
+			factors = append([]air.Expr{p.expandAnchored(ctx, &air.Mul{Args: group})}, rest...)
+			degree = degree - p.maxDegree + 1
+		}
+	}
+	//
+	return &air.Mul{Args: factors}
+}
+
+// Expand a given expression into a computed column, returning an access to
+// that column which is equivalent to the original expression.  Care is needed
+// here, since the bounds of a vanishing constraint determine the rows on which
+// it is checked.  Thus, simply accessing the computed column on the current row
+// could cause the constraint to be checked on rows where it previously was
+// not.  To avoid this, the computed column holds the expression's value at an
+// offset row, and is accessed with the corresponding shift.  Specifically,
+// the access retains the expression's bound on one side (the positive side
+// if it has one), and its value is unaffected.
+func (p *degreeReducer) expandAnchored(ctx trace.Context, e air.Expr) air.Expr {
+	var (
+		bounds = e.Bounds()
+		shift  = int(bounds.End)
+	)
+	//
+	if bounds.End == 0 {
+		shift = -int(bounds.Start)
+	}
+	//
+	index := p.expand(ctx, shiftExpr(e, -shift))
+	//
+	return air.NewColumnAccess(index, shift)
+}
+
+// Shift all column accesses within a given expression by a given amount.
+func shiftExpr(e air.Expr, shift int) air.Expr {
+	switch e := e.(type) {
+	case *air.Add:
+		return &air.Add{Args: shiftExprs(e.Args, shift)}
+	case *air.Sub:
+		return &air.Sub{Args: shiftExprs(e.Args, shift)}
+	case *air.Mul:
+		return &air.Mul{Args: shiftExprs(e.Args, shift)}
+	case *air.Constant:
+		return e
+	case *air.ColumnAccess:
+		return air.NewColumnAccess(e.Column, e.Shift+shift)
+	}
+	// Should be unreachable
+	panic(fmt.Sprintf("unknown expression: %v", e))
+}
+
+func shiftExprs(exprs []air.Expr, shift int) []air.Expr {
+	nexprs := make([]air.Expr, len(exprs))
+	//
+	for i, e := range exprs {
+		nexprs[i] = shiftExpr(e, shift)
+	}
+	//
+	return nexprs
+}
+
+// Split a given set of factors, each of degree at most one, into a leading
+// group of degree exactly maxDegree and the remainder.
+func splitFactors(factors []air.Expr, maxDegree uint) ([]air.Expr, []air.Expr) {
+	var (
+		group  []air.Expr
+		rest   []air.Expr
+		degree uint
+	)
+	//
+	for _, f := range factors {
+		if degree+f.Degree() <= maxDegree {
+			group = append(group, f)
+			degree += f.Degree()
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	//
+	return group, rest
+}
@@ -0,0 +1,96 @@
+package gadgets
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/sexp"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ApplyDomainSelectorGadget constructs an expression which evaluates to one on
+// every row of a given domain, and zero otherwise.  This allows a constraint
+// restricted to that domain to be enforced as a global constraint, by
+// multiplying it with the selector.  This is done by adding a new computed
+// column to hold the selector.  Since the selector depends only on the height
+// of the enclosing module, it is a fixed column whose values are determined by
+// the verifier, rather than the prover.  As such, no constraints are added to
+// enforce its values and, instead, it is exported as a fixed column (see
+// export.Column).
+func ApplyDomainSelectorGadget(ctx tr.Context, domain *constraint.Domain, schema *air.Schema) air.Expr {
+	// Sanity check
+	if ctx.IsVoid() || ctx.IsConflicted() {
+		panic("conflicting (or void) context")
+	}
+	// Construct selector computation
+	selector := &DomainSelector{ctx, domain}
+	// Determine computed column name
+	name := selector.Lisp(schema).String(false)
+	// Look up column
+	index, ok := sc.ColumnIndexOf(schema, ctx.Module(), name)
+	// Add new column (if it does not already exist)
+	if !ok {
+		index = schema.AddAssignment(assignment.NewComputedColumn(ctx, name, &sc.FieldType{}, selector))
+	}
+	// Done
+	return air.NewColumnAccess(index, 0)
+}
+
+// DomainSelector represents a computation which determines whether or not a
+// given row is in a given domain.
+type DomainSelector struct {
+	// Evaluation context which determines the height of the domain.
+	context tr.Context
+	// Domain being selected.
+	domain *constraint.Domain
+}
+
+// Domain returns the domain being selected.
+func (e *DomainSelector) Domain() *constraint.Domain {
+	return e.domain
+}
+
+// EvalAt determines whether or not a given row is in the domain, returning one
+// if it is and zero otherwise.  Rows outside the trace (e.g. for padding) are
+// never in the domain.
+func (e *DomainSelector) EvalAt(k int, trace tr.Trace) fr.Element {
+	if k >= 0 && e.domain.Contains(uint(k), trace.Height(e.context)) {
+		return fr.One()
+	}
+	//
+	return fr.NewElement(0)
+}
+
+// Bounds returns max shift in either the negative (left) or positive
+// direction (right).  In this case, there are no shifts.
+func (e *DomainSelector) Bounds() util.Bounds { return util.EMPTY_BOUND }
+
+// Context determines the evaluation context (i.e. enclosing module) for this
+// expression.
+func (e *DomainSelector) Context(schema sc.Schema) tr.Context {
+	return e.context
+}
+
+// RequiredColumns returns the set of columns on which this term depends.
+// In this case, that is the empty set.
+func (e *DomainSelector) RequiredColumns() *util.SortedSet[uint] {
+	return util.NewSortedSet[uint]()
+}
+
+// RequiredCells returns the set of trace cells on which this term depends.
+// In this case, that is the empty set.
+func (e *DomainSelector) RequiredCells(row int, trace tr.Trace) *util.AnySortedSet[tr.CellRef] {
+	return util.NewAnySortedSet[tr.CellRef]()
+}
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (e *DomainSelector) Lisp(schema sc.Schema) sexp.SExp {
+	return sexp.NewList([]sexp.SExp{
+		sexp.NewSymbol("domain"),
+		sexp.NewSymbol(e.domain.String()),
+	})
+}
@@ -8,6 +8,7 @@ import (
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/schema/assignment"
 	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // ApplyLexicographicSortingGadget Add sorting constraints for a sequence of one
@@ -26,8 +27,11 @@ import (
 // Finally, a delta column is used in a similar fashion as for the single column
 // case (see above).  The delta value captures the difference Ci[k]-Ci[k-1] to
 // ensure it is positive.  The delta column is constrained to a given bitwidth,
-// with constraints added as necessary to ensure this.
-func ApplyLexicographicSortingGadget(columns []uint, signs []bool, bitwidth uint, schema *air.Schema) {
+// with constraints added as necessary to ensure this.  When a selector column
+// is given, sorting is only enforced on rows whose preceding row is selected.
+// In such case, the selector bits and delta are all zero on other rows.
+func ApplyLexicographicSortingGadget(columns []uint, signs []bool, bitwidth uint, selector util.Option[uint],
+	schema *air.Schema) {
 	ncols := len(columns)
 	// Check preconditions
 	if ncols != len(signs) {
@@ -39,11 +43,11 @@ func ApplyLexicographicSortingGadget(columns []uint, signs []bool, bitwidth uint
 	prefix := constructLexicographicSortingPrefix(columns, signs, schema)
 	// Add trace computation
 	deltaIndex := schema.AddAssignment(
-		assignment.NewLexicographicSort(prefix, ctx, columns, signs, bitwidth))
+		assignment.NewLexicographicSort(prefix, ctx, columns, signs, bitwidth, selector))
 	// Construct selecto bits.
-	addLexicographicSelectorBits(prefix, ctx, deltaIndex, columns, schema)
+	addLexicographicSelectorBits(prefix, ctx, deltaIndex, columns, selector, schema)
 	// Construct delta terms
-	constraint := constructLexicographicDeltaConstraint(deltaIndex, columns, signs)
+	constraint := guardBySelector(selector, constructLexicographicDeltaConstraint(deltaIndex, columns, signs))
 	// Add delta constraint
 	deltaName := fmt.Sprintf("%s:delta", prefix)
 	schema.AddVanishingConstraint(deltaName, ctx, nil, constraint)
@@ -79,7 +83,7 @@ func constructLexicographicSortingPrefix(columns []uint, signs []bool, schema *a
 // NOTE: this implementation differs from the original corset which used an
 // additional "Eq" bit to help ensure at most one selector bit was enabled.
 func addLexicographicSelectorBits(prefix string, context trace.Context,
-	deltaIndex uint, columns []uint, schema *air.Schema) {
+	deltaIndex uint, columns []uint, selector util.Option[uint], schema *air.Schema) {
 	ncols := uint(len(columns))
 	// Calculate column index of first selector bit
 	bitIndex := deltaIndex + 1
@@ -104,7 +108,7 @@ func addLexicographicSelectorBits(prefix string, context trace.Context,
 		pDiff := air.NewColumnAccess(columns[i], 0).Sub(air.NewColumnAccess(columns[i], -1))
 		pName := fmt.Sprintf("%s:%d:a", prefix, i)
 		schema.AddVanishingConstraint(pName, context,
-			nil, air.NewConst64(1).Sub(&air.Add{Args: pterms}).Mul(pDiff))
+			nil, guardBySelector(selector, air.NewConst64(1).Sub(&air.Add{Args: pterms}).Mul(pDiff)))
 		// (∀j<i.Bj=0) ∧ Bi=1 ==> C[k]≠C[k-1]
 		qDiff := Normalise(air.NewColumnAccess(columns[i], 0).Sub(air.NewColumnAccess(columns[i], -1)), schema)
 		qName := fmt.Sprintf("%s:%d:b", prefix, i)
@@ -126,6 +130,16 @@ func addLexicographicSelectorBits(prefix string, context trace.Context,
 	schema.AddVanishingConstraint(name, context, nil, constraint)
 }
 
+// Guard a given constraint such that it only applies on rows whose preceding
+// row is selected by a given selector column (if present).
+func guardBySelector(selector util.Option[uint], constraint air.Expr) air.Expr {
+	if selector.HasValue() {
+		return air.NewColumnAccess(selector.Unwrap(), -1).Mul(constraint)
+	}
+	//
+	return constraint
+}
+
 // Construct the lexicographic delta constraint.  This states that the delta
 // column either holds 0 or the difference Ci[k] - Ci[k-1] (adjusted
 // appropriately for the sign) between the ith column whose multiplexor bit is
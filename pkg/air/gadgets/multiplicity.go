@@ -0,0 +1,38 @@
+package gadgets
+
+import (
+	"fmt"
+
+	"github.com/consensys/go-corset/pkg/air"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ApplyMultiplicityGadget adds a new computed column to the target module of a
+// given lookup, which holds the number of (selected) source rows matching each
+// (selected) target row.  No constraints are added to enforce its values, since
+// these are enforced by the log-derivative lookup which uses it.  The index of
+// the multiplicity column is returned.
+func ApplyMultiplicityGadget(handle string, target trace.Context, sources []uint, targets []uint,
+	sourceSelector util.Option[uint], targetSelector util.Option[uint], schema *air.Schema) uint {
+	// Sanity check
+	if target.IsVoid() || target.IsConflicted() {
+		panic("conflicting (or void) context")
+	}
+	// Determine a fresh name for the multiplicity column, since distinct
+	// lookups into the same module need not have distinct handles.
+	name := fmt.Sprintf("(multiplicity %s)", handle)
+	//
+	for i := 1; ; i++ {
+		if _, ok := sc.ColumnIndexOf(schema, target.Module(), name); !ok {
+			break
+		}
+		//
+		name = fmt.Sprintf("(multiplicity %s#%d)", handle, i)
+	}
+	// Add new column
+	return schema.AddAssignment(assignment.NewLookupMultiplicity(target, name, sources, targets, sourceSelector,
+		targetSelector))
+}
@@ -27,7 +27,9 @@ func Normalise(e air.Expr, schema *air.Schema) air.Expr {
 // (pseudo) multiplicative inverse of another expression.  Since this cannot be computed
 // directly using arithmetic constraints, it is done by adding a new computed
 // column which holds the multiplicative inverse.  Constraints are also added to
-// ensure it really holds the inverted value.
+// ensure it really holds the inverted value.  Inverse columns are interned by
+// their context and (canonical) expression, such that normalising the same
+// expression again (e.g. with operands reordered) reuses the existing column.
 func ApplyPseudoInverseGadget(e air.Expr, schema *air.Schema) air.Expr {
 	// Determine enclosing module.
 	ctx := e.Context(schema)
@@ -39,12 +41,18 @@ func ApplyPseudoInverseGadget(e air.Expr, schema *air.Schema) air.Expr {
 	ie := &Inverse{Expr: e}
 	// Determine computed column name
 	name := ie.Lisp(schema).String(false)
+	// Determine interning key
+	key := fmt.Sprintf("inv:%d:%d:%s", ctx.Module(), ctx.LengthMultiplier(), air.CanonicalKey(e))
+	// Check whether an equivalent inverse already exists
+	if index, ok := schema.InternedColumn(key, name); ok {
+		return air.NewColumnAccess(index, 0)
+	}
 	// Look up column
 	index, ok := sc.ColumnIndexOf(schema, ctx.Module(), name)
 	// Add new column (if it does not already exist)
 	if !ok {
 		// Add computed column
-		index = schema.AddAssignment(assignment.NewComputedColumn(ctx, name, ie))
+		index = schema.AddAssignment(assignment.NewComputedColumn(ctx, name, &sc.FieldType{}, ie))
 		// Construct 1/e
 		inv_e := air.NewColumnAccess(index, 0)
 		// Construct e/e
@@ -62,6 +70,8 @@ func ApplyPseudoInverseGadget(e air.Expr, schema *air.Schema) air.Expr {
 		r_name := fmt.Sprintf("%s =>", name)
 		schema.AddVanishingConstraint(r_name, ctx, nil, inv_e_implies_one_e_e)
 	}
+	// Record column for reuse
+	schema.InternColumn(key, index)
 	// Done
 	return air.NewColumnAccess(index, 0)
 }
@@ -19,6 +19,12 @@ type DataColumn = *assignment.DataColumn
 // columns (i.e. not arbitrary expressions).
 type LookupConstraint = *constraint.LookupConstraint[*ColumnAccess]
 
+// LogDerivativeLookupConstraint captures the essence of a lookup constraint
+// which is proven using a log-derivative argument.  As for lookup constraints,
+// these are only permitted between columns at the AIR level, and likewise for
+// the multiplicity.
+type LogDerivativeLookupConstraint = *constraint.LogDerivativeLookupConstraint[*ColumnAccess]
+
 // VanishingConstraint captures the essence of a vanishing constraint at the AIR level.
 type VanishingConstraint = *constraint.VanishingConstraint[constraint.ZeroTest[Expr]]
 
@@ -52,6 +58,13 @@ type Schema struct {
 	assertions []PropertyAssertion
 	// Cache list of columns declared in inputs and assignments.
 	column_cache []schema.Column
+	// Computed columns introduced by gadgets, indexed by a canonical key of
+	// their context and computation.  This allows gadgets to share columns
+	// which hold identical values, rather than introducing fresh ones.
+	interned map[string]uint
+	// Number of times an interned column was reused, rather than introducing
+	// a fresh column.
+	reused uint
 }
 
 // EmptySchema is used to construct a fresh schema onto which new columns and
@@ -64,6 +77,7 @@ func EmptySchema[C schema.Evaluable]() *Schema {
 	p.constraints = make([]schema.Constraint, 0)
 	p.assertions = make([]PropertyAssertion, 0)
 	p.column_cache = make([]schema.Column, 0)
+	p.interned = make(map[string]uint)
 	// Done
 	return p
 }
@@ -109,9 +123,37 @@ func (p *Schema) AddAssignment(c schema.Assignment) uint {
 	return index
 }
 
+// InternedColumn returns the computed column previously interned under a given
+// key (if one exists), where name is that of the column which would otherwise
+// be introduced.  A successful lookup is counted as a column saved only when
+// the interned column has a different name, since a column with the same name
+// would be reused anyway.
+func (p *Schema) InternedColumn(key string, name string) (uint, bool) {
+	index, ok := p.interned[key]
+	//
+	if ok && p.Columns().Nth(index).Name() != name {
+		p.reused++
+	}
+	//
+	return index, ok
+}
+
+// InternColumn records a given computed column under a given key, such that it
+// can be reused subsequently (e.g. by a gadget requiring the same column).
+func (p *Schema) InternColumn(key string, index uint) {
+	p.interned[key] = index
+}
+
+// SavedColumns returns the number of computed columns saved by reusing
+// interned columns, rather than introducing fresh ones.
+func (p *Schema) SavedColumns() uint {
+	return p.reused
+}
+
 // AddLookupConstraint appends a new lookup constraint.
 func (p *Schema) AddLookupConstraint(handle string, source trace.Context,
-	target trace.Context, sources []uint, targets []uint) {
+	target trace.Context, sources []uint, targets []uint, sourceSelector util.Option[uint],
+	targetSelector util.Option[uint]) {
 	if len(targets) != len(sources) {
 		panic("differeng number of target / source lookup columns")
 	}
@@ -125,16 +167,53 @@ func (p *Schema) AddLookupConstraint(handle string, source trace.Context,
 		into[i] = NewColumnAccess(targets[i], 0)
 	}
 	// Construct lookup constraint
-	var lookup LookupConstraint = constraint.NewLookupConstraint(handle, source, target, from, into)
+	var lookup LookupConstraint = constraint.NewLookupConstraint(handle, source, target, from, into,
+		selectorAccess(sourceSelector), selectorAccess(targetSelector))
 	// Add
 	p.constraints = append(p.constraints, lookup)
 }
 
-// AddPermutationConstraint appends a new permutation constraint which
-// ensures that one column is a permutation of another.
-func (p *Schema) AddPermutationConstraint(targets []uint, sources []uint) {
-	// TODO: sanity target and source columns are in the same module.
-	p.constraints = append(p.constraints, constraint.NewPermutationConstraint(targets, sources))
+// AddLogDerivativeLookupConstraint appends a new lookup constraint which is
+// proven using a log-derivative argument, where a given column in the target
+// module holds the multiplicity of each target row.
+func (p *Schema) AddLogDerivativeLookupConstraint(handle string, source trace.Context,
+	target trace.Context, sources []uint, targets []uint, sourceSelector util.Option[uint],
+	targetSelector util.Option[uint], multiplicity uint) {
+	if len(targets) != len(sources) {
+		panic("differeng number of target / source lookup columns")
+	}
+	//
+	from := make([]*ColumnAccess, len(sources))
+	into := make([]*ColumnAccess, len(targets))
+	// Construct column accesses from column indices.
+	for i := 0; i < len(from); i++ {
+		from[i] = NewColumnAccess(sources[i], 0)
+		into[i] = NewColumnAccess(targets[i], 0)
+	}
+	// Construct lookup constraint
+	var lookup LogDerivativeLookupConstraint = constraint.NewLogDerivativeLookupConstraint(handle, source, target,
+		from, into, selectorAccess(sourceSelector), selectorAccess(targetSelector),
+		NewColumnAccess(multiplicity, 0))
+	// Add
+	p.constraints = append(p.constraints, lookup)
+}
+
+// Construct an access for an (optional) selector column.
+func selectorAccess(selector util.Option[uint]) util.Option[*ColumnAccess] {
+	if selector.HasValue() {
+		return util.Some(NewColumnAccess(selector.Unwrap(), 0))
+	}
+	//
+	return util.None[*ColumnAccess]()
+}
+
+// AddPermutationConstraint appends a new permutation constraint which ensures
+// that one (or more) columns are a permutation of another, where either side is
+// optionally guarded by a selector column.
+func (p *Schema) AddPermutationConstraint(targets []uint, sources []uint, sourceSelector util.Option[uint],
+	targetSelector util.Option[uint]) {
+	p.constraints = append(p.constraints, constraint.NewPermutationConstraint(targets, sources, sourceSelector,
+		targetSelector))
 }
 
 // AddPropertyAssertion appends a new property assertion.
@@ -143,7 +222,7 @@ func (p *Schema) AddPropertyAssertion(handle string, context trace.Context, asse
 }
 
 // AddVanishingConstraint appends a new vanishing constraint.
-func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, domain *int, expr Expr) {
+func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, domain *constraint.Domain, expr Expr) {
 	if context.Module() >= uint(len(p.modules)) {
 		panic(fmt.Sprintf("invalid module index (%d)", context.Module()))
 	}
@@ -152,6 +231,22 @@ func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, do
 		constraint.NewVanishingConstraint(handle, context, domain, constraint.ZeroTest[Expr]{Expr: expr}))
 }
 
+// ReplaceVanishingConstraint replaces the expression of the vanishing
+// constraint at a given index with an equivalent expression (e.g. one of lower
+// degree).  The handle, context, domain and source of the constraint are
+// retained.
+func (p *Schema) ReplaceVanishingConstraint(index uint, expr Expr) {
+	vc, ok := p.constraints[index].(VanishingConstraint)
+	if !ok {
+		panic(fmt.Sprintf("constraint %d is not a vanishing constraint", index))
+	}
+	//
+	test := constraint.ZeroTest[Expr]{Expr: expr}
+	nvc := constraint.NewVanishingConstraint(vc.Handle(), vc.Context(), vc.Domain(), test)
+	nvc.SetSource(vc.Source())
+	p.constraints[index] = nvc
+}
+
 // AddRangeConstraint appends a new range constraint.
 func (p *Schema) AddRangeConstraint(column uint, bound fr.Element) {
 	col := p.Columns().Nth(column)
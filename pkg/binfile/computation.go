@@ -1,7 +1,9 @@
 package binfile
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/consensys/go-corset/pkg/hir"
 	sc "github.com/consensys/go-corset/pkg/schema"
@@ -38,41 +40,71 @@ type jsonInterleavedComputation struct {
 // believe this could fail the presence of sorted permutations of sorted
 // permutations.  In such case, it can be resolved using a more complex
 // allocation algorithm which considers the source dependencies.
-func (e jsonComputationSet) addToSchema(columns []column, colmap map[uint]uint, schema *hir.Schema) {
+func (e jsonComputationSet) addToSchema(columns []column, colmap map[uint]uint, schema *hir.Schema) error {
+	var err error
 	// Determine first allocation index
 	index := schema.Columns().Count()
 	//
 	for _, c := range e.Computations {
 		if c.Sorted != nil {
-			index = addSortedComputation(c.Sorted, index, columns, colmap, schema)
+			index, err = addSortedComputation(c.Sorted, index, columns, colmap, schema)
 		} else if c.Interleaved != nil {
-			index = addInterleavedComputation(c.Interleaved, index, columns, colmap, schema)
+			index, err = addInterleavedComputation(c.Interleaved, index, columns, colmap, schema)
 		} else {
-			panic("unknown computation encountered")
+			err = errors.New("unknown computation encountered")
 		}
+		//
+		if err != nil {
+			return malformed(c.Handle(), err)
+		}
+	}
+	//
+	return nil
+}
+
+// Handle returns a suitable handle for this computation (e.g. for reporting
+// errors), as determined by the column(s) it defines.
+func (c jsonComputation) Handle() string {
+	if c.Sorted != nil {
+		return strings.Join(c.Sorted.Tos, ", ")
+	} else if c.Interleaved != nil {
+		return c.Interleaved.Target
 	}
+	//
+	return "unknown"
 }
 
 func addSortedComputation(sorted *jsonSortedComputation, index uint,
-	columns []column, colmap map[uint]uint, schema *hir.Schema) uint {
-	targetIDs := asColumns(sorted.Tos)
+	columns []column, colmap map[uint]uint, schema *hir.Schema) (uint, error) {
+	targetIDs, err := asColumns(sorted.Tos)
+	if err != nil {
+		return 0, err
+	}
 	// Convert source refs into column indexes
-	ctx, sources := sourceColumnsFromHandles(sorted.Froms, columns, colmap, schema)
+	ctx, sources, err := sourceColumnsFromHandles(sorted.Froms, columns, colmap, schema)
+	if err != nil {
+		return 0, err
+	}
 	// Sanity checks
 	if len(sources) != len(targetIDs) {
-		panic("differing number of source / target columns in sorted permutation")
+		return 0, errors.New("differing number of source / target columns in sorted permutation")
+	} else if len(sorted.Signs) != len(targetIDs) {
+		return 0, errors.New("differing number of signs / target columns in sorted permutation")
 	}
 	// Convert target refs into columns
 	targets := make([]sc.Column, len(targetIDs))
 	//
 	for i, target_id := range targetIDs {
+		var dst_hnd Handle
 		// Extract binfile info about target column
-		dst_col := columns[target_id]
-		dst_hnd := asHandle(dst_col.Handle)
+		if dst_hnd, err = columnHandle(target_id, columns); err != nil {
+			return 0, err
+		}
+		//
 		src_col := schema.Columns().Nth(sources[i])
 		// Sanity check source column type
 		if src_col.Type().AsUint() == nil {
-			panic(fmt.Sprintf("source column %s has field type", src_col.Name()))
+			return 0, fmt.Errorf("source column %s has field type", src_col.Name())
 		}
 
 		targets[i] = sc.NewColumn(ctx, dst_hnd.column, src_col.Type())
@@ -83,17 +115,26 @@ func addSortedComputation(sorted *jsonSortedComputation, index uint,
 	// Finally, add the sorted permutation assignment
 	schema.AddAssignment(assignment.NewSortedPermutation(ctx, targets, sorted.Signs, sources))
 	//
-	return index
+	return index, nil
 }
 
 func addInterleavedComputation(c *jsonInterleavedComputation, index uint,
-	columns []column, colmap map[uint]uint, schema *hir.Schema) uint {
+	columns []column, colmap map[uint]uint, schema *hir.Schema) (uint, error) {
 	// Convert column handles into column indices in the schema
-	ctx, sources := sourceColumnsFromHandles(c.Froms, columns, colmap, schema)
+	ctx, sources, err := sourceColumnsFromHandles(c.Froms, columns, colmap, schema)
+	if err != nil {
+		return 0, err
+	}
 	// Determine column handle
-	target_id := asColumn(c.Target)
-	dst_col := columns[target_id]
-	dst_hnd := asHandle(dst_col.Handle)
+	target_id, err := asColumn(c.Target)
+	if err != nil {
+		return 0, err
+	}
+	//
+	dst_hnd, err := columnHandle(target_id, columns)
+	if err != nil {
+		return 0, err
+	}
 	// Initially assume bottom type
 	var dst_type sc.Type = sc.NewUintType(0)
 	// Ensure each column's types included
@@ -109,50 +150,67 @@ func addInterleavedComputation(c *jsonInterleavedComputation, index uint,
 	// Update allocation information.
 	colmap[target_id] = index
 	//
-	return index + 1
+	return index + 1, nil
 }
 
 func sourceColumnsFromHandles(handles []string, columns []column,
-	colmap map[uint]uint, schema *hir.Schema) (trace.Context, []uint) {
-	sourceIDs := asColumns(handles)
-	handle := asHandle(columns[sourceIDs[0]].Handle)
+	colmap map[uint]uint, schema *hir.Schema) (trace.Context, []uint, error) {
+	ctx := trace.VoidContext[uint]()
+	//
+	sourceIDs, err := asColumns(handles)
+	if err != nil {
+		return ctx, nil, err
+	} else if len(sourceIDs) == 0 {
+		return ctx, nil, errors.New("no source columns")
+	}
+	//
+	handle, err := columnHandle(sourceIDs[0], columns)
+	if err != nil {
+		return ctx, nil, err
+	}
 	// Resolve enclosing module
 	mid, ok := schema.Modules().Find(func(m sc.Module) bool {
 		return m.Name() == handle.module
 	})
 	// Sanity check assumptions
 	if !ok {
-		panic(fmt.Sprintf("unknown module %s", handle.module))
+		return ctx, nil, fmt.Errorf("unknown module %s", handle.module)
 	}
 	// Convert source refs into column indexes
 	sources := make([]uint, len(sourceIDs))
 	//
-	ctx := trace.VoidContext[uint]()
-	//
 	for i, source_id := range sourceIDs {
 		// Determine schema column index for ith source column.
 		src_cid, ok := colmap[source_id]
 		if !ok {
-			h := asHandle(columns[source_id].Handle)
-			panic(fmt.Sprintf("unallocated source column %s.%s", h.module, h.column))
+			return ctx, nil, fmt.Errorf("unallocated source column %s", handles[i])
 		}
 		// Extract schema info about source column
 		src_col := schema.Columns().Nth(src_cid)
 		// Sanity check enclosing modules match
 		if src_col.Context().Module() != mid {
-			panic("inconsistent enclosing module for sorted permutation (source)")
+			return ctx, nil, errors.New("inconsistent enclosing module for sorted permutation (source)")
 		}
 
 		ctx = ctx.Join(src_col.Context())
 		// Sanity check we have a sensible type here.
 		if ctx.IsConflicted() {
-			panic(fmt.Sprintf("source column %s has conflicted evaluation context", src_col.Name()))
+			return ctx, nil, fmt.Errorf("source column %s has conflicted evaluation context", src_col.Name())
 		} else if ctx.IsVoid() {
-			panic(fmt.Sprintf("source column %s has void evaluation context", src_col.Name()))
+			return ctx, nil, fmt.Errorf("source column %s has void evaluation context", src_col.Name())
 		}
 
 		sources[i] = src_cid
 	}
 	//
-	return ctx, sources
+	return ctx, sources, nil
+}
+
+// Determine the handle of the binfile column with a given index.
+func columnHandle(id uint, columns []column) (Handle, error) {
+	if id >= uint(len(columns)) {
+		return Handle{}, fmt.Errorf("unknown column #%d", id)
+	}
+	//
+	return asHandle(columns[id].Handle)
 }
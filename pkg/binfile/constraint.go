@@ -1,10 +1,12 @@
 package binfile
 
 import (
-	"fmt"
+	"errors"
 
 	"github.com/consensys/go-corset/pkg/hir"
 	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // JsonConstraint аn enumeration of constraint forms.  Exactly one of these fields
@@ -49,7 +51,22 @@ type jsonRangeConstraint struct {
 // Translation
 // =============================================================================
 
-func (e jsonConstraint) addToSchema(colmap map[uint]uint, schema *hir.Schema) {
+// Handle returns the handle of this constraint, or its kind if it has none.
+func (e jsonConstraint) Handle() string {
+	if e.Vanishes != nil {
+		return e.Vanishes.Handle
+	} else if e.Lookup != nil {
+		return e.Lookup.Handle
+	} else if e.InRange != nil {
+		return e.InRange.Handle
+	} else if e.Permutation != nil {
+		return "permutation"
+	}
+	//
+	return "unknown"
+}
+
+func (e jsonConstraint) addToSchema(colmap map[uint]uint, schema *hir.Schema) error {
 	// NOTE: for permutation constraints, we currently ignore them as they
 	// actually provide no useful information.  They are generated from
 	// "defpermutation" declarations, but lack information about the direction
@@ -57,7 +74,10 @@ func (e jsonConstraint) addToSchema(colmap map[uint]uint, schema *hir.Schema) {
 	// "Sorted" computations.
 	if e.Vanishes != nil {
 		// Translate the vanishing expression
-		expr := e.Vanishes.Expr.ToHir(colmap, schema)
+		expr, err := e.Vanishes.Expr.ToHir(colmap, schema)
+		if err != nil {
+			return err
+		}
 		// Translate Domain
 		domain := e.Vanishes.Domain.toHir()
 		// Determine enclosing module
@@ -65,41 +85,64 @@ func (e jsonConstraint) addToSchema(colmap map[uint]uint, schema *hir.Schema) {
 		// Construct the vanishing constraint
 		schema.AddVanishingConstraint(e.Vanishes.Handle, ctx, domain, expr)
 	} else if e.Lookup != nil {
-		sources := jsonExprsToHirUnit(e.Lookup.From, colmap, schema)
-		targets := jsonExprsToHirUnit(e.Lookup.To, colmap, schema)
+		sources, serr := jsonExprsToHirUnit(e.Lookup.From, colmap, schema)
+		targets, terr := jsonExprsToHirUnit(e.Lookup.To, colmap, schema)
+		//
+		if serr != nil {
+			return serr
+		} else if terr != nil {
+			return terr
+		}
+		//
 		sourceCtx := sc.JoinContexts(sources, schema)
 		targetCtx := sc.JoinContexts(targets, schema)
 		// Error check
 		if sourceCtx.IsConflicted() || sourceCtx.IsVoid() {
-			panic(fmt.Sprintf("lookup %s has conflicting source evaluation context", e.Lookup.Handle))
+			return errors.New("conflicting source evaluation context")
 		} else if targetCtx.IsConflicted() || targetCtx.IsVoid() {
-			panic(fmt.Sprintf("lookup %s has conflicting target evaluation context", e.Lookup.Handle))
+			return errors.New("conflicting target evaluation context")
+		} else if len(sources) != len(targets) {
+			return errors.New("differing number of source / target expressions")
 		}
 		// Add constraint
-		schema.AddLookupConstraint(e.Lookup.Handle, sourceCtx, targetCtx, sources, targets)
+		schema.AddLookupConstraint(e.Lookup.Handle, sourceCtx, targetCtx, sources, targets,
+			util.None[hir.UnitExpr](), util.None[hir.UnitExpr]())
 	} else if e.InRange != nil {
 		// Translate the vanishing expression
-		expr := e.InRange.Expr.ToHir(colmap, schema)
+		expr, err := e.InRange.Expr.ToHir(colmap, schema)
+		if err != nil {
+			return err
+		}
 		// Determine enclosing module
 		ctx := expr.Context(schema)
 		// Convert bound into max
-		bound := e.InRange.Max.ToField()
+		bound, err := e.InRange.Max.ToField()
+		if err != nil {
+			return err
+		}
+		//
 		handle := expr.Lisp(schema).String(true)
 		// Construct the vanishing constraint
 		schema.AddRangeConstraint(handle, ctx, expr, bound)
 	} else if e.Permutation == nil {
 		// Catch all
-		panic("Unknown JSON constraint encountered")
+		return errors.New("unknown JSON constraint encountered")
 	}
+	//
+	return nil
 }
 
-func (e jsonDomain) toHir() *int {
-	if len(e.Set) == 1 {
-		domain := e.Set[0]
-		return &domain
-	} else if e.Set != nil {
-		panic("Unknown domain")
+func (e jsonDomain) toHir() *constraint.Domain {
+	if e.Set == nil {
+		// Default
+		return nil
 	}
-	// Default
-	return nil
+	// Each row of the set becomes a distinct window
+	windows := make([]constraint.Window, len(e.Set))
+	//
+	for i, row := range e.Set {
+		windows[i] = constraint.Window{Start: row, End: row, Step: 1}
+	}
+	//
+	return constraint.NewDomain(windows...)
 }
@@ -2,6 +2,7 @@ package binfile
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/consensys/go-corset/pkg/hir"
@@ -119,64 +120,104 @@ type constraintSet struct {
 }
 
 // HirSchemaFromJson constructs an HIR schema from a set of bytes representing
-// the JSON encoding for a set of constraints / columns.
-func HirSchemaFromJson(bytes []byte) (schema *hir.Schema, err error) {
-	var res constraintSet
+// the JSON encoding for a set of constraints / columns.  Malformed columns or
+// constraints (e.g. referring to unknown columns) are reported as errors.
+func HirSchemaFromJson(bytes []byte) (*hir.Schema, error) {
+	var (
+		res  constraintSet
+		errs []error
+	)
 	// Unmarshall
-	jsonErr := json.Unmarshal(bytes, &res)
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return nil, err
+	}
 	// Construct schema
-	schema = hir.EmptySchema()
+	schema := hir.EmptySchema()
 	// Transfer column info
-	transferColumnInfo(&res.Columns)
+	if err := transferColumnInfo(&res.Columns); err != nil {
+		return nil, err
+	}
 	// Allocate registers
-	colmap := allocateRegisters(&res, schema)
+	colmap, err := allocateRegisters(&res, schema)
+	if err != nil {
+		return nil, err
+	}
 	// Double check allocation is correct
-	checkAllocation(&res.Columns, colmap, schema)
+	if err = checkAllocation(&res.Columns, colmap, schema); err != nil {
+		return nil, err
+	}
 	// Finally, add constraints
 	for _, c := range res.Constraints {
-		c.addToSchema(colmap, schema)
+		if err = c.addToSchema(colmap, schema); err != nil {
+			errs = append(errs, malformed(c.Handle(), err))
+		}
 	}
+	//
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	//
+	return schema, nil
+}
 
-	// For now return directly.
-	return schema, jsonErr
+// Construct an error reporting that the element (e.g. column or constraint)
+// with a given handle is malformed.
+func malformed(handle string, err error) error {
+	lerr := sc.NewLoweringError("HIR", handle, nil, err.Error())
+	return &lerr
 }
 
 // This transfers over some information from columns to registers.  It may seem
 // a slightly odd thing to do, but it simply allows us to separate processing of
 // columns from processing of registers.
-func transferColumnInfo(cs *columnSet) {
+func transferColumnInfo(cs *columnSet) error {
 	// Move key data from columns to registers
 	for _, c := range cs.Cols {
 		// Sanity checks
-		if c.Kind == "Computed" {
+		if c.Register >= uint(len(cs.Registers)) {
+			return malformed(c.Handle, fmt.Errorf("unknown register %d", c.Register))
+		} else if c.Kind == "Computed" {
 			cs.Registers[c.Register].Computed = true
 		} else if c.Computed {
-			fmt.Printf("COLUMN: %s\n", c.Handle)
-			panic("invalid JSON column configuration")
+			return malformed(c.Handle, errors.New("invalid JSON column configuration"))
 		} else if c.MustProve {
 			// Copy over must-prove info.
 			cs.Registers[c.Register].MustProve = true
 		}
 	}
+	//
+	return nil
 }
 
 // Allocate all registers as columns in the given schema, whilst producing a
 // "column mapping".  The mapping goes from binfile column indices to schema
 // column indices.
-func allocateRegisters(cs *constraintSet, schema *hir.Schema) map[uint]uint {
+func allocateRegisters(cs *constraintSet, schema *hir.Schema) (map[uint]uint, error) {
 	colmap := make(map[uint]uint)
 	//
 	for _, c := range cs.Columns.Registers {
 		// Computed columns are ignored because they are added separately from
 		// computations (see below).
 		if !c.Computed {
-			handle := asHandle(c.Handle)
+			handle, err := asHandle(c.Handle)
+			if err != nil {
+				return nil, malformed(c.Handle, err)
+			}
+			//
+			col_type, err := c.Type.toHir()
+			if err != nil {
+				return nil, malformed(c.Handle, err)
+			}
+			//
 			mid := registerModule(schema, handle.module)
 			ctx := trace.NewContext(mid, c.LengthMultiplier)
-			col_type := c.Type.toHir()
 			// Add column for this
 			cid := schema.AddDataColumn(ctx, handle.column, col_type)
 			// Check whether a type constraint required or not.
+			if c.MustProve {
+				schema.AddProvenColumn(cid)
+			}
+			//
 			if c.MustProve && col_type.AsUint() != nil {
 				bound := col_type.AsUint().Bound()
 				schema.AddRangeConstraint(c.Handle, ctx, &hir.ColumnAccess{Column: cid, Shift: 0}, bound)
@@ -190,48 +231,59 @@ func allocateRegisters(cs *constraintSet, schema *hir.Schema) map[uint]uint {
 		//
 		if !reg.Computed {
 			// Extract register handle
-			handle := asHandle(reg.Handle)
+			handle, err := asHandle(reg.Handle)
+			if err != nil {
+				return nil, malformed(col.Handle, err)
+			}
 			// Determine enclosing module
 			mid := registerModule(schema, handle.module)
 			// Lookup register in schema
 			cid, ok := sc.ColumnIndexOf(schema, mid, handle.column)
 			// Handle error case
 			if !ok {
-				panic(fmt.Sprintf("unknown column %s.%s", handle.module, handle.column))
+				return nil, malformed(col.Handle, fmt.Errorf("unknown column %s.%s", handle.module, handle.column))
 			}
 
 			colmap[uint(i)] = cid
 		}
 	}
 	// Add computations (and finalise column map)
-	cs.Computations.addToSchema(cs.Columns.Cols, colmap, schema)
+	if err := cs.Computations.addToSchema(cs.Columns.Cols, colmap, schema); err != nil {
+		return nil, err
+	}
 	//
-	return colmap
+	return colmap, nil
 }
 
 // Double check the allocation was made correctly.  This step is strictly
 // unnecessary, but provides a useful safety net given the complexity and
 // significance of getting the allocation right.
-func checkAllocation(cs *columnSet, colmap map[uint]uint, schema *hir.Schema) {
+func checkAllocation(cs *columnSet, colmap map[uint]uint, schema *hir.Schema) error {
 	for i, col := range cs.Cols {
 		// Determine register ID
 		reg := cs.Registers[col.Register]
 		// Extract register handle
-		handle := asHandle(reg.Handle)
+		handle, err := asHandle(reg.Handle)
+		if err != nil {
+			return malformed(col.Handle, err)
+		}
 		// Check it all lines up.
 		cid, ok := colmap[uint(i)]
 		// Sanity check
 		if !ok {
-			panic(fmt.Sprintf("unallocated column %s.%s", handle.module, handle.column))
+			return malformed(col.Handle, fmt.Errorf("unallocated column %s.%s", handle.module, handle.column))
 		}
 
 		sc_col := schema.Columns().Nth(cid)
 		sc_mod := schema.Modules().Nth(sc_col.Context().Module())
 		// Perform the check
 		if sc_mod.Name() != handle.module || sc_col.Name() != handle.column {
-			panic(fmt.Sprintf("invalid allocation %s.%s != %s.%s", handle.module, handle.column, sc_mod.Name(), sc_col.Name()))
+			return malformed(col.Handle, fmt.Errorf("invalid allocation %s.%s != %s.%s", handle.module, handle.column,
+				sc_mod.Name(), sc_col.Name()))
 		}
 	}
+	//
+	return nil
 }
 
 // Register a module within the schema.  If the module already exists, then
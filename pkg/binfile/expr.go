@@ -1,6 +1,7 @@
 package binfile
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -53,12 +54,10 @@ type jsonExprColumn struct {
 // Translation
 // =============================================================================
 
-// ToMir converts a typed expression extracted from a JSON file into an
-// expression in the Mid-Level Intermediate Representation.  This
-// should not generate an error provided the original JSON was
-// well-formed.
-
-func (e *jsonTypedExpr) ToHir(colmap map[uint]uint, schema *hir.Schema) hir.Expr {
+// ToHir converts a typed expression extracted from a JSON file into an
+// expression in the High-Level Intermediate Representation.  This fails if the
+// original JSON was malformed (e.g. refers to an unknown column).
+func (e *jsonTypedExpr) ToHir(colmap map[uint]uint, schema *hir.Schema) (hir.Expr, error) {
 	if e.Expr.Column != nil {
 		return e.Expr.Column.ToHir(colmap, schema)
 	} else if e.Expr.Const != nil {
@@ -70,28 +69,44 @@ func (e *jsonTypedExpr) ToHir(colmap map[uint]uint, schema *hir.Schema) hir.Expr
 		return jsonListToHir(e.Expr.List, colmap, schema)
 	}
 
-	panic("Unknown JSON expression encountered")
+	return nil, errors.New("unknown JSON expression encountered")
 }
 
 // ToHir converts a big integer represented as a sequence of unsigned 32bit
 // words into HIR constant expression.
-func (e *jsonExprConst) ToHir(schema *hir.Schema) hir.Expr {
-	return &hir.Constant{Val: e.ToField()}
+func (e *jsonExprConst) ToHir(schema *hir.Schema) (hir.Expr, error) {
+	val, err := e.ToField()
+	if err != nil {
+		return nil, err
+	}
+	//
+	return &hir.Constant{Val: val}, nil
 }
 
-func (e *jsonExprConst) ToField() fr.Element {
+func (e *jsonExprConst) ToField() (fr.Element, error) {
 	var num fr.Element
 	//
-	val := e.ToBigInt()
+	val, err := e.ToBigInt()
+	if err != nil {
+		return num, err
+	}
 	// Construct Field Value
 	num.SetBigInt(val)
 	//
-	return num
+	return num, nil
 }
 
-func (e *jsonExprConst) ToBigInt() *big.Int {
-	sign := int(e.BigInt[0].(float64))
-	words := e.BigInt[1].([]any)
+func (e *jsonExprConst) ToBigInt() (*big.Int, error) {
+	if len(e.BigInt) != 2 {
+		return nil, fmt.Errorf("invalid BigInt: %v", e.BigInt)
+	}
+	//
+	sign, sok := e.BigInt[0].(float64)
+	words, wok := e.BigInt[1].([]any)
+	//
+	if !sok || !wok {
+		return nil, fmt.Errorf("invalid BigInt: %v", e.BigInt)
+	}
 	// Begin
 	val := big.NewInt(0)
 	base := big.NewInt(1)
@@ -101,7 +116,12 @@ func (e *jsonExprConst) ToBigInt() *big.Int {
 	two32.Exp(two32, n, nil)
 	// Iterate the words
 	for _, w := range words {
-		word := big.NewInt(int64(w.(float64)))
+		wf, ok := w.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid BigInt word: %v", w)
+		}
+		//
+		word := big.NewInt(int64(wf))
 		word = word.Mul(word, base)
 		val = val.Add(val, word)
 		base = base.Mul(base, two32)
@@ -112,92 +132,118 @@ func (e *jsonExprConst) ToBigInt() *big.Int {
 	} else if sign == -1 {
 		val = val.Neg(val)
 	} else {
-		panic(fmt.Sprintf("Unknown BigInt sign: %d", sign))
+		return nil, fmt.Errorf("unknown BigInt sign: %v", sign)
 	}
 	// Done
-	return val
+	return val, nil
 }
 
-func (e *jsonExprColumn) ToHir(colmap map[uint]uint, schema *hir.Schema) hir.Expr {
+func (e *jsonExprColumn) ToHir(colmap map[uint]uint, schema *hir.Schema) (hir.Expr, error) {
 	// Determine binfile column index
-	cid := asColumn(e.Handle)
+	cid, err := asColumn(e.Handle)
+	if err != nil {
+		return nil, err
+	}
 	// Map to schema column index
-	return &hir.ColumnAccess{Column: colmap[cid], Shift: e.Shift}
+	index, ok := colmap[cid]
+	if !ok {
+		return nil, fmt.Errorf("unknown column %s", e.Handle)
+	}
+	//
+	return &hir.ColumnAccess{Column: index, Shift: e.Shift}, nil
 }
 
-func (e *jsonExprFuncall) ToHir(colmap map[uint]uint, schema *hir.Schema) hir.Expr {
+func (e *jsonExprFuncall) ToHir(colmap map[uint]uint, schema *hir.Schema) (hir.Expr, error) {
 	// Parse the arguments
-	args := make([]hir.Expr, len(e.Args))
-	for i := 0; i < len(e.Args); i++ {
-		args[i] = e.Args[i].ToHir(colmap, schema)
+	args, err := jsonExprsToHir(e.Args, colmap, schema)
+	if err != nil {
+		return nil, err
 	}
 	// Construct appropriate expression
 	switch e.Func {
 	case "Normalize":
 		if len(args) == 1 {
-			return &hir.Normalise{Arg: args[0]}
+			return &hir.Normalise{Arg: args[0]}, nil
 		} else {
-			panic("incorrect arguments for Normalize")
+			return nil, errors.New("incorrect arguments for Normalize")
 		}
 	case "VectorAdd", "Add":
-		return &hir.Add{Args: args}
+		return &hir.Add{Args: args}, nil
 	case "VectorMul", "Mul":
-		return &hir.Mul{Args: args}
+		return &hir.Mul{Args: args}, nil
 	case "VectorSub", "Sub":
-		return &hir.Sub{Args: args}
+		return &hir.Sub{Args: args}, nil
 	case "Exp":
 		if len(args) != 2 {
-			panic(fmt.Sprintf("incorrect number of arguments for Exp (%d)", len(args)))
+			return nil, fmt.Errorf("incorrect number of arguments for Exp (%d)", len(args))
 		}
 
 		c, ok := args[1].(*hir.Constant)
 
 		if !ok {
-			panic(fmt.Sprintf("constant power expected for Exp, got %s", args[1].Lisp(schema)))
+			return nil, fmt.Errorf("constant power expected for Exp, got %s", args[1].Lisp(schema))
 		} else if !c.Val.IsUint64() {
-			panic("constant power too large for Exp")
+			return nil, errors.New("constant power too large for Exp")
 		}
 
 		var k big.Int
 		// Convert power to uint64
 		c.Val.BigInt(&k)
 		// Done
-		return &hir.Exp{Arg: args[0], Pow: k.Uint64()}
+		return &hir.Exp{Arg: args[0], Pow: k.Uint64()}, nil
 	case "IfZero":
 		if len(args) == 2 {
-			return &hir.IfZero{Condition: args[0], TrueBranch: args[1], FalseBranch: nil}
+			return &hir.IfZero{Condition: args[0], TrueBranch: args[1], FalseBranch: nil}, nil
 		} else if len(args) == 3 {
-			return &hir.IfZero{Condition: args[0], TrueBranch: args[1], FalseBranch: args[2]}
+			return &hir.IfZero{Condition: args[0], TrueBranch: args[1], FalseBranch: args[2]}, nil
 		} else {
-			panic(fmt.Sprintf("incorrect number of arguments for IfZero (%d)", len(args)))
+			return nil, fmt.Errorf("incorrect number of arguments for IfZero (%d)", len(args))
 		}
 	case "IfNotZero":
 		if len(args) == 2 {
-			return &hir.IfZero{Condition: args[0], TrueBranch: nil, FalseBranch: args[1]}
+			return &hir.IfZero{Condition: args[0], TrueBranch: nil, FalseBranch: args[1]}, nil
 		} else if len(args) == 3 {
-			return &hir.IfZero{Condition: args[0], TrueBranch: args[2], FalseBranch: args[1]}
+			return &hir.IfZero{Condition: args[0], TrueBranch: args[2], FalseBranch: args[1]}, nil
 		} else {
-			panic(fmt.Sprintf("incorrect number of arguments for IfNotZero (%d)", len(args)))
+			return nil, fmt.Errorf("incorrect number of arguments for IfNotZero (%d)", len(args))
 		}
 	}
 	// Catch anything we've missed
-	panic(fmt.Sprintf("HANDLE %s\n", e.Func))
+	return nil, fmt.Errorf("unknown function %s", e.Func)
+}
+
+func jsonListToHir(Args []jsonTypedExpr, colmap map[uint]uint, schema *hir.Schema) (hir.Expr, error) {
+	args, err := jsonExprsToHir(Args, colmap, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hir.List{Args: args}, nil
 }
 
-func jsonListToHir(Args []jsonTypedExpr, colmap map[uint]uint, schema *hir.Schema) hir.Expr {
+func jsonExprsToHir(Args []jsonTypedExpr, colmap map[uint]uint, schema *hir.Schema) ([]hir.Expr, error) {
+	var err error
+	//
 	args := make([]hir.Expr, len(Args))
 	for i := 0; i < len(Args); i++ {
-		args[i] = Args[i].ToHir(colmap, schema)
+		if args[i], err = Args[i].ToHir(colmap, schema); err != nil {
+			return nil, err
+		}
 	}
 
-	return &hir.List{Args: args}
+	return args, nil
 }
 
-func jsonExprsToHirUnit(Args []jsonTypedExpr, colmap map[uint]uint, schema *hir.Schema) []hir.UnitExpr {
-	args := make([]hir.UnitExpr, len(Args))
-	for i := 0; i < len(Args); i++ {
-		args[i] = hir.NewUnitExpr(Args[i].ToHir(colmap, schema))
+func jsonExprsToHirUnit(Args []jsonTypedExpr, colmap map[uint]uint, schema *hir.Schema) ([]hir.UnitExpr, error) {
+	args, err := jsonExprsToHir(Args, colmap, schema)
+	if err != nil {
+		return nil, err
+	}
+	//
+	units := make([]hir.UnitExpr, len(args))
+	for i, arg := range args {
+		units[i] = hir.NewUnitExpr(arg)
 	}
 
-	return args
+	return units, nil
 }
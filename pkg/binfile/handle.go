@@ -1,6 +1,7 @@
 package binfile
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -15,28 +16,41 @@ type Handle struct {
 	column string
 }
 
-func asHandle(handle string) Handle {
+func asHandle(handle string) (Handle, error) {
 	split := strings.Split(handle, ".")
+	// Error check
+	if len(split) != 2 {
+		return Handle{}, fmt.Errorf("invalid column handle %s", handle)
+	}
 	// Easy
-	return Handle{split[0], split[1]}
+	return Handle{split[0], split[1]}, nil
 }
 
-func asColumn(handle string) uint {
+func asColumn(handle string) (uint, error) {
 	split := strings.Split(handle, "#")
+	// Error check
+	if len(split) != 2 {
+		return 0, fmt.Errorf("invalid column reference %s", handle)
+	}
+	//
 	column, err := strconv.Atoi(split[1])
 	// Error check
-	if err != nil {
-		panic(err.Error())
+	if err != nil || column < 0 {
+		return 0, fmt.Errorf("invalid column reference %s", handle)
 	}
 
-	return uint(column)
+	return uint(column), nil
 }
 
-func asColumns(handles []string) []uint {
+func asColumns(handles []string) ([]uint, error) {
+	var err error
+	//
 	cols := make([]uint, len(handles))
 	for i := 0; i < len(cols); i++ {
-		cols[i] = asColumn(handles[i])
+		if cols[i], err = asColumn(handles[i]); err != nil {
+			return nil, err
+		}
 	}
 
-	return cols
+	return cols, nil
 }
@@ -21,27 +21,24 @@ type jsonType struct {
 // Translation
 // =============================================================================
 
-func (e *jsonType) toHir() schema.Type {
+func (e *jsonType) toHir() (schema.Type, error) {
 	// Check whether magma is string
 	if str, ok := e.Magma.(string); ok {
 		switch str {
 		case "Native":
-			return &schema.FieldType{}
+			return &schema.FieldType{}, nil
 		case "Byte":
-			return schema.NewUintType(8)
+			return schema.NewUintType(8), nil
 		case "Binary":
-			return schema.NewUintType(1)
-		default:
-			panic(fmt.Sprintf("Unknown JSON type encountered: %s:%s", e.Magma, e.Conditioning))
+			return schema.NewUintType(1), nil
 		}
 	}
 	// Try as integer
 	if intMap, ok := e.Magma.(map[string]any); ok {
-		if val, isInt := intMap["Integer"]; isInt {
-			nbits := uint(val.(float64))
-			return schema.NewUintType(nbits)
+		if val, isInt := intMap["Integer"].(float64); isInt && val >= 0 {
+			return schema.NewUintType(uint(val)), nil
 		}
 	}
 	// Fail
-	panic(fmt.Sprintf("Unknown JSON type encountered: %s:%s", e.Magma, e.Conditioning))
+	return nil, fmt.Errorf("unknown JSON type encountered: %v:%s", e.Magma, e.Conditioning)
 }
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/schema/constraint"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -40,6 +44,7 @@ var checkCmd = &cobra.Command{
 		cfg.hir = GetFlag(cmd, "hir")
 		cfg.expand = !GetFlag(cmd, "raw")
 		cfg.report = GetFlag(cmd, "report")
+		cfg.explain = GetFlag(cmd, "explain")
 		cfg.reportPadding = GetUint(cmd, "report-context")
 		cfg.reportCellWidth = GetUint(cmd, "report-cellwidth")
 		cfg.spillage = GetInt(cmd, "spillage")
@@ -50,7 +55,34 @@ var checkCmd = &cobra.Command{
 		cfg.padding.Right = GetUint(cmd, "padding")
 		cfg.parallelExpansion = !GetFlag(cmd, "sequential")
 		cfg.batchSize = GetUint(cmd, "batch")
+		cfg.workers = GetUint(cmd, "workers")
 		cfg.ansiEscapes = GetFlag(cmd, "ansi-escapes")
+		cfg.collectAll = GetFlag(cmd, "collect-all")
+		cfg.lowering.LogDerivativeLookups = GetFlag(cmd, "logup")
+		cfg.lowering.MaxDegree = getMaxDegree(cmd)
+		cfg.failureLimit = 1
+		// Determine how many failures to report for each constraint
+		if max_failures := GetUint(cmd, "max-failures"); cfg.collectAll && max_failures == 0 {
+			cfg.failureLimit = math.MaxUint
+		} else if cfg.collectAll {
+			cfg.failureLimit = max_failures
+		}
+		// Determine output format for failures
+		cfg.format = GetString(cmd, "format")
+		if cfg.format != "text" && cfg.format != "json" && cfg.format != "sarif" {
+			fmt.Printf("unknown output format \"%s\"\n", cfg.format)
+			os.Exit(1)
+		}
+		// Open cache (if applicable)
+		if dir := GetString(cmd, "cache"); dir != "" {
+			cache, err := sc.NewCache(dir)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			//
+			cfg.cache = cache
+		}
 		// TODO: support true ranges
 		cfg.padding.Left = cfg.padding.Right
 		if !cfg.hir && !cfg.mir && !cfg.air {
@@ -63,12 +95,22 @@ var checkCmd = &cobra.Command{
 		hirSchema = readSchema(cfg.stdlib, cfg.debug, args[1:])
 		//
 		stats.Log("Reading constraints file")
+		// Locate constraints for structured reports
+		if cfg.format != "text" {
+			cfg.failures = newFailureReport()
+		}
 		// Parse trace file
 		columns := readTraceFile(args[0])
 		//
 		stats.Log("Reading trace file")
 		// Go!
-		if !checkTraceWithLowering(columns, hirSchema, cfg) {
+		ok := checkTraceWithLowering(columns, hirSchema, cfg)
+		// Output structured report (if applicable)
+		if cfg.failures != nil {
+			writeFailureReport(cfg.format, cfg.failures)
+		}
+		//
+		if !ok {
 			os.Exit(1)
 		}
 	},
@@ -107,6 +149,9 @@ type checkConfig struct {
 	// Specifies whether or not to report details of the failure (e.g. for
 	// debugging purposes).
 	report bool
+	// Specifies whether or not to explain failures in the report, by showing
+	// the value of each subexpression of the failing constraint.
+	explain bool
 	// Specifies the number of additional rows to show eitherside of the failing
 	// area. This essentially allows more contextual information to be shown.
 	reportPadding uint
@@ -116,8 +161,24 @@ type checkConfig struct {
 	parallelExpansion bool
 	// Size of constraint batches to execute in parallel
 	batchSize uint
+	// Number of workers across which the rows of any given constraint can be
+	// checked in parallel.
+	workers uint
 	// Enable ansi escape codes in reports
 	ansiEscapes bool
+	// Report every failure of a constraint, rather than just the first.
+	collectAll bool
+	// Maximum number of failures to report for any given constraint.
+	failureLimit uint
+	// Output format for failures (i.e. text, json or sarif)
+	format string
+	// Determines how constraints are lowered to the AIR level.
+	lowering mir.LoweringConfig
+	// Cache of results from previous checks (or nil if none).
+	cache *sc.Cache
+	// Accumulates failures for structured output formats (i.e. when the format
+	// is not text).
+	failures *failureReport
 }
 
 // Check a given trace is consistently accepted (or rejected) at the different
@@ -130,11 +191,11 @@ func checkTraceWithLowering(cols []tr.RawColumn, schema *hir.Schema, cfg checkCo
 	}
 
 	if cfg.mir {
-		res = checkTrace("MIR", cols, schema.LowerToMir(), cfg) && res
+		res = checkTrace("MIR", cols, lowerToMir(schema), cfg) && res
 	}
 
 	if cfg.air {
-		res = checkTrace("AIR", cols, schema.LowerToMir().LowerToAir(), cfg) && res
+		res = checkTrace("AIR", cols, lowerToAir(lowerToMir(schema), cfg.lowering), cfg) && res
 	}
 
 	return res
@@ -142,6 +203,7 @@ func checkTraceWithLowering(cols []tr.RawColumn, schema *hir.Schema, cfg checkCo
 
 func checkTrace(ir string, cols []tr.RawColumn, schema sc.Schema, cfg checkConfig) bool {
 	builder := sc.NewTraceBuilder(schema).Expand(cfg.expand).Parallel(cfg.parallelExpansion).BatchSize(cfg.batchSize)
+	builder = builder.Cache(cfg.cache)
 	//
 	for n := cfg.padding.Left; n <= cfg.padding.Right; n++ {
 		stats := util.NewPerfStats()
@@ -164,14 +226,9 @@ func checkTrace(ir string, cols []tr.RawColumn, schema sc.Schema, cfg checkConfi
 		// Check trace
 		stats.Log("Validating trace")
 		stats = util.NewPerfStats()
-		// Check constraints
-		if errs := sc.Accepts(cfg.batchSize, schema, trace); len(errs) > 0 {
-			reportFailures(ir, errs, trace, cfg)
-			return false
-		}
-		// Check assertions
-		if errs := sc.Asserts(cfg.batchSize, schema, trace); len(errs) > 0 {
-			reportFailures(ir, errs, trace, cfg)
+		// Check constraints and assertions
+		if errs := checkConstraints(schema, trace, cfg); len(errs) > 0 {
+			reportFailures(ir, errs, schema, trace, cfg)
 			return false
 		}
 
@@ -181,6 +238,23 @@ func checkTrace(ir string, cols []tr.RawColumn, schema sc.Schema, cfg checkConfi
 	return true
 }
 
+// Check the constraints of a given schema hold on a given trace and, if so,
+// that its assertions also hold.  When a cache is given, constraints known to
+// hold from previous checks are skipped.
+func checkConstraints(schema sc.Schema, trace tr.Trace, cfg checkConfig) []sc.Failure {
+	accepts, asserts := sc.AcceptsWith, sc.AssertsWith
+	//
+	if cfg.cache != nil {
+		accepts, asserts = cfg.cache.Accepts, cfg.cache.Asserts
+	}
+	//
+	if errs := accepts(cfg.batchSize, cfg.workers, cfg.failureLimit, schema, trace); len(errs) > 0 {
+		return errs
+	}
+	//
+	return asserts(cfg.batchSize, cfg.workers, cfg.failureLimit, schema, trace)
+}
+
 // Validate that values held in trace columns match the expected type.  This is
 // really a sanity check that the trace is not malformed.
 func validationCheck(tr tr.Trace, schema sc.Schema) error {
@@ -218,6 +292,12 @@ func validationCheck(tr tr.Trace, schema sc.Schema) error {
 
 // Validate that all elements of a given column are within the given type.
 func validateColumn(colType sc.Type, col tr.Column, mod sc.Module) error {
+	// Field elements are always accepted, hence there is no need to touch the
+	// column's data (which may not yet have been decoded).
+	if colType.AsUint() == nil {
+		return nil
+	}
+	//
 	for j := 0; j < int(col.Data().Len()); j++ {
 		jth := col.Get(j)
 		if !colType.Accept(jth) {
@@ -230,29 +310,177 @@ func validateColumn(colType sc.Type, col tr.Column, mod sc.Module) error {
 }
 
 // Report constraint failures, whilst providing contextual information (when requested).
-func reportFailures(ir string, failures []sc.Failure, trace tr.Trace, cfg checkConfig) {
+func reportFailures(ir string, failures []sc.Failure, schema sc.Schema, trace tr.Trace, cfg checkConfig) {
+	// Record failures for structured output (if applicable)
+	if cfg.failures != nil {
+		cfg.failures.add(ir, failures, trace)
+		return
+	}
+	//
 	errs := make([]error, len(failures))
 	for i, f := range failures {
-		errs[i] = errors.New(f.Message())
+		if src := failureSource(f); src != nil {
+			errs[i] = fmt.Errorf("%s at %s", f.Message(), src)
+		} else {
+			errs[i] = errors.New(f.Message())
+		}
 	}
 	// First, log errors
 	reportErrors(true, ir, errs)
+	// Summarise errors (when there can be more than one per constraint)
+	if cfg.collectAll {
+		reportSummary(ir, failures)
+	}
 	// Second, produce report (if requested)
 	if cfg.report {
 		for _, f := range failures {
-			reportFailure(f, trace, cfg)
+			reportFailure(f, schema, trace, cfg)
 		}
 	}
 }
 
+// Print a table summarising the failures of each constraint, including how many
+// times it failed and on which rows.
+func reportSummary(ir string, failures []sc.Failure) {
+	var (
+		handles []string
+		rows    = make(map[string][]uint)
+		counts  = make(map[string]uint)
+	)
+	// Group failures by handle
+	for _, f := range failures {
+		handle := failureHandle(f)
+		//
+		if _, ok := counts[handle]; !ok {
+			handles = append(handles, handle)
+		}
+		//
+		counts[handle]++
+		//
+		if row, ok := failureRow(f); ok {
+			rows[handle] = append(rows[handle], row)
+		}
+	}
+	// Print summary
+	tbl := util.NewTablePrinter(3, uint(len(handles))+1)
+	tbl.SetRow(0, fmt.Sprintf("Constraint (%s)", ir), "Failures", "Rows")
+	//
+	for i, handle := range handles {
+		tbl.SetRow(uint(i)+1, handle, fmt.Sprintf("%d", counts[handle]), summariseRows(rows[handle]))
+	}
+	//
+	tbl.SetMaxWidths(64)
+	tbl.Print()
+	fmt.Println()
+}
+
+// Summarise a set of failing rows, such that only the first few are shown.
+func summariseRows(rows []uint) string {
+	var builder strings.Builder
+	//
+	slices.Sort(rows)
+	//
+	for i, row := range rows {
+		if i == 5 {
+			builder.WriteString(", ...")
+			break
+		} else if i != 0 {
+			builder.WriteString(", ")
+		}
+		//
+		builder.WriteString(fmt.Sprintf("%d", row))
+	}
+	//
+	return builder.String()
+}
+
+// Determine the handle of the constraint responsible for a given failure.
+func failureHandle(failure sc.Failure) string {
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		return f.Handle()
+	case *sc.AssertionFailure:
+		return f.Handle()
+	case *constraint.LookupFailure:
+		return f.Handle()
+	case *constraint.MultiplicityFailure:
+		return f.Handle()
+	case *constraint.RangeFailure:
+		return f.Handle()
+	case *constraint.PermutationFailure:
+		return f.Handle()
+	default:
+		return failure.Message()
+	}
+}
+
+// Determine the row on which a given failure arose (if applicable).
+func failureRow(failure sc.Failure) (uint, bool) {
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		return f.Row(), true
+	case *sc.AssertionFailure:
+		return f.Row(), true
+	case *constraint.LookupFailure:
+		return f.Row(), true
+	case *constraint.MultiplicityFailure:
+		return f.Row(), true
+	case *constraint.RangeFailure:
+		return f.Row(), true
+	default:
+		return 0, false
+	}
+}
+
+// Determine the origin of the constraint responsible for a given failure (if
+// known).
+func failureSource(failure sc.Failure) *sc.Source {
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		return f.Source()
+	case *sc.AssertionFailure:
+		return f.Source()
+	case *constraint.LookupFailure:
+		return f.Source()
+	case *constraint.MultiplicityFailure:
+		return f.Source()
+	case *constraint.RangeFailure:
+		return f.Source()
+	case *constraint.PermutationFailure:
+		return f.Source()
+	default:
+		return nil
+	}
+}
+
 // Print a human-readable report detailing the given failure
-func reportFailure(failure sc.Failure, trace tr.Trace, cfg checkConfig) {
+func reportFailure(failure sc.Failure, schema sc.Schema, trace tr.Trace, cfg checkConfig) {
 	if f, ok := failure.(*constraint.VanishingFailure); ok {
 		cells := f.RequiredCells(trace)
 		reportConstraintFailure("constraint", f.Handle(), cells, trace, cfg)
+		// Explain failure (if requested)
+		if cfg.explain {
+			reportExplanation(f.Constraint(), f.Row(), schema, trace)
+		}
 	} else if f, ok := failure.(*sc.AssertionFailure); ok {
 		cells := f.RequiredCells(trace)
 		reportConstraintFailure("assertion", f.Handle(), cells, trace, cfg)
+		// Explain failure (if requested)
+		if cfg.explain {
+			reportExplanation(f.Constraint(), f.Row(), schema, trace)
+		}
+	}
+}
+
+// Print an annotated evaluation tree for a failing constraint at the failing
+// row, showing the value of each subexpression.  This is only possible for
+// constraints which are explainable.
+func reportExplanation(constraint sc.Testable, row uint, schema sc.Schema, trace tr.Trace) {
+	if c, ok := constraint.(sc.Explainable); ok {
+		if explanation := c.Explain(int(row), trace, schema); explanation != nil {
+			fmt.Printf("evaluation of row %d:\n", row)
+			fmt.Println(explanation.String())
+		}
 	}
 }
 
@@ -290,27 +518,50 @@ func reportConstraintFailure(kind string, handle string, cells *util.AnySortedSe
 	fmt.Println()
 }
 
+// Write out a structured report of the failures found in the given format.
+func writeFailureReport(format string, report *failureReport) {
+	var (
+		bytes []byte
+		err   error
+	)
+	//
+	if format == "sarif" {
+		bytes, err = report.toSARIF()
+	} else {
+		bytes, err = report.toJSON()
+	}
+	//
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	//
+	fmt.Println(string(bytes))
+}
+
 func reportErrors(error bool, ir string, errs []error) {
 	// Construct set to ensure deduplicate errors
 	set := make(map[string]bool, len(errs))
-	//
+	// Report each one (in order)
 	for _, err := range errs {
-		key := fmt.Sprintf("%s (%s)", err, ir)
-		set[key] = true
-	}
-	// Report each one
-	for e := range set {
-		if error {
+		e := fmt.Sprintf("%s (%s)", err, ir)
+		//
+		if set[e] {
+			continue
+		} else if error {
 			log.Errorln(e)
 		} else {
 			log.Warnln(e)
 		}
+		//
+		set[e] = true
 	}
 }
 
 func init() {
 	rootCmd.AddCommand(checkCmd)
 	checkCmd.Flags().Bool("report", false, "report details of failure for debugging")
+	checkCmd.Flags().Bool("explain", false, "explain failures in report by showing the value of each subexpression")
 	checkCmd.Flags().Uint("report-context", 2, "specify number of rows to show eitherside of failure in report")
 	checkCmd.Flags().Uint("report-cellwidth", 32, "specify max number of bytes to show in a given cell in the report")
 	checkCmd.Flags().Bool("raw", false, "assume input trace already expanded")
@@ -326,7 +577,17 @@ func init() {
 	checkCmd.Flags().Bool("sequential", false, "perform sequential trace expansion")
 	checkCmd.Flags().Uint("padding", 0, "specify amount of (front) padding to apply")
 	checkCmd.Flags().UintP("batch", "b", math.MaxUint, "specify batch size for constraint checking")
+	checkCmd.Flags().Uint("workers", uint(runtime.NumCPU()),
+		"specify number of workers across which the rows of a constraint are checked")
+	checkCmd.Flags().String("cache", "",
+		"specify directory in which to cache computed columns and constraint outcomes between runs")
 	checkCmd.Flags().Int("spillage", -1,
 		"specify amount of splillage to account for (where -1 indicates this should be inferred)")
+	checkCmd.Flags().Bool("collect-all", false, "report every failing row of a constraint, rather than just the first")
+	checkCmd.Flags().Uint("max-failures", 0,
+		"specify maximum number of failures to report per constraint with --collect-all (0 indicates no limit)")
+	checkCmd.Flags().Bool("logup", false, "lower lookups to log-derivative lookups (with multiplicity columns)")
+	checkCmd.Flags().Uint("max-degree", 0, "split AIR constraints exceeding a given degree (0 = unbounded)")
+	checkCmd.Flags().String("format", "text", "specify output format for failures (text, json or sarif)")
 	checkCmd.Flags().Bool("ansi-escapes", true, "specify whether to allow ANSI escapes or not (e.g. for colour reports)")
 }
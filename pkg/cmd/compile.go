@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/spf13/cobra"
+)
+
+var compileCmd = &cobra.Command{
+	Use:   "compile [flags] constraint_file(s)",
+	Short: "compile constraints into a binary constraint file.",
+	Long: `Compile a given set of constraint file(s) into a single binary constraint
+	file.  The resulting file is self-contained (i.e. it includes everything needed
+	from the standard library) and can be given directly to other commands (e.g.
+	check or debug) in place of the original source files.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		output := GetString(cmd, "output")
+		// Parse constraints
+		hirSchema := readSchema(stdlib, debug, args)
+		// Write binary file
+		writeBinaryFile(output, hirSchema)
+	},
+}
+
+// Write a compiled schema to disk.
+func writeBinaryFile(filename string, schema *hir.Schema) {
+	bytes, err := hir.ToBytes(schema)
+	// Check success
+	if err == nil {
+		if err = os.WriteFile(filename, bytes, 0644); err == nil {
+			return
+		}
+	}
+	// Handle error
+	fmt.Println(err)
+	os.Exit(4)
+}
+
+func init() {
+	rootCmd.AddCommand(compileCmd)
+	compileCmd.Flags().StringP("output", "o", "a.bin", "specify output file.")
+	compileCmd.Flags().Bool("no-stdlib", false, "prevents the standard library from being included")
+	compileCmd.Flags().Bool("debug", false, "enable debugging constraints")
+}
@@ -6,7 +6,10 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/air/export"
 	"github.com/consensys/go-corset/pkg/hir"
+	mir_pkg "github.com/consensys/go-corset/pkg/mir"
 	"github.com/consensys/go-corset/pkg/schema"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/util"
@@ -28,15 +31,21 @@ var debugCmd = &cobra.Command{
 		mir := GetFlag(cmd, "mir")
 		air := GetFlag(cmd, "air")
 		stats := GetFlag(cmd, "stats")
+		export := GetFlag(cmd, "export")
 		stdlib := !GetFlag(cmd, "no-stdlib")
 		debug := GetFlag(cmd, "debug")
+		lowering := mir_pkg.LoweringConfig{LogDerivativeLookups: GetFlag(cmd, "logup")}
+		lowering.MaxDegree = getMaxDegree(cmd)
+		optimisation := mir_pkg.OptimisationLevel(GetUint(cmd, "opt-level"))
 		// Parse constraints
 		hirSchema := readSchema(stdlib, debug, args)
 		// Print constraints
-		if stats {
-			printStats(hirSchema, hir, mir, air)
+		if export {
+			printExport(hirSchema, optimisation, lowering)
+		} else if stats {
+			printStats(hirSchema, hir, mir, air, optimisation, lowering)
 		} else {
-			printSchemas(hirSchema, hir, mir, air)
+			printSchemas(hirSchema, hir, mir, air, optimisation, lowering)
 		}
 	},
 }
@@ -47,13 +56,18 @@ func init() {
 	debugCmd.Flags().Bool("mir", false, "Print constraints at MIR level")
 	debugCmd.Flags().Bool("air", false, "Print constraints at AIR level")
 	debugCmd.Flags().Bool("stats", false, "Print summary information")
+	debugCmd.Flags().Bool("export", false, "Print AIR constraints in prover-friendly (JSON) format")
 	debugCmd.Flags().Bool("no-stdlib", false, "prevents the standard library from being included")
 	debugCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	debugCmd.Flags().Bool("logup", false, "lower lookups to log-derivative lookups with multiplicity columns")
+	debugCmd.Flags().Uint("opt-level", 0, "specify optimisation level applied to MIR constraints (0 = none, 2 = max)")
+	debugCmd.Flags().Uint("max-degree", 0, "split AIR constraints exceeding a given degree (0 = unbounded)")
 }
 
-func printSchemas(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
-	mirSchema := hirSchema.LowerToMir()
-	airSchema := mirSchema.LowerToAir()
+func printSchemas(hirSchema *hir.Schema, hir bool, mir bool, air bool, optimisation mir_pkg.OptimisationConfig,
+	lowering mir_pkg.LoweringConfig) {
+	mirSchema := lowerToMir(hirSchema).Optimise(optimisation)
+	airSchema := lowerToAir(mirSchema, lowering)
 
 	if hir {
 		printSchema(hirSchema)
@@ -68,6 +82,12 @@ func printSchemas(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
 	}
 }
 
+// Print the AIR schema in a format suitable for consumption by a prover.
+func printExport(hirSchema *hir.Schema, optimisation mir_pkg.OptimisationConfig, lowering mir_pkg.LoweringConfig) {
+	airSchema := lowerToAir(lowerToMir(hirSchema).Optimise(optimisation), lowering)
+	fmt.Println(export.ToJsonString(airSchema))
+}
+
 // Print out all declarations included in a given
 func printSchema(schema schema.Schema) {
 	for i := schema.Declarations(); i.HasNext(); {
@@ -81,10 +101,11 @@ func printSchema(schema schema.Schema) {
 	}
 }
 
-func printStats(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
+func printStats(hirSchema *hir.Schema, hir bool, mir bool, air bool, optimisation mir_pkg.OptimisationConfig,
+	lowering mir_pkg.LoweringConfig) {
 	schemas := make([]schema.Schema, 0)
-	mirSchema := hirSchema.LowerToMir()
-	airSchema := mirSchema.LowerToAir()
+	mirSchema := lowerToMir(hirSchema).Optimise(optimisation)
+	airSchema := lowerToAir(mirSchema, lowering)
 	// Construct columns
 	if hir {
 		schemas = append(schemas, hirSchema)
@@ -117,6 +138,17 @@ func printStats(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
 	//
 	tbl.SetMaxWidths(64)
 	tbl.Print()
+	// Report overhead of bounding constraint degree (if applicable)
+	if lowering.MaxDegree != 0 {
+		unbounded := lowering
+		unbounded.MaxDegree = 0
+		unboundedSchema := lowerToAir(mirSchema, unbounded)
+		//
+		columns := airSchema.Columns().Count() - unboundedSchema.Columns().Count()
+		constraints := airSchema.Constraints().Count() - unboundedSchema.Constraints().Count()
+		fmt.Printf("Bounding degree to %d added %d column(s) and %d constraint(s)\n", lowering.MaxDegree, columns,
+			constraints)
+	}
 }
 
 // ============================================================================
@@ -132,16 +164,23 @@ var schemaSummarisers []schemaSummariser = []schemaSummariser{
 	// Constraints
 	constraintCounter("Constraints", "*constraint.VanishingConstraint"),
 	constraintCounter("Lookups", "*constraint.LookupConstraint"),
+	constraintCounter("Log-Derivative Lookups", "*constraint.LogDerivativeLookupConstraint"),
 	constraintCounter("Permutations", "*constraint.PermutationConstraint"),
 	constraintCounter("Types", "*constraint.TypeConstraint"),
 	constraintCounter("Range", "*constraint.RangeConstraint"),
 	// Assignments
 	assignmentCounter("Decompositions", "*assignment.ByteDecomposition"),
+	assignmentCounter("Byte Conjunctions", "*assignment.ByteAnd"),
 	assignmentCounter("Computed Columns", "*assignment.ComputedColumn"),
 	assignmentCounter("Committed Columns", "*assignment.DataColumn"),
 	assignmentCounter("Interleavings", "*assignment.Interleaving"),
 	assignmentCounter("Lexicographic Orderings", "*assignment.LexicographicSort"),
+	assignmentCounter("Lookup Multiplicities", "*assignment.LookupMultiplicity"),
 	assignmentCounter("Sorted Permutations", "*assignment.SortedPermutation"),
+	// Interning
+	savedColumnsSummariser("Inverse Columns Saved"),
+	// Degree
+	maxDegreeSummariser("Max Constraint Degree"),
 	// Column Width
 	columnWidthSummariser(1, 1),
 	columnWidthSummariser(2, 4),
@@ -171,6 +210,40 @@ func assignmentCounter(title string, prefix string) schemaSummariser {
 	}
 }
 
+// Reports the number of computed columns saved by reusing interned columns
+// during lowering.  This only applies for AIR schemas.
+func savedColumnsSummariser(title string) schemaSummariser {
+	return schemaSummariser{
+		name: title,
+		summary: func(schema sc.Schema) int {
+			if airSchema, ok := schema.(*air.Schema); ok {
+				return int(airSchema.SavedColumns())
+			}
+			//
+			return 0
+		},
+	}
+}
+
+// Reports the maximum degree of any vanishing constraint.  This only applies
+// for AIR schemas, since only AIR expressions are polynomials.
+func maxDegreeSummariser(title string) schemaSummariser {
+	return schemaSummariser{
+		name: title,
+		summary: func(schema sc.Schema) int {
+			degree := uint(0)
+			//
+			for iter := schema.Constraints(); iter.HasNext(); {
+				if vc, ok := iter.Next().(air.VanishingConstraint); ok {
+					degree = max(degree, vc.Constraint().Expr.Degree())
+				}
+			}
+			//
+			return int(degree)
+		},
+	}
+}
+
 func typeOfCounter[T any](iter util.Iterator[T], prefix string) int {
 	count := 0
 
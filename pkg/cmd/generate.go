@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/consensys/go-corset/pkg/codegen"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "generate source code from a set of constraints.",
+	Long: `Generate source code for interacting with a given set of constraints (e.g.
+	for building traces).  Constraints can be given either as lisp or bin files.`,
+}
+
+var generateGoCmd = &cobra.Command{
+	Use:   "go [flags] constraint_file(s)",
+	Short: "generate Go code for building traces.",
+	Long: `Generate Go code for building traces of a given set of constraints.  This
+	provides a struct for each module with a typed setter for each of its input
+	columns, thus ensuring column names and values are checked when the trace is
+	built.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		output := GetString(cmd, "output")
+		pkgname := GetString(cmd, "package")
+		// Parse constraints
+		hirSchema := readSchema(stdlib, debug, args)
+		// Generate source code
+		source, err := codegen.GenerateGo(pkgname, hirSchema)
+		// Write source code
+		if err == nil && output == "" {
+			fmt.Print(source)
+			return
+		} else if err == nil {
+			if err = os.WriteFile(output, []byte(source), 0644); err == nil {
+				return
+			}
+		}
+		// Handle error
+		fmt.Println(err)
+		os.Exit(4)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateGoCmd)
+	generateGoCmd.Flags().StringP("output", "o", "", "specify output file (otherwise printed).")
+	generateGoCmd.Flags().String("package", "trace", "specify package name of generated code.")
+	generateGoCmd.Flags().Bool("no-stdlib", false, "prevents the standard library from being included")
+	generateGoCmd.Flags().Bool("debug", false, "enable debugging constraints")
+}
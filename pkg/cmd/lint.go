@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/spf13/cobra"
+)
+
+// LINT_WARNINGS_EXIT_CODE is the exit status of the lint command when warnings
+// are reported.  This is distinct from the exit status for errors (e.g.
+// invalid usage), such that the two can be distinguished.
+const LINT_WARNINGS_EXIT_CODE = 6
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [flags] constraint_file(s)",
+	Short: "report likely mistakes in a given set of constraints.",
+	Long: `Analyse a given set of constraints for likely mistakes.
+	This reports columns which are never used, or never constrained;
+	constraints which apply only to padding rows; and, columns declared
+	with ":prove" which have no range constraint.  Constraints can be
+	given either as lisp or bin files.  Exits with status 6 if any
+	warnings are reported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		// Parse constraints
+		hirSchema := readSchema(stdlib, debug, args)
+		// Analyse constraints
+		warnings := hir.Lint(hirSchema)
+		//
+		for _, w := range warnings {
+			printLintWarning(w)
+		}
+		//
+		if len(warnings) > 0 {
+			fmt.Printf("%d warning(s)\n", len(warnings))
+			os.Exit(LINT_WARNINGS_EXIT_CODE)
+		}
+	},
+}
+
+// Print a lint warning, highlighting the offending declaration (if known).
+func printLintWarning(w hir.LintWarning) {
+	msg := fmt.Sprintf("%s (%s)", w.Message(), w.Handle())
+	//
+	if src := w.Source(); src != nil {
+		printSyntaxError(src.File().SyntaxError(src.Expr(), msg))
+	} else {
+		fmt.Println(w.String())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().Bool("no-stdlib", false, "prevents the standard library from being included")
+	lintCmd.Flags().Bool("debug", false, "enable debugging constraints")
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/sexp"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// SARIF_SCHEMA identifies the schema to which SARIF reports conform.
+const SARIF_SCHEMA = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// failureReport accumulates structured information about the failures arising
+// from checking a trace, such that these can be output in a machine-readable
+// format (e.g. for use in continuous integration).
+type failureReport struct {
+	// Failures recorded thus far.
+	Failures []failureEntry `json:"failures"`
+}
+
+// failureEntry describes a single failure of a given constraint.
+type failureEntry struct {
+	// Handle of the failing constraint.
+	Handle string `json:"handle"`
+	// Kind of the failing constraint (e.g. vanishing, lookup, etc).
+	Kind string `json:"kind"`
+	// IR level at which the failure arose.
+	IR string `json:"ir"`
+	// Module in which the failure arose.
+	Module string `json:"module"`
+	// Row on which the failure arose (if applicable).
+	Row *uint `json:"row,omitempty"`
+	// Human-readable description of the failure.
+	Message string `json:"message"`
+	// Cells involved in the failure.
+	Cells []cellEntry `json:"cells,omitempty"`
+	// Source location of the failing constraint (if known).
+	Source *sourceEntry `json:"source,omitempty"`
+}
+
+// cellEntry describes a given trace cell involved in a failure.
+type cellEntry struct {
+	Column string `json:"column"`
+	Row    int    `json:"row"`
+	Value  string `json:"value"`
+}
+
+// sourceEntry describes a region of a given source file.  Lines and columns are
+// counted from 1.
+type sourceEntry struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+}
+
+// newFailureReport constructs an initially empty report.
+func newFailureReport() *failureReport {
+	return &failureReport{[]failureEntry{}}
+}
+
+// Record a set of failures arising at a given IR level.
+func (p *failureReport) add(ir string, failures []sc.Failure, trace tr.Trace) {
+	for _, f := range failures {
+		entry := failureEntry{Handle: failureHandle(f), Kind: failureKind(f), IR: ir, Message: f.Message()}
+		//
+		if row, ok := failureRow(f); ok {
+			entry.Row = &row
+		}
+		//
+		cols, cells := failureCells(f, trace)
+		//
+		if len(cols) > 0 {
+			entry.Module = trace.Modules().Nth(trace.Column(cols[0]).Context().Module()).Name()
+		}
+		//
+		for _, c := range cells {
+			col := trace.Column(c.Column)
+			mod := trace.Modules().Nth(col.Context().Module()).Name()
+			val := col.Get(c.Row)
+			entry.Cells = append(entry.Cells, cellEntry{tr.QualifiedColumnName(mod, col.Name()), c.Row, val.String()})
+		}
+		// Identify the sub-expression responsible (where known).
+		if src := failureSource(f); src != nil {
+			entry.Source = newSourceEntry(src.File(), src.Expr())
+		}
+		//
+		p.Failures = append(p.Failures, entry)
+	}
+}
+
+// Construct a source entry describing a given span of a given file.
+func newSourceEntry(file *sexp.SourceFile, span sexp.Span) *sourceEntry {
+	line, col := file.LineAndColumn(span.Start())
+	endLine, endCol := file.LineAndColumn(span.End())
+	//
+	return &sourceEntry{file.Filename(), line, col, endLine, endCol}
+}
+
+// Determine the kind of constraint responsible for a given failure.
+func failureKind(failure sc.Failure) string {
+	switch failure.(type) {
+	case *constraint.VanishingFailure:
+		return "vanishing"
+	case *sc.AssertionFailure:
+		return "assertion"
+	case *constraint.LookupFailure:
+		return "lookup"
+	case *constraint.MultiplicityFailure:
+		return "lookup"
+	case *constraint.RangeFailure:
+		return "range"
+	case *constraint.PermutationFailure:
+		return "permutation"
+	default:
+		return "unknown"
+	}
+}
+
+// Determine the columns and cells involved in a given failure.  The columns
+// are primarily used to identify the enclosing module, since some failures
+// (e.g. permutations) are not associated with any specific cells.
+func failureCells(failure sc.Failure, trace tr.Trace) ([]uint, []tr.CellRef) {
+	var cells *util.AnySortedSet[tr.CellRef]
+	//
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		cells = f.RequiredCells(trace)
+	case *sc.AssertionFailure:
+		cells = f.RequiredCells(trace)
+	case *constraint.LookupFailure:
+		cells = f.RequiredCells(trace)
+	case *constraint.MultiplicityFailure:
+		cells = f.RequiredCells(trace)
+	case *constraint.RangeFailure:
+		cells = f.RequiredCells(trace)
+	case *constraint.PermutationFailure:
+		return f.Targets(), nil
+	default:
+		return nil, nil
+	}
+	//
+	refs := cells.ToArray()
+	cols := make([]uint, len(refs))
+	//
+	for i, c := range refs {
+		cols[i] = c.Column
+	}
+	//
+	return cols, refs
+}
+
+// Convert this report into JSON.
+func (p *failureReport) toJSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// Convert this report into a SARIF log, such that failures can be used to
+// annotate the original source files (e.g. in code review tools).
+func (p *failureReport) toSARIF() ([]byte, error) {
+	var (
+		rules   []sarifRule
+		results = []sarifResult{}
+		kinds   = make(map[string]bool)
+	)
+	//
+	for _, f := range p.Failures {
+		if !kinds[f.Kind] {
+			kinds[f.Kind] = true
+			rules = append(rules, sarifRule{f.Kind, sarifMessage{fmt.Sprintf("%s constraint failure", f.Kind)}})
+		}
+		//
+		result := sarifResult{
+			RuleID:  f.Kind,
+			Level:   "error",
+			Message: sarifMessage{fmt.Sprintf("%s (%s)", f.Message, f.IR)},
+			Properties: sarifProperties{
+				Handle: f.Handle,
+				IR:     f.IR,
+				Module: f.Module,
+				Row:    f.Row,
+			},
+		}
+		//
+		if f.Source != nil {
+			region := sarifRegion{f.Source.Line, f.Source.Column, f.Source.EndLine, f.Source.EndColumn}
+			location := sarifPhysicalLocation{sarifArtifactLocation{f.Source.File}, region}
+			result.Locations = []sarifLocation{{location}}
+		}
+		//
+		results = append(results, result)
+	}
+	//
+	log := sarifLog{
+		Schema:  SARIF_SCHEMA,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{sarifTool{sarifDriver{"go-corset", rules}}, results}},
+	}
+	//
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// ============================================================================
+// SARIF
+// ============================================================================
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type sarifProperties struct {
+	Handle string `json:"handle"`
+	IR     string `json:"ir"`
+	Module string `json:"module"`
+	Row    *uint  `json:"row,omitempty"`
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"runtime"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	tr "github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/util"
@@ -46,7 +48,9 @@ var testCmd = &cobra.Command{
 		cfg.padding.Right = GetUint(cmd, "padding")
 		cfg.parallelExpansion = !GetFlag(cmd, "sequential")
 		cfg.batchSize = GetUint(cmd, "batch")
+		cfg.workers = GetUint(cmd, "workers")
 		cfg.ansiEscapes = GetFlag(cmd, "ansi-escapes")
+		cfg.failureLimit = 1
 		// TODO: support true ranges
 		cfg.padding.Left = cfg.padding.Right
 		// Normalise IRs
@@ -86,18 +90,18 @@ func runTests(nrows uint, cfg checkConfig, hirSchema *hir.Schema) bool {
 func testTraceWithLowering(trace tr.Trace, schema *hir.Schema, cfg checkConfig) bool {
 	ok := true
 	// Check whether assertions hold for this trace
-	asserts := sc.Asserts(cfg.batchSize, schema, trace)
+	asserts := sc.AssertsWith(cfg.batchSize, cfg.workers, cfg.failureLimit, schema, trace)
 	// Process individually
 	if cfg.hir {
 		ok = testTrace("HIR", asserts, trace, schema, cfg) && ok
 	}
 
 	if cfg.mir {
-		ok = testTrace("MIR", asserts, trace, schema.LowerToMir(), cfg) && ok
+		ok = testTrace("MIR", asserts, trace, lowerToMir(schema), cfg) && ok
 	}
 
 	if cfg.air {
-		ok = testTrace("AIR", asserts, trace, schema.LowerToMir().LowerToAir(), cfg) && ok
+		ok = testTrace("AIR", asserts, trace, lowerToAir(lowerToMir(schema), mir.LoweringConfig{}), cfg) && ok
 	}
 
 	return ok
@@ -108,9 +112,11 @@ func testTrace(ir string, asserts []sc.Failure, trace tr.Trace, schema sc.Schema
 	//
 	for n := cfg.padding.Left; n <= cfg.padding.Right; n++ {
 		// Check constraints
-		if errs := sc.Accepts(cfg.batchSize, schema, trace); len(asserts) > 0 && len(errs) == 0 {
+		errs := sc.AcceptsWith(cfg.batchSize, cfg.workers, cfg.failureLimit, schema, trace)
+		//
+		if len(asserts) > 0 && len(errs) == 0 {
 			// Trace accepts, but at least one assertion has failed.
-			reportFailures(ir, asserts, trace, cfg)
+			reportFailures(ir, asserts, schema, trace, cfg)
 			// Indicate all is not well
 			ok = false
 		}
@@ -146,6 +152,8 @@ func init() {
 	testCmd.Flags().Bool("sequential", false, "perform sequential trace expansion")
 	testCmd.Flags().Uint("padding", 0, "specify amount of (front) padding to apply")
 	testCmd.Flags().UintP("batch", "b", math.MaxUint, "specify batch size for constraint checking")
+	testCmd.Flags().Uint("workers", uint(runtime.NumCPU()),
+		"specify number of workers across which the rows of a constraint are checked")
 	testCmd.Flags().Int("spillage", -1,
 		"specify amount of splillage to account for (where -1 indicates this should be inferred)")
 	testCmd.Flags().Bool("ansi-escapes", true, "specify whether to allow ANSI escapes or not (e.g. for colour reports)")
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/lt"
 	"github.com/consensys/go-corset/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +25,11 @@ var traceCmd = &cobra.Command{
 			fmt.Println(cmd.UsageString())
 			os.Exit(1)
 		}
+		// Upgrade trace file (if requested)
+		if GetFlag(cmd, "convert") {
+			convertTraceFile(args[0], GetString(cmd, "out"))
+			return
+		}
 		// Parse trace
 		cols := readTraceFile(args[0])
 		list := GetFlag(cmd, "list")
@@ -68,6 +74,46 @@ func init() {
 	traceCmd.Flags().Uint("max-width", 32, "specify maximum display width for a column")
 	traceCmd.Flags().StringP("out", "o", "", "Specify output file to write trace")
 	traceCmd.Flags().StringP("filter", "f", "", "Filter columns matching regex")
+	traceCmd.Flags().Bool("convert", false,
+		"upgrade an lt trace file to the latest format (in place, unless an output file is given)")
+}
+
+// Upgrade a given LT trace file to the latest version of the format, whilst
+// preserving any module metadata.  The original file is verified first, such
+// that corrupted columns are not given fresh checksums.  The upgraded file is first written alongside
+// its target and then moved into place, since the original file remains mapped
+// into memory whilst it is being read.
+func convertTraceFile(filename string, output string) {
+	file, err := lt.OpenFile(filename)
+	//
+	if output == "" {
+		output = filename
+	}
+	//
+	if err == nil {
+		err = file.Verify()
+	}
+	//
+	if err == nil {
+		var bytes []byte
+		//
+		if bytes, err = lt.ToBytesV2(file.Columns(), file.Header().Metadata); err == nil {
+			tmpfile := output + ".tmp"
+			//
+			if err = os.WriteFile(tmpfile, bytes, 0644); err == nil {
+				err = os.Rename(tmpfile, output)
+			}
+		}
+		//
+		if err == nil {
+			err = file.Close()
+		}
+	}
+	// Handle error
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
 }
 
 // Construct a new trace containing only those columns from the original who
@@ -6,9 +6,12 @@ import (
 	"path"
 	"strings"
 
+	"github.com/consensys/go-corset/pkg/air"
 	"github.com/consensys/go-corset/pkg/binfile"
 	"github.com/consensys/go-corset/pkg/corset"
 	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/trace/json"
@@ -71,6 +74,18 @@ func GetStringArray(cmd *cobra.Command, flag string) []string {
 	return r
 }
 
+// Get the maximum constraint degree for lowering, checking it is either 0
+// (i.e. unbounded) or at least 2, since lower bounds cannot be met.
+func getMaxDegree(cmd *cobra.Command) uint {
+	degree := GetUint(cmd, "max-degree")
+	if degree == 1 {
+		fmt.Println("maximum degree must be at least 2")
+		os.Exit(4)
+	}
+
+	return degree
+}
+
 // Write a given trace file to disk
 func writeTraceFile(filename string, columns []trace.RawColumn) {
 	var err error
@@ -104,28 +119,32 @@ func writeTraceFile(filename string, columns []trace.RawColumn) {
 
 // Parse a trace file using a parser based on the extension of the filename.
 func readTraceFile(filename string) []trace.RawColumn {
-	var tr []trace.RawColumn
-	// Read data file
-	bytes, err := os.ReadFile(filename)
-	// Check success
-	if err == nil {
-		// Check file extension
-		ext := path.Ext(filename)
-		//
-		switch ext {
-		case ".json":
+	var (
+		tr  []trace.RawColumn
+		err error
+	)
+	// Check file extension
+	switch ext := path.Ext(filename); ext {
+	case ".json":
+		var bytes []byte
+		// Read data file
+		if bytes, err = os.ReadFile(filename); err == nil {
 			tr, err = json.FromBytes(bytes)
-			if err == nil {
-				return tr
-			}
-		case ".lt":
-			tr, err = lt.FromBytes(bytes)
-			if err == nil {
-				return tr
-			}
-		default:
-			err = fmt.Errorf("Unknown trace file format: %s", ext)
 		}
+	case ".lt":
+		var file *lt.File
+		// Memory map data file, such that columns are only decoded when needed.
+		// Observe that the file is never closed, since the columns must remain
+		// accessible until the process exits.
+		if file, err = lt.OpenFile(filename); err == nil {
+			tr = file.Columns()
+		}
+	default:
+		err = fmt.Errorf("Unknown trace file format: %s", ext)
+	}
+	// Check success
+	if err == nil {
+		return tr
 	}
 	// Handle error
 	fmt.Println(err)
@@ -153,8 +172,14 @@ func readBinaryFile(filename string) *hir.Schema {
 	// Read schema file
 	bytes, err := os.ReadFile(filename)
 	// Handle errors
-	if err == nil {
-		// Read the binary file
+	if err == nil && hir.IsBinaryFile(bytes) {
+		// Read the compiled schema
+		schema, err = hir.FromBytes(bytes)
+		if err == nil {
+			return schema
+		}
+	} else if err == nil {
+		// Read the (legacy) binary file
 		schema, err = binfile.HirSchemaFromJson(bytes)
 		if err == nil {
 			return schema
@@ -170,19 +195,7 @@ func readBinaryFile(filename string) *hir.Schema {
 // Parse a set of source files and compile them into a single schema.  This can
 // result, for example, in a syntax error, etc.
 func readSourceFiles(stdlib bool, debug bool, filenames []string) *hir.Schema {
-	srcfiles := make([]*sexp.SourceFile, len(filenames))
-	// Read each file
-	for i, n := range filenames {
-		// Read source file
-		bytes, err := os.ReadFile(n)
-		// Sanity check for errors
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(3)
-		}
-		//
-		srcfiles[i] = sexp.NewSourceFile(n, bytes)
-	}
+	srcfiles := loadSourceFiles(filenames)
 	// Parse and compile source files
 	schema, errs := corset.CompileSourceFiles(stdlib, debug, srcfiles)
 	// Check for any errors
@@ -199,6 +212,69 @@ func readSourceFiles(stdlib bool, debug bool, filenames []string) *hir.Schema {
 	return nil
 }
 
+// Lower a given HIR schema to the MIR level, reporting any errors arising and
+// exiting if there are any.
+func lowerToMir(schema *hir.Schema) *mir.Schema {
+	mirSchema, errs := schema.LowerToMir()
+	// Check for any errors
+	if len(errs) == 0 {
+		return mirSchema
+	}
+	// Report errors
+	printLoweringErrors(errs)
+	// Fail
+	os.Exit(4)
+	// unreachable
+	return nil
+}
+
+// Lower a given MIR schema to the AIR level using a given configuration,
+// reporting any errors arising and exiting if there are any.
+func lowerToAir(schema *mir.Schema, cfg mir.LoweringConfig) *air.Schema {
+	airSchema, errs := schema.LowerToAirWith(cfg)
+	// Check for any errors
+	if len(errs) == 0 {
+		return airSchema
+	}
+	// Report errors
+	printLoweringErrors(errs)
+	// Fail
+	os.Exit(4)
+	// unreachable
+	return nil
+}
+
+// Print lowering errors, highlighting the originating declaration (if known).
+func printLoweringErrors(errs []sc.LoweringError) {
+	for _, err := range errs {
+		if src := err.Source(); src != nil {
+			msg := fmt.Sprintf("%s (lowering %s to %s)", err.Message(), err.Handle(), err.Level())
+			printSyntaxError(src.File().SyntaxError(src.Expr(), msg))
+		} else {
+			fmt.Println(err.Error())
+		}
+	}
+}
+
+// Read the contents of a set of source files.
+func loadSourceFiles(filenames []string) []*sexp.SourceFile {
+	srcfiles := make([]*sexp.SourceFile, len(filenames))
+	// Read each file
+	for i, n := range filenames {
+		// Read source file
+		bytes, err := os.ReadFile(n)
+		// Sanity check for errors
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(3)
+		}
+		//
+		srcfiles[i] = sexp.NewSourceFile(n, bytes)
+	}
+	//
+	return srcfiles
+}
+
 // Print a syntax error with appropriate highlighting.
 func printSyntaxError(err *sexp.SyntaxError) {
 	span := err.Span()
@@ -0,0 +1,300 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// GenerateGo generates Go source code for building traces of a given schema.
+// Specifically, a struct is generated for each module with a setter method for
+// each of its input columns.  The type accepted by each setter is determined by
+// the column's type, and values which do not fit within the column's bitwidth
+// are rejected (by panicking).  Finally, a Trace struct is generated which
+// contains every module and produces the corresponding raw columns (in the
+// order they are declared in the schema).
+func GenerateGo(pkgname string, schema *hir.Schema) (string, error) {
+	var (
+		gen     goGenerator
+		modules = make([]goModule, 0)
+		names   = make(map[string]bool)
+	)
+	// Initialise modules
+	for i := schema.Modules(); i.HasNext(); {
+		mod := i.Next()
+		name := uniqueIdentifier(mod.Name(), "Root", names)
+		modules = append(modules, goModule{mod.Name(), name, make(map[string]bool), nil})
+	}
+	// Allocate columns to modules
+	for i := schema.InputColumns(); i.HasNext(); {
+		col := i.Next()
+		mod := &modules[col.Context().Module()]
+		name := uniqueIdentifier(col.Name(), "Column", mod.setters)
+		mod.columns = append(mod.columns, goColumn{col, name})
+	}
+	//
+	gen.generateTrace(modules, schema)
+	//
+	for _, mod := range modules {
+		gen.generateModule(mod)
+	}
+	//
+	gen.generateColumnHelper()
+	// Format generated code
+	source := generateHeader(pkgname, gen.imports) + gen.String()
+	bytes, err := format.Source([]byte(source))
+	//
+	return string(bytes), err
+}
+
+// Represents a module for which code is being generated.
+type goModule struct {
+	// Name of the module in the schema.
+	name string
+	// Name of the generated identifier for this module.
+	identifier string
+	// Setter names allocated so far (used to avoid name clashes).
+	setters map[string]bool
+	// Input columns of this module (in order of declaration).
+	columns []goColumn
+}
+
+// Represents an input column for which code is being generated.
+type goColumn struct {
+	column sc.Column
+	// Name of the generated identifier for this column.
+	identifier string
+}
+
+type goGenerator struct {
+	strings.Builder
+	// Imports required by the generated code (beyond those always required).
+	imports []string
+}
+
+func (p *goGenerator) printf(format string, args ...any) {
+	p.WriteString(fmt.Sprintf(format, args...))
+}
+
+func generateHeader(pkgname string, imports []string) string {
+	var header strings.Builder
+	//
+	header.WriteString("// Code generated by go-corset. DO NOT EDIT.\n\n")
+	header.WriteString(fmt.Sprintf("package %s\n\n", pkgname))
+	header.WriteString("import (\n")
+	//
+	for _, imp := range imports {
+		header.WriteString(fmt.Sprintf("%q\n", imp))
+	}
+	//
+	header.WriteString("\n\"github.com/consensys/gnark-crypto/ecc/bls12-377/fr\"\n")
+	header.WriteString("\"github.com/consensys/go-corset/pkg/trace\"\n")
+	header.WriteString("\"github.com/consensys/go-corset/pkg/util\"\n")
+	header.WriteString(")\n\n")
+	//
+	return header.String()
+}
+
+// Record that the generated code requires a given import.
+func (p *goGenerator) require(imports ...string) {
+	for _, imp := range imports {
+		if !slices.Contains(p.imports, imp) {
+			p.imports = append(p.imports, imp)
+		}
+	}
+	//
+	slices.Sort(p.imports)
+}
+
+func (p *goGenerator) generateTrace(modules []goModule, schema *hir.Schema) {
+	p.printf("// Trace provides typed access to the input columns of every module.\n")
+	p.printf("type Trace struct {\n")
+	//
+	for _, mod := range modules {
+		p.printf("%s %sModule\n", mod.identifier, mod.identifier)
+	}
+	//
+	p.printf("}\n\n")
+	// Constructor
+	p.printf("// NewTrace constructs an empty trace.\n")
+	p.printf("func NewTrace() *Trace {\n")
+	p.printf("return &Trace{\n")
+	//
+	for _, mod := range modules {
+		p.printf("%s: %sModule{[%d]column{\n", mod.identifier, mod.identifier, len(mod.columns))
+		//
+		for _, col := range mod.columns {
+			p.printf("{%q, %q, %d, nil},\n", mod.name, col.column.Name(), col.column.Type().BitWidth())
+		}
+		//
+		p.printf("}},\n")
+	}
+	//
+	p.printf("}\n}\n\n")
+	// Raw columns
+	p.printf("// Columns returns the raw columns of this trace, in the order they are\n")
+	p.printf("// declared in the schema.\n")
+	p.printf("func (t *Trace) Columns() []trace.RawColumn {\n")
+	p.printf("return []trace.RawColumn{\n")
+	// Track index of each column within its module
+	indices := make([]uint, len(modules))
+	//
+	for i := schema.InputColumns(); i.HasNext(); {
+		mid := i.Next().Context().Module()
+		p.printf("t.%s.columns[%d].raw(),\n", modules[mid].identifier, indices[mid])
+		indices[mid]++
+	}
+	//
+	p.printf("}\n}\n\n")
+}
+
+func (p *goGenerator) generateModule(mod goModule) {
+	p.printf("// %sModule provides typed access to the input columns of module %q.\n",
+		mod.identifier, mod.name)
+	p.printf("type %sModule struct {\n", mod.identifier)
+	p.printf("columns [%d]column\n", len(mod.columns))
+	p.printf("}\n\n")
+	//
+	for i, col := range mod.columns {
+		p.generateSetter(mod, i, col)
+	}
+}
+
+func (p *goGenerator) generateSetter(mod goModule, index int, col goColumn) {
+	var (
+		name     = trace.QualifiedColumnName(mod.name, col.column.Name())
+		datatype = col.column.Type()
+		bitwidth = datatype.BitWidth()
+		receiver = fmt.Sprintf("func (m *%sModule) Set%s(row uint, val", mod.identifier, col.identifier)
+	)
+	//
+	p.printf("// Set%s sets the value of column %q (%s) on a given row.\n", col.identifier, name,
+		datatype.String())
+	//
+	switch {
+	case datatype.AsUint() == nil:
+		p.printf("%s fr.Element) {\n", receiver)
+		p.printf("m.columns[%d].set(row, val)\n", index)
+	case bitwidth <= 64:
+		gotype := goUintType(bitwidth)
+		p.printf("%s %s) {\n", receiver, gotype)
+		// Check whether bitwidth is less than that of the Go type.
+		if bitwidth < goUintWidth(bitwidth) {
+			p.require("fmt")
+			p.printf("if val >= (1 << %d) {\n", bitwidth)
+			p.printf("panic(fmt.Sprintf(\"value %%d out-of-bounds for column %s (%s)\", val))\n",
+				escape(name), datatype.String())
+			p.printf("}\n")
+		}
+		//
+		p.printf("m.columns[%d].set(row, fr.NewElement(uint64(val)))\n", index)
+	default:
+		p.require("fmt", "math/big")
+		p.printf("%s *big.Int) {\n", receiver)
+		p.printf("if val.Sign() < 0 || val.BitLen() > %d {\n", bitwidth)
+		p.printf("panic(fmt.Sprintf(\"value %%s out-of-bounds for column %s (%s)\", val.String()))\n",
+			escape(name), datatype.String())
+		p.printf("}\n")
+		p.printf("var element fr.Element\n")
+		p.printf("element.SetBigInt(val)\n")
+		p.printf("m.columns[%d].set(row, element)\n", index)
+	}
+	//
+	p.printf("}\n\n")
+}
+
+func (p *goGenerator) generateColumnHelper() {
+	p.WriteString(`// column holds the values assigned to a given column so far.
+type column struct {
+	module   string
+	name     string
+	bitwidth uint
+	data     []fr.Element
+}
+
+// set assigns a given value to a given row of this column, extending the column
+// (with zeros) as necessary.
+func (c *column) set(row uint, val fr.Element) {
+	for uint(len(c.data)) <= row {
+		c.data = append(c.data, fr.Element{})
+	}
+	//
+	c.data[row] = val
+}
+
+// raw converts this column into a raw trace column.
+func (c *column) raw() trace.RawColumn {
+	data := util.NewFrArray(uint(len(c.data)), c.bitwidth)
+	for i, v := range c.data {
+		data.Set(uint(i), v)
+	}
+	//
+	return trace.RawColumn{Module: c.module, Name: c.name, Data: data}
+}
+`)
+}
+
+// Determine the smallest Go unsigned integer type which can hold values of a
+// given bitwidth (up to 64 bits).
+func goUintType(bitwidth uint) string {
+	return fmt.Sprintf("uint%d", goUintWidth(bitwidth))
+}
+
+func goUintWidth(bitwidth uint) uint {
+	switch {
+	case bitwidth <= 8:
+		return 8
+	case bitwidth <= 16:
+		return 16
+	case bitwidth <= 32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// Construct a unique (exported) Go identifier from a given name, such as
+// "BYTE_HI" becoming "ByteHi".  If the name is empty (or starts with a digit)
+// then the given default is used as a prefix.  Names already in use are
+// disambiguated by appending a suffix.
+func uniqueIdentifier(name string, prefix string, used map[string]bool) string {
+	var builder strings.Builder
+	// Split name into words
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	//
+	for _, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		builder.WriteString(string(runes))
+	}
+	//
+	identifier := builder.String()
+	//
+	if identifier == "" || !unicode.IsLetter([]rune(identifier)[0]) {
+		identifier = prefix + identifier
+	}
+	// Disambiguate (if necessary)
+	unique := identifier
+	for i := 1; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", identifier, i)
+	}
+	//
+	used[unique] = true
+	//
+	return unique
+}
+
+// Escape a name for inclusion within a format string, which is itself within a
+// string literal.
+func escape(name string) string {
+	quoted := fmt.Sprintf("%q", name)
+	return strings.ReplaceAll(quoted[1:len(quoted)-1], "%", "%%")
+}
@@ -149,6 +149,9 @@ type ColumnBinding struct {
 	cid uint
 	// Column's enclosing module
 	module string
+	// Column's enclosing perspective (if applicable), or the empty string
+	// otherwise.
+	perspective string
 	// Determines whether this is a computed column, or not.
 	computed bool
 	// Determines whether this column must be proven (or not).
@@ -159,11 +162,13 @@ type ColumnBinding struct {
 	dataType Type
 }
 
-// NewInputColumnBinding constructs a new column binding in a given module.
-// This is for the case where all information about the column is already known,
-// and will not be inferred from elsewhere.
-func NewInputColumnBinding(module string, mustProve bool, multiplier uint, datatype Type) *ColumnBinding {
-	return &ColumnBinding{math.MaxUint, module, false, mustProve, multiplier, datatype}
+// NewInputColumnBinding constructs a new column binding in a given module (and,
+// optionally, a given perspective within that module).  This is for the case
+// where all information about the column is already known, and will not be
+// inferred from elsewhere.
+func NewInputColumnBinding(module string, perspective string, mustProve bool, multiplier uint,
+	datatype Type) *ColumnBinding {
+	return &ColumnBinding{math.MaxUint, module, perspective, false, mustProve, multiplier, datatype}
 }
 
 // NewComputedColumnBinding constructs a new column binding in a given
@@ -173,7 +178,7 @@ func NewInputColumnBinding(module string, mustProve bool, multiplier uint, datat
 // not immediately available and must be determined from those columns from
 // which it is constructed.
 func NewComputedColumnBinding(module string) *ColumnBinding {
-	return &ColumnBinding{math.MaxUint, module, true, false, 0, nil}
+	return &ColumnBinding{math.MaxUint, module, "", true, false, 0, nil}
 }
 
 // IsFinalised checks whether this binding has been finalised yet or not.
@@ -208,6 +213,12 @@ func (p *ColumnBinding) ColumnId() uint {
 	return p.cid
 }
 
+// Perspective returns the name of the perspective in which this column was
+// declared, or the empty string if it was not declared in a perspective.
+func (p *ColumnBinding) Perspective() string {
+	return p.perspective
+}
+
 // ============================================================================
 // ConstantBinding
 // ============================================================================
@@ -242,6 +253,45 @@ func (p *ConstantBinding) Context() Context {
 	return tr.VoidContext[string]()
 }
 
+// ============================================================================
+// PerspectiveBinding
+// ============================================================================
+
+// PerspectiveBinding represents a perspective within a given module.  A
+// perspective groups together a set of columns which are only "active" when a
+// given selector expression is non-zero.  Since at most one perspective in a
+// module should be active on any given row, columns from different perspectives
+// can share the same underlying registers.
+type PerspectiveBinding struct {
+	// Selector expression which determines (when non-zero) on which rows this
+	// perspective is active.
+	selector Expr
+	// Indicates whether or not the selector has been resolved.
+	resolved bool
+}
+
+// NewPerspectiveBinding constructs a new (unfinalised) perspective binding for a
+// given selector expression.
+func NewPerspectiveBinding(selector Expr) PerspectiveBinding {
+	return PerspectiveBinding{selector, false}
+}
+
+// IsFinalised checks whether this binding has been finalised yet or not.
+func (p *PerspectiveBinding) IsFinalised() bool {
+	return p.resolved
+}
+
+// Finalise this binding, which indicates the selector expression has been
+// resolved.
+func (p *PerspectiveBinding) Finalise() {
+	p.resolved = true
+}
+
+// Selector returns the selector expression for this perspective.
+func (p *PerspectiveBinding) Selector() Expr {
+	return p.selector
+}
+
 // ============================================================================
 // ParameterBinding
 // ============================================================================
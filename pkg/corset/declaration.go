@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/util"
 )
@@ -264,6 +265,58 @@ func (e *DefColumn) Lisp() sexp.SExp {
 	return list
 }
 
+// ============================================================================
+// defcomputedcolumn
+// ============================================================================
+
+// DefComputedColumn declares a new column whose values are computed from a
+// given expression during trace expansion, rather than being provided by the
+// user.  For example, "(defcomputedcolumn (Z :u16) (+ X Y))" declares a column
+// Z whose value on each row is the sum of X and Y on that row.  Observe that no
+// constraints are generated to enforce this relationship (unless the column's
+// type must be proven).
+type DefComputedColumn struct {
+	// The target column being defined
+	Target *DefColumn
+	// The expression used to compute the value of the target column on each
+	// row.
+	Computation Expr
+}
+
+// Definitions returns the set of symbols defined by this declaration.  Observe
+// that these may not yet have been finalised.
+func (p *DefComputedColumn) Definitions() util.Iterator[SymbolDefinition] {
+	iter := util.NewUnitIterator(p.Target)
+	return util.NewCastIterator[*DefColumn, SymbolDefinition](iter)
+}
+
+// Dependencies needed to signal declaration.
+func (p *DefComputedColumn) Dependencies() util.Iterator[Symbol] {
+	return util.NewArrayIterator(p.Computation.Dependencies())
+}
+
+// Defines checks whether this declaration defines the given symbol.  The symbol
+// in question needs to have been resolved already for this to make sense.
+func (p *DefComputedColumn) Defines(symbol Symbol) bool {
+	return &p.Target.binding == symbol.Binding()
+}
+
+// IsFinalised checks whether this declaration has already been finalised.  If
+// so, then we don't need to finalise it again.
+func (p *DefComputedColumn) IsFinalised() bool {
+	return p.Target.binding.IsFinalised()
+}
+
+// Lisp converts this node into its lisp representation.  This is primarily used
+// for debugging purposes.
+func (p *DefComputedColumn) Lisp() sexp.SExp {
+	return sexp.NewList([]sexp.SExp{
+		sexp.NewSymbol("defcomputedcolumn"),
+		p.Target.Lisp(),
+		p.Computation.Lisp(),
+	})
+}
+
 // ============================================================================
 // defconst
 // ============================================================================
@@ -387,15 +440,20 @@ type DefConstraint struct {
 	// debugging (i.e. so we know which constaint failed, etc).
 	Handle string
 	// Domain of this constraint, where nil indicates a global constraint.
-	// Otherwise, a given value indicates a single row on which this constraint
-	// should apply (where negative values are taken from the end, meaning that
-	// -1 represents the last row of a given module).
-	Domain *int
+	// Otherwise, this identifies the rows on which this constraint should apply
+	// (where negative rows are taken from the end, meaning that -1 represents
+	// the last row of a given module).
+	Domain *constraint.Domain
 	// A selector which determines for which rows this constraint is active.
 	// Specifically, when the expression evaluates to a non-zero value then the
 	// constraint is active; otherwiser, its inactive. Nil is permitted to
 	// indicate no guard is present.
 	Guard Expr
+	// The perspective in which this constraint is declared (if applicable).
+	// When present, the constraint is additionally guarded by the
+	// perspective's selector, and columns declared in that perspective can be
+	// accessed directly.  Nil is permitted to indicate no perspective.
+	Perspective *PerspectiveName
 	// The constraint itself which (when active) should evaluate to zero for the
 	// relevant set of rows.
 	Constraint Expr
@@ -416,6 +474,10 @@ func (p *DefConstraint) Dependencies() util.Iterator[Symbol] {
 	if p.Guard != nil {
 		guard_deps = p.Guard.Dependencies()
 	}
+	// Include perspective (if applicable)
+	if p.Perspective != nil {
+		guard_deps = append(guard_deps, p.Perspective)
+	}
 	// Extract bodies dependencies
 	body_deps := p.Constraint.Dependencies()
 	// Done
@@ -446,10 +508,8 @@ func (p *DefConstraint) Lisp() sexp.SExp {
 	modifiers := sexp.EmptyList()
 	// domain
 	if p.Domain != nil {
-		domain := fmt.Sprintf("{%d}", *p.Domain)
-		//
 		modifiers.Append(sexp.NewSymbol(":domain"))
-		modifiers.Append(sexp.NewSymbol(domain))
+		modifiers.Append(sexp.NewSymbol(p.Domain.String()))
 	}
 	//
 	if p.Guard != nil {
@@ -457,6 +517,11 @@ func (p *DefConstraint) Lisp() sexp.SExp {
 		modifiers.Append(p.Guard.Lisp())
 	}
 	//
+	if p.Perspective != nil {
+		modifiers.Append(sexp.NewSymbol(":perspective"))
+		modifiers.Append(p.Perspective.Lisp())
+	}
+	//
 	return sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("defconstraint"),
 		sexp.NewSymbol(p.Handle),
@@ -596,7 +661,8 @@ func (p *DefInterleaved) Lisp() sexp.SExp {
 // set of source tuples is a subset of the target tuples.  This does not need to
 // be a strict subset, so the two sets can be identical.  Furthermore, these are
 // not treated as multi-sets, hence the number of occurrences of a given tuple
-// is not relevant.
+// is not relevant.  Optionally, either side may be guarded by a selector, in
+// which case only those rows where the selector is non-zero participate.
 type DefLookup struct {
 	// Unique handle given to this constraint.  This is primarily useful for
 	// debugging (i.e. so we know which constaint failed, etc).
@@ -607,6 +673,12 @@ type DefLookup struct {
 	// Target expressions for lookup (i.e. these values must contain all of the
 	// source values, but may contain more).
 	Targets []Expr
+	// Selector which determines which source rows participate in the lookup.
+	// Nil is permitted to indicate every source row participates.
+	SourceSelector Expr
+	// Selector which determines which target rows participate in the lookup.
+	// Nil is permitted to indicate every target row participates.
+	TargetSelector Expr
 	// Indicates whether or not target and source expressions have been resolved.
 	finalised bool
 }
@@ -621,6 +693,14 @@ func (p *DefLookup) Definitions() util.Iterator[SymbolDefinition] {
 func (p *DefLookup) Dependencies() util.Iterator[Symbol] {
 	sourceDeps := DependenciesOfExpressions(p.Sources)
 	targetDeps := DependenciesOfExpressions(p.Targets)
+	// Include selectors (if applicable)
+	if p.SourceSelector != nil {
+		sourceDeps = append(sourceDeps, p.SourceSelector.Dependencies()...)
+	}
+	//
+	if p.TargetSelector != nil {
+		targetDeps = append(targetDeps, p.TargetSelector.Dependencies()...)
+	}
 	// Combine deps
 	return util.NewArrayIterator(append(sourceDeps, targetDeps...))
 }
@@ -657,12 +737,24 @@ func (p *DefLookup) Lisp() sexp.SExp {
 		sources[i] = t.Lisp()
 	}
 	//
-	return sexp.NewList([]sexp.SExp{
+	list := sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("deflookup"),
 		sexp.NewSymbol(p.Handle),
 		sexp.NewList(targets),
 		sexp.NewList(sources),
 	})
+	// Selectors (if applicable)
+	if p.SourceSelector != nil {
+		list.Append(sexp.NewSymbol(":source-selector"))
+		list.Append(p.SourceSelector.Lisp())
+	}
+	//
+	if p.TargetSelector != nil {
+		list.Append(sexp.NewSymbol(":target-selector"))
+		list.Append(p.TargetSelector.Lisp())
+	}
+	//
+	return list
 }
 
 // ============================================================================
@@ -672,29 +764,42 @@ func (p *DefLookup) Lisp() sexp.SExp {
 // DefPermutation represents a (lexicographically sorted) permutation of a set
 // of source columns in a given source context, manifested as an assignment to a
 // corresponding set of target columns.  The sort direction for each of the
-// source columns can be specified as increasing or decreasing.
+// source columns can be specified as increasing or decreasing.  The source
+// columns may reside in a different module from the target columns, in which
+// case the height of the target module is determined by the permutation.
+// Furthermore, the source rows can be filtered by a selector column, in which
+// case a target selector column is also declared to identify the target rows
+// arising from selected source rows.
 type DefPermutation struct {
 	Targets []*DefColumn
 	Sources []Symbol
 	Signs   []bool
+	// Source selector (or nil if none given).
+	SourceSelector Symbol
+	// Target selector (or nil if none given).
+	TargetSelector *DefColumn
 }
 
 // Definitions returns the set of symbols defined by this declaration.  Observe
 // that these may not yet have been finalised.
 func (p *DefPermutation) Definitions() util.Iterator[SymbolDefinition] {
-	iter := util.NewArrayIterator(p.Targets)
+	iter := util.NewArrayIterator(p.columns())
 	return util.NewCastIterator[*DefColumn, SymbolDefinition](iter)
 }
 
 // Dependencies needed to signal declaration.
 func (p *DefPermutation) Dependencies() util.Iterator[Symbol] {
+	if p.SourceSelector != nil {
+		return util.NewArrayIterator(append(append([]Symbol{}, p.Sources...), p.SourceSelector))
+	}
+	//
 	return util.NewArrayIterator(p.Sources)
 }
 
 // Defines checks whether this declaration defines the given symbol.  The symbol
 // in question needs to have been resolved already for this to make sense.
 func (p *DefPermutation) Defines(symbol Symbol) bool {
-	for _, col := range p.Targets {
+	for _, col := range p.columns() {
 		if &col.binding == symbol.Binding() {
 			return true
 		}
@@ -706,7 +811,7 @@ func (p *DefPermutation) Defines(symbol Symbol) bool {
 // IsFinalised checks whether this declaration has already been finalised.  If
 // so, then we don't need to finalise it again.
 func (p *DefPermutation) IsFinalised() bool {
-	for _, col := range p.Targets {
+	for _, col := range p.columns() {
 		if !col.binding.IsFinalised() {
 			return false
 		}
@@ -715,6 +820,16 @@ func (p *DefPermutation) IsFinalised() bool {
 	return true
 }
 
+// Columns declared by this permutation, where the target selector (if present)
+// is declared last.
+func (p *DefPermutation) columns() []*DefColumn {
+	if p.TargetSelector != nil {
+		return append(append([]*DefColumn{}, p.Targets...), p.TargetSelector)
+	}
+	//
+	return p.Targets
+}
+
 // Lisp converts this node into its lisp representation.  This is primarily used
 // for debugging purposes.
 func (p *DefPermutation) Lisp() sexp.SExp {
@@ -738,10 +853,122 @@ func (p *DefPermutation) Lisp() sexp.SExp {
 			t.Lisp()})
 	}
 	//
-	return sexp.NewList([]sexp.SExp{
+	list := sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("defpermutation"),
 		sexp.NewList(targets),
 		sexp.NewList(sources)})
+	// Selectors
+	if p.SourceSelector != nil {
+		list.Append(sexp.NewSymbol(":source-selector"))
+		list.Append(p.SourceSelector.Lisp())
+	}
+	//
+	if p.TargetSelector != nil {
+		list.Append(sexp.NewSymbol(":target-selector"))
+		list.Append(p.TargetSelector.Lisp())
+	}
+	//
+	return list
+}
+
+// ============================================================================
+// defperspective
+// ============================================================================
+
+// DefPerspective represents a set of columns within a module which are only
+// "active" when a given selector expression evaluates to a non-zero value.  Each
+// column declared within a perspective is named relative to that perspective
+// (e.g. column X declared in perspective p is named "p/X").  Constraints
+// declared with the ":perspective" attribute can refer to those columns
+// directly by their unqualified name, and are automatically guarded by the
+// selector.  Since at most one perspective is expected to be active on any given
+// row, columns from different perspectives can be allocated to the same
+// underlying register.
+type DefPerspective struct {
+	// Name of the perspective.
+	name string
+	// Columns declared within this perspective.
+	Columns []*DefColumn
+	// Binding for this perspective (which includes the selector).
+	binding PerspectiveBinding
+}
+
+// IsFunction is never true for a perspective definition.
+func (p *DefPerspective) IsFunction() bool {
+	return false
+}
+
+// Binding returns the allocated binding for this symbol (which may or may not
+// be finalised).
+func (p *DefPerspective) Binding() Binding {
+	return &p.binding
+}
+
+// Name of symbol being defined
+func (p *DefPerspective) Name() string {
+	return p.name
+}
+
+// Selector returns the selector expression for this perspective.
+func (p *DefPerspective) Selector() Expr {
+	return p.binding.selector
+}
+
+// Definitions returns the set of symbols defined by this declaration.  Observe
+// that these may not yet have been finalised.
+func (p *DefPerspective) Definitions() util.Iterator[SymbolDefinition] {
+	defs := make([]SymbolDefinition, len(p.Columns)+1)
+	// Perspective itself
+	defs[0] = p
+	// Columns declared within perspective
+	for i, c := range p.Columns {
+		defs[i+1] = c
+	}
+	//
+	return util.NewArrayIterator(defs)
+}
+
+// Dependencies needed to signal declaration.
+func (p *DefPerspective) Dependencies() util.Iterator[Symbol] {
+	return util.NewArrayIterator(p.binding.selector.Dependencies())
+}
+
+// Defines checks whether this declaration defines the given symbol.  The symbol
+// in question needs to have been resolved already for this to make sense.
+func (p *DefPerspective) Defines(symbol Symbol) bool {
+	if &p.binding == symbol.Binding() {
+		return true
+	}
+	//
+	for _, col := range p.Columns {
+		if &col.binding == symbol.Binding() {
+			return true
+		}
+	}
+	// Done
+	return false
+}
+
+// IsFinalised checks whether this declaration has already been finalised.  If
+// so, then we don't need to finalise it again.
+func (p *DefPerspective) IsFinalised() bool {
+	return p.binding.IsFinalised()
+}
+
+// Lisp converts this node into its lisp representation.  This is primarily used
+// for debugging purposes.
+func (p *DefPerspective) Lisp() sexp.SExp {
+	columns := sexp.EmptyList()
+	// Add lisp for each individual column
+	for _, c := range p.Columns {
+		columns.Append(c.Lisp())
+	}
+	//
+	return sexp.NewList([]sexp.SExp{
+		sexp.NewSymbol("defperspective"),
+		sexp.NewSymbol(p.name),
+		p.binding.selector.Lisp(),
+		columns})
 }
 
 // ============================================================================
@@ -33,11 +33,23 @@ func NewGlobalEnvironment(scope *GlobalScope) GlobalEnvironment {
 	columnId := uint(0)
 	// Allocate input columns first.
 	for _, m := range scope.modules {
+		// Perspective columns allocated so far in this module.
+		var allocated []*ColumnBinding
+		//
 		for _, b := range m.bindings {
 			if binding, ok := b.(*ColumnBinding); ok && !binding.computed {
-				binding.AllocateId(columnId)
-				// Increase the column id
-				columnId += binding.dataType.Width()
+				// Check whether an existing register can be shared
+				if cid, ok := findPerspectiveRegister(binding, allocated); ok {
+					binding.AllocateId(cid)
+				} else {
+					binding.AllocateId(columnId)
+					// Increase the column id
+					columnId += binding.dataType.Width()
+				}
+				// Record perspective columns
+				if binding.perspective != "" {
+					allocated = append(allocated, binding)
+				}
 			}
 		}
 	}
@@ -55,6 +67,48 @@ func NewGlobalEnvironment(scope *GlobalScope) GlobalEnvironment {
 	return GlobalEnvironment{scope}
 }
 
+// Find an existing register onto which a given perspective column can be
+// allocated, given the set of perspective columns already allocated in the
+// enclosing module.  A column can share a register only when: (1) the register
+// holds no column from the same perspective; (2) the column and register have
+// identical types, length multipliers and proof obligations.  Array columns are
+// never shared.
+func findPerspectiveRegister(binding *ColumnBinding, allocated []*ColumnBinding) (uint, bool) {
+	if binding.perspective == "" || binding.dataType.Width() != 1 {
+		return 0, false
+	}
+	//
+	for _, col := range allocated {
+		if isRegisterCompatible(binding, col) && !isRegisterOccupied(binding.perspective, col.cid, allocated) {
+			return col.cid, true
+		}
+	}
+	// Failed
+	return 0, false
+}
+
+// Check whether a given column is compatible with another column already
+// allocated to some register, such that both can be stored in that register.
+func isRegisterCompatible(binding *ColumnBinding, col *ColumnBinding) bool {
+	lhs := binding.dataType.AsUnderlying()
+	rhs := col.dataType.AsUnderlying()
+	//
+	return col.dataType.Width() == 1 && binding.multiplier == col.multiplier &&
+		binding.mustProve == col.mustProve && lhs.SubtypeOf(rhs) && rhs.SubtypeOf(lhs)
+}
+
+// Check whether a column from a given perspective is already allocated to a
+// given register.
+func isRegisterOccupied(perspective string, cid uint, allocated []*ColumnBinding) bool {
+	for _, col := range allocated {
+		if col.cid == cid && col.perspective == perspective {
+			return true
+		}
+	}
+	//
+	return false
+}
+
 // Module returns the identifier of the module with the given name.
 func (p GlobalEnvironment) Module(name string) *ModuleScope {
 	return p.scope.Module(name)
@@ -2,11 +2,15 @@ package corset
 
 import (
 	"fmt"
+	"maps"
 	"math/big"
 	"reflect"
+	"slices"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // Expr represents an arbitrary expression over the columns of a given context
@@ -173,6 +177,68 @@ func (e *ArrayAccess) Dependencies() []Symbol {
 	return append(deps, e)
 }
 
+// ============================================================================
+// Bitwise
+// ============================================================================
+
+// Bitwise represents a bitwise operation (e.g. bitwise and, shift left, etc)
+// over one or more arguments, each of which is treated as an unsigned integer
+// of a fixed bitwidth.  For shift operations, the second argument is the shift
+// amount (which must ultimately be constant).  Such expressions arise only from
+// the corresponding intrinsics, where the bitwidth is determined by the types
+// of the actual arguments.
+type Bitwise struct {
+	Op    util.BitwiseOp
+	Width uint
+	Args  []Expr
+}
+
+// AsConstant attempts to evaluate this expression as a constant (signed) value.
+// If this expression is not constant, then nil is returned.
+func (e *Bitwise) AsConstant() *big.Int {
+	var (
+		args = make([]fr.Element, len(e.Args))
+		val  big.Int
+	)
+	//
+	for i, arg := range e.Args {
+		c := arg.AsConstant()
+		if c == nil || c.Sign() < 0 {
+			return nil
+		}
+		//
+		args[i].SetBigInt(c)
+	}
+	//
+	result := util.EvalBitwise(e.Op, e.Width, args)
+	//
+	return result.BigInt(&val)
+}
+
+// Multiplicity determines the number of values that evaluating this expression
+// can generate.
+func (e *Bitwise) Multiplicity() uint {
+	return determineMultiplicity(e.Args)
+}
+
+// Context returns the context for this expression.  Observe that the
+// expression must have been resolved for this to be defined (i.e. it may
+// panic if it has not been resolved yet).
+func (e *Bitwise) Context() Context {
+	return ContextOfExpressions(e.Args)
+}
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (e *Bitwise) Lisp() sexp.SExp {
+	return ListOfExpressions(sexp.NewSymbol(e.Op.String()), e.Args)
+}
+
+// Dependencies needed to signal declaration.
+func (e *Bitwise) Dependencies() []Symbol {
+	return DependenciesOfExpressions(e.Args)
+}
+
 // ============================================================================
 // Constants
 // ============================================================================
@@ -518,6 +584,113 @@ func (e *Invoke) Dependencies() []Symbol {
 	return append(deps, e.fn)
 }
 
+// ============================================================================
+// Let
+// ============================================================================
+
+// Let represents a block of one or more local variables which are bound to
+// given expressions within a body.  For example, "(let ((x (+ A 1)) (y (* x
+// x))) (- y B))" binds x to "(+ A 1)" and y to "(* x x)" within "(- y B)".
+// Observe that bindings are sequential, such that each binding is visible in
+// those following it.  Let expressions are eliminated during preprocessing by
+// inlining the bound expressions.
+type Let struct {
+	// Variable bindings
+	Vars []LocalVariableBinding
+	// Expressions bound to each variable
+	Args []Expr
+	// Body of let
+	Body Expr
+}
+
+// AsConstant attempts to evaluate this expression as a constant (signed) value.
+// If this expression is not constant, then nil is returned.
+func (e *Let) AsConstant() *big.Int {
+	// Bindings must be resolved in order to inline them
+	for _, v := range e.Vars {
+		if !v.IsFinalised() {
+			return nil
+		}
+	}
+	//
+	return e.Inline(nil).AsConstant()
+}
+
+// Multiplicity determines the number of values that evaluating this expression
+// can generate.
+func (e *Let) Multiplicity() uint {
+	return determineMultiplicity(append([]Expr{e.Body}, e.Args...))
+}
+
+// Context returns the context for this expression.  Observe that the
+// expression must have been resolved for this to be defined (i.e. it may
+// panic if it has not been resolved yet).
+func (e *Let) Context() Context {
+	// Accesses to local variables have no context of their own, hence the
+	// context is determined from the inlined form.
+	return e.Inline(nil).Context()
+}
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (e *Let) Lisp() sexp.SExp {
+	bindings := make([]sexp.SExp, len(e.Vars))
+	//
+	for i, v := range e.Vars {
+		bindings[i] = sexp.NewList([]sexp.SExp{sexp.NewSymbol(v.name), e.Args[i].Lisp()})
+	}
+	//
+	return sexp.NewList([]sexp.SExp{
+		sexp.NewSymbol("let"),
+		sexp.NewList(bindings),
+		e.Body.Lisp()})
+}
+
+// Dependencies needed to signal declaration.
+func (e *Let) Dependencies() []Symbol {
+	var deps []Symbol
+	// Remove occurrences of variables defined by this expression.  Since
+	// bindings are sequential, a variable only captures occurrences after its
+	// declaration.
+	for i, arg := range e.Args {
+		deps = append(deps, removeLocalDependencies(arg.Dependencies(), e.Vars[:i])...)
+	}
+	//
+	return append(deps, removeLocalDependencies(e.Body.Dependencies(), e.Vars)...)
+}
+
+// Inline this let expression by substituting each bound expression through
+// the body.  Observe that, since bindings are sequential, each bound expression
+// is itself substituted through those following it.
+func (e *Let) Inline(srcmap *sexp.SourceMaps[Node]) Expr {
+	mapping := make(map[uint]Expr)
+	//
+	for i, arg := range e.Args {
+		mapping[e.Vars[i].index] = Substitute(arg, mapping, srcmap)
+	}
+	//
+	return Substitute(e.Body, mapping, srcmap)
+}
+
+// Remove any dependencies on the given local variables.
+func removeLocalDependencies(deps []Symbol, vars []LocalVariableBinding) []Symbol {
+	var rest []Symbol
+	//
+	for _, s := range deps {
+		captured := false
+		//
+		for _, v := range vars {
+			captured = captured || (!s.IsQualified() && s.Name() == v.name)
+		}
+		//
+		if !captured {
+			rest = append(rest, s)
+		}
+	}
+	//
+	return rest
+}
+
 // ============================================================================
 // List
 // ============================================================================
@@ -556,6 +729,94 @@ func (e *List) Dependencies() []Symbol {
 	return DependenciesOfExpressions(e.Args)
 }
 
+// ============================================================================
+// Match
+// ============================================================================
+
+// Match represents a choice between one or more branches, based on the value
+// of a given selector.  For example, "(match OP (1 e1) (2 e2) (else e3))"
+// evaluates e1 when OP==1, e2 when OP==2 and e3 otherwise.  Each case value
+// must be a distinct constant.  The default branch is optional and, when it is
+// absent, nothing is produced if no case matches (analogous to an "if" without
+// a false branch).  Match expressions are eliminated during preprocessing by
+// desugaring them into a chain of conditionals.
+type Match struct {
+	// Selector being matched against
+	Selector Expr
+	// Case values (which must be constant)
+	Cases []Expr
+	// Branches corresponding to each case
+	Branches []Expr
+	// Default branch (optional)
+	Default Expr
+}
+
+// AsConstant attempts to evaluate this expression as a constant (signed) value.
+// If this expression is not constant, then nil is returned.
+func (e *Match) AsConstant() *big.Int {
+	return e.Desugar(nil).AsConstant()
+}
+
+// Multiplicity determines the number of values that evaluating this expression
+// can generate.
+func (e *Match) Multiplicity() uint {
+	return determineMultiplicity(append([]Expr{e.Selector, e.Default}, e.Branches...))
+}
+
+// Context returns the context for this expression.  Observe that the
+// expression must have been resolved for this to be defined (i.e. it may
+// panic if it has not been resolved yet).
+func (e *Match) Context() Context {
+	return ContextOfExpressions(append([]Expr{e.Selector, e.Default}, e.Branches...))
+}
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (e *Match) Lisp() sexp.SExp {
+	list := sexp.NewList([]sexp.SExp{sexp.NewSymbol("match"), e.Selector.Lisp()})
+	//
+	for i, c := range e.Cases {
+		list.Append(sexp.NewList([]sexp.SExp{c.Lisp(), e.Branches[i].Lisp()}))
+	}
+	//
+	if e.Default != nil {
+		list.Append(sexp.NewList([]sexp.SExp{sexp.NewSymbol("else"), e.Default.Lisp()}))
+	}
+	//
+	return list
+}
+
+// Dependencies needed to signal declaration.
+func (e *Match) Dependencies() []Symbol {
+	deps := DependenciesOfExpressions([]Expr{e.Selector, e.Default})
+	deps = append(deps, DependenciesOfExpressions(e.Cases)...)
+	//
+	return append(deps, DependenciesOfExpressions(e.Branches)...)
+}
+
+// Desugar this match expression into an equivalent chain of conditionals.  For
+// example, "(match OP (1 e1) (2 e2) (else e3))" becomes "(if (- OP 1) e1 (if (-
+// OP 2) e2 e3))", where each condition has if-zero semantics.  An (optional)
+// source map is provided which will be updated, such that the freshly created
+// conditionals are mapped to this expression.
+func (e *Match) Desugar(srcmap *sexp.SourceMaps[Node]) Expr {
+	expr := e.Default
+	//
+	for i := len(e.Cases) - 1; i >= 0; i-- {
+		condition := &Sub{[]Expr{e.Selector, e.Cases[i]}}
+		conditional := &If{1, condition, e.Branches[i], expr}
+		//
+		if srcmap != nil {
+			srcmap.Copy(e, condition)
+			srcmap.Copy(e, conditional)
+		}
+		//
+		expr = conditional
+	}
+	//
+	return expr
+}
+
 // ============================================================================
 // Multiplication
 // ============================================================================
@@ -910,7 +1171,9 @@ func ContextOfExpressions(exprs []Expr) Context {
 	context := tr.VoidContext[string]()
 	//
 	for _, e := range exprs {
-		context = context.Join(e.Context())
+		if e != nil {
+			context = context.Join(e.Context())
+		}
 	}
 	//
 	return context
@@ -930,6 +1193,9 @@ func Substitute(expr Expr, mapping map[uint]Expr, srcmap *sexp.SourceMaps[Node])
 	case *Add:
 		args := SubstituteAll(e.Args, mapping, srcmap)
 		nexpr = &Add{args}
+	case *Bitwise:
+		args := SubstituteAll(e.Args, mapping, srcmap)
+		nexpr = &Bitwise{e.Op, e.Width, args}
 	case *Constant:
 		return e
 	case *Debug:
@@ -952,9 +1218,33 @@ func Substitute(expr Expr, mapping map[uint]Expr, srcmap *sexp.SourceMaps[Node])
 	case *Invoke:
 		args := SubstituteAll(e.args, mapping, srcmap)
 		nexpr = &Invoke{e.fn, e.signature, args}
+	case *Let:
+		var (
+			// Variables are copied so that resolving this instance does not
+			// affect the original.
+			vars = slices.Clone(e.Vars)
+			args = make([]Expr, len(e.Args))
+			// Variables bound here shadow those in the mapping which have the
+			// same index, and this must be respected to avoid capturing them.
+			inner = maps.Clone(mapping)
+		)
+		//
+		for i, arg := range e.Args {
+			args[i] = Substitute(arg, inner, srcmap)
+			delete(inner, e.Vars[i].index)
+		}
+		//
+		body := Substitute(e.Body, inner, srcmap)
+		nexpr = &Let{vars, args, body}
 	case *List:
 		args := SubstituteAll(e.Args, mapping, srcmap)
 		nexpr = &List{args}
+	case *Match:
+		selector := Substitute(e.Selector, mapping, srcmap)
+		cases := SubstituteAll(e.Cases, mapping, srcmap)
+		branches := SubstituteAll(e.Branches, mapping, srcmap)
+		dflt := SubstituteOptional(e.Default, mapping, srcmap)
+		nexpr = &Match{selector, cases, branches, dflt}
 	case *Mul:
 		args := SubstituteAll(e.Args, mapping, srcmap)
 		nexpr = &Mul{args}
@@ -1047,15 +1337,20 @@ func ListOfExpressions(head sexp.SExp, exprs []Expr) *sexp.List {
 // any of the expressions are not themselves constant, then neither is the
 // result.
 func AsConstantOfExpressions(exprs []Expr, fn func(*big.Int, *big.Int) *big.Int) *big.Int {
-	var val *big.Int = big.NewInt(0)
+	var val *big.Int
 	//
 	for _, arg := range exprs {
 		c := arg.AsConstant()
 		if c == nil {
 			return nil
+		} else if val == nil {
+			// Fold starts from the first argument (rather than zero), since
+			// otherwise subtraction and multiplication are incorrect.
+			val = new(big.Int).Set(c)
+		} else {
+			// Evaluate function
+			val = fn(val, c)
 		}
-		// Evaluate function
-		val = fn(val, c)
 	}
 	//
 	return val
@@ -1072,3 +1367,45 @@ func determineMultiplicity(exprs []Expr) uint {
 	//
 	return width
 }
+
+// IsUnitExpression checks whether a given (preprocessed) expression always
+// evaluates to exactly one value on any given row.  Lists are the obvious
+// exception, but a conditional missing either branch also fails this test since
+// it produces no value at all when that branch is taken.
+func IsUnitExpression(expr Expr) bool {
+	switch e := expr.(type) {
+	case *Add:
+		return areUnitExpressions(e.Args)
+	case *ArrayAccess, *Constant, *VariableAccess:
+		return true
+	case *Bitwise:
+		return areUnitExpressions(e.Args)
+	case *Exp:
+		return IsUnitExpression(e.Arg)
+	case *If:
+		return e.TrueBranch != nil && e.FalseBranch != nil &&
+			areUnitExpressions([]Expr{e.Condition, e.TrueBranch, e.FalseBranch})
+	case *List:
+		return len(e.Args) == 1 && IsUnitExpression(e.Args[0])
+	case *Mul:
+		return areUnitExpressions(e.Args)
+	case *Normalise:
+		return IsUnitExpression(e.Arg)
+	case *Shift:
+		return IsUnitExpression(e.Arg)
+	case *Sub:
+		return areUnitExpressions(e.Args)
+	default:
+		return false
+	}
+}
+
+func areUnitExpressions(exprs []Expr) bool {
+	for _, e := range exprs {
+		if !IsUnitExpression(e) {
+			return false
+		}
+	}
+	//
+	return true
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // IntrinsicDefinition is a SymbolDefinition for an intrinsic (i.e. built-in)
@@ -17,9 +19,11 @@ type IntrinsicDefinition struct {
 	min_arity uint
 	// Maximum number of arguments this intrinsic can accept.
 	max_arity uint
-	// Construct an instance of this intrinsic for a given arity (i.e. number of
-	// arguments).
-	constructor func(uint) Expr
+	// Construct an instance of this intrinsic for a given set of argument types,
+	// returning the expected parameter types and the body.  Most intrinsics
+	// depend only on the number of arguments, but some (e.g. the bitwise
+	// operators) are specialised according to the argument types.
+	constructor func([]Type) ([]Type, Expr)
 }
 
 var _ FunctionBinding = &IntrinsicDefinition{}
@@ -67,13 +71,8 @@ func (p *IntrinsicDefinition) HasArity(arity uint) bool {
 // (in which case, an error should be reported).  Furthermore, if no
 // appropriate signature exists then this will return nil.
 func (p *IntrinsicDefinition) Select(args []Type) *FunctionSignature {
-	// construct the body
-	body := p.constructor(uint(len(args)))
-	types := make([]Type, len(args))
-	//
-	for i := 0; i < len(types); i++ {
-		types[i] = NewFieldType()
-	}
+	// construct the parameters and body
+	types, body := p.constructor(args)
 	// Allow return type to be inferred.
 	return &FunctionSignature{true, types, nil, body}
 }
@@ -102,18 +101,89 @@ var INTRINSICS []IntrinsicDefinition = []IntrinsicDefinition{
 	{"-", 1, math.MaxUint, intrinsicSub},
 	// Multiplication
 	{"*", 1, math.MaxUint, intrinsicMul},
+	// Bitwise and
+	{"band", 2, math.MaxUint, intrinsicBitwise(util.BAND)},
+	// Bitwise or
+	{"bor", 2, math.MaxUint, intrinsicBitwise(util.BOR)},
+	// Bitwise exclusive or
+	{"bxor", 2, math.MaxUint, intrinsicBitwise(util.BXOR)},
+	// Bitwise not
+	{"bnot", 1, 1, intrinsicBitwise(util.BNOT)},
+	// Shift left
+	{"shl", 2, 2, intrinsicBitwise(util.SHL)},
+	// Shift right
+	{"shr", 2, 2, intrinsicBitwise(util.SHR)},
 }
 
-func intrinsicAdd(arity uint) Expr {
-	return &Add{intrinsicNaryBody(arity)}
+// MAX_BITWISE_WIDTH determines the widest unsigned integer type supported by
+// the bitwise intrinsics.  This must be strictly less than the bitwidth of the
+// field since, otherwise, the decomposition of an operand (at the AIR level)
+// would not be unique.
+const MAX_BITWISE_WIDTH = fr.Bits - 1
+
+func intrinsicAdd(args []Type) ([]Type, Expr) {
+	return intrinsicFieldParams(args), &Add{intrinsicNaryBody(uint(len(args)))}
+}
+
+func intrinsicSub(args []Type) ([]Type, Expr) {
+	return intrinsicFieldParams(args), &Sub{intrinsicNaryBody(uint(len(args)))}
 }
 
-func intrinsicSub(arity uint) Expr {
-	return &Sub{intrinsicNaryBody(arity)}
+func intrinsicMul(args []Type) ([]Type, Expr) {
+	return intrinsicFieldParams(args), &Mul{intrinsicNaryBody(uint(len(args)))}
 }
 
-func intrinsicMul(arity uint) Expr {
-	return &Mul{intrinsicNaryBody(arity)}
+// Construct a bitwise intrinsic for a given operation.  The bitwidth of the
+// operation is determined by the widest unsigned integer operand, and all
+// operands are required to be unsigned integers.  Observe that the shift
+// amount for a shift operation is not an operand in this sense, and can be any
+// value (though it must be constant).
+func intrinsicBitwise(op util.BitwiseOp) func([]Type) ([]Type, Expr) {
+	return func(args []Type) ([]Type, Expr) {
+		var (
+			operands = args
+			width    uint
+		)
+		//
+		if op.IsShift() && len(args) > 0 {
+			operands = args[:1]
+		}
+		// Determine width
+		for _, arg := range operands {
+			if arg == nil || arg.AsUnderlying() == nil {
+				continue
+			} else if t := arg.AsUnderlying().AsUint(); t != nil {
+				width = max(width, t.BitWidth())
+			}
+		}
+		// Construct parameter types
+		params := make([]Type, len(args))
+		//
+		for i := range params {
+			if i >= len(operands) {
+				params[i] = NewFieldType()
+			} else if args[i] != nil && args[i].AsUnderlying() != nil && args[i].AsUnderlying().AsUint() != nil {
+				// Since unsigned integer types are only subtypes of themselves,
+				// each operand is simply checked against its own type.
+				params[i] = args[i]
+			} else {
+				params[i] = NewUintType(width)
+			}
+		}
+		//
+		return params, &Bitwise{op, width, intrinsicNaryBody(uint(len(args)))}
+	}
+}
+
+// Construct parameters for an intrinsic which accepts any field elements.
+func intrinsicFieldParams(args []Type) []Type {
+	types := make([]Type, len(args))
+	//
+	for i := 0; i < len(types); i++ {
+		types[i] = NewFieldType()
+	}
+	//
+	return types
 }
 
 func intrinsicNaryBody(arity uint) []Expr {
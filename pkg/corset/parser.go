@@ -10,6 +10,7 @@ import (
 	"unicode"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
 	"github.com/consensys/go-corset/pkg/sexp"
 )
 
@@ -154,6 +155,8 @@ func NewParser(srcfile *sexp.SourceFile, srcmap *sexp.SourceMap[sexp.SExp]) *Par
 	p.AddRecursiveListRule("begin", beginParserRule)
 	p.AddRecursiveListRule("debug", debugParserRule)
 	p.AddListRule("for", forParserRule(parser))
+	p.AddListRule("let", letParserRule(parser))
+	p.AddListRule("match", matchParserRule(parser))
 	p.AddListRule("reduce", reduceParserRule(parser))
 	p.AddRecursiveListRule("if", ifParserRule)
 	p.AddRecursiveListRule("shift", shiftParserRule)
@@ -239,6 +242,8 @@ func (p *Parser) parseDeclaration(module string, s *sexp.List) (Declaration, []S
 		decl, errors = p.parseDefAlias(false, s.Elements)
 	} else if s.MatchSymbols(1, "defcolumns") {
 		decl, errors = p.parseDefColumns(module, s)
+	} else if s.Len() == 3 && s.MatchSymbols(1, "defcomputedcolumn") {
+		decl, errors = p.parseDefComputedColumn(module, s.Elements)
 	} else if s.Len() > 1 && s.MatchSymbols(1, "defconst") {
 		decl, errors = p.parseDefConst(s.Elements)
 	} else if s.Len() == 4 && s.MatchSymbols(2, "defconstraint") {
@@ -253,10 +258,12 @@ func (p *Parser) parseDeclaration(module string, s *sexp.List) (Declaration, []S
 		decl, errors = p.parseDefInRange(s.Elements)
 	} else if s.Len() == 3 && s.MatchSymbols(1, "definterleaved") {
 		decl, errors = p.parseDefInterleaved(module, s.Elements)
-	} else if s.Len() == 4 && s.MatchSymbols(1, "deflookup") {
+	} else if s.Len() >= 4 && s.MatchSymbols(1, "deflookup") {
 		decl, errors = p.parseDefLookup(s.Elements)
-	} else if s.Len() == 3 && s.MatchSymbols(2, "defpermutation") {
+	} else if s.Len() >= 3 && s.MatchSymbols(2, "defpermutation") {
 		decl, errors = p.parseDefPermutation(module, s.Elements)
+	} else if s.Len() == 4 && s.MatchSymbols(2, "defperspective") {
+		decl, errors = p.parseDefPerspective(module, s.Elements)
 	} else if s.Len() == 3 && s.MatchSymbols(2, "defproperty") {
 		decl, errors = p.parseDefProperty(s.Elements)
 	} else {
@@ -315,7 +322,7 @@ func (p *Parser) parseDefColumns(module string, l *sexp.List) (Declaration, []Sy
 	var errors []SyntaxError
 	// Process column declarations one by one.
 	for i := 1; i < len(l.Elements); i++ {
-		binding := NewInputColumnBinding(module, false, 1, NewFieldType())
+		binding := NewInputColumnBinding(module, "", false, 1, NewFieldType())
 		decl, err := p.parseColumnDeclaration(l.Elements[i], binding)
 		// Extract column name
 		if err != nil {
@@ -415,6 +422,31 @@ func (p *Parser) parseArrayDimension(s sexp.SExp) (uint, *SyntaxError) {
 	return 0, p.translator.SyntaxError(s, "invalid array dimension")
 }
 
+// Parse a computed column declaration
+func (p *Parser) parseDefComputedColumn(module string, elements []sexp.SExp) (Declaration, []SyntaxError) {
+	var errors []SyntaxError
+	// Parse target column
+	binding := NewComputedColumnBinding(module)
+	// Default type for computed columns (if none given)
+	binding.dataType = NewFieldType()
+	//
+	target, err := p.parseColumnDeclaration(elements[1], binding)
+	if err != nil {
+		errors = append(errors, *err)
+	} else if _, ok := target.binding.dataType.(*ArrayType); ok {
+		errors = append(errors, *p.translator.SyntaxError(elements[1], "computed array columns not supported"))
+	}
+	// Translate computation
+	expr, errs := p.translator.Translate(elements[2])
+	errors = append(errors, errs...)
+	// Error Check
+	if len(errors) != 0 {
+		return nil, errors
+	}
+	// Done
+	return &DefComputedColumn{target, expr}, nil
+}
+
 // Parse a constant declaration
 func (p *Parser) parseDefConst(elements []sexp.SExp) (Declaration, []SyntaxError) {
 	var (
@@ -464,7 +496,7 @@ func (p *Parser) parseDefConstraint(elements []sexp.SExp) (Declaration, []Syntax
 	}
 	// Vanishing constraints do not have global scope, hence qualified column
 	// accesses are not permitted.
-	domain, guard, errs := p.parseConstraintAttributes(elements[2])
+	domain, guard, perspective, errs := p.parseConstraintAttributes(elements[2])
 	errors = append(errors, errs...)
 	// Translate expression
 	expr, errs := p.translator.Translate(elements[3])
@@ -474,7 +506,7 @@ func (p *Parser) parseDefConstraint(elements []sexp.SExp) (Declaration, []Syntax
 		return nil, errors
 	}
 	// Done
-	return &DefConstraint{elements[1].AsSymbol().Value, domain, guard, expr, false}, nil
+	return &DefConstraint{elements[1].AsSymbol().Value, domain, guard, perspective, expr, false}, nil
 }
 
 // Parse a interleaved declaration
@@ -526,6 +558,8 @@ func (p *Parser) parseDefLookup(elements []sexp.SExp) (Declaration, []SyntaxErro
 		sources []Expr
 		targets []Expr
 	)
+	// Parse (optional) selectors
+	sourceSelector, targetSelector, errors := p.parseLookupAttributes(elements[4:])
 	// Extract items
 	handle := elements[1]
 	sexpTargets := elements[2].AsList()
@@ -566,7 +600,41 @@ func (p *Parser) parseDefLookup(elements []sexp.SExp) (Declaration, []SyntaxErro
 		return nil, errors
 	}
 	// Done
-	return &DefLookup{handle.AsSymbol().Value, sources, targets, false}, nil
+	return &DefLookup{handle.AsSymbol().Value, sources, targets, sourceSelector, targetSelector, false}, nil
+}
+
+// Parse the (optional) attributes following the source expressions of a lookup
+// declaration.  Currently, these can only identify selectors for the source or
+// target rows.
+func (p *Parser) parseLookupAttributes(attrs []sexp.SExp) (source Expr, target Expr, err []SyntaxError) {
+	var errors []SyntaxError
+	// Process each attribute in turn
+	for i := 0; i < len(attrs); i++ {
+		ith := attrs[i]
+		// Check start of attribute
+		if ith.AsSymbol() == nil {
+			errors = append(errors, *p.translator.SyntaxError(ith, "malformed attribute"))
+		} else if i+1 == len(attrs) {
+			errors = append(errors, *p.translator.SyntaxError(ith, "missing selector"))
+		} else {
+			var errs []SyntaxError
+			// Check what we've got
+			switch ith.AsSymbol().Value {
+			case ":source-selector":
+				i++
+				source, errs = p.translator.Translate(attrs[i])
+			case ":target-selector":
+				i++
+				target, errs = p.translator.Translate(attrs[i])
+			default:
+				errs = p.translator.SyntaxErrors(ith, "unknown attribute")
+			}
+			//
+			errors = append(errors, errs...)
+		}
+	}
+	// Done
+	return source, target, errors
 }
 
 // Parse a permutation declaration
@@ -577,6 +645,8 @@ func (p *Parser) parseDefPermutation(module string, elements []sexp.SExp) (Decla
 		signs   []bool
 		targets []*DefColumn
 	)
+	// Parse (optional) selectors
+	sourceSelector, targetSelector, errors := p.parsePermutationAttributes(module, elements[3:])
 	//
 	sexpTargets := elements[1].AsList()
 	sexpSources := elements[2].AsList()
@@ -620,13 +690,66 @@ func (p *Parser) parseDefPermutation(module string, elements []sexp.SExp) (Decla
 		return nil, errors
 	}
 	//
-	return &DefPermutation{targets, sources, signs}, nil
+	return &DefPermutation{targets, sources, signs, sourceSelector, targetSelector}, nil
 }
 
-func (p *Parser) parsePermutedColumnDeclaration(signRequired bool, e sexp.SExp) (*ColumnName, bool, *SyntaxError) {
+// Parse attributes of a permutation declaration, which currently consist of an
+// optional source selector and its corresponding target selector.  Observe
+// that, whilst the source selector refers to an existing column, the target
+// selector declares a new column.
+func (p *Parser) parsePermutationAttributes(module string, attrs []sexp.SExp) (Symbol, *DefColumn, []SyntaxError) {
+	var (
+		errors []SyntaxError
+		source Symbol
+		target *DefColumn
+	)
+	// Process each attribute in turn
+	for i := 0; i < len(attrs); i++ {
+		ith := attrs[i]
+		// Check start of attribute
+		if ith.AsSymbol() == nil {
+			errors = append(errors, *p.translator.SyntaxError(ith, "malformed attribute"))
+		} else if i+1 == len(attrs) {
+			errors = append(errors, *p.translator.SyntaxError(ith, "missing selector"))
+		} else {
+			var err *SyntaxError
+			// Check what we've got
+			switch ith.AsSymbol().Value {
+			case ":source-selector":
+				i++
+				if attrs[i].AsSymbol() == nil {
+					err = p.translator.SyntaxError(attrs[i], "invalid source selector")
+				} else {
+					source = newPermutedColumnName(attrs[i].AsSymbol().Value)
+					p.mapSourceNode(attrs[i], source)
+				}
+			case ":target-selector":
+				i++
+				binding := NewComputedColumnBinding(module)
+				target, err = p.parseColumnDeclaration(attrs[i], binding)
+			default:
+				err = p.translator.SyntaxError(ith, "unknown attribute")
+			}
+			//
+			if err != nil {
+				errors = append(errors, *err)
+			}
+		}
+	}
+	// Selectors must be given together
+	if len(errors) == 0 && source != nil && target == nil {
+		errors = append(errors, *p.translator.SyntaxError(attrs[0], "missing target selector"))
+	} else if len(errors) == 0 && source == nil && target != nil {
+		errors = append(errors, *p.translator.SyntaxError(attrs[0], "missing source selector"))
+	}
+	// Done
+	return source, target, errors
+}
+
+func (p *Parser) parsePermutedColumnDeclaration(signRequired bool, e sexp.SExp) (Symbol, bool, *SyntaxError) {
 	var (
 		err  *SyntaxError
-		name *ColumnName
+		name Symbol
 		sign bool
 	)
 	// Check whether extended declaration or not.
@@ -644,11 +767,11 @@ func (p *Parser) parsePermutedColumnDeclaration(signRequired bool, e sexp.SExp)
 			return nil, false, err
 		}
 		// Parse column name
-		name = NewColumnName(l.Get(1).AsSymbol().Value)
+		name = newPermutedColumnName(l.Get(1).AsSymbol().Value)
 	} else if signRequired {
 		return nil, false, p.translator.SyntaxError(e, "missing sort direction")
 	} else {
-		name = NewColumnName(e.String(false))
+		name = newPermutedColumnName(e.String(false))
 	}
 	// Update source mapping
 	p.mapSourceNode(e, name)
@@ -656,6 +779,16 @@ func (p *Parser) parsePermutedColumnDeclaration(signRequired bool, e sexp.SExp)
 	return name, sign, nil
 }
 
+// Construct the name of a permuted column, which may be qualified (i.e. to
+// permute columns from another module).
+func newPermutedColumnName(name string) Symbol {
+	if split := strings.Split(name, "."); len(split) == 2 {
+		return &VariableAccess{&split[0], split[1], false, nil}
+	}
+	//
+	return NewColumnName(name)
+}
+
 func (p *Parser) parsePermutedColumnSign(sign *sexp.Symbol) (bool, *SyntaxError) {
 	switch sign.Value {
 	case "+", "↓":
@@ -667,6 +800,50 @@ func (p *Parser) parsePermutedColumnSign(sign *sexp.Symbol) (bool, *SyntaxError)
 	}
 }
 
+// Parse a perspective declaration
+func (p *Parser) parseDefPerspective(module string, elements []sexp.SExp) (Declaration, []SyntaxError) {
+	var (
+		errors      []SyntaxError
+		sexpColumns *sexp.List = elements[3].AsList()
+		columns     []*DefColumn
+	)
+	// Check perspective name and columns
+	if !isIdentifier(elements[1]) {
+		errors = append(errors, *p.translator.SyntaxError(elements[1], "invalid perspective name"))
+	} else if sexpColumns == nil {
+		errors = append(errors, *p.translator.SyntaxError(elements[3], "expected column declarations"))
+	}
+	// Translate selector
+	selector, errs := p.translator.Translate(elements[2])
+	errors = append(errors, errs...)
+	// Error check
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	// Parse column declarations
+	name := elements[1].AsSymbol().Value
+	columns = make([]*DefColumn, sexpColumns.Len())
+	//
+	for i := 0; i < sexpColumns.Len(); i++ {
+		binding := NewInputColumnBinding(module, name, false, 1, NewFieldType())
+		decl, err := p.parseColumnDeclaration(sexpColumns.Get(i), binding)
+		// Check for errors
+		if err != nil {
+			errors = append(errors, *err)
+		} else {
+			// Column names are qualified by their enclosing perspective.
+			decl.name = fmt.Sprintf("%s/%s", name, decl.name)
+			columns[i] = decl
+		}
+	}
+	// Error Check
+	if len(errors) != 0 {
+		return nil, errors
+	}
+	// Done
+	return &DefPerspective{name, columns, NewPerspectiveBinding(selector)}, nil
+}
+
 // Parse a property assertion
 func (p *Parser) parseDefProperty(elements []sexp.SExp) (Declaration, []SyntaxError) {
 	var errors []SyntaxError
@@ -835,11 +1012,12 @@ func (p *Parser) parseDefInRange(elements []sexp.SExp) (Declaration, []SyntaxErr
 	return &DefInRange{Expr: expr, Bound: bound}, nil
 }
 
-func (p *Parser) parseConstraintAttributes(attributes sexp.SExp) (domain *int, guard Expr, err []SyntaxError) {
+func (p *Parser) parseConstraintAttributes(attributes sexp.SExp) (domain *constraint.Domain, guard Expr,
+	perspective *PerspectiveName, err []SyntaxError) {
 	var errors []SyntaxError
 	// Check attribute list is a list
 	if attributes.AsList() == nil {
-		return nil, nil, p.translator.SyntaxErrors(attributes, "expected attribute list")
+		return nil, nil, nil, p.translator.SyntaxErrors(attributes, "expected attribute list")
 	}
 	// Deconstruct as list
 	attrs := attributes.AsList()
@@ -859,6 +1037,9 @@ func (p *Parser) parseConstraintAttributes(attributes sexp.SExp) (domain *int, g
 			case ":guard":
 				i++
 				guard, errs = p.translator.Translate(attrs.Get(i))
+			case ":perspective":
+				i++
+				perspective, errs = p.parsePerspectiveAttribute(attrs.Get(i))
 			default:
 				errs = p.translator.SyntaxErrors(ith, "unknown attribute")
 			}
@@ -870,37 +1051,76 @@ func (p *Parser) parseConstraintAttributes(attributes sexp.SExp) (domain *int, g
 	}
 	// Error Check
 	if len(errors) != 0 {
-		return nil, nil, errors
+		return nil, nil, nil, errors
 	}
 	// Done
-	return domain, guard, nil
+	return domain, guard, perspective, nil
+}
+
+func (p *Parser) parsePerspectiveAttribute(attribute sexp.SExp) (*PerspectiveName, []SyntaxError) {
+	if !isIdentifier(attribute) {
+		return nil, p.translator.SyntaxErrors(attribute, "invalid perspective name")
+	}
+	//
+	name := NewPerspectiveName(attribute.AsSymbol().Value)
+	p.mapSourceNode(attribute, name)
+	//
+	return name, nil
 }
 
-func (p *Parser) parseDomainAttribute(attribute sexp.SExp) (domain *int, err []SyntaxError) {
+// Parse a domain attribute, which is a set of one or more domain elements.  Each
+// element is either a single row (e.g. "0" or "-1"), a range of rows (e.g.
+// "0..7") or a strided range of rows (e.g. "0..-1:4" for every fourth row).
+func (p *Parser) parseDomainAttribute(attribute sexp.SExp) (domain *constraint.Domain, err []SyntaxError) {
 	if attribute.AsSet() == nil {
 		return nil, p.translator.SyntaxErrors(attribute, "malformed domain set")
 	}
 	// Sanity check
 	set := attribute.AsSet()
+	windows := make([]constraint.Window, set.Len())
+	//
+	if set.Len() == 0 {
+		return nil, p.translator.SyntaxErrors(attribute, "empty domain")
+	}
 	// Check all domain elements well-formed.
 	for i := 0; i < set.Len(); i++ {
 		ith := set.Get(i)
 		if ith.AsSymbol() == nil {
 			return nil, p.translator.SyntaxErrors(ith, "malformed domain")
+		} else if window, ok := parseDomainWindow(ith.AsSymbol().Value); !ok {
+			return nil, p.translator.SyntaxErrors(ith, "malformed domain element")
+		} else {
+			windows[i] = window
 		}
 	}
-	// Currently, only support domains of size 1.
-	if set.Len() == 1 {
-		first, err := strconv.Atoi(set.Get(0).AsSymbol().Value)
-		// Check for parse error
-		if err != nil {
-			return nil, p.translator.SyntaxErrors(set.Get(0), "malformed domain element")
+	// Done
+	return constraint.NewDomain(windows...), nil
+}
+
+// Parse a single element of a domain set, such as "0", "0..7" or "0..-1:4".
+func parseDomainWindow(element string) (constraint.Window, bool) {
+	var (
+		end  int
+		step uint64 = 1
+		err  error
+	)
+	//
+	first, rest, isRange := strings.Cut(element, "..")
+	start, serr := strconv.Atoi(first)
+	// Single rows are not ranges
+	if !isRange {
+		return constraint.Window{Start: start, End: start, Step: 1}, serr == nil
+	} else if last, stride, ok := strings.Cut(rest, ":"); ok {
+		end, err = strconv.Atoi(last)
+		//
+		if err == nil {
+			step, err = strconv.ParseUint(stride, 10, 32)
 		}
-		// Done
-		return &first, nil
+	} else {
+		end, err = strconv.Atoi(rest)
 	}
-	// Fail
-	return nil, p.translator.SyntaxErrors(attribute, "multiple values not supported")
+	//
+	return constraint.Window{Start: start, End: end, Step: uint(step)}, serr == nil && err == nil && step > 0
 }
 
 func (p *Parser) parseType(term sexp.SExp) (Type, bool, *SyntaxError) {
@@ -1006,6 +1226,102 @@ func forParserRule(p *Parser) sexp.ListRule[Expr] {
 	}
 }
 
+func letParserRule(p *Parser) sexp.ListRule[Expr] {
+	return func(list *sexp.List) (Expr, []SyntaxError) {
+		var (
+			errors []SyntaxError
+			vars   []LocalVariableBinding
+			args   []Expr
+		)
+		// Check we've got the expected number
+		if list.Len() != 3 {
+			msg := fmt.Sprintf("expected 2 arguments, found %d", list.Len()-1)
+			return nil, p.translator.SyntaxErrors(list, msg)
+		}
+		// Extract bindings
+		bindings := list.Get(1).AsList()
+		//
+		if bindings == nil || bindings.Len() == 0 {
+			errors = append(errors, *p.translator.SyntaxError(list.Get(1), "invalid let bindings"))
+		} else {
+			for i := 0; i < bindings.Len(); i++ {
+				ith := bindings.Get(i).AsList()
+				// Check binding has form "(x e)"
+				if ith == nil || ith.Len() != 2 || !isIdentifier(ith.Get(0)) {
+					errors = append(errors, *p.translator.SyntaxError(bindings.Get(i), "invalid let binding"))
+					continue
+				}
+				// Parse bound expression
+				arg, errs := p.translator.Translate(ith.Get(1))
+				errors = append(errors, errs...)
+				// The type of each variable is determined during resolution.
+				vars = append(vars, NewLocalVariableBinding(ith.Get(0).AsSymbol().Value, nil))
+				args = append(args, arg)
+			}
+		}
+		// Parse body
+		body, errs := p.translator.Translate(list.Get(2))
+		errors = append(errors, errs...)
+		// Error check
+		if len(errors) > 0 {
+			return nil, errors
+		}
+		// Done
+		return &Let{vars, args, body}, nil
+	}
+}
+
+func matchParserRule(p *Parser) sexp.ListRule[Expr] {
+	return func(list *sexp.List) (Expr, []SyntaxError) {
+		var (
+			errors   []SyntaxError
+			cases    []Expr
+			branches []Expr
+			dflt     Expr
+		)
+		// Check we've got the expected number
+		if list.Len() < 3 {
+			msg := fmt.Sprintf("expected at least 2 arguments, found %d", list.Len()-1)
+			return nil, p.translator.SyntaxErrors(list, msg)
+		}
+		// Parse selector
+		selector, errs := p.translator.Translate(list.Get(1))
+		errors = append(errors, errs...)
+		// Parse cases
+		for i := 2; i < list.Len(); i++ {
+			ith := list.Get(i).AsList()
+			// Check case has form "(v e)"
+			if ith == nil || ith.Len() != 2 {
+				errors = append(errors, *p.translator.SyntaxError(list.Get(i), "invalid match case"))
+				continue
+			}
+			// Parse branch
+			branch, errs := p.translator.Translate(ith.Get(1))
+			errors = append(errors, errs...)
+			//
+			if symbol := ith.Get(0).AsSymbol(); symbol == nil || symbol.Value != "else" {
+				// Parse case value
+				value, errs := p.translator.Translate(ith.Get(0))
+				errors = append(errors, errs...)
+				cases = append(cases, value)
+				branches = append(branches, branch)
+			} else if i+1 != list.Len() {
+				errors = append(errors, *p.translator.SyntaxError(ith, "else must be the last case"))
+			} else if i == 2 {
+				errors = append(errors, *p.translator.SyntaxError(list, "expected at least one case"))
+			} else {
+				dflt = branch
+			}
+		}
+		// Error check
+		if len(errors) > 0 {
+			return nil, errors
+		}
+		// Done
+		return &Match{selector, cases, branches, dflt}, nil
+	}
+}
+
 // Parse a range which, represented as a string is "[s:e]".
 func parseForRange(p *Parser, interval sexp.SExp) (uint, uint, []SyntaxError) {
 	var (
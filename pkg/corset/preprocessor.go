@@ -63,6 +63,8 @@ func (p *preprocessor) preprocessDeclaration(decl Declaration, module string) []
 		// ignore
 	} else if _, ok := decl.(*DefColumns); ok {
 		// ignore
+	} else if d, ok := decl.(*DefComputedColumn); ok {
+		errors = p.preprocessDefComputedColumn(d, module)
 	} else if _, ok := decl.(*DefConst); ok {
 		// ignore
 	} else if d, ok := decl.(*DefConstraint); ok {
@@ -77,6 +79,8 @@ func (p *preprocessor) preprocessDeclaration(decl Declaration, module string) []
 		errors = p.preprocessDefLookup(d, module)
 	} else if _, Ok := decl.(*DefPermutation); Ok {
 		// ignore
+	} else if d, ok := decl.(*DefPerspective); ok {
+		errors = p.preprocessDefPerspective(d, module)
 	} else if d, ok := decl.(*DefProperty); ok {
 		errors = p.preprocessDefProperty(d, module)
 	} else {
@@ -87,6 +91,15 @@ func (p *preprocessor) preprocessDeclaration(decl Declaration, module string) []
 	return errors
 }
 
+// preprocess a "defcomputedcolumn" declaration.
+func (p *preprocessor) preprocessDefComputedColumn(decl *DefComputedColumn, module string) []SyntaxError {
+	var errors []SyntaxError
+	// preprocess computation
+	decl.Computation, errors = p.preprocessExpressionInModule(decl.Computation, module)
+	// Done
+	return errors
+}
+
 // preprocess a "defconstraint" declaration.
 func (p *preprocessor) preprocessDefConstraint(decl *DefConstraint, module string) []SyntaxError {
 	var (
@@ -113,7 +126,12 @@ func (p *preprocessor) preprocessDefLookup(decl *DefLookup, module string) []Syn
 	decl.Sources, source_errs = p.preprocessExpressionsInModule(decl.Sources, module)
 	decl.Targets, target_errs = p.preprocessExpressionsInModule(decl.Targets, module)
 	// Combine errors
-	return append(source_errs, target_errs...)
+	errors := append(source_errs, target_errs...)
+	// preprocess (optional) selectors
+	decl.SourceSelector, source_errs = p.preprocessOptionalExpressionInModule(decl.SourceSelector, module)
+	decl.TargetSelector, target_errs = p.preprocessOptionalExpressionInModule(decl.TargetSelector, module)
+	// Combine errors
+	return append(errors, append(source_errs, target_errs...)...)
 }
 
 // preprocess a "definrange" declaration.
@@ -125,6 +143,15 @@ func (p *preprocessor) preprocessDefInRange(decl *DefInRange, module string) []S
 	return errors
 }
 
+// preprocess a "defperspective" declaration.
+func (p *preprocessor) preprocessDefPerspective(decl *DefPerspective, module string) []SyntaxError {
+	var errors []SyntaxError
+	// preprocess selector expression
+	decl.binding.selector, errors = p.preprocessExpressionInModule(decl.binding.selector, module)
+	// Done
+	return errors
+}
+
 // preprocess a "defproperty" declaration.
 func (p *preprocessor) preprocessDefProperty(decl *DefProperty, module string) []SyntaxError {
 	var errors []SyntaxError
@@ -229,6 +256,9 @@ func (p *preprocessor) preprocessExpressionInModule(expr Expr, module string) (E
 	case *Add:
 		args, errs := p.preprocessExpressionsInModule(e.Args, module)
 		nexpr, errors = &Add{args}, errs
+	case *Bitwise:
+		args, errs := p.preprocessExpressionsInModule(e.Args, module)
+		nexpr, errors = &Bitwise{e.Op, e.Width, args}, errs
 	case *Constant:
 		return e, nil
 	case *Debug:
@@ -250,9 +280,13 @@ func (p *preprocessor) preprocessExpressionInModule(expr Expr, module string) (E
 		nexpr, errors = &If{e.kind, args[0], args[1], args[2]}, errs
 	case *Invoke:
 		return p.preprocessInvokeInModule(e, module)
+	case *Let:
+		return p.preprocessExpressionInModule(e.Inline(p.srcmap), module)
 	case *List:
 		args, errs := p.preprocessVoidableExpressionsInModule(e.Args, module)
 		nexpr, errors = &List{args}, errs
+	case *Match:
+		return p.preprocessExpressionInModule(e.Desugar(p.srcmap), module)
 	case *Mul:
 		args, errs := p.preprocessExpressionsInModule(e.Args, module)
 		nexpr, errors = &Mul{args}, errs
@@ -307,6 +341,12 @@ func (p *preprocessor) preprocessForInModule(expr *For, module string) (Expr, []
 func (p *preprocessor) preprocessInvokeInModule(expr *Invoke, module string) (Expr, []SyntaxError) {
 	if expr.signature != nil {
 		body := expr.signature.Apply(expr.Args(), p.srcmap)
+		// Intrinsic bodies have no source mapping of their own, so errors
+		// arising from them are attributed to the invocation instead.
+		if _, ok := expr.fn.binding.(*IntrinsicDefinition); ok {
+			p.srcmap.Copy(expr, body)
+		}
+		//
 		return p.preprocessExpressionInModule(body, module)
 	}
 	//
@@ -325,6 +365,11 @@ func (p *preprocessor) preprocessReduceInModule(expr *Reduce, module string) (Ex
 		// Build reduction
 		for i := 1; i < len(list.Args); i++ {
 			reduction = sig.Apply([]Expr{reduction, list.Args[i]}, p.srcmap)
+			// As for invocations, intrinsic bodies are attributed to the
+			// reduction itself.
+			if _, ok := expr.fn.binding.(*IntrinsicDefinition); ok {
+				p.srcmap.Copy(expr, reduction)
+			}
 		}
 		// done
 		return reduction, errors
@@ -2,6 +2,7 @@ package corset
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/consensys/go-corset/pkg/sexp"
 )
@@ -185,7 +186,7 @@ func (r *resolver) finaliseDeclarationsInModule(scope *ModuleScope, decls []Decl
 			// Check whether already finalised
 			if !d.IsFinalised() {
 				// No, so attempt to finalise
-				ready, errs := r.declarationDependenciesAreFinalised(scope, d)
+				ready, errs := r.declarationDependenciesAreFinalised(declarationScope(scope, d), d)
 				// Check what we found
 				if errs != nil {
 					errors = append(errors, errs...)
@@ -226,7 +227,7 @@ func (r *resolver) finaliseDeclarationsInModule(scope *ModuleScope, decls []Decl
 // Check that a given set of symbols have been finalised.  This is important,
 // since we cannot finalise a declaration until all of its dependencies have
 // themselves been finalised.
-func (r *resolver) declarationDependenciesAreFinalised(scope *ModuleScope,
+func (r *resolver) declarationDependenciesAreFinalised(scope Scope,
 	decl Declaration) (bool, []SyntaxError) {
 	var (
 		errors    []SyntaxError
@@ -259,10 +260,12 @@ func (r *resolver) declarationDependenciesAreFinalised(scope *ModuleScope,
 
 // Finalise a declaration.
 func (r *resolver) finaliseDeclaration(scope *ModuleScope, decl Declaration) []SyntaxError {
-	if d, ok := decl.(*DefConst); ok {
+	if d, ok := decl.(*DefComputedColumn); ok {
+		return r.finaliseDefComputedColumnInModule(scope, d)
+	} else if d, ok := decl.(*DefConst); ok {
 		return r.finaliseDefConstInModule(scope, d)
 	} else if d, ok := decl.(*DefConstraint); ok {
-		return r.finaliseDefConstraintInModule(scope, d)
+		return r.finaliseDefConstraintInModule(declarationScope(scope, d), d)
 	} else if d, ok := decl.(*DefFun); ok {
 		return r.finaliseDefFunInModule(scope, d)
 	} else if d, ok := decl.(*DefInRange); ok {
@@ -272,7 +275,9 @@ func (r *resolver) finaliseDeclaration(scope *ModuleScope, decl Declaration) []S
 	} else if d, ok := decl.(*DefLookup); ok {
 		return r.finaliseDefLookupInModule(scope, d)
 	} else if d, ok := decl.(*DefPermutation); ok {
-		return r.finaliseDefPermutationInModule(d)
+		return r.finaliseDefPermutationInModule(scope, d)
+	} else if d, ok := decl.(*DefPerspective); ok {
+		return r.finaliseDefPerspectiveInModule(scope, d)
 	} else if d, ok := decl.(*DefProperty); ok {
 		return r.finaliseDefPropertyInModule(scope, d)
 	}
@@ -280,6 +285,46 @@ func (r *resolver) finaliseDeclaration(scope *ModuleScope, decl Declaration) []S
 	return nil
 }
 
+// Determine the scope in which the symbols used within a given declaration
+// should be resolved.  Generally speaking, this is simply the enclosing module.
+// However, constraints declared within a perspective can additionally access
+// the columns of that perspective directly.
+func declarationScope(scope *ModuleScope, decl Declaration) Scope {
+	if d, ok := decl.(*DefConstraint); ok && d.Perspective != nil {
+		return NewPerspectiveScope(scope, d.Perspective.Name())
+	}
+	//
+	return scope
+}
+
+// Finalise a computed column declaration after all symbols have been resolved.
+// This requires resolving the computation and, from that, determining the
+// length multiplier of the computed column.  Observe that the type of the
+// computed column is always as declared, rather than being inferred.
+func (r *resolver) finaliseDefComputedColumnInModule(enclosing Scope, decl *DefComputedColumn) []SyntaxError {
+	var (
+		scope = NewLocalScope(enclosing, false, false)
+	)
+	// Resolve computation
+	_, errors := r.finaliseExpressionInModule(scope, decl.Computation)
+	// Finalise details only if no errors
+	if len(errors) == 0 {
+		var multiplier uint = 1
+		// Determine length multiplier.  Observe that a computation with a void
+		// context is simply a constant, in which case the column has the same
+		// height as its enclosing module.
+		if context := decl.Computation.Context(); !context.IsVoid() {
+			multiplier = context.LengthMultiplier()
+		}
+		// Lookup existing declaration
+		binding := decl.Target.Binding().(*ColumnBinding)
+		// Finalise column binding
+		binding.Finalise(multiplier, binding.dataType)
+	}
+	// Done
+	return errors
+}
+
 // Finalise one or more constant definitions within a given module.
 // Specifically, we need to check that the constant values provided are indeed
 // constants.
@@ -382,25 +427,41 @@ func (r *resolver) finaliseDefInterleavedInModule(decl *DefInterleaved) []Syntax
 }
 
 // Finalise a permutation assignment after all symbols have been resolved.  This
-// requires checking the contexts of all columns is consistent.
-func (r *resolver) finaliseDefPermutationInModule(decl *DefPermutation) []SyntaxError {
+// requires checking the contexts of all columns is consistent.  Source columns
+// (including the source selector) must reside in the same module, though this
+// can differ from the enclosing module.  Qualified source columns are only
+// permitted in this case.  Furthermore, in this case, or when a selector is
+// given, the height of the enclosing module is determined by the permutation.
+// Hence, it cannot also contain input columns.
+func (r *resolver) finaliseDefPermutationInModule(scope *ModuleScope, decl *DefPermutation) []SyntaxError {
 	var (
 		multiplier uint = 0
+		module     string
 		errors     []SyntaxError
 	)
 	// Finalise each column in turn
 	for i := 0; i < len(decl.Sources); i++ {
 		ith := decl.Sources[i]
 		// Lookup source of column being permuted
-		source := ith.Binding().(*ColumnBinding)
+		source, ok := ith.Binding().(*ColumnBinding)
 		// Sanity check length multiplier
-		if i == 0 && source.dataType.AsUnderlying().AsUint() == nil {
+		if !ok {
+			errors = append(errors, *r.srcmap.SyntaxError(ith, "not a column"))
+			continue
+		} else if i == 0 && source.dataType.AsUnderlying().AsUint() == nil {
 			errors = append(errors, *r.srcmap.SyntaxError(ith, "fixed-width type required"))
 		} else if i == 0 {
 			multiplier = source.multiplier
+			module = source.module
 		} else if multiplier != source.multiplier {
 			// Problem
 			errors = append(errors, *r.srcmap.SyntaxError(ith, "incompatible length multiplier"))
+		} else if module != source.module {
+			errors = append(errors, *r.srcmap.SyntaxError(ith, "conflicting context"))
+		}
+		// Qualified columns are only permitted across modules
+		if ith.IsQualified() && source.module == scope.EnclosingModule() {
+			errors = append(errors, *r.srcmap.SyntaxError(ith, "qualified column in enclosing module"))
 		}
 		// All good, finalise target column
 		target := decl.Targets[i].Binding().(*ColumnBinding)
@@ -408,6 +469,59 @@ func (r *resolver) finaliseDefPermutationInModule(decl *DefPermutation) []Syntax
 		target.multiplier = source.multiplier
 		target.dataType = source.dataType
 	}
+	// Finalise selectors (if applicable)
+	if decl.SourceSelector != nil {
+		errors = append(errors, r.finalisePermutationSelector(decl, multiplier, module)...)
+	}
+	// Check enclosing module can be sized by this permutation (if applicable)
+	if len(errors) == 0 && (decl.SourceSelector != nil || module != scope.EnclosingModule()) &&
+		scope.HasInputColumns() {
+		errors = append(errors, *r.srcmap.SyntaxError(decl, "enclosing module cannot have input columns"))
+	}
+	// Done
+	return errors
+}
+
+// Finalise the selectors of a permutation, where the source selector must be
+// consistent with the source columns being permuted.  The target selector is
+// always binary.
+func (r *resolver) finalisePermutationSelector(decl *DefPermutation, multiplier uint, module string) []SyntaxError {
+	var errors []SyntaxError
+	//
+	selector, ok := decl.SourceSelector.Binding().(*ColumnBinding)
+	//
+	if !ok {
+		errors = append(errors, *r.srcmap.SyntaxError(decl.SourceSelector, "not a column"))
+	} else if selector.multiplier != multiplier {
+		errors = append(errors, *r.srcmap.SyntaxError(decl.SourceSelector, "incompatible length multiplier"))
+	} else if selector.module != module {
+		errors = append(errors, *r.srcmap.SyntaxError(decl.SourceSelector, "conflicting context"))
+	}
+	// Finalise target selector
+	target := decl.TargetSelector.Binding().(*ColumnBinding)
+	target.multiplier = multiplier
+	target.dataType = NewUintType(1)
+	//
+	return errors
+}
+
+// Finalise a perspective declaration after all symbols have been resolved.
+// This requires checking that the selector is well-typed.  Specifically, like a
+// guard, it cannot have loobean semantics.
+func (r *resolver) finaliseDefPerspectiveInModule(enclosing Scope, decl *DefPerspective) []SyntaxError {
+	var (
+		scope = NewLocalScope(enclosing, false, false)
+	)
+	// Resolve selector
+	selector_t, errors := r.finaliseExpressionInModule(scope, decl.Selector())
+	//
+	if selector_t != nil && selector_t.HasLoobeanSemantics() {
+		err := r.srcmap.SyntaxError(decl.Selector(), "unexpected loobean selector")
+		errors = append(errors, *err)
+	} else if len(errors) == 0 {
+		// Finalise declaration.
+		decl.binding.Finalise()
+	}
 	// Done
 	return errors
 }
@@ -458,10 +572,32 @@ func (r *resolver) finaliseDefLookupInModule(enclosing Scope, decl *DefLookup) [
 	_, source_errors := r.finaliseExpressionsInModule(sourceScope, decl.Sources)
 	// Resolve target expressions
 	_, target_errors := r.finaliseExpressionsInModule(targetScope, decl.Targets)
+	// Resolve (optional) selectors
+	source_errors = append(source_errors, r.finaliseLookupSelector(sourceScope, decl.SourceSelector, decl.Sources)...)
+	target_errors = append(target_errors, r.finaliseLookupSelector(targetScope, decl.TargetSelector, decl.Targets)...)
 	//
 	return append(source_errors, target_errors...)
 }
 
+// Resolve the (optional) selector for one side of a lookup constraint.  Like a
+// guard, a selector cannot have loobean semantics.  Furthermore, it must be
+// evaluated in the same context as the expressions it selects.
+func (r *resolver) finaliseLookupSelector(scope LocalScope, selector Expr, exprs []Expr) []SyntaxError {
+	if selector == nil {
+		return nil
+	}
+	// Resolve selector
+	selector_t, errors := r.finaliseExpressionInModule(scope, selector)
+	//
+	if selector_t != nil && selector_t.HasLoobeanSemantics() {
+		errors = append(errors, *r.srcmap.SyntaxError(selector, "unexpected loobean selector"))
+	} else if len(errors) == 0 && ContextOfExpressions(append([]Expr{selector}, exprs...)).IsConflicted() {
+		errors = append(errors, *r.srcmap.SyntaxError(selector, "conflicting context"))
+	}
+	//
+	return errors
+}
+
 // Resolve those variables appearing in the body of this property assertion.
 func (r *resolver) finaliseDefPropertyInModule(enclosing Scope, decl *DefProperty) []SyntaxError {
 	var (
@@ -505,6 +641,10 @@ func (r *resolver) finaliseExpressionInModule(scope LocalScope, expr Expr) (Type
 	} else if v, ok := expr.(*Add); ok {
 		types, errs := r.finaliseExpressionsInModule(scope, v.Args)
 		return LeastUpperBoundAll(types), errs
+	} else if v, ok := expr.(*Bitwise); ok {
+		// Arguments already type checked against parameters of the intrinsic.
+		_, errs := r.finaliseExpressionsInModule(scope, v.Args)
+		return NewUintType(v.Width), errs
 	} else if v, ok := expr.(*Constant); ok {
 		nbits := v.Val.BitLen()
 		return NewUintType(uint(nbits)), nil
@@ -526,9 +666,13 @@ func (r *resolver) finaliseExpressionInModule(scope LocalScope, expr Expr) (Type
 		return r.finaliseIfInModule(scope, v)
 	} else if v, ok := expr.(*Invoke); ok {
 		return r.finaliseInvokeInModule(scope, v)
+	} else if v, ok := expr.(*Let); ok {
+		return r.finaliseLetInModule(scope, v)
 	} else if v, ok := expr.(*List); ok {
 		types, errs := r.finaliseExpressionsInModule(scope, v.Args)
 		return LeastUpperBoundAll(types), errs
+	} else if v, ok := expr.(*Match); ok {
+		return r.finaliseMatchInModule(scope, v)
 	} else if v, ok := expr.(*Mul); ok {
 		types, errs := r.finaliseExpressionsInModule(scope, v.Args)
 		return GreatestLowerBoundAll(types), errs
@@ -598,6 +742,86 @@ func (r *resolver) finaliseIfInModule(scope LocalScope, expr *If) (Type, []Synta
 	return GreatestLowerBoundAll(types[1:]), errs
 }
 
+// Resolve a let expression contained within some expression which, in turn, is
+// contained within some module.  Each variable is declared in turn (i.e. after
+// its bound expression has been resolved), with its type determined by that
+// expression.
+func (r *resolver) finaliseLetInModule(scope LocalScope, expr *Let) (Type, []SyntaxError) {
+	var errors []SyntaxError
+	//
+	nestedscope := scope.NestedScope()
+	//
+	for i := range expr.Vars {
+		datatype, errs := r.finaliseExpressionInModule(nestedscope, expr.Args[i])
+		errors = append(errors, errs...)
+		// Declare local variable
+		expr.Vars[i].datatype = datatype
+		nestedscope.DeclareLocal(expr.Vars[i].name, &expr.Vars[i])
+	}
+	// Error check
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	// Continue resolution
+	return r.finaliseExpressionInModule(nestedscope, expr.Body)
+}
+
+// Resolve a match expression contained within some expression which, in turn,
+// is contained within some module.  This checks that every case value is a
+// distinct constant which fits within the type of the selector (where this is
+// an unsigned integer type).
+func (r *resolver) finaliseMatchInModule(scope LocalScope, expr *Match) (Type, []SyntaxError) {
+	var (
+		purescope = scope.NestedPureScope()
+		seen      = make(map[string]bool)
+	)
+	// Resolve selector
+	selector, errors := r.finaliseExpressionInModule(scope, expr.Selector)
+	// Resolve cases
+	_, errs := r.finaliseExpressionsInModule(purescope, expr.Cases)
+	errors = append(errors, errs...)
+	// Resolve branches
+	types, errs := r.finaliseExpressionsInModule(scope, append([]Expr{expr.Default}, expr.Branches...))
+	errors = append(errors, errs...)
+	// Error check
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	// Check case values
+	for _, c := range expr.Cases {
+		if val := c.AsConstant(); val == nil {
+			errors = append(errors, *r.srcmap.SyntaxError(c, "expected constant case"))
+		} else if val.Sign() < 0 {
+			errors = append(errors, *r.srcmap.SyntaxError(c, "negative case"))
+		} else if seen[val.String()] {
+			errors = append(errors, *r.srcmap.SyntaxError(c, "duplicate case"))
+		} else if !matchCaseFits(val, selector) {
+			msg := fmt.Sprintf("case out-of-bounds for selector (type %s)", selector)
+			errors = append(errors, *r.srcmap.SyntaxError(c, msg))
+		} else {
+			seen[val.String()] = true
+		}
+	}
+	// Error check
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	// Join result types
+	return GreatestLowerBoundAll(types), nil
+}
+
+// Check whether a given case value fits within the type of a selector.  Only
+// unsigned integer types constrain the permitted values.
+func matchCaseFits(val *big.Int, selector Type) bool {
+	if selector == nil || selector.AsUnderlying() == nil {
+		return true
+	} else if t := selector.AsUnderlying().AsUint(); t != nil {
+		return uint(val.BitLen()) <= t.BitWidth()
+	}
+	//
+	return true
+}
+
 // Resolve a specific invocation contained within some expression which, in
 // turn, is contained within some module.  Note, qualified accesses are only
 // permitted in a global context.
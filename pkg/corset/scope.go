@@ -171,6 +171,18 @@ func (p *ModuleScope) Column(name string) *ColumnBinding {
 	return p.bindings[bid].(*ColumnBinding)
 }
 
+// HasInputColumns determines whether any input (i.e. non-computed) columns are
+// declared within this module.
+func (p *ModuleScope) HasInputColumns() bool {
+	for _, b := range p.bindings {
+		if binding, ok := b.(*ColumnBinding); ok && !binding.computed {
+			return true
+		}
+	}
+	//
+	return false
+}
+
 // Declare declares a given binding within this module scope.
 func (p *ModuleScope) Declare(symbol SymbolDefinition) bool {
 	// construct binding identifier
@@ -221,6 +233,47 @@ func (p *ModuleScope) Alias(alias string, symbol Symbol) bool {
 	return false
 }
 
+// =============================================================================
+// Perspective Scope
+// =============================================================================
+
+// PerspectiveScope represents the scope of a declaration made within a given
+// perspective of a module.  In such a scope, columns declared in the
+// perspective can be accessed directly by their (unqualified) name.  All other
+// symbols are resolved in the enclosing module scope as usual.
+type PerspectiveScope struct {
+	// Name of the perspective
+	perspective string
+	// Enclosing module scope
+	enclosing *ModuleScope
+}
+
+// NewPerspectiveScope constructs a new perspective scope for a given
+// perspective within a given module scope.
+func NewPerspectiveScope(enclosing *ModuleScope, perspective string) PerspectiveScope {
+	return PerspectiveScope{perspective, enclosing}
+}
+
+// HasModule checks whether a given module exists, or not.
+func (p PerspectiveScope) HasModule(module string) bool {
+	return p.enclosing.HasModule(module)
+}
+
+// Bind looks up a given variable being referenced within this perspective.
+// Unqualified column accesses are first resolved against the columns of the
+// perspective, before falling back to the enclosing module.
+func (p PerspectiveScope) Bind(symbol Symbol) bool {
+	if !symbol.IsQualified() && !symbol.IsFunction() {
+		name := fmt.Sprintf("%s/%s", p.perspective, symbol.Name())
+		// Check whether this is a perspective column.
+		if binding, ok := p.enclosing.Binding(name, false).(*ColumnBinding); ok {
+			return symbol.Resolve(binding)
+		}
+	}
+	// No, this is not a perspective column.
+	return p.enclosing.Bind(symbol)
+}
+
 // =============================================================================
 // Local Scope
 // =============================================================================
@@ -324,9 +377,12 @@ func (p LocalScope) Bind(symbol Symbol) bool {
 	return p.enclosing.Bind(symbol)
 }
 
-// DeclareLocal registers a new local variable (e.g. a parameter).
+// DeclareLocal registers a new local variable (e.g. a parameter).  Observe
+// that a local variable may shadow another of the same name, in which case the
+// number of locals remains unchanged.  Hence, the index is determined by the
+// number of bindings to ensure it is unique.
 func (p *LocalScope) DeclareLocal(name string, binding *LocalVariableBinding) uint {
-	index := uint(len(p.locals))
+	index := uint(len(p.bindings))
 	binding.Finalise(index)
 	p.locals[name] = index
 	p.bindings = append(p.bindings, binding)
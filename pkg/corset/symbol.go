@@ -65,6 +65,16 @@ func NewColumnName(name string) *ColumnName {
 	return &ColumnName{name, false, nil, false}
 }
 
+// PerspectiveName represents a name used in a position where it can only be
+// resolved against a perspective.
+type PerspectiveName = Name[*PerspectiveBinding]
+
+// NewPerspectiveName construct a new perspective name which is (initially)
+// unresolved.
+func NewPerspectiveName(name string) *PerspectiveName {
+	return &PerspectiveName{name, false, nil, false}
+}
+
 // Name represents a name within some syntactic item.  Essentially this wraps a
 // string and provides a mechanism for it to be associated with source line
 // information.
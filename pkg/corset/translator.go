@@ -9,6 +9,7 @@ import (
 	"github.com/consensys/go-corset/pkg/schema/assignment"
 	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // TranslateCircuit translates the components of a Corset circuit and add them
@@ -20,7 +21,7 @@ import (
 func TranslateCircuit(env Environment, srcmap *sexp.SourceMaps[Node],
 	circuit *Circuit) (*hir.Schema, []SyntaxError) {
 	//
-	t := translator{env, srcmap, hir.EmptySchema()}
+	t := translator{env, srcmap, hir.EmptySchema(), make(map[string]bool)}
 	// Allocate all modules into schema
 	t.translateModules(circuit)
 	// Translate input columns
@@ -47,6 +48,9 @@ type translator struct {
 	srcmap *sexp.SourceMaps[Node]
 	// Represents the schema being constructed by this translator.
 	schema *hir.Schema
+	// Identifies operands of bitwise operations which are already constrained
+	// (see constrainBitwiseOperands).
+	operands map[string]bool
 }
 
 func (t *translator) translateModules(circuit *Circuit) {
@@ -78,17 +82,72 @@ func (t *translator) translateInputColumns(circuit *Circuit) []SyntaxError {
 // Translate all input column declarations occurring in a given module within the circuit.
 func (t *translator) translateInputColumnsInModule(module string, decls []Declaration) []SyntaxError {
 	var errors []SyntaxError
+	// Determine register names for perspective columns
+	registers := t.perspectiveRegisterNames(module, decls)
 	//
 	for _, d := range decls {
 		if dcols, ok := d.(*DefColumns); ok {
 			errs := t.translateDefColumns(dcols, module)
 			errors = append(errors, errs...)
+		} else if dpersp, ok := d.(*DefPerspective); ok {
+			errs := t.translateDefPerspective(dpersp, module, registers)
+			errors = append(errors, errs...)
 		}
 	}
 	// Done
 	return errors
 }
 
+// Determine the names of all registers allocated to perspective columns in a
+// given module.  Since columns from different perspectives can be allocated to
+// the same register, the name of a register combines the names of all columns
+// allocated to it (e.g. "p/X_xor_q/Y").
+func (t *translator) perspectiveRegisterNames(module string, decls []Declaration) map[uint]string {
+	registers := make(map[uint]string)
+	//
+	for _, d := range decls {
+		if dpersp, ok := d.(*DefPerspective); ok {
+			for _, c := range dpersp.Columns {
+				cid := t.env.Column(module, c.Name()).cid
+				//
+				if name, ok := registers[cid]; ok {
+					registers[cid] = fmt.Sprintf("%s_xor_%s", name, c.Name())
+				} else {
+					registers[cid] = c.Name()
+				}
+			}
+		}
+	}
+	//
+	return registers
+}
+
+// Translate the columns declared in a "defperspective" declaration.  Observe
+// that columns sharing a register with some column from an earlier perspective
+// are skipped, since their register has already been allocated.
+func (t *translator) translateDefPerspective(decl *DefPerspective, module string,
+	registers map[uint]string) []SyntaxError {
+	var errors []SyntaxError
+	//
+	for _, c := range decl.Columns {
+		cid := t.env.Column(module, c.Name()).cid
+		//
+		if cid < t.schema.Columns().Count() {
+			// Register already allocated
+			continue
+		} else if _, ok := c.DataType().(*ArrayType); ok {
+			// Array columns are never shared
+			errs := t.translateDefColumn(c, module)
+			errors = append(errors, errs...)
+		} else {
+			errs := t.translateRawColumn(c, module, registers[cid], c.DataType().AsUnderlying(), cid)
+			errors = append(errors, errs...)
+		}
+	}
+	//
+	return errors
+}
+
 // Translate a "defcolumns" declaration.
 func (t *translator) translateDefColumns(decl *DefColumns, module string) []SyntaxError {
 	var errors []SyntaxError
@@ -127,10 +186,14 @@ func (t *translator) translateRawColumn(decl *DefColumn, module string, name str
 	//
 	context := t.env.ContextFrom(module, decl.LengthMultiplier())
 	cid := t.schema.AddDataColumn(context, name, datatype)
+	// Record origin of column
+	t.schema.Declaration(cid).(*assignment.DataColumn).SetSource(t.sourceOf(decl))
 	// Prove type (if requested)
 	if decl.MustProve() {
 		bound := datatype.AsUint().Bound()
-		t.schema.AddRangeConstraint(name, context, &hir.ColumnAccess{Column: cid, Shift: 0}, bound)
+		t.schema.AddProvenColumn(cid)
+		t.schema.AddRangeConstraint(name, context, &hir.ColumnAccess{Column: cid, Shift: 0}, bound).
+			SetSource(t.sourceOf(decl))
 	}
 	// Sanity check column identifier
 	if columnId != cid {
@@ -177,6 +240,8 @@ func (t *translator) translateDeclaration(decl Declaration, module string) []Syn
 		// Not an assignment or a constraint, hence ignore.
 	} else if _, ok := decl.(*DefColumns); ok {
 		// Not an assignment or a constraint, hence ignore.
+	} else if d, ok := decl.(*DefComputedColumn); ok {
+		errors = t.translateDefComputedColumn(d, module)
 	} else if _, ok := decl.(*DefConst); ok {
 		// For now, constants are always compiled out when going down to HIR.
 	} else if d, ok := decl.(*DefConstraint); ok {
@@ -192,6 +257,8 @@ func (t *translator) translateDeclaration(decl Declaration, module string) []Syn
 		errors = t.translateDefLookup(d, module)
 	} else if d, Ok := decl.(*DefPermutation); Ok {
 		errors = t.translateDefPermutation(d, module)
+	} else if _, ok := decl.(*DefPerspective); ok {
+		// Not an assignment or a constraint, hence ignore.
 	} else if d, ok := decl.(*DefProperty); ok {
 		errors = t.translateDefProperty(d, module)
 	} else {
@@ -202,6 +269,43 @@ func (t *translator) translateDeclaration(decl Declaration, module string) []Syn
 	return errors
 }
 
+// Translate a "defcomputedcolumn" declaration.
+func (t *translator) translateDefComputedColumn(decl *DefComputedColumn, module string) []SyntaxError {
+	// Sanity check computation produces exactly one value per row
+	if !IsUnitExpression(decl.Computation) {
+		return t.srcmap.SyntaxErrors(decl.Computation, "computation must produce exactly one value")
+	}
+	// Translate computation
+	expr, errors := t.translateExpressionInModule(decl.Computation, module, 0)
+	//
+	if len(errors) > 0 {
+		return errors
+	}
+	// Lookup target column info
+	info := t.env.Column(module, decl.Target.Name())
+	// Construct context for this assignment
+	context := t.env.ContextFrom(module, info.multiplier)
+	// Extract underlying datatype
+	datatype := info.dataType.AsUnderlying()
+	// Add the assignment and check the first identifier.
+	computed := assignment.NewComputedColumn(context, decl.Target.Name(), datatype, hir.NewUnitExpr(expr))
+	computed.SetSource(t.sourceOf(decl))
+	cid := t.schema.AddAssignment(computed)
+	// Prove type (if requested)
+	if info.mustProve {
+		bound := datatype.AsUint().Bound()
+		t.schema.AddProvenColumn(cid)
+		t.schema.AddRangeConstraint(decl.Target.Name(), context, &hir.ColumnAccess{Column: cid, Shift: 0}, bound).
+			SetSource(t.sourceOf(decl))
+	}
+	// Sanity check column identifiers align.
+	if cid != info.ColumnId() {
+		errors = append(errors, *t.srcmap.SyntaxError(decl, "invalid column identifier"))
+	}
+	// Done
+	return errors
+}
+
 // Translate a "defconstraint" declaration.
 func (t *translator) translateDefConstraint(decl *DefConstraint, module string) []SyntaxError {
 	// Translate constraint body
@@ -210,15 +314,30 @@ func (t *translator) translateDefConstraint(decl *DefConstraint, module string)
 	guard, guard_errors := t.translateOptionalExpressionInModule(decl.Guard, module, 0)
 	// Combine errors
 	errors = append(errors, guard_errors...)
-	// Apply guard
+	// Translate (optional) perspective selector
+	selector, selector_errors := t.translateSelectorInModule(decl.Perspective, module)
+	// Combine errors
+	errors = append(errors, selector_errors...)
+	// Check for void constraint
 	if constraint == nil {
 		// NOTE: in this case, the constraint itself has been translated as nil.
 		// This means there is no constraint (e.g. its a debug constraint, but
 		// debug mode is not enabled).
 		return errors
-	} else if guard != nil {
+	}
+	// Record origins of sub-expressions (e.g. list elements)
+	source := t.sourceOf(decl)
+	t.attributeSubExprs(decl.Constraint, constraint, source)
+	// Gate bitwise operands by guard and perspective selector (if applicable)
+	t.gateBitwiseOperands(constraint, guard, selector)
+	// Apply guard (if applicable)
+	if guard != nil {
 		constraint = &hir.Mul{Args: []hir.Expr{guard, constraint}}
 	}
+	// Apply perspective selector (if applicable)
+	if selector != nil {
+		constraint = &hir.Mul{Args: []hir.Expr{selector, constraint}}
+	}
 	//
 	if len(errors) == 0 {
 		context := constraint.Context(t.schema)
@@ -232,13 +351,93 @@ func (t *translator) translateDefConstraint(decl *DefConstraint, module string)
 			return t.srcmap.SyntaxErrors(decl, "invalid context inferred")
 		} else {
 			// Add translated constraint
-			t.schema.AddVanishingConstraint(decl.Handle, context, decl.Domain, constraint)
+			t.schema.AddVanishingConstraint(decl.Handle, context, decl.Domain, constraint).SetSource(source)
+			t.constrainBitwiseOperands(constraint, source)
 		}
 	}
 	// Done
 	return errors
 }
 
+// Gate the (non-constant) operands of any bitwise operations within a given
+// constraint by its guard and perspective selector (if applicable).  That is,
+// each operand x becomes (selector * guard/guard * x), which is simply x on rows
+// where the constraint is active and 0 otherwise.  This ensures operands are
+// only required to fit within the bitwidth of their operation on rows where the
+// constraint is active (see constrainBitwiseOperands).
+func (t *translator) gateBitwiseOperands(constraint hir.Expr, guard hir.Expr, selector hir.Expr) {
+	var gates []hir.Expr
+	//
+	if guard != nil {
+		gates = append(gates, &hir.Normalise{Arg: guard})
+	}
+	//
+	if selector != nil {
+		gates = append(gates, selector)
+	}
+	//
+	if len(gates) == 0 {
+		return
+	}
+	//
+	for _, op := range hir.BitwiseOperations(constraint) {
+		for i, arg := range op.Args {
+			// Shift amounts and constant operands are left as is.
+			if (op.Op.IsShift() && i == 1) || arg.Context(t.schema).IsVoid() {
+				continue
+			}
+			//
+			op.Args[i] = &hir.Mul{Args: append(append([]hir.Expr{}, gates...), arg)}
+		}
+	}
+}
+
+// Constrain the operands of any bitwise operations within a given constraint to
+// fit within the bitwidth of their operation.  This is necessary because, at
+// the AIR level, every operand is decomposed into bytes on every row, which
+// implicitly rejects any wider value.  Making this explicit ensures traces are
+// accepted (or rejected) consistently at every level.  Operands are gated by the
+// guard and perspective selector of their constraint (see gateBitwiseOperands)
+// and, hence, are only checked where the constraint is active.
+func (t *translator) constrainBitwiseOperands(constraint hir.Expr, source *sc.Source) {
+	for _, op := range hir.BitwiseOperations(constraint) {
+		for i, arg := range op.Args {
+			// Shift amounts are constants, and not operands as such.
+			if op.Op.IsShift() && i == 1 {
+				continue
+			} else if ca, ok := arg.(*hir.ColumnAccess); ok {
+				// Constraint applies on every row, hence shift is irrelevant
+				arg = &hir.ColumnAccess{Column: ca.Column, Shift: 0}
+			}
+			//
+			context := arg.Context(t.schema)
+			// Constant operands are checked statically.
+			if context.IsVoid() {
+				continue
+			}
+			//
+			name := fmt.Sprintf("%s:u%d", arg.Lisp(t.schema).String(false), op.Width)
+			key := fmt.Sprintf("%d:%s", context.Module(), name)
+			//
+			if !t.operands[key] {
+				t.operands[key] = true
+				t.schema.AddRangeConstraint(name, context, arg, sc.NewUintType(op.Width).Bound()).SetSource(source)
+			}
+		}
+	}
+}
+
+// Translate the selector for the perspective of a given declaration.  If there
+// is no perspective, then nil is returned.
+func (t *translator) translateSelectorInModule(perspective *PerspectiveName, module string) (hir.Expr, []SyntaxError) {
+	if perspective != nil {
+		binding := perspective.Binding().(*PerspectiveBinding)
+		return t.translateExpressionInModule(binding.selector, module, 0)
+	}
+	//
+	return nil, nil
+}
+
 // Translate a "deflookup" declaration.
 //
 //nolint:staticcheck
@@ -248,12 +447,18 @@ func (t *translator) translateDefLookup(decl *DefLookup, module string) []Syntax
 	targets, tgt_errs := t.translateUnitExpressionsInModule(decl.Targets, module, 0)
 	// Combine errors
 	errors := append(src_errs, tgt_errs...)
+	// Translate (optional) selectors
+	src_selector, src_errs := t.translateLookupSelector(decl.SourceSelector, module)
+	tgt_selector, tgt_errs := t.translateLookupSelector(decl.TargetSelector, module)
+	// Combine errors
+	errors = append(errors, append(src_errs, tgt_errs...)...)
 	//
 	if len(errors) == 0 {
-		src_context := t.env.ToContext(ContextOfExpressions(decl.Sources))
-		target_context := t.env.ToContext(ContextOfExpressions(decl.Targets))
+		src_context := t.env.ToContext(ContextOfExpressions(append([]Expr{decl.SourceSelector}, decl.Sources...)))
+		target_context := t.env.ToContext(ContextOfExpressions(append([]Expr{decl.TargetSelector}, decl.Targets...)))
 		// Add translated constraint
-		t.schema.AddLookupConstraint(decl.Handle, src_context, target_context, sources, targets)
+		t.schema.AddLookupConstraint(decl.Handle, src_context, target_context, sources, targets,
+			src_selector, tgt_selector).SetSource(t.sourceOf(decl))
 	}
 	// Done
 	return errors
@@ -267,7 +472,7 @@ func (t *translator) translateDefInRange(decl *DefInRange, module string) []Synt
 	if len(errors) == 0 {
 		context := t.env.ContextFrom(module, 1)
 		// Add translated constraint
-		t.schema.AddRangeConstraint("", context, expr, decl.Bound)
+		t.schema.AddRangeConstraint("", context, expr, decl.Bound).SetSource(t.sourceOf(decl))
 	}
 	// Done
 	return errors
@@ -289,7 +494,9 @@ func (t *translator) translateDefInterleaved(decl *DefInterleaved, module string
 	// Extract underlying datatype
 	datatype := info.dataType.AsUnderlying()
 	// Register assignment
-	cid := t.schema.AddAssignment(assignment.NewInterleaving(context, decl.Target.Name(), sources, datatype))
+	interleaving := assignment.NewInterleaving(context, decl.Target.Name(), sources, datatype)
+	interleaving.SetSource(t.sourceOf(decl))
+	cid := t.schema.AddAssignment(interleaving)
 	// Sanity check column identifiers align.
 	if cid != info.ColumnId() {
 		errors = append(errors, *t.srcmap.SyntaxError(decl, "invalid column identifier"))
@@ -298,6 +505,18 @@ func (t *translator) translateDefInterleaved(decl *DefInterleaved, module string
 	return errors
 }
 
+// Translate the (optional) selector for one side of a lookup constraint.
+func (t *translator) translateLookupSelector(selector Expr, module string) (util.Option[hir.UnitExpr],
+	[]SyntaxError) {
+	if selector == nil {
+		return util.None[hir.UnitExpr](), nil
+	}
+	//
+	expr, errors := t.translateExpressionInModule(selector, module, 0)
+	//
+	return util.Some(hir.NewUnitExpr(expr)), errors
+}
+
 // Translate a "defpermutation" declaration.
 func (t *translator) translateDefPermutation(decl *DefPermutation, module string) []SyntaxError {
 	var (
@@ -317,15 +536,28 @@ func (t *translator) translateDefPermutation(decl *DefPermutation, module string
 		datatype := target.dataType.AsUnderlying()
 		// Construct columns
 		targets[i] = sc.NewColumn(context, decl.Targets[i].Name(), datatype)
-		sources[i] = t.env.Column(module, decl.Sources[i].Name()).ColumnId()
+		sources[i] = decl.Sources[i].Binding().(*ColumnBinding).ColumnId()
 		signs[i] = decl.Signs[i]
 		// Record first CID
 		if i == 0 {
 			firstCid = target.ColumnId()
 		}
 	}
+	// Construct the assignment
+	permutation := assignment.NewSortedPermutation(context, targets, signs, sources)
+	// Apply selectors (if applicable)
+	if decl.SourceSelector != nil {
+		selector := decl.SourceSelector.Binding().(*ColumnBinding).ColumnId()
+		target := t.env.Column(module, decl.TargetSelector.Name())
+		datatype := target.dataType.AsUnderlying()
+		targetSelector := sc.NewColumn(context, decl.TargetSelector.Name(), datatype)
+		permutation = assignment.NewFilteredSortedPermutation(context, targets, signs, sources, selector,
+			targetSelector)
+	}
+	//
+	permutation.SetSource(t.sourceOf(decl))
 	// Add the assignment and check the first identifier.
-	cid := t.schema.AddAssignment(assignment.NewSortedPermutation(context, targets, signs, sources))
+	cid := t.schema.AddAssignment(permutation)
 	// Sanity check column identifiers align.
 	if cid != firstCid {
 		errors = append(errors, *t.srcmap.SyntaxError(decl, "invalid column identifier"))
@@ -342,12 +574,42 @@ func (t *translator) translateDefProperty(decl *DefProperty, module string) []Sy
 	if len(errors) == 0 {
 		context := t.env.ContextFrom(module, 1)
 		// Add translated constraint
-		t.schema.AddPropertyAssertion(decl.Handle, context, assertion)
+		t.schema.AddPropertyAssertion(decl.Handle, context, assertion).SetSource(t.sourceOf(decl))
 	}
 	// Done
 	return errors
 }
 
+// Determine the origin of a given declaration, or nil if this is unknown.
+func (t *translator) sourceOf(decl Node) *sc.Source {
+	if file, span, ok := t.srcmap.Lookup(decl); ok {
+		return sc.NewSource(file, span)
+	}
+	//
+	return nil
+}
+
+// Record the origins of the elements of a list, such that constraints arising
+// from them can be attributed accordingly.  Nested lists are also handled,
+// whilst all other expressions are attributed in their entirety to the
+// enclosing declaration.
+func (t *translator) attributeSubExprs(expr Expr, hirExpr hir.Expr, source *sc.Source) {
+	list, ok1 := expr.(*List)
+	hirList, ok2 := hirExpr.(*hir.List)
+	// Sanity check lists align
+	if !ok1 || !ok2 || source == nil || len(list.Args) != len(hirList.Args) {
+		return
+	}
+	//
+	for i, arg := range list.Args {
+		if _, span, ok := t.srcmap.Lookup(arg); ok && hirList.Args[i] != nil {
+			ith := source.Narrow(span)
+			t.schema.AttributeExpr(hirList.Args[i], ith)
+			t.attributeSubExprs(arg, hirList.Args[i], ith)
+		}
+	}
+}
+
 // Translate an optional expression in a given context.  That is an expression
 // which maybe nil (i.e. doesn't exist).  In such case, nil is returned (i.e.
 // without any errors).
@@ -414,6 +676,8 @@ func (t *translator) translateExpressionInModule(expr Expr, module string, shift
 	case *Add:
 		args, errs := t.translateExpressionsInModule(e.Args, module, shift)
 		return &hir.Add{Args: args}, errs
+	case *Bitwise:
+		return t.translateBitwiseInModule(e, module, shift)
 	case *Constant:
 		var val fr.Element
 		// Initialise field from bigint
@@ -474,10 +738,8 @@ func (t *translator) translateArrayAccessInModule(expr *ArrayAccess, shift int)
 	if len(errors) > 0 {
 		return nil, errors
 	}
-	// Lookup underlying column info
-	info := t.env.Column(binding.module, expr.Name())
 	// Update column id (remember indices start from 1)
-	columnId := info.cid + uint(index.Uint64()) - 1
+	columnId := binding.ColumnId() + uint(index.Uint64()) - 1
 	// Done
 	return &hir.ColumnAccess{Column: columnId, Shift: shift}, nil
 }
@@ -499,6 +761,39 @@ func (t *translator) translateExpInModule(expr *Exp, module string, shift int) (
 	return nil, errs
 }
 
+func (t *translator) translateBitwiseInModule(expr *Bitwise, module string, shift int) (hir.Expr, []SyntaxError) {
+	args, errs := t.translateExpressionsInModule(expr.Args, module, shift)
+	// Check bitwidth is supported
+	if expr.Width > MAX_BITWISE_WIDTH {
+		msg := fmt.Sprintf("bitwise operation too wide (u%d exceeds u%d)", expr.Width, MAX_BITWISE_WIDTH)
+		errs = append(errs, *t.srcmap.SyntaxError(expr, msg))
+	}
+	// Constant operands must fit within the bitwidth (see also
+	// constrainBitwiseOperands).
+	for i, arg := range expr.Args {
+		if expr.Op.IsShift() && i == 1 {
+			break
+		} else if c := arg.AsConstant(); c != nil && (c.Sign() < 0 || uint(c.BitLen()) > expr.Width) {
+			msg := fmt.Sprintf("constant operand not within bitwidth (u%d)", expr.Width)
+			errs = append(errs, *t.srcmap.SyntaxError(arg, msg))
+		}
+	}
+	// Shift amounts must be statically known
+	if expr.Op.IsShift() {
+		if amount := expr.Args[1].AsConstant(); amount == nil {
+			errs = append(errs, *t.srcmap.SyntaxError(expr.Args[1], "expected constant shift amount"))
+		} else if amount.Sign() < 0 {
+			errs = append(errs, *t.srcmap.SyntaxError(expr.Args[1], "negative shift amount"))
+		}
+	}
+	// Sanity check errors
+	if len(errs) == 0 {
+		return &hir.Bitwise{Op: expr.Op, Width: expr.Width, Args: args}, errs
+	}
+	//
+	return nil, errs
+}
+
 func (t *translator) translateShiftInModule(expr *Shift, module string, shift int) (hir.Expr, []SyntaxError) {
 	constant := expr.Shift.AsConstant()
 	// Determine the shift constant
@@ -514,10 +809,8 @@ func (t *translator) translateShiftInModule(expr *Shift, module string, shift in
 func (t *translator) translateVariableAccessInModule(expr *VariableAccess, module string,
 	shift int) (hir.Expr, []SyntaxError) {
 	if binding, ok := expr.Binding().(*ColumnBinding); ok {
-		// Lookup column binding
-		cinfo := t.env.Column(binding.module, expr.Name())
 		// Done
-		return &hir.ColumnAccess{Column: cinfo.ColumnId(), Shift: shift}, nil
+		return &hir.ColumnAccess{Column: binding.ColumnId(), Shift: shift}, nil
 	} else if binding, ok := expr.Binding().(*ConstantBinding); ok {
 		// Just fill in the constant.
 		return t.translateExpressionInModule(binding.value, module, shift)
@@ -0,0 +1,709 @@
+package hir
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// BINARY_MAGIC identifies a compiled schema file, and distinguishes it from
+// other (e.g. legacy Corset) binary constraint files.
+const BINARY_MAGIC = "go-corset"
+
+// BINARY_MAJOR_VERSION is the major version of the compiled schema format.  A
+// compiled schema can only be read by a tool supporting the same major
+// version.
+const BINARY_MAJOR_VERSION = 1
+
+// BINARY_MINOR_VERSION is the minor version of the compiled schema format.
+// Minor versions are backwards compatible, meaning a compiled schema can be
+// read by any tool supporting the same (or a later) minor version.
+const BINARY_MINOR_VERSION = 4
+
+// ToBytes serialises a given schema into a compiled schema file.  The file is
+// self-contained, and can be read back (e.g. using FromBytes) to reconstruct
+// an identical schema without needing access to the original source files.
+func ToBytes(schema *Schema) ([]byte, error) {
+	file := jsonBinaryFile{
+		Magic:   BINARY_MAGIC,
+		Version: jsonVersion{BINARY_MAJOR_VERSION, BINARY_MINOR_VERSION},
+		Schema:  encodeSchema(schema),
+	}
+	//
+	return json.Marshal(file)
+}
+
+// IsBinaryFile determines whether or not a given array of bytes represents a
+// compiled schema file (of any version).
+func IsBinaryFile(data []byte) bool {
+	var header jsonHeader
+	//
+	return json.Unmarshal(data, &header) == nil && header.Magic == BINARY_MAGIC
+}
+
+// FromBytes parses a byte array representing a compiled schema file into a
+// schema, or produces an error if the file was malformed in some way (or has
+// an incompatible version).
+func FromBytes(data []byte) (*Schema, error) {
+	var file jsonBinaryFile
+	// Parse JSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	} else if file.Magic != BINARY_MAGIC {
+		return nil, errors.New("not a compiled schema file")
+	} else if file.Version.Major != BINARY_MAJOR_VERSION || file.Version.Minor > BINARY_MINOR_VERSION {
+		return nil, fmt.Errorf("incompatible compiled schema file (version %d.%d, expected %d.%d)",
+			file.Version.Major, file.Version.Minor, BINARY_MAJOR_VERSION, BINARY_MINOR_VERSION)
+	}
+	//
+	return file.Schema.decode()
+}
+
+// ============================================================================
+// File Format
+// ============================================================================
+
+type jsonHeader struct {
+	Magic string `json:"magic"`
+}
+
+type jsonVersion struct {
+	Major uint `json:"major"`
+	Minor uint `json:"minor"`
+}
+
+type jsonBinaryFile struct {
+	// Identifies this as a compiled schema file.
+	Magic string `json:"magic"`
+	// Version of the format used within this file.
+	Version jsonVersion `json:"version"`
+	// The compiled schema itself.
+	Schema jsonSchema `json:"schema"`
+}
+
+type jsonSchema struct {
+	Modules     []string         `json:"modules"`
+	Inputs      []jsonColumn     `json:"inputs"`
+	Assignments []jsonAssignment `json:"assignments"`
+	Constraints []jsonConstraint `json:"constraints"`
+	Assertions  []jsonConstraint `json:"assertions"`
+	// Columns whose types must be proven.  This was introduced in minor
+	// version 4.
+	Proven []uint `json:"proven,omitempty"`
+}
+
+type jsonContext struct {
+	Module     uint `json:"module"`
+	Multiplier uint `json:"multiplier"`
+}
+
+type jsonWindow struct {
+	Start int  `json:"start"`
+	End   int  `json:"end"`
+	Step  uint `json:"step"`
+}
+
+type jsonColumn struct {
+	Context jsonContext `json:"context"`
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+}
+
+// Assignments are distinguished by their kind, which determines which of the
+// remaining fields are used.
+type jsonAssignment struct {
+	Kind    string      `json:"kind"`
+	Context jsonContext `json:"context"`
+	// Declared columns (computed columns and interleavings declare exactly
+	// one).
+	Targets []jsonColumn `json:"targets"`
+	// Source columns (interleavings and permutations only).
+	Sources []uint `json:"sources,omitempty"`
+	// Sorting directions (permutations only).
+	Signs []bool `json:"signs,omitempty"`
+	// Computation (computed columns only).
+	Expr *jsonExpr `json:"expr,omitempty"`
+	// Source selector (permutations only), in which case the last declared
+	// column is the target selector.  This was introduced in minor version 3.
+	Selector *uint `json:"selector,omitempty"`
+}
+
+// Constraints are distinguished by their kind, which determines which of the
+// remaining fields are used.
+type jsonConstraint struct {
+	Kind    string      `json:"kind"`
+	Handle  string      `json:"handle"`
+	Context jsonContext `json:"context"`
+	// Restricted domain consisting of a single row (vanishing constraints
+	// only).
+	Domain *int `json:"domain,omitempty"`
+	// Restricted domain consisting of multiple rows (vanishing constraints
+	// only).  This was introduced in minor version 1.
+	Windows []jsonWindow `json:"windows,omitempty"`
+	// Constrained expression (vanishing, range and property assertions).
+	Expr *jsonExpr `json:"expr,omitempty"`
+	// Bound (range constraints only).
+	Bound string `json:"bound,omitempty"`
+	// Target context (lookups only).
+	TargetContext *jsonContext `json:"target_context,omitempty"`
+	// Source expressions (lookups only).
+	Sources []*jsonExpr `json:"sources,omitempty"`
+	// Target expressions (lookups only).
+	Targets []*jsonExpr `json:"targets,omitempty"`
+	// Source selector (lookups only).  This was introduced in minor version 2.
+	SourceSelector *jsonExpr `json:"source_selector,omitempty"`
+	// Target selector (lookups only).  This was introduced in minor version 2.
+	TargetSelector *jsonExpr `json:"target_selector,omitempty"`
+}
+
+// Expressions are distinguished by their operator, which determines which of
+// the remaining fields are used.  Observe that optional arguments (e.g. the
+// branches of a conditional) are represented as null.
+type jsonExpr struct {
+	Op    string      `json:"op"`
+	Args  []*jsonExpr `json:"args,omitempty"`
+	Col   uint        `json:"col,omitempty"`
+	Shift int         `json:"shift,omitempty"`
+	Val   string      `json:"val,omitempty"`
+	Pow   uint64      `json:"pow,omitempty"`
+	Width uint        `json:"width,omitempty"`
+}
+
+// ============================================================================
+// Encoding
+// ============================================================================
+
+func encodeSchema(schema *Schema) jsonSchema {
+	var js jsonSchema
+	//
+	js.Modules = make([]string, len(schema.modules))
+	for i := range schema.modules {
+		js.Modules[i] = schema.modules[i].Name()
+	}
+	//
+	js.Inputs = make([]jsonColumn, len(schema.inputs))
+	for i, d := range schema.inputs {
+		js.Inputs[i] = encodeColumn(d.Columns().Next())
+	}
+	//
+	js.Assignments = make([]jsonAssignment, len(schema.assignments))
+	for i, a := range schema.assignments {
+		js.Assignments[i] = encodeAssignment(a)
+	}
+	//
+	js.Constraints = make([]jsonConstraint, len(schema.constraints))
+	for i, c := range schema.constraints {
+		js.Constraints[i] = encodeConstraint(c)
+	}
+	//
+	js.Assertions = make([]jsonConstraint, len(schema.assertions))
+	for i, a := range schema.assertions {
+		js.Assertions[i] = jsonConstraint{Kind: "assert", Handle: a.Handle(),
+			Context: encodeContext(a.Context()), Expr: encodeExpr(a.Property().Expr)}
+	}
+	//
+	js.Proven = schema.proven
+	//
+	return js
+}
+
+func encodeAssignment(a sc.Assignment) jsonAssignment {
+	js := jsonAssignment{Context: encodeContext(a.Context())}
+	//
+	for i := a.Columns(); i.HasNext(); {
+		js.Targets = append(js.Targets, encodeColumn(i.Next()))
+	}
+	//
+	switch a := a.(type) {
+	case ComputedColumn:
+		js.Kind = "computed"
+		js.Expr = encodeExpr(a.Expr().expr)
+	case *assignment.Interleaving:
+		js.Kind = "interleave"
+		js.Sources = a.Sources()
+	case Permutation:
+		js.Kind = "permute"
+		js.Sources = a.Sources()
+		js.Signs = a.Signs()
+		//
+		if a.Selector().HasValue() {
+			selector := a.Selector().Unwrap()
+			js.Selector = &selector
+		}
+	default:
+		panic(fmt.Sprintf("unknown HIR assignment encountered (%T)", a))
+	}
+	//
+	return js
+}
+
+func encodeConstraint(c sc.Constraint) jsonConstraint {
+	switch c := c.(type) {
+	case VanishingConstraint:
+		row, windows := encodeDomain(c.Domain())
+		//
+		return jsonConstraint{Kind: "vanish", Handle: c.Handle(), Context: encodeContext(c.Context()),
+			Domain: row, Windows: windows, Expr: encodeExpr(c.Constraint().Expr)}
+	case RangeConstraint:
+		bound := c.Bound()
+		//
+		return jsonConstraint{Kind: "range", Handle: c.Handle(), Context: encodeContext(c.Context()),
+			Expr: encodeExpr(c.Target().expr), Bound: bound.String()}
+	case LookupConstraint:
+		target := encodeContext(c.TargetContext())
+		//
+		return jsonConstraint{Kind: "lookup", Handle: c.Handle(), Context: encodeContext(c.SourceContext()),
+			TargetContext: &target, Sources: encodeUnitExprs(c.Sources()), Targets: encodeUnitExprs(c.Targets()),
+			SourceSelector: encodeSelector(c.SourceSelector()), TargetSelector: encodeSelector(c.TargetSelector())}
+	default:
+		panic(fmt.Sprintf("unknown HIR constraint encountered (%T)", c))
+	}
+}
+
+func encodeSelector(selector util.Option[UnitExpr]) *jsonExpr {
+	if selector.HasValue() {
+		return encodeExpr(selector.Unwrap().expr)
+	}
+	//
+	return nil
+}
+
+func encodeUnitExprs(exprs []UnitExpr) []*jsonExpr {
+	jexprs := make([]*jsonExpr, len(exprs))
+	for i, e := range exprs {
+		jexprs[i] = encodeExpr(e.expr)
+	}
+	//
+	return jexprs
+}
+
+func encodeExpr(e Expr) *jsonExpr {
+	switch e := e.(type) {
+	case nil:
+		return nil
+	case *ColumnAccess:
+		return &jsonExpr{Op: "col", Col: e.Column, Shift: e.Shift}
+	case *Constant:
+		return &jsonExpr{Op: "const", Val: e.Val.String()}
+	case *Add:
+		return &jsonExpr{Op: "add", Args: encodeExprs(e.Args)}
+	case *Sub:
+		return &jsonExpr{Op: "sub", Args: encodeExprs(e.Args)}
+	case *Mul:
+		return &jsonExpr{Op: "mul", Args: encodeExprs(e.Args)}
+	case *List:
+		return &jsonExpr{Op: "list", Args: encodeExprs(e.Args)}
+	case *Exp:
+		return &jsonExpr{Op: "exp", Args: encodeExprs([]Expr{e.Arg}), Pow: e.Pow}
+	case *Normalise:
+		return &jsonExpr{Op: "norm", Args: encodeExprs([]Expr{e.Arg})}
+	case *IfZero:
+		return &jsonExpr{Op: "if", Args: encodeExprs([]Expr{e.Condition, e.TrueBranch, e.FalseBranch})}
+	case *Bitwise:
+		return &jsonExpr{Op: e.Op.String(), Args: encodeExprs(e.Args), Width: e.Width}
+	default:
+		panic(fmt.Sprintf("unknown HIR expression encountered (%T)", e))
+	}
+}
+
+func encodeExprs(exprs []Expr) []*jsonExpr {
+	jexprs := make([]*jsonExpr, len(exprs))
+	for i, e := range exprs {
+		jexprs[i] = encodeExpr(e)
+	}
+	//
+	return jexprs
+}
+
+func encodeColumn(col sc.Column) jsonColumn {
+	return jsonColumn{encodeContext(col.Context()), col.Name(), encodeType(col.Type())}
+}
+
+func encodeContext(ctx trace.Context) jsonContext {
+	return jsonContext{ctx.Module(), ctx.LengthMultiplier()}
+}
+
+// Encode the domain of a vanishing constraint.  Domains consisting of a single
+// row are encoded as that row, as in earlier versions of the format.
+func encodeDomain(domain *constraint.Domain) (*int, []jsonWindow) {
+	if domain == nil {
+		return nil, nil
+	} else if row, ok := domain.Row(); ok {
+		return &row, nil
+	}
+	//
+	windows := make([]jsonWindow, len(domain.Windows()))
+	//
+	for i, w := range domain.Windows() {
+		windows[i] = jsonWindow{w.Start, w.End, w.Step}
+	}
+	//
+	return nil, windows
+}
+
+func encodeType(t sc.Type) string {
+	if t.AsUint() != nil {
+		return fmt.Sprintf("u%d", t.AsUint().BitWidth())
+	}
+	//
+	return "field"
+}
+
+// ============================================================================
+// Decoding
+// ============================================================================
+
+// Decode a given schema.  Since columns are identified by their index, it is
+// critical that inputs and assignments are added in exactly the order they
+// were originally declared.
+func (js *jsonSchema) decode() (*Schema, error) {
+	schema := EmptySchema()
+	// Determine total number of columns, so that column accesses can be
+	// checked.
+	ncols := uint(len(js.Inputs))
+	for _, a := range js.Assignments {
+		ncols += uint(len(a.Targets))
+	}
+	//
+	for _, name := range js.Modules {
+		schema.AddModule(name)
+	}
+	// Decode input columns
+	for _, c := range js.Inputs {
+		ctx, datatype, err := c.decode(schema)
+		if err != nil {
+			return nil, err
+		}
+		//
+		schema.AddDataColumn(ctx, c.Name, datatype)
+	}
+	// Decode assignments
+	for _, a := range js.Assignments {
+		decl, err := a.decode(schema, ncols)
+		if err != nil {
+			return nil, err
+		}
+		//
+		schema.AddAssignment(decl)
+	}
+	// Decode proven columns
+	if err := checkColumns(js.Proven, ncols); err != nil {
+		return nil, err
+	}
+	//
+	for _, cid := range js.Proven {
+		schema.AddProvenColumn(cid)
+	}
+	// Decode constraints
+	for _, c := range js.Constraints {
+		if err := c.decode(schema, ncols); err != nil {
+			return nil, err
+		}
+	}
+	// Decode assertions
+	for _, a := range js.Assertions {
+		if a.Kind != "assert" {
+			return nil, fmt.Errorf("invalid assertion kind \"%s\"", a.Kind)
+		} else if err := a.decode(schema, ncols); err != nil {
+			return nil, err
+		}
+	}
+	//
+	return schema, nil
+}
+
+func (jc *jsonColumn) decode(schema *Schema) (trace.Context, sc.Type, error) {
+	ctx, err := jc.Context.decode(schema)
+	if err != nil {
+		return ctx, nil, err
+	}
+	//
+	datatype, err := decodeType(jc.Type)
+	//
+	return ctx, datatype, err
+}
+
+func (ja *jsonAssignment) decode(schema *Schema, ncols uint) (sc.Assignment, error) {
+	ctx, err := ja.Context.decode(schema)
+	if err != nil {
+		return nil, err
+	}
+	//
+	targets := make([]sc.Column, len(ja.Targets))
+	//
+	for i, c := range ja.Targets {
+		tctx, datatype, err := c.decode(schema)
+		if err != nil {
+			return nil, err
+		} else if tctx != ctx {
+			return nil, fmt.Errorf("inconsistent context for column %s", c.Name)
+		}
+		//
+		targets[i] = sc.NewColumn(tctx, c.Name, datatype)
+	}
+	//
+	if err := checkColumns(ja.Sources, ncols); err != nil {
+		return nil, err
+	}
+	//
+	switch {
+	case ja.Kind == "computed" && len(targets) == 1:
+		expr, err := ja.Expr.decode(ncols)
+		if err != nil {
+			return nil, err
+		}
+		//
+		return assignment.NewComputedColumn(ctx, targets[0].Name(), targets[0].Type(), NewUnitExpr(expr)), nil
+	case ja.Kind == "interleave" && len(targets) == 1 && len(ja.Sources) > 0:
+		if ctx.LengthMultiplier()%uint(len(ja.Sources)) != 0 {
+			return nil, fmt.Errorf("invalid length multiplier for column %s", targets[0].Name())
+		}
+		//
+		return assignment.NewInterleaving(ctx, targets[0].Name(), ja.Sources, targets[0].Type()), nil
+	case ja.Kind == "permute" && ja.Selector != nil && len(targets) == len(ja.Sources)+1 &&
+		len(ja.Sources) == len(ja.Signs):
+		if err := checkColumns([]uint{*ja.Selector}, ncols); err != nil {
+			return nil, err
+		}
+		//
+		n := len(ja.Sources)
+		//
+		return assignment.NewFilteredSortedPermutation(ctx, targets[:n], ja.Signs, ja.Sources, *ja.Selector,
+			targets[n]), nil
+	case ja.Kind == "permute" && len(targets) == len(ja.Sources) && len(targets) == len(ja.Signs):
+		return assignment.NewSortedPermutation(ctx, targets, ja.Signs, ja.Sources), nil
+	default:
+		return nil, fmt.Errorf("invalid assignment (kind \"%s\")", ja.Kind)
+	}
+}
+
+// Decode a given constraint (or assertion) directly into the given schema.
+func (jc *jsonConstraint) decode(schema *Schema, ncols uint) error {
+	ctx, err := jc.Context.decode(schema)
+	if err != nil {
+		return err
+	}
+	//
+	switch jc.Kind {
+	case "vanish", "assert", "range":
+		expr, err := jc.Expr.decode(ncols)
+		if err != nil {
+			return err
+		} else if jc.Kind == "vanish" {
+			domain, err := jc.decodeDomain()
+			if err != nil {
+				return err
+			}
+			//
+			schema.AddVanishingConstraint(jc.Handle, ctx, domain, expr)
+		} else if jc.Kind == "assert" {
+			schema.AddPropertyAssertion(jc.Handle, ctx, expr)
+		} else {
+			var bound fr.Element
+			//
+			if _, err := bound.SetString(jc.Bound); err != nil {
+				return fmt.Errorf("invalid bound for range constraint %s", jc.Handle)
+			}
+			//
+			schema.AddRangeConstraint(jc.Handle, ctx, expr, bound)
+		}
+	case "lookup":
+		if jc.TargetContext == nil || len(jc.Sources) != len(jc.Targets) {
+			return fmt.Errorf("invalid lookup constraint %s", jc.Handle)
+		}
+		//
+		target, err := jc.TargetContext.decode(schema)
+		if err != nil {
+			return err
+		}
+		//
+		sources, err1 := decodeUnitExprs(jc.Sources, ncols)
+		targets, err2 := decodeUnitExprs(jc.Targets, ncols)
+		sourceSelector, err3 := decodeSelector(jc.SourceSelector, ncols)
+		targetSelector, err4 := decodeSelector(jc.TargetSelector, ncols)
+		//
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			return err
+		}
+		//
+		schema.AddLookupConstraint(jc.Handle, ctx, target, sources, targets, sourceSelector, targetSelector)
+	default:
+		return fmt.Errorf("invalid constraint kind \"%s\"", jc.Kind)
+	}
+	//
+	return nil
+}
+
+func decodeSelector(jexpr *jsonExpr, ncols uint) (util.Option[UnitExpr], error) {
+	if jexpr == nil {
+		return util.None[UnitExpr](), nil
+	}
+	//
+	e, err := jexpr.decode(ncols)
+	if err != nil {
+		return util.None[UnitExpr](), err
+	}
+	//
+	return util.Some(NewUnitExpr(e)), nil
+}
+
+func decodeUnitExprs(jexprs []*jsonExpr, ncols uint) ([]UnitExpr, error) {
+	exprs := make([]UnitExpr, len(jexprs))
+	//
+	for i, je := range jexprs {
+		e, err := je.decode(ncols)
+		if err != nil {
+			return nil, err
+		}
+		//
+		exprs[i] = NewUnitExpr(e)
+	}
+	//
+	return exprs, nil
+}
+
+// Decode a given (non-optional) expression.
+func (je *jsonExpr) decode(ncols uint) (Expr, error) {
+	if je == nil {
+		return nil, errors.New("missing expression")
+	}
+	// Check arities
+	switch je.Op {
+	case "col", "const":
+		if len(je.Args) != 0 {
+			return nil, fmt.Errorf("unexpected arguments for \"%s\"", je.Op)
+		}
+	case "exp", "norm", "bnot":
+		if len(je.Args) != 1 {
+			return nil, fmt.Errorf("expected one argument for \"%s\"", je.Op)
+		}
+	case "shl", "shr":
+		if len(je.Args) != 2 {
+			return nil, fmt.Errorf("expected two arguments for \"%s\"", je.Op)
+		}
+	case "if":
+		if len(je.Args) != 3 || (je.Args[1] == nil && je.Args[2] == nil) {
+			return nil, errors.New("invalid arguments for \"if\"")
+		}
+	default:
+		if len(je.Args) == 0 {
+			return nil, fmt.Errorf("expected one or more arguments for \"%s\"", je.Op)
+		}
+	}
+	// Decode arguments
+	args := make([]Expr, len(je.Args))
+	//
+	for i, arg := range je.Args {
+		var err error
+		// Only the branches of a conditional are optional
+		if arg != nil || je.Op != "if" || i == 0 {
+			if args[i], err = arg.decode(ncols); err != nil {
+				return nil, err
+			}
+		}
+	}
+	//
+	switch je.Op {
+	case "col":
+		if je.Col >= ncols {
+			return nil, fmt.Errorf("invalid column index (%d)", je.Col)
+		}
+		//
+		return &ColumnAccess{je.Col, je.Shift}, nil
+	case "const":
+		var val fr.Element
+		//
+		if _, err := val.SetString(je.Val); err != nil {
+			return nil, fmt.Errorf("invalid constant \"%s\"", je.Val)
+		}
+		//
+		return &Constant{val}, nil
+	case "add":
+		return &Add{args}, nil
+	case "sub":
+		return &Sub{args}, nil
+	case "mul":
+		return &Mul{args}, nil
+	case "list":
+		return &List{args}, nil
+	case "exp":
+		return &Exp{args[0], je.Pow}, nil
+	case "norm":
+		return &Normalise{args[0]}, nil
+	case "if":
+		return &IfZero{args[0], args[1], args[2]}, nil
+	}
+	// Check for bitwise operations
+	for op := util.BAND; op <= util.SHR; op++ {
+		if je.Op == op.String() {
+			if je.Width == 0 {
+				return nil, fmt.Errorf("invalid width for \"%s\"", je.Op)
+			}
+			//
+			return &Bitwise{op, je.Width, args}, nil
+		}
+	}
+	//
+	return nil, fmt.Errorf("unknown operator \"%s\"", je.Op)
+}
+
+func (jc *jsonContext) decode(schema *Schema) (trace.Context, error) {
+	if jc.Module >= uint(len(schema.modules)) {
+		return trace.VoidContext[uint](), fmt.Errorf("invalid module index (%d)", jc.Module)
+	} else if jc.Multiplier == 0 {
+		return trace.VoidContext[uint](), errors.New("invalid length multiplier (0)")
+	}
+	//
+	return trace.NewContext(jc.Module, jc.Multiplier), nil
+}
+
+func (jc *jsonConstraint) decodeDomain() (*constraint.Domain, error) {
+	if jc.Windows == nil && jc.Domain == nil {
+		return nil, nil
+	} else if jc.Windows == nil {
+		return constraint.NewRowDomain(*jc.Domain), nil
+	}
+	//
+	windows := make([]constraint.Window, len(jc.Windows))
+	//
+	for i, w := range jc.Windows {
+		if w.Step == 0 {
+			return nil, errors.New("invalid domain step (0)")
+		}
+		//
+		windows[i] = constraint.Window{Start: w.Start, End: w.End, Step: w.Step}
+	}
+	//
+	return constraint.NewDomain(windows...), nil
+}
+
+func decodeType(t string) (sc.Type, error) {
+	if t == "field" {
+		return &sc.FieldType{}, nil
+	} else if nbits, ok := strings.CutPrefix(t, "u"); ok {
+		if n, err := strconv.ParseUint(nbits, 10, 32); err == nil && n > 0 {
+			return sc.NewUintType(uint(n)), nil
+		}
+	}
+	//
+	return nil, fmt.Errorf("invalid type \"%s\"", t)
+}
+
+func checkColumns(columns []uint, ncols uint) error {
+	for _, c := range columns {
+		if c >= ncols {
+			return fmt.Errorf("invalid column index (%d)", c)
+		}
+	}
+	//
+	return nil
+}
@@ -46,6 +46,26 @@ func (e *Exp) EvalAllAt(k int, tr trace.Trace) []fr.Element {
 	return vals
 }
 
+// EvalAllAt evaluates a bitwise operation at a given row in a trace by first
+// evaluating all of its arguments at that row.  Observe that, since the n-ary
+// operations are associative, they can be evaluated pairwise.
+func (e *Bitwise) EvalAllAt(k int, tr trace.Trace) []fr.Element {
+	if e.Op == util.BNOT {
+		vals := e.Args[0].EvalAllAt(k, tr)
+		for i := range vals {
+			vals[i] = util.EvalBitwise(e.Op, e.Width, vals[i:i+1])
+		}
+		// Done
+		return vals
+	}
+	//
+	fn := func(l fr.Element, r fr.Element) fr.Element {
+		return util.EvalBitwise(e.Op, e.Width, []fr.Element{l, r})
+	}
+	//
+	return evalExprsAt(k, tr, e.Args, fn)
+}
+
 // EvalAllAt evaluates a conditional at a given row in a trace by first evaluating
 // its condition at that row.  If that condition is zero then the true branch
 // (if applicable) is evaluated; otherwise if the condition is non-zero then
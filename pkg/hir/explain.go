@@ -0,0 +1,99 @@
+package hir
+
+import (
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// Explain the evaluation of a column access at a given row in a trace.
+func (e *ColumnAccess) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema)
+}
+
+// Explain the evaluation of a constant at a given row in a trace.
+func (e *Constant) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema)
+}
+
+// Explain the evaluation of a sum at a given row in a trace.
+func (e *Add) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of a product at a given row in a trace.
+func (e *Mul) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of an exponentiation at a given row in a trace.
+func (e *Exp) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Arg)
+}
+
+// Explain the evaluation of a bitwise operation at a given row in a trace.
+func (e *Bitwise) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of a conditional at a given row in a trace.  This
+// identifies which branch(es) were taken, noting that a condition can evaluate
+// to multiple values at this level.  Branches which were not taken are included
+// in the explanation, but are not evaluated.
+func (e *IfZero) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	var zero, nonzero bool
+	// Evaluate condition
+	condition := e.Condition.Explain(k, tr, schema).Annotate("condition")
+	// Determine which branches were taken
+	for _, cond := range condition.Values() {
+		zero = zero || cond.IsZero()
+		nonzero = nonzero || !cond.IsZero()
+	}
+	//
+	children := []*sc.Explanation{condition}
+	children = append(children, explainBranch(e.TrueBranch, "true branch", zero, k, tr, schema)...)
+	children = append(children, explainBranch(e.FalseBranch, "false branch", nonzero, k, tr, schema)...)
+	//
+	return sc.NewExplanation(e.Lisp(schema).String(false), e.EvalAllAt(k, tr), children...)
+}
+
+// Explain the evaluation of a list at a given row in a trace.
+func (e *List) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of a normalisation at a given row in a trace.
+func (e *Normalise) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Arg)
+}
+
+// Explain the evaluation of a subtraction at a given row in a trace.
+func (e *Sub) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of an expression at a given row in a trace, where
+// every argument of that expression is evaluated (and explained) as well.
+func explainExpr(e Expr, k int, tr trace.Trace, schema sc.Schema, args ...Expr) *sc.Explanation {
+	children := make([]*sc.Explanation, len(args))
+	//
+	for i, arg := range args {
+		children[i] = arg.Explain(k, tr, schema)
+	}
+	//
+	return sc.NewExplanation(e.Lisp(schema).String(false), e.EvalAllAt(k, tr), children...)
+}
+
+// Explain an (optional) branch of a conditional, which is only evaluated when
+// taken.
+func explainBranch(branch Expr, kind string, taken bool, k int, tr trace.Trace,
+	schema sc.Schema) []*sc.Explanation {
+	//
+	if branch == nil {
+		return nil
+	} else if !taken {
+		label := branch.Lisp(schema).String(false)
+		return []*sc.Explanation{sc.NewExplanation(label, nil).Annotate(kind + ", not taken")}
+	}
+	//
+	return []*sc.Explanation{branch.Explain(k, tr, schema).Annotate(kind)}
+}
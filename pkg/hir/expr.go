@@ -22,8 +22,8 @@ type Expr interface {
 	// LowerTo lowers this expression into the Mid-Level Intermediate
 	// Representation.  Observe that a single expression at this
 	// level can expand into *multiple* expressions at the MIR
-	// level.
-	LowerTo(*mir.Schema) []mir.Expr
+	// level.  This fails if the expression is malformed.
+	LowerTo(*mir.Schema) ([]mir.Expr, error)
 	// EvalAt evaluates this expression in a given tabular context.
 	// Observe that if this expression is *undefined* within this
 	// context then it returns "nil".  An expression can be
@@ -31,6 +31,10 @@ type Expr interface {
 	// row which does not exist (e.g. at index -1); secondly, if
 	// it accesses a column which does not exist.
 	EvalAllAt(int, trace.Trace) []fr.Element
+	// Explain the evaluation of this expression at a given row in a given
+	// trace, by producing an evaluation tree annotated with the value(s) of
+	// each subexpression.
+	Explain(int, trace.Trace, sc.Schema) *sc.Explanation
 
 	// Multiplicity returns the number of underlyg expressions that this
 	// expression will expand to.
@@ -218,6 +222,60 @@ func (p *Exp) Multiplicity() uint {
 	return p.Arg.Multiplicity()
 }
 
+// ============================================================================
+// Bitwise
+// ============================================================================
+
+// Bitwise represents a bitwise operation (e.g. bitwise and, shift left, etc)
+// over one or more arguments, each of which is treated as an unsigned integer
+// of a given bitwidth.  For shift operations, the second argument is the
+// (constant) shift amount.
+type Bitwise struct {
+	Op    util.BitwiseOp
+	Width uint
+	Args  []Expr
+}
+
+// Bounds returns max shift in either the negative (left) or positive
+// direction (right).
+func (p *Bitwise) Bounds() util.Bounds { return util.BoundsForArray(p.Args) }
+
+// Context determines the evaluation context (i.e. enclosing module) for this
+// expression.
+func (p *Bitwise) Context(schema sc.Schema) trace.Context {
+	return sc.JoinContexts[Expr](p.Args, schema)
+}
+
+// RequiredColumns returns the set of columns on which this term depends.
+// That is, columns whose values may be accessed when evaluating this term
+// on a given trace.
+func (p *Bitwise) RequiredColumns() *util.SortedSet[uint] {
+	return util.UnionSortedSets(p.Args, func(e Expr) *util.SortedSet[uint] {
+		return e.RequiredColumns()
+	})
+}
+
+// RequiredCells returns the set of trace cells on which this term depends.
+// That is, evaluating this term at the given row in the given trace will read
+// these cells.
+func (p *Bitwise) RequiredCells(row int, tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	return util.UnionAnySortedSets(p.Args, func(e Expr) *util.AnySortedSet[trace.CellRef] {
+		return e.RequiredCells(row, tr)
+	})
+}
+
+// Multiplicity returns the number of underlyg expressions that this
+// expression will expand to.
+func (p *Bitwise) Multiplicity() uint {
+	count := uint(0)
+	//
+	for _, e := range p.Args {
+		count += e.Multiplicity()
+	}
+	//
+	return count
+}
+
 // ============================================================================
 // List
 // ============================================================================
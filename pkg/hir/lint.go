@@ -0,0 +1,235 @@
+package hir
+
+import (
+	"slices"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// LintWarning identifies an element of a schema which, whilst valid, most likely
+// indicates a mistake.  For example, a column which is declared but never used.
+type LintWarning struct {
+	// Module enclosing the element in question.
+	module string
+	// Handle of the element in question (e.g. a column name or constraint
+	// handle).
+	handle string
+	// Source of the element in question, or nil if unknown.
+	source *sc.Source
+	// Describes the problem.
+	msg string
+}
+
+// Module returns the name of the module enclosing the element in question.
+func (p *LintWarning) Module() string {
+	return p.module
+}
+
+// Handle returns the handle of the element in question (e.g. the name of a
+// column, or the handle of a constraint).
+func (p *LintWarning) Handle() string {
+	return p.handle
+}
+
+// Source returns the source of the element in question, or nil if this is
+// unknown (e.g. for columns).
+func (p *LintWarning) Source() *sc.Source {
+	return p.source
+}
+
+// Message returns a description of the problem.
+func (p *LintWarning) Message() string {
+	return p.msg
+}
+
+func (p *LintWarning) String() string {
+	return trace.QualifiedColumnName(p.module, p.handle) + ": " + p.msg
+}
+
+// Lint analyses a given schema for elements which, whilst valid, most likely
+// indicate mistakes.  Specifically, this reports: columns which are never used;
+// columns which are used, but never constrained (even indirectly); constraints
+// which apply only to padding rows; and, columns which must have their type
+// proven (e.g. were declared ":prove") but have no range constraint enforcing
+// it.
+func Lint(schema *Schema) []LintWarning {
+	warnings := lintColumns(schema)
+	warnings = append(warnings, lintPaddingConstraints(schema)...)
+	warnings = append(warnings, lintProvenColumns(schema)...)
+	//
+	return warnings
+}
+
+// Report columns which are unused or unconstrained.  A column is used if any
+// constraint, assertion or assignment depends upon it.  Likewise, a column is
+// constrained if any constraint depends upon it, or it is related to other
+// columns by a permutation or interleaving (since these are enforced by the
+// prover).  Furthermore, the columns on which a constrained computed column
+// depends are themselves (indirectly) constrained.
+func lintColumns(schema *Schema) []LintWarning {
+	var (
+		warnings    []LintWarning
+		ncols       = schema.Columns().Count()
+		used        = make([]bool, ncols)
+		constrained = make([]bool, ncols)
+		// Columns declared by each assignment
+		declared = make([][]uint, len(schema.assignments))
+	)
+	// Constraints use and constrain the columns they depend upon
+	for iter := schema.Constraints(); iter.HasNext(); {
+		for cols := iter.Next().RequiredColumns().Iter(); cols.HasNext(); {
+			cid := cols.Next()
+			used[cid], constrained[cid] = true, true
+		}
+	}
+	// Assertions use (but do not constrain) the columns they depend upon
+	for _, assertion := range schema.assertions {
+		for cols := assertion.Property().RequiredColumns().Iter(); cols.HasNext(); {
+			used[cols.Next()] = true
+		}
+	}
+	// Assignments use the columns they depend upon
+	for i, cid := 0, uint(len(schema.inputs)); i < len(schema.assignments); i++ {
+		ith := schema.assignments[i]
+		//
+		for cols := ith.Columns(); cols.HasNext(); cols.Next() {
+			declared[i] = append(declared[i], cid)
+			cid++
+		}
+		//
+		for _, dep := range ith.Dependencies() {
+			used[dep] = true
+		}
+		// Permutations and interleavings constrain the columns they relate
+		if isRelation(ith) {
+			markConstrained(constrained, declared[i])
+			markConstrained(constrained, ith.Dependencies())
+		}
+	}
+	// Propagate constrained columns through assignments
+	for changed := true; changed; {
+		changed = false
+		//
+		for i, ith := range schema.assignments {
+			if slices.ContainsFunc(declared[i], func(cid uint) bool { return constrained[cid] }) {
+				changed = markConstrained(constrained, ith.Dependencies()) || changed
+			}
+		}
+	}
+	// Report any problems
+	for cid := uint(0); cid < ncols; cid++ {
+		if !used[cid] {
+			warnings = append(warnings, columnWarning(schema, cid, "column is never used"))
+		} else if !constrained[cid] {
+			warnings = append(warnings, columnWarning(schema, cid, "column is never constrained"))
+		}
+	}
+	//
+	return warnings
+}
+
+// Determine whether a given assignment relates its columns via a relationship
+// which is enforced by the prover.
+func isRelation(a sc.Assignment) bool {
+	switch a.(type) {
+	case *assignment.SortedPermutation, *assignment.Interleaving:
+		return true
+	}
+	//
+	return false
+}
+
+// Mark a given set of columns as constrained, returning true if any were not
+// already marked.
+func markConstrained(constrained []bool, columns []uint) bool {
+	changed := false
+	//
+	for _, cid := range columns {
+		if !constrained[cid] {
+			constrained[cid], changed = true, true
+		}
+	}
+	//
+	return changed
+}
+
+// Report vanishing constraints whose domain includes only padding rows, since
+// these never constrain the user-provided rows of a trace.  Every module begins
+// with one or more padding rows (i.e. its spillage), hence constraints applying
+// only to these rows (e.g. a constraint on the first row) are reported.
+func lintPaddingConstraints(schema *Schema) []LintWarning {
+	var warnings []LintWarning
+	//
+	for iter := schema.Constraints(); iter.HasNext(); {
+		if vc, ok := iter.Next().(VanishingConstraint); ok && vc.Domain() != nil {
+			spillage := sc.RequiredSpillage(vc.Context().Module(), schema)
+			//
+			if onlyPadding(vc.Domain(), spillage) {
+				module := moduleName(schema, vc.Context().Module())
+				msg := "constraint applies only to padding rows"
+				warnings = append(warnings, LintWarning{module, vc.Handle(), vc.Source(), msg})
+			}
+		}
+	}
+	//
+	return warnings
+}
+
+// Determine whether every row in a given domain is within the initial padding
+// rows.  Observe that negative rows are relative to the end of the trace and,
+// hence, are never considered padding.
+func onlyPadding(domain *constraint.Domain, spillage uint) bool {
+	for _, w := range domain.Windows() {
+		if w.Start < 0 || w.End < 0 || uint(w.End) >= spillage {
+			return false
+		}
+	}
+	//
+	return true
+}
+
+// Report columns which must have their types proven, but for which there is no
+// range constraint.
+func lintProvenColumns(schema *Schema) []LintWarning {
+	var warnings []LintWarning
+	//
+	for _, cid := range schema.ProvenColumns() {
+		if !hasRangeConstraint(schema, cid) {
+			warnings = append(warnings, columnWarning(schema, cid, "proven column has no range constraint"))
+		}
+	}
+	//
+	return warnings
+}
+
+// Determine whether a given column is the target of some range constraint.
+func hasRangeConstraint(schema *Schema, cid uint) bool {
+	for iter := schema.Constraints(); iter.HasNext(); {
+		if rc, ok := iter.Next().(RangeConstraint); ok {
+			if ca, ok := rc.Target().expr.(*ColumnAccess); ok && ca.Column == cid && ca.Shift == 0 {
+				return true
+			}
+		}
+	}
+	//
+	return false
+}
+
+// Construct a warning for a given column.
+func columnWarning(schema *Schema, cid uint, msg string) LintWarning {
+	col := schema.Columns().Nth(cid)
+	//
+	src := sc.SourceOf(schema.Declaration(cid))
+	//
+	return LintWarning{moduleName(schema, col.Context().Module()), col.Name(), src, msg}
+}
+
+// Determine the name of a given module.
+func moduleName(schema *Schema, mid uint) string {
+	mod := schema.Modules().Nth(mid)
+	//
+	return mod.Name()
+}
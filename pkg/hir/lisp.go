@@ -69,6 +69,12 @@ func (e *Exp) Lisp(schema sc.Schema) sexp.SExp {
 	return sexp.NewList([]sexp.SExp{sexp.NewSymbol("^"), arg, pow})
 }
 
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (e *Bitwise) Lisp(schema sc.Schema) sexp.SExp {
+	return nary2Lisp(schema, fmt.Sprintf("%s:u%d", e.Op, e.Width), e.Args)
+}
+
 // Lisp converts this schema element into a simple S-Expression, for example
 // so it can be printed.
 func (e *IfZero) Lisp(schema sc.Schema) sexp.SExp {
@@ -2,16 +2,22 @@ package hir
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/mir"
 	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // LowerToMir lowers (or refines) an HIR table into an MIR schema.  That means
 // lowering all the columns and constraints, whilst adding additional columns /
-// constraints as necessary to preserve the original semantics.
-func (p *Schema) LowerToMir() *mir.Schema {
+// constraints as necessary to preserve the original semantics.  If any element
+// cannot be lowered, then the errors arising are returned instead.
+func (p *Schema) LowerToMir() (*mir.Schema, []sc.LoweringError) {
+	var errors []sc.LoweringError
+	//
 	mirSchema := mir.EmptySchema()
 	// Copy modules
 	for _, mod := range p.modules {
@@ -22,122 +28,314 @@ func (p *Schema) LowerToMir() *mir.Schema {
 		col := input.(DataColumn)
 		mirSchema.AddDataColumn(col.Context(), col.Name(), col.Type())
 	}
-	// Lower assignments (nothing to do here)
+	// Lower assignments
 	for _, a := range p.assignments {
-		mirSchema.AddAssignment(a)
+		errors = p.lowerElement(errors, a, func() error {
+			return lowerAssignmentToMir(a, mirSchema)
+		})
 	}
 	// Lower constraints
 	for _, c := range p.constraints {
-		lowerConstraintToMir(c, mirSchema)
+		errors = p.lowerElement(errors, c, func() error {
+			return p.lowerConstraintToMir(c, mirSchema)
+		})
 	}
 	// Copy property assertions.  Observe, these do not require lowering
 	// because they are already MIR-level expressions.
 	for _, c := range p.assertions {
-		properties := c.Property().Expr.LowerTo(mirSchema)
-		for _, p := range properties {
-			mirSchema.AddPropertyAssertion(c.Handle(), c.Context(), p)
+		errors = p.lowerElement(errors, c, func() error {
+			properties, err := c.Property().Expr.LowerTo(mirSchema)
+			if err != nil {
+				return err
+			}
+			//
+			for _, p := range properties {
+				mirSchema.AddPropertyAssertion(c.Handle(), c.Context(), p).SetSource(c.Source())
+			}
+			//
+			return nil
+		})
+	}
+	//
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	//
+	return mirSchema, nil
+}
+
+// Lower a given element into the MIR level, appending an error to those given
+// if this fails.
+func (p *Schema) lowerElement(errors []sc.LoweringError, element any, lower func() error) []sc.LoweringError {
+	if err := sc.LowerElement("MIR", element, p, lower); err != nil {
+		return append(errors, *err)
+	}
+	//
+	return errors
+}
+
+// Lower an assignment to the MIR level.  Only computed columns require any
+// work, since their computations are HIR expressions.  All other assignments
+// are passed through unchanged.
+func lowerAssignmentToMir(a sc.Assignment, schema *mir.Schema) error {
+	if v, ok := a.(ComputedColumn); ok {
+		return lowerComputedColumnToMir(v, schema)
+	}
+	//
+	schema.AddAssignment(a)
+	//
+	return nil
+}
+
+// Lower a computed column to the MIR level.  Observe that the usual lowering of
+// expressions (e.g. for vanishing constraints) cannot be used here, since it
+// only preserves whether or not an expression evaluates to zero.  Instead, the
+// computation must be lowered so as to preserve its value.
+func lowerComputedColumnToMir(c ComputedColumn, schema *mir.Schema) error {
+	column := c.Columns().Next()
+	//
+	expr, err := lowerComputationTo(c.Expr().expr, schema)
+	if err != nil {
+		return err
+	}
+	//
+	schema.AddAssignment(assignment.NewComputedColumn(column.Context(), column.Name(), column.Type(), expr))
+	//
+	return nil
+}
+
+// Lower a unit expression to exactly one MIR expression which evaluates to the
+// same value on every row.  Conditionals are compiled out using normalisation
+// to select the appropriate branch, such that "(if c t f)" becomes "(1 - ~c) *
+// t + ~c * f".  This fails for expressions which cannot be computed (e.g.
+// lists of more than one expression).
+func lowerComputationTo(e Expr, schema *mir.Schema) (mir.Expr, error) {
+	if p, ok := e.(*Add); ok {
+		args, err := lowerComputationsTo(p.Args, schema)
+		return &mir.Add{Args: args}, err
+	} else if p, ok := e.(*Constant); ok {
+		return &mir.Constant{Value: p.Val}, nil
+	} else if p, ok := e.(*ColumnAccess); ok {
+		return &mir.ColumnAccess{Column: p.Column, Shift: p.Shift}, nil
+	} else if p, ok := e.(*Mul); ok {
+		args, err := lowerComputationsTo(p.Args, schema)
+		return &mir.Mul{Args: args}, err
+	} else if p, ok := e.(*Exp); ok {
+		arg, err := lowerComputationTo(p.Arg, schema)
+		return &mir.Exp{Arg: arg, Pow: p.Pow}, err
+	} else if p, ok := e.(*Normalise); ok {
+		arg, err := lowerComputationTo(p.Arg, schema)
+		return &mir.Normalise{Arg: arg}, err
+	} else if p, ok := e.(*IfZero); ok && p.TrueBranch != nil && p.FalseBranch != nil {
+		args, err := lowerComputationsTo([]Expr{p.Condition, p.TrueBranch, p.FalseBranch}, schema)
+		if err != nil {
+			return nil, err
 		}
+		//
+		norm := &mir.Normalise{Arg: args[0]}
+		oneMinusNorm := &mir.Sub{Args: []mir.Expr{&mir.Constant{Value: fr.One()}, norm}}
+		tb := &mir.Mul{Args: []mir.Expr{oneMinusNorm, args[1]}}
+		fb := &mir.Mul{Args: []mir.Expr{norm, args[2]}}
+		//
+		return &mir.Add{Args: []mir.Expr{tb, fb}}, nil
+	} else if p, ok := e.(*List); ok && len(p.Args) == 1 {
+		return lowerComputationTo(p.Args[0], schema)
+	} else if p, ok := e.(*Sub); ok {
+		args, err := lowerComputationsTo(p.Args, schema)
+		return &mir.Sub{Args: args}, err
+	} else if p, ok := e.(*Bitwise); ok {
+		args, err := lowerComputationsTo(p.Args, schema)
+		return &mir.Bitwise{Op: p.Op, Width: p.Width, Args: args}, err
 	}
 	//
-	return mirSchema
+	return nil, fmt.Errorf("invalid computation: %s", e.Lisp(schema))
 }
 
-func lowerConstraintToMir(c sc.Constraint, schema *mir.Schema) {
+// Lower a vector of unit expressions to the MIR level.
+func lowerComputationsTo(es []Expr, schema *mir.Schema) ([]mir.Expr, error) {
+	rs := make([]mir.Expr, len(es))
+	//
+	for i, e := range es {
+		r, err := lowerComputationTo(e, schema)
+		if err != nil {
+			return nil, err
+		}
+		//
+		rs[i] = r
+	}
+	//
+	return rs, nil
+}
+
+func (p *Schema) lowerConstraintToMir(c sc.Constraint, schema *mir.Schema) error {
 	// Check what kind of constraint we have
 	if v, ok := c.(LookupConstraint); ok {
-		lowerLookupConstraint(v, schema)
+		return lowerLookupConstraint(v, schema)
 	} else if v, ok := c.(VanishingConstraint); ok {
-		mir_exprs := v.Constraint().Expr.LowerTo(schema)
-		// Add individual constraints arising
-		for _, mir_expr := range mir_exprs {
-			schema.AddVanishingConstraint(v.Handle(), v.Context(), v.Domain(), mir_expr)
+		// Split constraint into its components, such that the constraints
+		// arising from each can be attributed to the appropriate sub-expression.
+		for _, component := range p.splitConstraint(v.Constraint().Expr, v.Source()) {
+			mir_exprs, err := component.Left.LowerTo(schema)
+			if err != nil {
+				return err
+			}
+			// Add individual constraints arising
+			for _, mir_expr := range mir_exprs {
+				schema.AddVanishingConstraint(v.Handle(), v.Context(), v.Domain(), mir_expr).SetSource(component.Right)
+			}
 		}
 	} else if v, ok := c.(RangeConstraint); ok {
-		mir_exprs := v.Target().LowerTo(schema)
+		mir_exprs, err := v.Target().LowerTo(schema)
+		if err != nil {
+			return err
+		}
 		// Add individual constraints arising
 		for _, mir_expr := range mir_exprs {
-			schema.AddRangeConstraint(v.Handle(), v.Context(), mir_expr, v.Bound())
+			schema.AddRangeConstraint(v.Handle(), v.Context(), mir_expr, v.Bound()).SetSource(v.Source())
 		}
 	} else {
-		// Should be unreachable as no other constraint types can be added to a
-		// schema.
-		panic("unreachable")
+		return fmt.Errorf("unknown constraint (%T)", c)
 	}
+	//
+	return nil
 }
 
-func lowerLookupConstraint(c LookupConstraint, schema *mir.Schema) {
+func lowerLookupConstraint(c LookupConstraint, schema *mir.Schema) error {
+	var err error
+	//
 	sources := c.Sources()
 	targets := c.Targets()
 	from := make([]mir.Expr, len(sources))
 	into := make([]mir.Expr, len(targets))
 	// Convert general expressions into unit expressions.
 	for i := 0; i < len(from); i++ {
-		from[i] = lowerUnitTo(sources[i], schema)
-		into[i] = lowerUnitTo(targets[i], schema)
+		if from[i], err = lowerUnitTo(sources[i], schema); err != nil {
+			return err
+		} else if into[i], err = lowerUnitTo(targets[i], schema); err != nil {
+			return err
+		}
+	}
+	// Lower selectors (if applicable)
+	sourceSelector, serr := lowerSelectorTo(c.SourceSelector(), schema)
+	targetSelector, terr := lowerSelectorTo(c.TargetSelector(), schema)
+	//
+	if serr != nil {
+		return serr
+	} else if terr != nil {
+		return terr
 	}
 	//
-	schema.AddLookupConstraint(c.Handle(), c.SourceContext(), c.TargetContext(), from, into)
+	schema.AddLookupConstraint(c.Handle(), c.SourceContext(), c.TargetContext(), from, into, sourceSelector,
+		targetSelector).SetSource(c.Source())
+	//
+	return nil
 }
 
-// Lower an expression which is expected to lower into a single expression.
-// This will panic if the unit expression is malformed (i.e. does not lower
-// into a single expression).
-func lowerUnitTo(e UnitExpr, schema *mir.Schema) mir.Expr {
-	exprs := lowerTo(e.expr, schema)
+// Lower an (optional) lookup selector to the MIR level.
+func lowerSelectorTo(selector util.Option[UnitExpr], schema *mir.Schema) (util.Option[mir.Expr], error) {
+	if selector.HasValue() {
+		expr, err := lowerUnitTo(selector.Unwrap(), schema)
+		return util.Some(expr), err
+	}
+	//
+	return util.None[mir.Expr](), nil
+}
+
+// Split a constraint into its components by breaking down lists, such that
+// each component is paired with its origin (if known).  Lists nested under a
+// multiplication (e.g. arising from a guard) are also broken down, since
+// "(* g (begin a b))" is equivalent to "(begin (* g a) (* g b))".  Observe that
+// lowering each component in turn produces the same constraints as lowering
+// the original expression.
+func (p *Schema) splitConstraint(e Expr, source *sc.Source) []util.Pair[Expr, *sc.Source] {
+	if s, ok := p.exprSources[e]; ok {
+		source = s
+	}
+	//
+	if l, ok := e.(*List); ok {
+		var components []util.Pair[Expr, *sc.Source]
+		//
+		for _, arg := range l.Args {
+			components = append(components, p.splitConstraint(arg, source)...)
+		}
+		//
+		return components
+	} else if m, ok := e.(*Mul); ok && len(m.Args) > 0 {
+		n := len(m.Args) - 1
+		components := p.splitConstraint(m.Args[n], source)
+		// Reconstruct multiplication around each component
+		for i, c := range components {
+			args := slices.Clone(m.Args)
+			args[n] = c.Left
+			components[i].Left = &Mul{Args: args}
+		}
+		//
+		return components
+	}
+	//
+	return []util.Pair[Expr, *sc.Source]{util.NewPair(e, source)}
+}
 
-	if len(exprs) != 1 {
-		panic("invalid unitary expression")
+// Lower an expression which is expected to lower into a single expression.
+// This fails if the unit expression is malformed (i.e. does not lower into a
+// single expression).
+func lowerUnitTo(e UnitExpr, schema *mir.Schema) (mir.Expr, error) {
+	exprs, err := lowerTo(e.expr, schema)
+	if err != nil {
+		return nil, err
+	} else if len(exprs) != 1 {
+		return nil, fmt.Errorf("invalid unitary expression: %s", e.Lisp(schema))
 	}
 
-	return exprs[0]
+	return exprs[0], nil
 }
 
 // LowerTo lowers a sum expression to the MIR level.  This requires expanding
 // the arguments, then lowering them.  Furthermore, conditionals are "lifted" to
 // the top.
-func (e *Add) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *Add) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers a constant to the MIR level.   This requires expanding the
 // arguments, then lowering them.  Furthermore, conditionals are "lifted" to the
 // top.
-func (e *Constant) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *Constant) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers a column access to the MIR level.  This requires expanding
 // the arguments, then lowering them.  Furthermore, conditionals are "lifted" to
 // the top.
-func (e *ColumnAccess) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *ColumnAccess) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers an exponent expression to the MIR level.  This requires expanding
 // the argument andn lowering it.  Furthermore, conditionals are "lifted" to
 // the top.
-func (e *Exp) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *Exp) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers a product expression to the MIR level.  This requires expanding
 // the arguments, then lowering them.  Furthermore, conditionals are "lifted" to
 // the top.
-func (e *Mul) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *Mul) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers a list expression to the MIR level by eliminating it
 // altogether.  This still requires expanding the arguments, then lowering them.
 // Furthermore, conditionals are "lifted" to the top..
-func (e *List) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *List) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers a normalise expression to the MIR level.  This requires
 // expanding the arguments, then lowering them.  Furthermore, conditionals are
 // "lifted" to the top..
-func (e *Normalise) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *Normalise) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
@@ -145,14 +343,21 @@ func (e *Normalise) LowerTo(schema *mir.Schema) []mir.Expr {
 // expression using normalisation at the MIR level.  This also requires
 // expanding the arguments, then lowering them.  Furthermore, conditionals are
 // "lifted" to the top.
-func (e *IfZero) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *IfZero) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
+	return lowerTo(e, schema)
+}
+
+// LowerTo lowers a bitwise expression to the MIR level.  This requires
+// expanding the arguments, then lowering them.  Furthermore, conditionals are
+// "lifted" to the top.
+func (e *Bitwise) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
 // LowerTo lowers a subtract expression to the MIR level. This also requires
 // expanding the arguments, then lowering them.  Furthermore, conditionals are
 // "lifted" to the top.
-func (e *Sub) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e *Sub) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return lowerTo(e, schema)
 }
 
@@ -163,31 +368,41 @@ func (e *Sub) LowerTo(schema *mir.Schema) []mir.Expr {
 // Lowers a given expression to the MIR level.  The expression is first expanded
 // into one or more target expressions. Furthermore, conditions must be "lifted"
 // to the root.
-func lowerTo(e Expr, schema *mir.Schema) []mir.Expr {
+func lowerTo(e Expr, schema *mir.Schema) ([]mir.Expr, error) {
 	// First expand expression
-	es := expand(e, schema)
+	es, err := expand(e, schema)
+	if err != nil {
+		return nil, err
+	}
 	// Now lower each one (carefully)
 	mes := make([]mir.Expr, len(es))
 	//
 	for i, e := range es {
-		c := extractCondition(e, schema)
-		b := extractBody(e, schema)
+		c, cerr := extractCondition(e, schema)
+		b, berr := extractBody(e, schema)
+		//
+		if cerr != nil {
+			return nil, cerr
+		} else if berr != nil {
+			return nil, berr
+		}
+		//
 		mes[i] = mul2(c, b)
 	}
 	// Done
-	return mes
+	return mes, nil
 }
 
 // Extract the "condition" of an expression.  Every expression can be view as a
 // conditional constraint of the form "if c then e", where "c" is the condition.
 // This is allowed to return nil if the body is unconditional.
-func extractCondition(e Expr, schema *mir.Schema) mir.Expr {
+func extractCondition(e Expr, schema *mir.Schema) (mir.Expr, error) {
 	if p, ok := e.(*Add); ok {
 		return extractConditions(p.Args, schema)
 	} else if _, ok := e.(*Constant); ok {
-		return nil
+		return nil, nil
 	} else if _, ok := e.(*ColumnAccess); ok {
-		return nil
+		return nil, nil
 	} else if p, ok := e.(*Mul); ok {
 		return extractConditions(p.Args, schema)
 	} else if p, ok := e.(*Normalise); ok {
@@ -198,33 +413,50 @@ func extractCondition(e Expr, schema *mir.Schema) mir.Expr {
 		return extractIfZeroCondition(p, schema)
 	} else if p, ok := e.(*Sub); ok {
 		return extractConditions(p.Args, schema)
+	} else if p, ok := e.(*Bitwise); ok {
+		return extractConditions(p.Args, schema)
 	}
-	// Should be unreachable
-	panic(fmt.Sprintf("unknown expression: %s", e.Lisp(schema)))
+	//
+	return nil, fmt.Errorf("unknown expression: %s", e.Lisp(schema))
 }
 
-func extractConditions(es []Expr, schema *mir.Schema) mir.Expr {
+func extractConditions(es []Expr, schema *mir.Schema) (mir.Expr, error) {
 	var r mir.Expr = nil
 	for _, e := range es {
-		r = mul2(r, extractCondition(e, schema))
+		c, err := extractCondition(e, schema)
+		if err != nil {
+			return nil, err
+		}
+		//
+		r = mul2(r, c)
 	}
 
-	return r
+	return r, nil
 }
 
 // Extracting from conditional expressions is slightly more complex than others,
 // so it gets a case of its own.
-func extractIfZeroCondition(e *IfZero, schema *mir.Schema) mir.Expr {
-	var bc mir.Expr
+func extractIfZeroCondition(e *IfZero, schema *mir.Schema) (mir.Expr, error) {
+	var (
+		bc  mir.Expr
+		err error
+	)
+	// Expansion should ensure both branches are not present.  This is necessary
+	// to ensure exactly one expression is generated from this expression.
+	if e.TrueBranch != nil && e.FalseBranch != nil {
+		return nil, fmt.Errorf("unexpanded expression: %s", e.Lisp(schema))
+	}
 	// Lower condition
-	cc := extractCondition(e.Condition, schema)
-	cb := extractBody(e.Condition, schema)
+	cc, cerr := extractCondition(e.Condition, schema)
+	cb, berr := extractBody(e.Condition, schema)
+	//
+	if cerr != nil {
+		return nil, cerr
+	} else if berr != nil {
+		return nil, berr
+	}
 	// Add conditions arising
-	if e.TrueBranch != nil && e.FalseBranch != nil {
-		// Expansion should ensure this case does not exist.  This is necessary
-		// to ensure exactly one expression is generated from this expression.
-		panic(fmt.Sprintf("unexpanded expression (%s)", e.Lisp(schema)))
-	} else if e.TrueBranch != nil {
+	if e.TrueBranch != nil {
 		// (1 - NORM(cb)) for true branch
 		normBody := &mir.Normalise{Arg: cb}
 		oneMinusNormBody := &mir.Sub{
@@ -236,56 +468,70 @@ func extractIfZeroCondition(e *IfZero, schema *mir.Schema) mir.Expr {
 
 		cb = oneMinusNormBody
 		// Lower conditional's arising from body
-		bc = extractCondition(e.TrueBranch, schema)
+		bc, err = extractCondition(e.TrueBranch, schema)
 	} else {
 		// Lower conditional's arising from body
-		bc = extractCondition(e.FalseBranch, schema)
+		bc, err = extractCondition(e.FalseBranch, schema)
 	}
 	//
-	return mul3(cc, cb, bc)
+	return mul3(cc, cb, bc), err
 }
 
 // Translate the "body" of an expression.  Every expression can be view as a
 // conditional constraint of the form "if c then e", where "e" is the
 // constraint.
-func extractBody(e Expr, schema *mir.Schema) mir.Expr {
+func extractBody(e Expr, schema *mir.Schema) (mir.Expr, error) {
 	if p, ok := e.(*Add); ok {
-		return &mir.Add{Args: extractBodies(p.Args, schema)}
+		args, err := extractBodies(p.Args, schema)
+		return &mir.Add{Args: args}, err
 	} else if p, ok := e.(*Constant); ok {
-		return &mir.Constant{Value: p.Val}
+		return &mir.Constant{Value: p.Val}, nil
 	} else if p, ok := e.(*ColumnAccess); ok {
-		return &mir.ColumnAccess{Column: p.Column, Shift: p.Shift}
+		return &mir.ColumnAccess{Column: p.Column, Shift: p.Shift}, nil
 	} else if p, ok := e.(*Mul); ok {
-		return &mir.Mul{Args: extractBodies(p.Args, schema)}
+		args, err := extractBodies(p.Args, schema)
+		return &mir.Mul{Args: args}, err
 	} else if p, ok := e.(*Exp); ok {
-		return &mir.Exp{Arg: extractBody(p.Arg, schema), Pow: p.Pow}
+		arg, err := extractBody(p.Arg, schema)
+		return &mir.Exp{Arg: arg, Pow: p.Pow}, err
 	} else if p, ok := e.(*Normalise); ok {
-		return &mir.Normalise{Arg: extractBody(p.Arg, schema)}
+		arg, err := extractBody(p.Arg, schema)
+		return &mir.Normalise{Arg: arg}, err
 	} else if p, ok := e.(*IfZero); ok {
 		if p.TrueBranch != nil && p.FalseBranch != nil {
 			// Expansion should ensure this case does not exist.  This is necessary
 			// to ensure exactly one expression is generated from this expression.
-			panic(fmt.Sprintf("unexpanded expression (%s)", e.Lisp(schema)))
+			return nil, fmt.Errorf("unexpanded expression: %s", e.Lisp(schema))
 		} else if p.TrueBranch != nil {
 			return extractBody(p.TrueBranch, schema)
 		}
 		// Done
 		return extractBody(p.FalseBranch, schema)
 	} else if p, ok := e.(*Sub); ok {
-		return &mir.Sub{Args: extractBodies(p.Args, schema)}
+		args, err := extractBodies(p.Args, schema)
+		return &mir.Sub{Args: args}, err
+	} else if p, ok := e.(*Bitwise); ok {
+		args, err := extractBodies(p.Args, schema)
+		return &mir.Bitwise{Op: p.Op, Width: p.Width, Args: args}, err
 	}
-	// Should be unreachable
-	panic(fmt.Sprintf("unknown expression: %s", e.Lisp(schema)))
+	//
+	return nil, fmt.Errorf("unknown expression: %s", e.Lisp(schema))
 }
 
 // Extract a vector of expanded expressions to the MIR level.
-func extractBodies(es []Expr, schema *mir.Schema) []mir.Expr {
+func extractBodies(es []Expr, schema *mir.Schema) ([]mir.Expr, error) {
 	rs := make([]mir.Expr, len(es))
+	//
 	for i, e := range es {
-		rs[i] = extractBody(e, schema)
+		r, err := extractBody(e, schema)
+		if err != nil {
+			return nil, err
+		}
+		//
+		rs[i] = r
 	}
 
-	return rs
+	return rs, nil
 }
 
 // ============================================================================
@@ -297,16 +543,16 @@ func extractBodies(es []Expr, schema *mir.Schema) []mir.Expr {
 // Y Z))" is broken down into two distinct expressions "(- X Y)" and "(- Y Z)".
 // Likewise, a condition such as "(if X Y Z)" is broken down into two
 // expressions "(if X Y)" and "(ifnot X Z)".  These are necessary steps for the
-// conversion into a lower-level form.
-func expand(e Expr, schema sc.Schema) []Expr {
+// conversion into a lower-level form.  This fails for unknown expressions.
+func expand(e Expr, schema sc.Schema) ([]Expr, error) {
 	if p, ok := e.(*Add); ok {
 		return expandWithNaryConstructor(p.Args, func(nargs []Expr) Expr {
 			return &Add{Args: nargs}
 		}, schema)
 	} else if _, ok := e.(*Constant); ok {
-		return []Expr{e}
+		return []Expr{e}, nil
 	} else if _, ok := e.(*ColumnAccess); ok {
-		return []Expr{e}
+		return []Expr{e}, nil
 	} else if p, ok := e.(*Mul); ok {
 		return expandWithNaryConstructor(p.Args, func(nargs []Expr) Expr {
 			return &Mul{Args: nargs}
@@ -314,61 +560,85 @@ func expand(e Expr, schema sc.Schema) []Expr {
 	} else if p, ok := e.(*List); ok {
 		ees := make([]Expr, 0)
 		for _, arg := range p.Args {
-			ees = append(ees, expand(arg, schema)...)
+			es, err := expand(arg, schema)
+			if err != nil {
+				return nil, err
+			}
+			//
+			ees = append(ees, es...)
 		}
 
-		return ees
+		return ees, nil
 	} else if p, ok := e.(*Exp); ok {
-		ees := expand(p.Arg, schema)
+		ees, err := expand(p.Arg, schema)
 		for i, ee := range ees {
 			ees[i] = &Exp{ee, p.Pow}
 		}
 
-		return ees
+		return ees, err
 	} else if p, ok := e.(*Normalise); ok {
-		ees := expand(p.Arg, schema)
+		ees, err := expand(p.Arg, schema)
 		for i, ee := range ees {
 			ees[i] = &Normalise{ee}
 		}
 
-		return ees
+		return ees, err
 	} else if p, ok := e.(*IfZero); ok {
-		ees := make([]Expr, 0)
-		if p.TrueBranch != nil {
-			// Expand true branch with condition
-			ees = expandWithBinaryConstructor(p.Condition, p.TrueBranch, func(c Expr, tb Expr) Expr {
-				return &IfZero{c, tb, nil}
-			}, schema)
-		}
-
-		if p.FalseBranch != nil {
-			// Expand false branch with condition
-			fes := expandWithBinaryConstructor(p.Condition, p.FalseBranch, func(c Expr, fb Expr) Expr {
-				return &IfZero{c, nil, fb}
-			}, schema)
-			ees = append(ees, fes...)
-		}
-		// Done
-		return ees
+		return expandIfZero(p, schema)
 	} else if p, ok := e.(*Sub); ok {
 		return expandWithNaryConstructor(p.Args, func(nargs []Expr) Expr {
 			return &Sub{Args: nargs}
 		}, schema)
+	} else if p, ok := e.(*Bitwise); ok {
+		return expandWithNaryConstructor(p.Args, func(nargs []Expr) Expr {
+			return &Bitwise{Op: p.Op, Width: p.Width, Args: nargs}
+		}, schema)
 	}
-	// Should be unreachable
-	panic(fmt.Sprintf("unknown expression: %s", e.Lisp(schema)))
+	//
+	return nil, fmt.Errorf("unknown expression: %s", e.Lisp(schema))
+}
+
+// Expand a conditional into one expression for each branch (after expanding
+// the condition and the branch itself).
+func expandIfZero(e *IfZero, schema sc.Schema) ([]Expr, error) {
+	var (
+		tes []Expr
+		fes []Expr
+		err error
+	)
+	//
+	if e.TrueBranch != nil {
+		// Expand true branch with condition
+		tes, err = expandWithBinaryConstructor(e.Condition, e.TrueBranch, func(c Expr, tb Expr) Expr {
+			return &IfZero{c, tb, nil}
+		}, schema)
+	}
+	//
+	if e.FalseBranch != nil && err == nil {
+		// Expand false branch with condition
+		fes, err = expandWithBinaryConstructor(e.Condition, e.FalseBranch, func(c Expr, fb Expr) Expr {
+			return &IfZero{c, nil, fb}
+		}, schema)
+	}
+	// Done
+	return append(tes, fes...), err
 }
 
 type binaryConstructor func(Expr, Expr) Expr
 type naryConstructor func([]Expr) Expr
 
 // LowerWithBinaryConstructor is a generic mechanism for lowering down to a binary expression.
-func expandWithBinaryConstructor(lhs Expr, rhs Expr, create binaryConstructor, schema sc.Schema) []Expr {
+func expandWithBinaryConstructor(lhs Expr, rhs Expr, create binaryConstructor, schema sc.Schema) ([]Expr, error) {
 	var res []Expr
 	// Lower all three expressions
-	is := expand(lhs, schema)
-	js := expand(rhs, schema)
-
+	is, ierr := expand(lhs, schema)
+	js, jerr := expand(rhs, schema)
+	//
+	if ierr != nil {
+		return nil, ierr
+	} else if jerr != nil {
+		return nil, jerr
+	}
 	// Now construct
 	for i := 0; i < len(is); i++ {
 		for j := 0; j < len(js); j++ {
@@ -379,7 +649,7 @@ func expandWithBinaryConstructor(lhs Expr, rhs Expr, create binaryConstructor, s
 		}
 	}
 
-	return res
+	return res, nil
 }
 
 // LowerWithNaryConstructor performs the cross-product expansion of an nary HIR
@@ -400,7 +670,7 @@ func expandWithBinaryConstructor(lhs Expr, rhs Expr, create binaryConstructor, s
 //
 // This will expand into *four* MIR expressions (i.e. the cross product of the
 // left and right ifs).
-func expandWithNaryConstructor(args []Expr, constructor naryConstructor, schema sc.Schema) []Expr {
+func expandWithNaryConstructor(args []Expr, constructor naryConstructor, schema sc.Schema) ([]Expr, error) {
 	// Accumulator is initially empty
 	acc := make([]Expr, len(args))
 	// Start from the first argument
@@ -411,7 +681,7 @@ func expandWithNaryConstructor(args []Expr, constructor naryConstructor, schema
 // Specifically, "i" determines how much of args has been lowered thus
 // far, whilst "acc" represents the current array being generated.
 func expandWithNaryConstructorHelper(i int, acc []Expr, args []Expr,
-	constructor naryConstructor, schema sc.Schema) []Expr {
+	constructor naryConstructor, schema sc.Schema) ([]Expr, error) {
 	if i == len(acc) {
 		// Base Case
 		nacc := make([]Expr, len(acc))
@@ -420,19 +690,30 @@ func expandWithNaryConstructorHelper(i int, acc []Expr, args []Expr,
 		copy(nacc, acc)
 		// Apply the constructor to produce the appropriate
 		// mir.Expr.
-		return []Expr{constructor(nacc)}
+		return []Expr{constructor(nacc)}, nil
 	}
 
 	// Recursive Case
 	var nargs []Expr
-
-	for _, ith := range expand(args[i], schema) {
+	//
+	iths, err := expand(args[i], schema)
+	if err != nil {
+		return nil, err
+	}
+	//
+	for _, ith := range iths {
+		var es []Expr
+		//
 		acc[i] = ith
-		iths := expandWithNaryConstructorHelper(i+1, acc, args, constructor, schema)
-		nargs = append(nargs, iths...)
+		//
+		if es, err = expandWithNaryConstructorHelper(i+1, acc, args, constructor, schema); err != nil {
+			return nil, err
+		}
+		//
+		nargs = append(nargs, es...)
 	}
 
-	return nargs
+	return nargs, nil
 }
 
 // Multiply three expressions together, any of which could be nil.
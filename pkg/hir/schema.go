@@ -2,6 +2,7 @@ package hir
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/schema"
@@ -36,6 +37,11 @@ type PropertyAssertion = *sc.PropertyAssertion[ZeroArrayTest]
 // Permutation captures the notion of a (sorted) permutation at the HIR level.
 type Permutation = *assignment.SortedPermutation
 
+// ComputedColumn captures the notion of a user-defined computed column at the
+// HIR level.  As for lookups, the UnitExpr adaptor is required here since the
+// computation must produce exactly one value on each row.
+type ComputedColumn = *assignment.ComputedColumn[UnitExpr]
+
 // Schema for HIR constraints and columns.
 type Schema struct {
 	// The modules of the schema
@@ -49,8 +55,14 @@ type Schema struct {
 	constraints []sc.Constraint
 	// The property assertions for this schema.
 	assertions []PropertyAssertion
+	// Columns whose types must be proven (e.g. were declared ":prove").
+	proven []uint
 	// Cache list of columns declared in inputs and assignments.
 	column_cache []sc.Column
+	// Origins of sub-expressions within constraints, such as the elements of a
+	// list.  This allows constraints arising from these sub-expressions during
+	// lowering to be attributed accordingly.
+	exprSources map[Expr]*sc.Source
 }
 
 // EmptySchema is used to construct a fresh schema onto which new columns and
@@ -63,6 +75,7 @@ func EmptySchema() *Schema {
 	p.constraints = make([]sc.Constraint, 0)
 	p.assertions = make([]PropertyAssertion, 0)
 	p.column_cache = make([]sc.Column, 0)
+	p.exprSources = make(map[Expr]*sc.Source)
 	// Done
 	return p
 }
@@ -93,9 +106,19 @@ func (p *Schema) AddDataColumn(context trace.Context, name string, base sc.Type)
 	return cid
 }
 
+// AddProvenColumn records that the type of a given column must be proven (e.g.
+// because it was declared ":prove").  Observe that this does not itself enforce
+// the type; rather, a suitable range constraint is expected to be added.
+func (p *Schema) AddProvenColumn(cid uint) {
+	if !slices.Contains(p.proven, cid) {
+		p.proven = append(p.proven, cid)
+	}
+}
+
 // AddLookupConstraint appends a new lookup constraint.
 func (p *Schema) AddLookupConstraint(handle string, source trace.Context, target trace.Context,
-	sources []UnitExpr, targets []UnitExpr) {
+	sources []UnitExpr, targets []UnitExpr, sourceSelector util.Option[UnitExpr],
+	targetSelector util.Option[UnitExpr]) LookupConstraint {
 	if len(targets) != len(sources) {
 		panic("differeng number of target / source lookup columns")
 	}
@@ -103,8 +126,10 @@ func (p *Schema) AddLookupConstraint(handle string, source trace.Context, target
 	// columns are in the target module (though source != target is permitted).
 
 	// Finally add constraint
-	p.constraints = append(p.constraints,
-		constraint.NewLookupConstraint(handle, source, target, sources, targets))
+	lookup := constraint.NewLookupConstraint(handle, source, target, sources, targets, sourceSelector, targetSelector)
+	p.constraints = append(p.constraints, lookup)
+	//
+	return lookup
 }
 
 // AddAssignment appends a new assignment (i.e. set of computed columns) to be
@@ -122,25 +147,42 @@ func (p *Schema) AddAssignment(c sc.Assignment) uint {
 }
 
 // AddVanishingConstraint appends a new vanishing constraint.
-func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, domain *int, expr Expr) {
+func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, domain *constraint.Domain,
+	expr Expr) VanishingConstraint {
 	if context.Module() >= uint(len(p.modules)) {
 		panic(fmt.Sprintf("invalid module index (%d)", context.Module()))
 	}
 
-	p.constraints = append(p.constraints,
-		constraint.NewVanishingConstraint(handle, context, domain, ZeroArrayTest{expr}))
+	vanishing := constraint.NewVanishingConstraint(handle, context, domain, ZeroArrayTest{expr})
+	p.constraints = append(p.constraints, vanishing)
+
+	return vanishing
 }
 
 // AddRangeConstraint appends a new range constraint with a raw bound.
-func (p *Schema) AddRangeConstraint(handle string, context trace.Context, expr Expr, bound fr.Element) {
+func (p *Schema) AddRangeConstraint(handle string, context trace.Context, expr Expr,
+	bound fr.Element) RangeConstraint {
 	// Check whether is a field type, as these can actually be ignored.
 	maxExpr := MaxExpr{expr}
-	p.constraints = append(p.constraints, constraint.NewRangeConstraint[MaxExpr](handle, context, maxExpr, bound))
+	rangeConstraint := constraint.NewRangeConstraint[MaxExpr](handle, context, maxExpr, bound)
+	p.constraints = append(p.constraints, rangeConstraint)
+
+	return rangeConstraint
 }
 
 // AddPropertyAssertion appends a new property assertion.
-func (p *Schema) AddPropertyAssertion(handle string, context trace.Context, property Expr) {
-	p.assertions = append(p.assertions, sc.NewPropertyAssertion[ZeroArrayTest](handle, context, ZeroArrayTest{property}))
+func (p *Schema) AddPropertyAssertion(handle string, context trace.Context, property Expr) PropertyAssertion {
+	assertion := sc.NewPropertyAssertion[ZeroArrayTest](handle, context, ZeroArrayTest{property})
+	p.assertions = append(p.assertions, assertion)
+
+	return assertion
+}
+
+// AttributeExpr records the origin of a given sub-expression within some
+// constraint (e.g. an element of a list).  When that constraint is lowered, any
+// constraints arising from the sub-expression are attributed to it.
+func (p *Schema) AttributeExpr(expr Expr, source *sc.Source) {
+	p.exprSources[expr] = source
 }
 
 // ============================================================================
@@ -171,6 +213,36 @@ func (p *Schema) Assignments() util.Iterator[sc.Assignment] {
 	return util.NewArrayIterator(p.assignments)
 }
 
+// Declaration returns the declaration (i.e. data column or assignment) which
+// declares the column with a given index.
+func (p *Schema) Declaration(cid uint) sc.Declaration {
+	index := cid
+	//
+	for _, d := range p.inputs {
+		if n := d.Columns().Count(); index >= n {
+			index -= n
+		} else {
+			return d
+		}
+	}
+	//
+	for _, d := range p.assignments {
+		if n := d.Columns().Count(); index >= n {
+			index -= n
+		} else {
+			return d
+		}
+	}
+	//
+	panic(fmt.Sprintf("invalid column index (%d)", cid))
+}
+
+// ProvenColumns returns the indices of those columns whose types must be
+// proven.
+func (p *Schema) ProvenColumns() []uint {
+	return p.proven
+}
+
 // Columns returns an array over the underlying columns of this sc.
 // Specifically, the index of a column in this array is its column index.
 func (p *Schema) Columns() util.Iterator[sc.Column] {
@@ -37,6 +37,11 @@ func (p ZeroArrayTest) TestAt(row int, trace tr.Trace) bool {
 	return true
 }
 
+// Explain the evaluation of this zero test at a given row in a given trace.
+func (p ZeroArrayTest) Explain(row int, trace tr.Trace, schema sc.Schema) *sc.Explanation {
+	return p.Expr.Explain(row, trace, schema)
+}
+
 // Bounds determines the bounds for this zero test.
 func (p ZeroArrayTest) Bounds() util.Bounds {
 	return p.Expr.Bounds()
@@ -202,7 +207,7 @@ func (e MaxExpr) RequiredCells(row int, trace tr.Trace) *util.AnySortedSet[tr.Ce
 }
 
 // LowerTo lowers a max expressions down to one or more expressions at the MIR level.
-func (e MaxExpr) LowerTo(schema *mir.Schema) []mir.Expr {
+func (e MaxExpr) LowerTo(schema *mir.Schema) ([]mir.Expr, error) {
 	return e.expr.LowerTo(schema)
 }
 
@@ -211,3 +216,54 @@ func (e MaxExpr) LowerTo(schema *mir.Schema) []mir.Expr {
 func (e MaxExpr) Lisp(schema sc.Schema) sexp.SExp {
 	return e.expr.Lisp(schema)
 }
+
+// ============================================================================
+// Bitwise Operations
+// ============================================================================
+
+// BitwiseOperations returns every bitwise operation occurring within a given
+// expression (including those nested within other bitwise operations).
+func BitwiseOperations(e Expr) []*Bitwise {
+	return bitwiseOperations(e, nil)
+}
+
+func bitwiseOperations(e Expr, ops []*Bitwise) []*Bitwise {
+	switch e := e.(type) {
+	case *Add:
+		return bitwiseOperationsOf(e.Args, ops)
+	case *Sub:
+		return bitwiseOperationsOf(e.Args, ops)
+	case *Mul:
+		return bitwiseOperationsOf(e.Args, ops)
+	case *List:
+		return bitwiseOperationsOf(e.Args, ops)
+	case *Exp:
+		return bitwiseOperations(e.Arg, ops)
+	case *Normalise:
+		return bitwiseOperations(e.Arg, ops)
+	case *IfZero:
+		ops = bitwiseOperations(e.Condition, ops)
+		//
+		if e.TrueBranch != nil {
+			ops = bitwiseOperations(e.TrueBranch, ops)
+		}
+		//
+		if e.FalseBranch != nil {
+			ops = bitwiseOperations(e.FalseBranch, ops)
+		}
+		//
+		return ops
+	case *Bitwise:
+		return bitwiseOperationsOf(e.Args, append(ops, e))
+	}
+	// Constants and column accesses
+	return ops
+}
+
+func bitwiseOperationsOf(exprs []Expr, ops []*Bitwise) []*Bitwise {
+	for _, e := range exprs {
+		ops = bitwiseOperations(e, ops)
+	}
+	//
+	return ops
+}
@@ -1,15 +1,14 @@
 package mir
 
 import (
-	"fmt"
-
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/util"
 )
 
 // ApplyConstantPropagation simply collapses constant expressions down to single
-// values.  For example, "(+ 1 2)" would be collapsed down to "3".
+// values.  For example, "(+ 1 2)" would be collapsed down to "3".  Unknown
+// expressions are left unchanged, such that they are reported when lowered.
 func applyConstantPropagation(e Expr, schema sc.Schema) Expr {
 	if p, ok := e.(*Add); ok {
 		return applyConstantPropagationAdd(p.Args, schema)
@@ -25,9 +24,11 @@ func applyConstantPropagation(e Expr, schema sc.Schema) Expr {
 		return applyConstantPropagationNorm(p.Arg, schema)
 	} else if p, ok := e.(*Sub); ok {
 		return applyConstantPropagationSub(p.Args, schema)
+	} else if p, ok := e.(*Bitwise); ok {
+		return applyConstantPropagationBitwise(p, schema)
 	}
-	// Should be unreachable
-	panic(fmt.Sprintf("unknown expression: %s", e.Lisp(schema).String(true)))
+	//
+	return e
 }
 
 func applyConstantPropagationAdd(es []Expr, schema sc.Schema) Expr {
@@ -155,3 +156,25 @@ func applyConstantPropagationNorm(arg Expr, schema sc.Schema) Expr {
 	//
 	return &Normalise{arg}
 }
+
+func applyConstantPropagationBitwise(e *Bitwise, schema sc.Schema) Expr {
+	is_const := true
+	rs := make([]Expr, len(e.Args))
+	vals := make([]fr.Element, len(e.Args))
+	//
+	for i, arg := range e.Args {
+		rs[i] = applyConstantPropagation(arg, schema)
+		// Check for constant
+		if c, ok := rs[i].(*Constant); ok {
+			vals[i] = c.Value
+		} else {
+			is_const = false
+		}
+	}
+	// Check if constant
+	if is_const {
+		return &Constant{util.EvalBitwise(e.Op, e.Width, vals)}
+	}
+	// Done
+	return &Bitwise{e.Op, e.Width, rs}
+}
@@ -72,6 +72,18 @@ func (e *Normalise) EvalAt(k int, tr trace.Trace) fr.Element {
 	return val
 }
 
+// EvalAt evaluates a bitwise operation at a given row in a trace by first
+// evaluating all of its arguments at that row.
+func (e *Bitwise) EvalAt(k int, tr trace.Trace) fr.Element {
+	vals := make([]fr.Element, len(e.Args))
+	//
+	for i, arg := range e.Args {
+		vals[i] = arg.EvalAt(k, tr)
+	}
+	//
+	return util.EvalBitwise(e.Op, e.Width, vals)
+}
+
 // EvalAt evaluates a subtraction at a given row in a trace by first evaluating all of
 // its arguments at that row.
 func (e *Sub) EvalAt(k int, tr trace.Trace) fr.Element {
@@ -0,0 +1,61 @@
+package mir
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// Explain the evaluation of a column access at a given row in a trace.
+func (e *ColumnAccess) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema)
+}
+
+// Explain the evaluation of a constant at a given row in a trace.
+func (e *Constant) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema)
+}
+
+// Explain the evaluation of a sum at a given row in a trace.
+func (e *Add) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of a product at a given row in a trace.
+func (e *Mul) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of an exponentiation at a given row in a trace.
+func (e *Exp) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Arg)
+}
+
+// Explain the evaluation of a normalisation at a given row in a trace.
+func (e *Normalise) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Arg)
+}
+
+// Explain the evaluation of a bitwise operation at a given row in a trace.
+func (e *Bitwise) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of a subtraction at a given row in a trace.
+func (e *Sub) Explain(k int, tr trace.Trace, schema sc.Schema) *sc.Explanation {
+	return explainExpr(e, k, tr, schema, e.Args...)
+}
+
+// Explain the evaluation of an expression at a given row in a trace, where
+// every argument of that expression is evaluated (and explained) as well.
+func explainExpr(e Expr, k int, tr trace.Trace, schema sc.Schema, args ...Expr) *sc.Explanation {
+	children := make([]*sc.Explanation, len(args))
+	//
+	for i, arg := range args {
+		children[i] = arg.Explain(k, tr, schema)
+	}
+	//
+	value := e.EvalAt(k, tr)
+	//
+	return sc.NewExplanation(e.Lisp(schema).String(false), []fr.Element{value}, children...)
+}
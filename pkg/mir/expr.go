@@ -15,6 +15,10 @@ import (
 type Expr interface {
 	util.Boundable
 	sc.Evaluable
+	// Explain the evaluation of this expression at a given row in a given
+	// trace, by producing an evaluation tree annotated with the value of each
+	// subexpression.
+	Explain(int, trace.Trace, sc.Schema) *sc.Explanation
 }
 
 // ============================================================================
@@ -219,6 +223,47 @@ func (p *Normalise) RequiredCells(row int, tr trace.Trace) *util.AnySortedSet[tr
 	return p.Arg.RequiredCells(row, tr)
 }
 
+// ============================================================================
+// Bitwise
+// ============================================================================
+
+// Bitwise represents a bitwise operation (e.g. bitwise and) over one or more
+// arguments, each of which is treated as an unsigned integer of the given
+// bitwidth.  For shift operations, the second argument is the shift amount.
+type Bitwise struct {
+	Op    util.BitwiseOp
+	Width uint
+	Args  []Expr
+}
+
+// Bounds returns max shift in either the negative (left) or positive
+// direction (right).
+func (p *Bitwise) Bounds() util.Bounds { return util.BoundsForArray(p.Args) }
+
+// Context determines the evaluation context (i.e. enclosing module) for this
+// expression.
+func (p *Bitwise) Context(schema sc.Schema) trace.Context {
+	return sc.JoinContexts[Expr](p.Args, schema)
+}
+
+// RequiredColumns returns the set of columns on which this term depends.
+// That is, columns whose values may be accessed when evaluating this term
+// on a given trace.
+func (p *Bitwise) RequiredColumns() *util.SortedSet[uint] {
+	return util.UnionSortedSets(p.Args, func(e Expr) *util.SortedSet[uint] {
+		return e.RequiredColumns()
+	})
+}
+
+// RequiredCells returns the set of trace cells on which this term depends.
+// That is, evaluating this term at the given row in the given trace will read
+// these cells.
+func (p *Bitwise) RequiredCells(row int, tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	return util.UnionAnySortedSets(p.Args, func(e Expr) *util.AnySortedSet[trace.CellRef] {
+		return e.RequiredCells(row, tr)
+	})
+}
+
 // ============================================================================
 // ColumnAccess
 // ============================================================================
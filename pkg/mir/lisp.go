@@ -63,6 +63,13 @@ func (e *Exp) Lisp(schema sc.Schema) sexp.SExp {
 	return sexp.NewList([]sexp.SExp{sexp.NewSymbol("^"), arg, pow})
 }
 
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.  Observe that the bitwidth is included as part of the
+// operator, since it affects the semantics (e.g. of bitwise negation).
+func (e *Bitwise) Lisp(schema sc.Schema) sexp.SExp {
+	return nary2Lisp(schema, fmt.Sprintf("%s:u%d", e.Op, e.Width), e.Args)
+}
+
 func nary2Lisp(schema sc.Schema, op string, exprs []Expr) sexp.SExp {
 	arr := make([]sexp.SExp, 1+len(exprs))
 	arr[0] = sexp.NewSymbol(op)
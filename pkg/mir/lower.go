@@ -4,16 +4,41 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/air"
 	air_gadgets "github.com/consensys/go-corset/pkg/air/gadgets"
 	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/assignment"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
 	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
+// LoweringConfig determines how an MIR schema is lowered into an AIR schema,
+// since some choices depend upon the capabilities of the intended prover.
+type LoweringConfig struct {
+	// Lower lookups into log-derivative lookups, each of which has a computed
+	// multiplicity column in its target module.
+	LogDerivativeLookups bool
+	// Maximum degree of any vanishing constraint, where 0 indicates no bound.
+	// Constraints exceeding this are split using intermediate computed columns.
+	MaxDegree uint
+}
+
 // LowerToAir lowers (or refines) an MIR table into an AIR schema.  That means
 // lowering all the columns and constraints, whilst adding additional columns /
-// constraints as necessary to preserve the original semantics.
-func (p *Schema) LowerToAir() *air.Schema {
+// constraints as necessary to preserve the original semantics.  If any element
+// cannot be lowered (e.g. a constraint which can never vanish), then the errors
+// arising are returned instead.
+func (p *Schema) LowerToAir() (*air.Schema, []sc.LoweringError) {
+	return p.LowerToAirWith(LoweringConfig{})
+}
+
+// LowerToAirWith lowers (or refines) an MIR table into an AIR schema using a
+// given configuration.
+func (p *Schema) LowerToAirWith(cfg LoweringConfig) (*air.Schema, []sc.LoweringError) {
+	var errors []sc.LoweringError
+	//
 	airSchema := air.EmptySchema[Expr]()
 	// Copy modules
 	for _, mod := range p.modules {
@@ -31,65 +56,137 @@ func (p *Schema) LowerToAir() *air.Schema {
 	for _, assign := range p.assignments {
 		airSchema.AddAssignment(assign)
 	}
-	// Now, lower assignments.
+	// Now, lower assignments.  Any constraints arising (e.g. from gadgets) are
+	// attributed to the assignment being lowered.
 	for _, assign := range p.assignments {
-		lowerAssignmentToAir(assign, p, airSchema)
+		n := airSchema.Constraints().Count()
+		errors = p.lowerElement(errors, assign, func() error {
+			if err := lowerAssignmentToAir(assign, p, airSchema); err != nil {
+				return err
+			}
+			//
+			return boundDegree(n, airSchema, cfg)
+		})
+		sc.AttributeFrom(airSchema.Constraints(), n, sc.SourceOf(assign))
 	}
-	// Lower vanishing constraints
+	// Lower vanishing constraints.  Again, any constraints arising are
+	// attributed to the constraint being lowered.
 	for _, c := range p.constraints {
-		lowerConstraintToAir(c, airSchema)
+		n := airSchema.Constraints().Count()
+		errors = p.lowerElement(errors, c, func() error {
+			if err := lowerConstraintToAir(c, airSchema, cfg); err != nil {
+				return err
+			}
+			//
+			return boundDegree(n, airSchema, cfg)
+		})
+		sc.AttributeFrom(airSchema.Constraints(), n, sc.SourceOf(c))
 	}
 	// Add assertions (these do not need to be lowered)
 	for _, assertion := range p.assertions {
+		n := airSchema.Assertions().Count()
 		airSchema.AddPropertyAssertion(assertion.Handle(), assertion.Context(), assertion.Property())
+		sc.AttributeFrom(airSchema.Assertions(), n, assertion.Source())
+	}
+	// Add table of byte conjunctions for bitwise operations (if required).  This
+	// must come last, since it depends upon every conjunction.
+	air_gadgets.ApplyByteAndTableGadget(cfg.LogDerivativeLookups, airSchema)
+	//
+	if len(errors) > 0 {
+		return nil, errors
 	}
 	// Done
-	return airSchema
+	return airSchema, nil
+}
+
+// Lower a given element into the AIR level, appending an error to those given
+// if this fails.
+func (p *Schema) lowerElement(errors []sc.LoweringError, element any, lower func() error) []sc.LoweringError {
+	if err := sc.LowerElement("AIR", element, p, lower); err != nil {
+		return append(errors, *err)
+	}
+	//
+	return errors
+}
+
+// Ensure constraints arising from lowering (i.e. those from a given index
+// onwards) are within the maximum degree (if applicable).  This fails for
+// constraints which cannot be bounded.
+func boundDegree(start uint, schema *air.Schema, cfg LoweringConfig) error {
+	if cfg.MaxDegree != 0 {
+		return air_gadgets.ApplyDegreeBoundGadget(start, cfg.MaxDegree, schema)
+	}
+	//
+	return nil
 }
 
 // Lower an assignment to the AIR level.
-func lowerAssignmentToAir(c sc.Assignment, mirSchema *Schema, airSchema *air.Schema) {
+func lowerAssignmentToAir(c sc.Assignment, mirSchema *Schema, airSchema *air.Schema) error {
 	if v, ok := c.(Permutation); ok {
-		lowerPermutationToAir(v, mirSchema, airSchema)
+		return lowerPermutationToAir(v, mirSchema, airSchema)
 	} else if _, ok := c.(Interleaving); ok {
 		// Nothing to do for interleaving constraints, as they can be passed
 		// directly down to the AIR level
-		return
-	} else {
-		panic("unknown assignment")
+		return nil
+	} else if _, ok := c.(ComputedColumn); ok {
+		// Nothing to do for computed columns either.  Although the computation
+		// is an MIR expression, it is only ever evaluated during trace
+		// expansion and, hence, never needs to be lowered.
+		return nil
 	}
+	//
+	return fmt.Errorf("unknown assignment (%T)", c)
 }
 
-// Lower a constraint to the AIR level.
-func lowerConstraintToAir(c sc.Constraint, schema *air.Schema) {
+// Lower a constraint to the AIR level.  This fails for constraints which can
+// never hold, or which are malformed.
+func lowerConstraintToAir(c sc.Constraint, schema *air.Schema, cfg LoweringConfig) error {
 	// Check what kind of constraint we have
 	if v, ok := c.(LookupConstraint); ok {
-		lowerLookupConstraintToAir(v, schema)
+		return lowerLookupConstraintToAir(v, schema, cfg)
 	} else if v, ok := c.(VanishingConstraint); ok {
-		lowerVanishingConstraintToAir(v, schema)
+		return lowerVanishingConstraintToAir(v, schema)
 	} else if v, ok := c.(RangeConstraint); ok {
-		lowerRangeConstraintToAir(v, schema)
-	} else {
-		// Should be unreachable as no other constraint types can be added to a
-		// schema.
-		panic("unreachable")
+		return lowerRangeConstraintToAir(v, schema)
 	}
+	//
+	return fmt.Errorf("unknown constraint (%T)", c)
 }
 
 // Lower a vanishing constraint to the AIR level.  This is relatively
 // straightforward and simply relies on lowering the expression being
 // constrained.  This may result in the generation of computed columns, e.g. to
-// hold inverses, etc.
-func lowerVanishingConstraintToAir(v VanishingConstraint, schema *air.Schema) {
-	air_expr := lowerExprTo(v.Context(), v.Constraint().Expr, schema)
+// hold inverses, etc.  This fails if the constraint reduces to a non-zero
+// constant, since it can then never hold.
+func lowerVanishingConstraintToAir(v VanishingConstraint, schema *air.Schema) error {
+	air_expr, err := lowerExprTo(v.Context(), v.Constraint().Expr, schema)
+	if err != nil {
+		return err
+	}
 	// Check whether this is a constant
 	constant := air_expr.AsConstant()
 	// Check for compile-time constants
 	if constant != nil && !constant.IsZero() {
-		panic(fmt.Sprintf("constraint %s cannot vanish!", v.Handle()))
+		return fmt.Errorf("constraint cannot vanish (reduces to %s)", constant.String())
 	} else if constant == nil {
-		schema.AddVanishingConstraint(v.Handle(), v.Context(), v.Domain(), air_expr)
+		domain := v.Domain()
+		// Constraints on the first or last row are supported directly by the
+		// prover.  Any other domain requires a selector column instead.
+		if domain != nil && !isFirstOrLast(domain) {
+			selector := air_gadgets.ApplyDomainSelectorGadget(v.Context(), domain, schema)
+			air_expr, domain = selector.Mul(air_expr), nil
+		}
+		//
+		schema.AddVanishingConstraint(v.Handle(), v.Context(), domain, air_expr)
 	}
+	//
+	return nil
+}
+
+// Determine whether a given domain consists solely of the first or last row.
+func isFirstOrLast(domain *constraint.Domain) bool {
+	row, ok := domain.Row()
+	return ok && (row == 0 || row == -1)
 }
 
 // Lower a range constraint to the AIR level.  The challenge here is that a
@@ -98,14 +195,22 @@ func lowerVanishingConstraintToAir(v VanishingConstraint, schema *air.Schema) {
 // expression is encountered, we must generate a computed column to hold the
 // value of that expression, along with appropriate constraints to enforce the
 // expected value.
-func lowerRangeConstraintToAir(v RangeConstraint, schema *air.Schema) {
+func lowerRangeConstraintToAir(v RangeConstraint, schema *air.Schema) error {
 	// Lower target expression
-	target := lowerExprTo(v.Context(), v.Target(), schema)
+	target, err := lowerExprTo(v.Context(), v.Target(), schema)
+	if err != nil {
+		return err
+	}
 	// Expand target expression (if necessary)
 	column := air_gadgets.Expand(v.Context(), target, schema)
+	// Determine bitwidth implied by bound (where this is a power of two)
+	nbits, pow2 := boundBitWidth(v.Bound())
 	// Yes, a constraint is implied.  Now, decide whether to use a range
 	// constraint or just a vanishing constraint.
-	if v.BoundedAtMost(2) {
+	if _, ok := air_gadgets.ByteDecompositionOf(column, nbits, schema); pow2 && ok {
+		// Already enforced by byte decomposition (e.g. of a bitwise operand)
+		return nil
+	} else if v.BoundedAtMost(2) {
 		// u1 => use vanishing constraint X * (X - 1)
 		air_gadgets.ApplyBinaryGadget(column, schema)
 	} else if v.BoundedAtMost(256) {
@@ -120,6 +225,24 @@ func lowerRangeConstraintToAir(v RangeConstraint, schema *air.Schema) {
 		// Apply bitwidth gadget
 		air_gadgets.ApplyBitwidthGadget(column, uint(bi.BitLen()-1), schema)
 	}
+	//
+	return nil
+}
+
+// Determine the bitwidth n of a given bound of the form 2^n, or false if the
+// bound is not of this form.
+func boundBitWidth(bound fr.Element) (uint, bool) {
+	var bi big.Int
+	//
+	bound.BigInt(&bi)
+	//
+	if bi.Sign() <= 0 {
+		return 0, false
+	}
+	//
+	nbits := uint(bi.BitLen() - 1)
+	//
+	return nbits, bi.TrailingZeroBits() == nbits
 }
 
 // Lower a lookup constraint to the AIR level.  The challenge here is that a
@@ -127,21 +250,62 @@ func lowerRangeConstraintToAir(v RangeConstraint, schema *air.Schema) {
 // it can only access columns directly.  Therefore, whenever a general
 // expression is encountered, we must generate a computed column to hold the
 // value of that expression, along with appropriate constraints to enforce the
-// expected value.
-func lowerLookupConstraintToAir(c LookupConstraint, schema *air.Schema) {
+// expected value.  The same applies to any selectors.  When log-derivative
+// lookups are requested, a multiplicity column is additionally introduced.
+func lowerLookupConstraintToAir(c LookupConstraint, schema *air.Schema, cfg LoweringConfig) error {
 	targets := make([]uint, len(c.Targets()))
 	sources := make([]uint, len(c.Sources()))
 	//
 	for i := 0; i < len(targets); i++ {
 		// Lower source and target expressions
-		target := lowerExprTo(c.TargetContext(), c.Targets()[i], schema)
-		source := lowerExprTo(c.SourceContext(), c.Sources()[i], schema)
+		target, terr := lowerExprTo(c.TargetContext(), c.Targets()[i], schema)
+		source, serr := lowerExprTo(c.SourceContext(), c.Sources()[i], schema)
+		//
+		if terr != nil {
+			return terr
+		} else if serr != nil {
+			return serr
+		}
 		// Expand them
 		targets[i] = air_gadgets.Expand(c.TargetContext(), target, schema)
 		sources[i] = air_gadgets.Expand(c.SourceContext(), source, schema)
 	}
+	// Lower selectors (if applicable)
+	sourceSelector, serr := lowerSelectorToAir(c.SourceContext(), c.SourceSelector(), schema)
+	targetSelector, terr := lowerSelectorToAir(c.TargetContext(), c.TargetSelector(), schema)
+	//
+	if serr != nil {
+		return serr
+	} else if terr != nil {
+		return terr
+	}
 	// finally add the constraint
-	schema.AddLookupConstraint(c.Handle(), c.SourceContext(), c.TargetContext(), sources, targets)
+	if cfg.LogDerivativeLookups {
+		multiplicity := air_gadgets.ApplyMultiplicityGadget(c.Handle(), c.TargetContext(), sources, targets,
+			sourceSelector, targetSelector, schema)
+		schema.AddLogDerivativeLookupConstraint(c.Handle(), c.SourceContext(), c.TargetContext(), sources, targets,
+			sourceSelector, targetSelector, multiplicity)
+	} else {
+		schema.AddLookupConstraint(c.Handle(), c.SourceContext(), c.TargetContext(), sources, targets, sourceSelector,
+			targetSelector)
+	}
+	//
+	return nil
+}
+
+// Lower an (optional) lookup selector to the AIR level, such that it is held
+// in a column.
+func lowerSelectorToAir(ctx trace.Context, selector util.Option[Expr], schema *air.Schema) (util.Option[uint], error) {
+	if selector.HasValue() {
+		expr, err := lowerExprTo(ctx, selector.Unwrap(), schema)
+		if err != nil {
+			return util.None[uint](), err
+		}
+		//
+		return util.Some(air_gadgets.Expand(ctx, expr, schema)), nil
+	}
+	//
+	return util.None[uint](), nil
 }
 
 // Lower a permutation to the AIR level.  This has quite a few
@@ -149,24 +313,43 @@ func lowerLookupConstraintToAir(c LookupConstraint, schema *air.Schema) {
 // new columns.  Secondly, sorting constraints (and their associated
 // computed columns) must also be added.  Finally, a trace
 // computation is required to ensure traces are correctly expanded to
-// meet the requirements of a sorted permutation.
-func lowerPermutationToAir(c Permutation, mirSchema *Schema, airSchema *air.Schema) {
-	c_targets := c.Targets()
-	ncols := len(c_targets)
-	//
-	targets := make([]uint, ncols)
+// meet the requirements of a sorted permutation.  For a permutation of selected
+// source rows, the target selector is additionally constrained to be binary
+// and, furthermore, can only be unset on a prefix of the target rows (i.e.
+// padding rows).  Sorting constraints only apply to selected rows.
+func lowerPermutationToAir(c Permutation, mirSchema *Schema, airSchema *air.Schema) error {
+	var (
+		c_targets = c.Targets()
+		ncols     = len(c_targets)
+		targets   = make([]uint, ncols)
+		selector  = util.None[uint]()
+		err       error
+	)
 	// Add individual permutation constraints
 	for i := 0; i < ncols; i++ {
-		var ok bool
-		// TODO: how best to avoid this lookup?
-		targets[i], ok = sc.ColumnIndexOf(airSchema, c.Module(), c_targets[i].Name())
-
-		if !ok {
-			panic("internal failure")
+		if targets[i], err = lowerPermutationColumnToAir(c, c_targets[i], airSchema); err != nil {
+			return err
+		}
+	}
+	//
+	if c.TargetSelector().HasValue() {
+		var sel uint
+		//
+		if sel, err = lowerPermutationColumnToAir(c, c.TargetSelector().Unwrap(), airSchema); err != nil {
+			return err
 		}
+		//
+		selector = util.Some(sel)
+		// Selector must be binary
+		air_gadgets.ApplyBinaryGadget(sel, airSchema)
+		// Selector cannot be unset after being set (i.e. S[k-1] ==> S[k])
+		Sk := air.NewColumnAccess(sel, 0)
+		Skm1 := air.NewColumnAccess(sel, -1)
+		name := fmt.Sprintf("%s:prefix", c.TargetSelector().Unwrap().Name())
+		airSchema.AddVanishingConstraint(name, c.Context(), nil, Skm1.Mul(air.NewConst64(1).Sub(Sk)))
 	}
 	//
-	airSchema.AddPermutationConstraint(targets, c.Sources())
+	airSchema.AddPermutationConstraint(targets, c.Sources(), c.Selector(), selector)
 	// Add sorting constraints + computed columns as necessary.
 	if ncols == 1 {
 		// For a single column sort, its actually a bit easier because we don't
@@ -176,7 +359,7 @@ func lowerPermutationToAir(c Permutation, mirSchema *Schema, airSchema *air.Sche
 		// also requires bitwidth constraints.
 		bitwidth := mirSchema.Columns().Nth(c.Sources()[0]).Type().AsUint().BitWidth()
 		// Add column sorting constraints
-		air_gadgets.ApplyColumnSortGadget(targets[0], c.Signs()[0], bitwidth, airSchema)
+		air_gadgets.ApplyColumnSortGadget(targets[0], c.Signs()[0], bitwidth, selector, airSchema)
 	} else {
 		// For a multi column sort, its a bit harder as we need additional
 		// logicl to ensure the target columns are lexicographally sorted.
@@ -190,8 +373,23 @@ func lowerPermutationToAir(c Permutation, mirSchema *Schema, airSchema *air.Sche
 			}
 		}
 		// Add lexicographically sorted constraints
-		air_gadgets.ApplyLexicographicSortingGadget(targets, c.Signs(), bitwidth, airSchema)
+		air_gadgets.ApplyLexicographicSortingGadget(targets, c.Signs(), bitwidth, selector, airSchema)
 	}
+	//
+	return nil
+}
+
+// Determine the index of a column declared by a given permutation at the AIR
+// level.  This fails if no such column exists.
+func lowerPermutationColumnToAir(c Permutation, column sc.Column, airSchema *air.Schema) (uint, error) {
+	// TODO: how best to avoid this lookup?
+	index, ok := sc.ColumnIndexOf(airSchema, c.Module(), column.Name())
+	//
+	if !ok {
+		return 0, fmt.Errorf("unknown permutation column %s", column.Name())
+	}
+	//
+	return index, nil
 }
 
 // Lower an expression into the Arithmetic Intermediate Representation.
@@ -199,8 +397,8 @@ func lowerPermutationToAir(c Permutation, mirSchema *Schema, airSchema *air.Sche
 // new columns into the given table (with appropriate constraints).  This first
 // performs constant propagation to ensure lowering is as efficient as possible.
 // A module identifier is required to determine where any computed columns
-// should be located.
-func lowerExprTo(ctx trace.Context, e1 Expr, schema *air.Schema) air.Expr {
+// should be located.  This fails if the expression is malformed.
+func lowerExprTo(ctx trace.Context, e1 Expr, schema *air.Schema) (air.Expr, error) {
 	// Apply constant propagation
 	e2 := applyConstantPropagation(e1, schema)
 	// Lower properly
@@ -209,36 +407,47 @@ func lowerExprTo(ctx trace.Context, e1 Expr, schema *air.Schema) air.Expr {
 
 // Inner form is used for recursive calls and does not repeat the constant
 // propagation phase.
-func lowerExprToInner(ctx trace.Context, e Expr, schema *air.Schema) air.Expr {
+func lowerExprToInner(ctx trace.Context, e Expr, schema *air.Schema) (air.Expr, error) {
 	if p, ok := e.(*Add); ok {
-		return &air.Add{Args: lowerExprs(ctx, p.Args, schema)}
+		args, err := lowerExprs(ctx, p.Args, schema)
+		return &air.Add{Args: args}, err
 	} else if p, ok := e.(*Constant); ok {
-		return &air.Constant{Value: p.Value}
+		return &air.Constant{Value: p.Value}, nil
 	} else if p, ok := e.(*ColumnAccess); ok {
-		return &air.ColumnAccess{Column: p.Column, Shift: p.Shift}
+		return &air.ColumnAccess{Column: p.Column, Shift: p.Shift}, nil
 	} else if p, ok := e.(*Mul); ok {
-		return &air.Mul{Args: lowerExprs(ctx, p.Args, schema)}
+		args, err := lowerExprs(ctx, p.Args, schema)
+		return &air.Mul{Args: args}, err
 	} else if p, ok := e.(*Exp); ok {
 		return lowerExpTo(ctx, p, schema)
 	} else if p, ok := e.(*Normalise); ok {
 		// Lower the expression being normalised
-		e := lowerExprToInner(ctx, p.Arg, schema)
+		arg, err := lowerExprToInner(ctx, p.Arg, schema)
+		if err != nil {
+			return nil, err
+		}
 		// Construct an expression representing the normalised value of e.  That is,
 		// an expression which is 0 when e is 0, and 1 when e is non-zero.
-		return air_gadgets.Normalise(e, schema)
+		return air_gadgets.Normalise(arg, schema), nil
 	} else if p, ok := e.(*Sub); ok {
-		return &air.Sub{Args: lowerExprs(ctx, p.Args, schema)}
+		args, err := lowerExprs(ctx, p.Args, schema)
+		return &air.Sub{Args: args}, err
+	} else if p, ok := e.(*Bitwise); ok {
+		return lowerBitwiseTo(ctx, p, schema)
 	}
-	// Should be unreachable
-	panic(fmt.Sprintf("unknown expression: %s", e.Lisp(schema).String(true)))
+	//
+	return nil, fmt.Errorf("unknown expression: %s", e.Lisp(schema).String(true))
 }
 
 // LowerTo lowers an exponent expression to the AIR level by lowering the
 // argument, and then constructing a multiplication.  This is because the AIR
 // level does not support an explicit exponent operator.
-func lowerExpTo(ctx trace.Context, e *Exp, schema *air.Schema) air.Expr {
+func lowerExpTo(ctx trace.Context, e *Exp, schema *air.Schema) (air.Expr, error) {
 	// Lower the expression being raised
-	le := lowerExprToInner(ctx, e.Arg, schema)
+	le, err := lowerExprToInner(ctx, e.Arg, schema)
+	if err != nil {
+		return nil, err
+	}
 	// Multiply it out k times
 	es := make([]air.Expr, e.Pow)
 	//
@@ -246,17 +455,243 @@ func lowerExpTo(ctx trace.Context, e *Exp, schema *air.Schema) air.Expr {
 		es[i] = le
 	}
 	// Done
-	return &air.Mul{Args: es}
+	return &air.Mul{Args: es}, nil
 }
 
-// Lower a set of zero or more MIR expressions.
-func lowerExprs(ctx trace.Context, exprs []Expr, schema *air.Schema) []air.Expr {
-	n := len(exprs)
-	nexprs := make([]air.Expr, n)
+// Lower a bitwise expression to the AIR level.  Since the AIR level does not
+// support bitwise operations, each operand is expanded into a column which is
+// decomposed into bytes (which are constrained to recompose to the original
+// operand).  Conjunctions are then computed byte-by-byte, with each triple of
+// bytes looked up in a table of byte conjunctions (see ApplyByteAndGadget).
+// Disjunction and exclusive-or are implemented arithmetically from
+// conjunction, since x|y == x+y-(x&y) and x^y == x+y-2*(x&y).  Likewise,
+// bitwise negation is implemented as (2^n-1)-x.  Finally, shifts (whose amount
+// must be a constant) split their operand into two parts, one of which forms
+// the result.  Operations as wide as the field are not supported, since the
+// decomposition of an operand would not then be unique.
+func lowerBitwiseTo(ctx trace.Context, e *Bitwise, schema *air.Schema) (air.Expr, error) {
+	var (
+		columns, shifted, err = lowerBitwiseOperands(ctx, e, schema)
+		result                air.Expr
+	)
+	//
+	if err != nil {
+		return nil, err
+	}
+	//
+	switch e.Op {
+	case util.BAND, util.BOR, util.BXOR:
+		result = lowerBitwiseFold(ctx, e.Op, columns, e.Width, schema)
+	case util.BNOT:
+		// Ensure operand fits within n bits
+		air_gadgets.ApplyByteDecompositionGadget(columns[0], e.Width, schema)
+		// ~x == (2^n - 1) - x
+		max := powerOfTwo(e.Width)
+		one := fr.One()
+		max.Sub(&max, &one)
+		result = air.NewConst(max).Sub(air.NewColumnAccess(columns[0], 0))
+	case util.SHL, util.SHR:
+		var k uint
+		//
+		if k, err = lowerShiftAmount(e, schema); err != nil {
+			return nil, err
+		}
+		//
+		result = lowerShiftTo(ctx, e.Op, columns[0], e.Width, k, schema)
+	default:
+		return nil, fmt.Errorf("unknown bitwise operation (%s)", e.Op)
+	}
+	// Retain bounds of shifted operands
+	if len(shifted) > 0 {
+		result = &air.Add{Args: append([]air.Expr{result}, shifted...)}
+	}
+	//
+	return result, nil
+}
 
-	for i := 0; i < n; i++ {
-		nexprs[i] = lowerExprToInner(ctx, exprs[i], schema)
+// Lower the operands of a bitwise operation (i.e. excluding any shift amount),
+// expanding each into a column.  Since expanding a shifted operand loses its
+// bounds, the difference between each shifted operand and its column is also
+// returned.  This difference is zero on every row where the operand is defined
+// and, when added to the result, ensures the enclosing constraint is not
+// applied on rows where the operand is undefined.
+func lowerBitwiseOperands(ctx trace.Context, e *Bitwise, schema *air.Schema) ([]uint, []air.Expr, error) {
+	var shifted []air.Expr
+	//
+	if e.Width >= fr.Bits {
+		return nil, nil, fmt.Errorf("bitwise operation too wide (u%d)", e.Width)
+	}
+	// Determine operands (i.e. excluding shift amount)
+	operands := e.Args
+	if e.Op.IsShift() {
+		operands = e.Args[:1]
+	}
+	// Expand each operand into a column
+	columns := make([]uint, len(operands))
+	//
+	for i, arg := range operands {
+		le, err := lowerExprToInner(ctx, arg, schema)
+		if err != nil {
+			return nil, nil, err
+		}
+		//
+		columns[i] = air_gadgets.Expand(ctx, le, schema)
+		//
+		if bounds := le.Bounds(); bounds.Start != 0 || bounds.End != 0 {
+			shifted = append(shifted, le.Sub(air.NewColumnAccess(columns[i], 0)))
+		}
+	}
+	//
+	return columns, shifted, nil
+}
+
+// Lower a conjunction, disjunction or exclusive-or of two or more columns, by
+// folding the operation over them from left to right.  At each step, the
+// conjunction of the accumulated result and the next operand is computed
+// byte-by-byte, and the result of the operation is then constructed
+// arithmetically from that.
+func lowerBitwiseFold(ctx trace.Context, op util.BitwiseOp, columns []uint, n uint,
+	schema *air.Schema) air.Expr {
+	// Ensure first operand fits within n bits.
+	air_gadgets.ApplyByteDecompositionGadget(columns[0], n, schema)
+	//
+	var result air.Expr = air.NewColumnAccess(columns[0], 0)
+	//
+	for _, col := range columns[1:] {
+		lhs := air_gadgets.Expand(ctx, result, schema)
+		index := air_gadgets.ApplyByteAndGadget(lhs, col, n, schema)
+		x := air.NewColumnAccess(lhs, 0)
+		y := air.NewColumnAccess(col, 0)
+		conj := recomposeBytes(index, n)
+		//
+		switch op {
+		case util.BAND:
+			result = conj
+		case util.BOR:
+			// x | y == x + y - (x & y)
+			result = x.Add(y).Sub(conj)
+		default:
+			// x ^ y == x + y - 2*(x & y)
+			result = x.Add(y).Sub(air.NewConst64(2).Mul(conj))
+		}
+	}
+	//
+	return result
+}
+
+// Lower a shift of a given column by a constant amount k.  Rather than
+// decomposing the operand into its bits, it is split at the appropriate bit
+// into two parts, each of which is constrained to fit within its bitwidth.  For
+// a right shift, the upper part is the result; for a left shift, the lower
+// part is the result (after being shifted up).
+func lowerShiftTo(ctx trace.Context, op util.BitwiseOp, col uint, n uint, k uint, schema *air.Schema) air.Expr {
+	switch {
+	case k == 0:
+		air_gadgets.ApplyByteDecompositionGadget(col, n, schema)
+		return air.NewColumnAccess(col, 0)
+	case k >= n:
+		air_gadgets.ApplyByteDecompositionGadget(col, n, schema)
+		return air.NewConst64(0)
+	case op == util.SHR:
+		hi, _ := splitBits(ctx, col, n, k, schema)
+		return air.NewColumnAccess(hi, 0)
+	default:
+		_, lo := splitBits(ctx, col, n, n-k, schema)
+		return air.NewColumnAccess(lo, 0).Mul(air.NewConst(powerOfTwo(k)))
+	}
+}
+
+// Split a given column into its upper n-j bits and its lower j bits, returning
+// the columns holding each part.  The upper part is computed directly, whilst
+// the lower part is then determined from it.  Both parts are constrained to fit
+// within their respective bitwidths, which (together) ensures the original
+// column fits within n bits.
+func splitBits(ctx trace.Context, col uint, n uint, j uint, schema *air.Schema) (uint, uint) {
+	var (
+		shift     = fr.NewElement(uint64(j))
+		upper     = &Bitwise{util.SHR, n, []Expr{&ColumnAccess{col, 0}, &Constant{shift}}}
+		name      = upper.Lisp(schema).String(false)
+		hi, ok    = sc.ColumnIndexOf(schema, ctx.Module(), name)
+		pow2j     = air.NewConst(powerOfTwo(j))
+		remainder air.Expr
+	)
+	// Add column for upper part (if it does not already exist)
+	if !ok {
+		hi = schema.AddAssignment(assignment.NewComputedColumn[Expr](ctx, name, sc.NewUintType(n-j), upper))
 	}
+	// Lower part is what remains, i.e. x - hi*2^j
+	remainder = air.NewColumnAccess(col, 0).Sub(air.NewColumnAccess(hi, 0).Mul(pow2j))
+	lo := air_gadgets.Expand(ctx, remainder, schema)
+	// Constrain both parts
+	air_gadgets.ApplyByteDecompositionGadget(hi, n-j, schema)
+	air_gadgets.ApplyByteDecompositionGadget(lo, j, schema)
+	//
+	return hi, lo
+}
 
-	return nexprs
+// Determine the (constant) shift amount for a given shift operation.  Any
+// shift beyond the bitwidth is capped, since the result is zero regardless.
+// This fails if the shift amount is not constant.
+func lowerShiftAmount(e *Bitwise, schema *air.Schema) (uint, error) {
+	c, ok := e.Args[1].(*Constant)
+	if !ok {
+		return 0, fmt.Errorf("non-constant shift amount: %s", e.Lisp(schema).String(true))
+	}
+	//
+	var amount big.Int
+	//
+	c.Value.BigInt(&amount)
+	//
+	if !amount.IsUint64() || amount.Uint64() > uint64(e.Width) {
+		return e.Width, nil
+	}
+	//
+	return uint(amount.Uint64()), nil
+}
+
+// Recompose the bytes of a byte decomposition for a given number of bits into a
+// single value, where the first byte is the least significant.
+func recomposeBytes(index uint, nbits uint) air.Expr {
+	var (
+		fr256       = fr.NewElement(256)
+		coefficient = fr.NewElement(1)
+		es          = make([]air.Expr, (nbits+7)/8)
+	)
+	//
+	for i := range es {
+		es[i] = air.NewColumnAccess(index+uint(i), 0).Mul(air.NewConst(coefficient))
+		coefficient.Mul(&coefficient, &fr256)
+	}
+	//
+	return &air.Add{Args: es}
+}
+
+// Construct the field element 2^k.
+func powerOfTwo(k uint) fr.Element {
+	var (
+		val big.Int
+		res fr.Element
+	)
+	//
+	val.Lsh(big.NewInt(1), k)
+	res.SetBigInt(&val)
+	//
+	return res
+}
+
+// Lower a set of zero or more MIR expressions.
+func lowerExprs(ctx trace.Context, exprs []Expr, schema *air.Schema) ([]air.Expr, error) {
+	var (
+		n      = len(exprs)
+		nexprs = make([]air.Expr, n)
+		err    error
+	)
+	//
+	for i := 0; i < n; i++ {
+		if nexprs[i], err = lowerExprToInner(ctx, exprs[i], schema); err != nil {
+			return nil, err
+		}
+	}
+	//
+	return nexprs, nil
 }
@@ -0,0 +1,708 @@
+package mir
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// OptimisationConfig determines which optimisation passes are applied to the
+// expressions of an MIR schema, prior to it being lowered into an AIR schema.
+// Passes are applied in the order given below.
+type OptimisationConfig struct {
+	// Fold constant subexpressions, and eliminate algebraic identities (e.g.
+	// x*1, x+0, x^1, etc).
+	ConstantFolding bool
+	// Rewrite polynomial (sub)expressions into a normal form as a sum of
+	// products, where like terms are combined.  Observe that, since this can
+	// increase the size of an expression (e.g. when multiplying out sums), the
+	// normal form is only used when it is no larger than the original.
+	PolynomialNormalForm bool
+	// Eliminate common subexpressions across the constraints of the same
+	// module, such that equivalent subexpressions (modulo the ordering of
+	// commutative operands) share a single representation.  Amongst other
+	// things, this ensures normalisations of equivalent expressions share a
+	// single inverse column when lowered.
+	CommonSubexpressionElimination bool
+}
+
+// OPTIMISATION_LEVELS determines the optimisation passes applied at each
+// optimisation level, where level 0 corresponds to no optimisation.
+var OPTIMISATION_LEVELS = []OptimisationConfig{
+	{false, false, false},
+	{true, false, true},
+	{true, true, true},
+}
+
+// OptimisationLevel returns the optimisation configuration for a given level,
+// where levels beyond the maximum are treated as the maximum.
+func OptimisationLevel(level uint) OptimisationConfig {
+	return OPTIMISATION_LEVELS[min(level, uint(len(OPTIMISATION_LEVELS)-1))]
+}
+
+// Optimise constructs an optimised version of this schema according to a given
+// configuration.  Specifically, the expressions of all vanishing, range and
+// lookup constraints are optimised.  Since the rows on which a vanishing
+// constraint is checked depend upon the shifts it contains, an optimised
+// expression is only used when it has the same bounds as the original.  Other
+// elements of the schema (e.g. assignments and assertions) are unchanged.
+func (p *Schema) Optimise(cfg OptimisationConfig) *Schema {
+	schema := EmptySchema()
+	schema.modules = p.modules
+	schema.inputs = p.inputs
+	schema.assignments = p.assignments
+	schema.assertions = p.assertions
+	schema.column_cache = p.column_cache
+	// Common subexpressions are identified across the constraints of the same
+	// module.
+	tables := make([]map[string]Expr, len(p.modules))
+	//
+	for i := range tables {
+		tables[i] = make(map[string]Expr)
+	}
+	//
+	optimise := func(e Expr) Expr {
+		ctx := e.Context(p)
+		// Constant expressions have no enclosing module
+		if ctx.IsVoid() || ctx.IsConflicted() {
+			return optimiseExpr(e, cfg, nil)
+		}
+		//
+		return optimiseExpr(e, cfg, tables[ctx.Module()])
+	}
+	//
+	for _, c := range p.constraints {
+		schema.constraints = append(schema.constraints, optimiseConstraint(c, optimise))
+	}
+	//
+	return schema
+}
+
+// Optimise the expressions of a given constraint using a given optimiser,
+// whilst preserving the constraint's origin.
+func optimiseConstraint(c sc.Constraint, optimise func(Expr) Expr) sc.Constraint {
+	var optimised sc.Constraint
+	//
+	if v, ok := c.(VanishingConstraint); ok {
+		expr := constraint.ZeroTest[Expr]{Expr: optimise(v.Constraint().Expr)}
+		optimised = constraint.NewVanishingConstraint(v.Handle(), v.Context(), v.Domain(), expr)
+	} else if v, ok := c.(RangeConstraint); ok {
+		optimised = constraint.NewRangeConstraint(v.Handle(), v.Context(), optimise(v.Target()), v.Bound())
+	} else if v, ok := c.(LookupConstraint); ok {
+		sources := mapExprs(v.Sources(), optimise)
+		targets := mapExprs(v.Targets(), optimise)
+		sourceSelector := optimiseSelector(v.SourceSelector(), optimise)
+		targetSelector := optimiseSelector(v.TargetSelector(), optimise)
+		optimised = constraint.NewLookupConstraint(v.Handle(), v.SourceContext(), v.TargetContext(), sources, targets,
+			sourceSelector, targetSelector)
+	} else {
+		// Should be unreachable as no other constraint types can be added to a
+		// schema.
+		panic("unreachable")
+	}
+	//
+	optimised.(sc.Sourced).SetSource(sc.SourceOf(c))
+	//
+	return optimised
+}
+
+func optimiseSelector(selector util.Option[Expr], optimise func(Expr) Expr) util.Option[Expr] {
+	if selector.HasValue() {
+		return util.Some(optimise(selector.Unwrap()))
+	}
+	//
+	return selector
+}
+
+// Optimise a given expression according to a given configuration, using a
+// given table of common subexpressions (which may be nil).  If the optimised
+// expression has different bounds from the original, the original is returned.
+func optimiseExpr(e Expr, cfg OptimisationConfig, table map[string]Expr) Expr {
+	optimised := e
+	//
+	if cfg.ConstantFolding {
+		optimised = foldConstants(optimised)
+	}
+	//
+	if cfg.PolynomialNormalForm {
+		optimised = normaliseExpr(optimised)
+	}
+	//
+	if cfg.CommonSubexpressionElimination && table != nil {
+		optimised = eliminateCommonSubexpressions(optimised, table)
+	}
+	// Sanity check bounds are preserved
+	if optimised.Bounds() != e.Bounds() {
+		return e
+	}
+	//
+	return optimised
+}
+
+// ============================================================================
+// Constant Folding
+// ============================================================================
+
+// Fold constant subexpressions together, and eliminate algebraic identities.
+// For example, "(+ x 0)" becomes "x", "(* x 1)" becomes "x", "(^ x 1)" becomes
+// "x" and "(~ (~ x))" becomes "(~ x)".
+func foldConstants(e Expr) Expr {
+	switch e := e.(type) {
+	case *Add:
+		return foldAdd(mapExprs(e.Args, foldConstants))
+	case *Sub:
+		return foldSub(mapExprs(e.Args, foldConstants))
+	case *Mul:
+		return foldMul(mapExprs(e.Args, foldConstants))
+	case *Exp:
+		return foldExp(foldConstants(e.Arg), e.Pow)
+	case *Normalise:
+		return foldNormalise(foldConstants(e.Arg))
+	case *Bitwise:
+		return foldBitwise(e.Op, e.Width, mapExprs(e.Args, foldConstants))
+	case *Constant, *ColumnAccess:
+		return e
+	}
+	// Should be unreachable
+	panic(fmt.Sprintf("unknown expression: %v", e))
+}
+
+func foldAdd(args []Expr) Expr {
+	var (
+		sum  fr.Element
+		rest []Expr
+	)
+	//
+	for _, arg := range args {
+		if c, ok := arg.(*Constant); ok {
+			sum.Add(&sum, &c.Value)
+		} else if a, ok := arg.(*Add); ok {
+			// Flatten nested sums
+			rest = append(rest, a.Args...)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	// Retain non-zero constant
+	if !sum.IsZero() || len(rest) == 0 {
+		rest = append(rest, &Constant{sum})
+	}
+	//
+	if len(rest) == 1 {
+		return rest[0]
+	}
+	//
+	return &Add{rest}
+}
+
+func foldSub(args []Expr) Expr {
+	var (
+		sum  fr.Element
+		rest []Expr
+	)
+	// Subtracted constants are combined together
+	for _, arg := range args[1:] {
+		if c, ok := arg.(*Constant); ok {
+			sum.Add(&sum, &c.Value)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	// Subtraction of a constant from a constant
+	if c, ok := args[0].(*Constant); ok && len(rest) == 0 {
+		var val fr.Element
+		return &Constant{*val.Sub(&c.Value, &sum)}
+	}
+	// Retain non-zero constant
+	if !sum.IsZero() {
+		rest = append(rest, &Constant{sum})
+	}
+	//
+	if len(rest) == 0 {
+		return args[0]
+	}
+	//
+	return &Sub{append([]Expr{args[0]}, rest...)}
+}
+
+func foldMul(args []Expr) Expr {
+	var rest []Expr
+	//
+	prod := fr.One()
+	//
+	for _, arg := range args {
+		if c, ok := arg.(*Constant); ok {
+			prod.Mul(&prod, &c.Value)
+		} else if m, ok := arg.(*Mul); ok {
+			// Flatten nested products
+			rest = append(rest, m.Args...)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	// Check for annihilator
+	if prod.IsZero() {
+		return &Constant{prod}
+	} else if !prod.IsOne() || len(rest) == 0 {
+		// Retain constant (at the front, by convention)
+		rest = append([]Expr{&Constant{prod}}, rest...)
+	}
+	//
+	if len(rest) == 1 {
+		return rest[0]
+	}
+	//
+	return &Mul{rest}
+}
+
+func foldExp(arg Expr, pow uint64) Expr {
+	if c, ok := arg.(*Constant); ok {
+		var val fr.Element
+		// Clone value
+		val.Set(&c.Value)
+		// Compute exponent (in place)
+		util.Pow(&val, pow)
+		//
+		return &Constant{val}
+	} else if pow == 0 {
+		return &Constant{fr.One()}
+	} else if pow == 1 {
+		return arg
+	}
+	//
+	return &Exp{arg, pow}
+}
+
+func foldNormalise(arg Expr) Expr {
+	if c, ok := arg.(*Constant); ok {
+		if c.Value.IsZero() {
+			return c
+		}
+		//
+		return &Constant{fr.One()}
+	} else if _, ok := arg.(*Normalise); ok {
+		// Normalisation is idempotent
+		return arg
+	}
+	//
+	return &Normalise{arg}
+}
+
+func foldBitwise(op util.BitwiseOp, width uint, args []Expr) Expr {
+	vals := make([]fr.Element, len(args))
+	//
+	for i, arg := range args {
+		c, ok := arg.(*Constant)
+		if !ok {
+			return &Bitwise{op, width, args}
+		}
+		//
+		vals[i] = c.Value
+	}
+	//
+	return &Constant{util.EvalBitwise(op, width, vals)}
+}
+
+// ============================================================================
+// Polynomial Normal Form
+// ============================================================================
+
+// MAX_POLYNOMIAL_TERMS determines the maximum number of terms in a polynomial
+// considered when computing normal forms.  Beyond this, the original expression
+// is retained, since multiplying out large polynomials is expensive.
+const MAX_POLYNOMIAL_TERMS = 256
+
+// A term in a polynomial, consisting of a coefficient and a product of zero or
+// more atoms.  Atoms are non-polynomial expressions (e.g. column accesses,
+// normalisations, etc).  Atoms are kept sorted by key, where repeated atoms
+// correspond to powers.
+type polyTerm struct {
+	coefficient fr.Element
+	atoms       []Expr
+	keys        []string
+}
+
+// Determine the key of this term, which identifies its atoms (but not its
+// coefficient).
+func (p *polyTerm) key() string {
+	return strings.Join(p.keys, "*")
+}
+
+// A polynomial is a sum of terms, kept sorted by key where no two terms have
+// the same key, and no term has a zero coefficient.
+type polynomial []polyTerm
+
+// Rewrite a given expression into polynomial normal form, provided this is no
+// larger than the original.  Subexpressions of atoms (e.g. the argument of a
+// normalisation) are themselves rewritten into normal form.
+func normaliseExpr(e Expr) Expr {
+	if poly, ok := toPolynomial(e); ok {
+		if normalised := fromPolynomial(poly); exprSize(normalised) <= exprSize(e) {
+			return normalised
+		}
+	}
+	// Retain original
+	return e
+}
+
+// Convert an expression into a polynomial, or fail if the polynomial becomes
+// too large.
+func toPolynomial(e Expr) (polynomial, bool) {
+	switch e := e.(type) {
+	case *Constant:
+		return constantPolynomial(e.Value), true
+	case *Add:
+		return foldPolynomials(e.Args, nil, addPolynomials)
+	case *Sub:
+		return foldPolynomials(e.Args, nil, subPolynomials)
+	case *Mul:
+		return foldPolynomials(e.Args, constantPolynomial(fr.One()), mulPolynomials)
+	case *Exp:
+		arg, ok := toPolynomial(e.Arg)
+		res := constantPolynomial(fr.One())
+		// Multiply out
+		for i := uint64(0); ok && i < e.Pow; i++ {
+			res = mulPolynomials(res, arg)
+			ok = len(res) <= MAX_POLYNOMIAL_TERMS
+		}
+		//
+		return res, ok
+	case *Normalise:
+		return atomPolynomial(&Normalise{normaliseExpr(e.Arg)}), true
+	case *Bitwise:
+		return atomPolynomial(&Bitwise{e.Op, e.Width, mapExprs(e.Args, normaliseExpr)}), true
+	default:
+		return atomPolynomial(e), true
+	}
+}
+
+// Combine the polynomials of zero or more arguments using a given function,
+// where the result for zero arguments is given.
+func foldPolynomials(args []Expr, empty polynomial, fn func(polynomial, polynomial) polynomial) (polynomial, bool) {
+	if len(args) == 0 {
+		return empty, true
+	}
+	//
+	res, ok := toPolynomial(args[0])
+	//
+	for i := 1; ok && i < len(args); i++ {
+		var ith polynomial
+		//
+		if ith, ok = toPolynomial(args[i]); ok {
+			res = fn(res, ith)
+			ok = len(res) <= MAX_POLYNOMIAL_TERMS
+		}
+	}
+	//
+	return res, ok
+}
+
+func constantPolynomial(value fr.Element) polynomial {
+	if value.IsZero() {
+		return nil
+	}
+	//
+	return polynomial{polyTerm{value, nil, nil}}
+}
+
+func atomPolynomial(atom Expr) polynomial {
+	return polynomial{polyTerm{fr.One(), []Expr{atom}, []string{exprKey(atom)}}}
+}
+
+func addPolynomials(lhs polynomial, rhs polynomial) polynomial {
+	res := slices.Clone(lhs)
+	//
+	for _, term := range rhs {
+		res = addTerm(res, term)
+	}
+	//
+	return res
+}
+
+func subPolynomials(lhs polynomial, rhs polynomial) polynomial {
+	res := slices.Clone(lhs)
+	//
+	for _, term := range rhs {
+		term.coefficient.Neg(&term.coefficient)
+		res = addTerm(res, term)
+	}
+	//
+	return res
+}
+
+func mulPolynomials(lhs polynomial, rhs polynomial) polynomial {
+	var res polynomial
+	//
+	for _, l := range lhs {
+		for _, r := range rhs {
+			var term polyTerm
+			//
+			term.coefficient.Mul(&l.coefficient, &r.coefficient)
+			term.atoms, term.keys = mergeAtoms(l, r)
+			res = addTerm(res, term)
+		}
+	}
+	//
+	return res
+}
+
+// Add a given term into a polynomial, combining it with any like term.
+func addTerm(poly polynomial, term polyTerm) polynomial {
+	key := term.key()
+	index, found := slices.BinarySearchFunc(poly, key, func(t polyTerm, key string) int {
+		return strings.Compare(t.key(), key)
+	})
+	//
+	if !found {
+		return slices.Insert(poly, index, term)
+	}
+	// Combine like terms
+	poly[index].coefficient.Add(&poly[index].coefficient, &term.coefficient)
+	// Remove term altogether if it cancelled out
+	if poly[index].coefficient.IsZero() {
+		return slices.Delete(poly, index, index+1)
+	}
+	//
+	return poly
+}
+
+// Merge the atoms of two terms together, maintaining the ordering by key.
+func mergeAtoms(lhs polyTerm, rhs polyTerm) ([]Expr, []string) {
+	atoms := make([]Expr, 0, len(lhs.atoms)+len(rhs.atoms))
+	keys := make([]string, 0, len(lhs.keys)+len(rhs.keys))
+	//
+	i, j := 0, 0
+	for i < len(lhs.atoms) || j < len(rhs.atoms) {
+		if j >= len(rhs.atoms) || (i < len(lhs.atoms) && lhs.keys[i] <= rhs.keys[j]) {
+			atoms, keys = append(atoms, lhs.atoms[i]), append(keys, lhs.keys[i])
+			i++
+		} else {
+			atoms, keys = append(atoms, rhs.atoms[j]), append(keys, rhs.keys[j])
+			j++
+		}
+	}
+	//
+	return atoms, keys
+}
+
+// Convert a polynomial back into an expression.  Terms whose coefficients are
+// closer to the field modulus than to zero (e.g. -1) are subtracted, rather
+// than added.
+func fromPolynomial(poly polynomial) Expr {
+	var pos, neg []Expr
+	//
+	for _, term := range poly {
+		var negated fr.Element
+		//
+		negated.Neg(&term.coefficient)
+		//
+		if negated.Cmp(&term.coefficient) < 0 {
+			neg = append(neg, fromTerm(negated, term.atoms))
+		} else {
+			pos = append(pos, fromTerm(term.coefficient, term.atoms))
+		}
+	}
+	//
+	var sum Expr
+	//
+	switch len(pos) {
+	case 0:
+		sum = &Constant{fr.NewElement(0)}
+	case 1:
+		sum = pos[0]
+	default:
+		sum = &Add{pos}
+	}
+	//
+	if len(neg) == 0 {
+		return sum
+	}
+	//
+	return &Sub{append([]Expr{sum}, neg...)}
+}
+
+// Convert a term back into an expression, where repeated atoms are converted
+// into exponents.
+func fromTerm(coefficient fr.Element, atoms []Expr) Expr {
+	var factors []Expr
+	//
+	if !coefficient.IsOne() || len(atoms) == 0 {
+		factors = append(factors, &Constant{coefficient})
+	}
+	//
+	for i := 0; i < len(atoms); {
+		j := i + 1
+		// Count repeated atoms
+		for j < len(atoms) && exprKey(atoms[j]) == exprKey(atoms[i]) {
+			j++
+		}
+		//
+		if j-i == 1 {
+			factors = append(factors, atoms[i])
+		} else {
+			factors = append(factors, &Exp{atoms[i], uint64(j - i)})
+		}
+		//
+		i = j
+	}
+	//
+	if len(factors) == 1 {
+		return factors[0]
+	}
+	//
+	return &Mul{factors}
+}
+
+// Determine the number of nodes in a given expression.
+func exprSize(e Expr) uint {
+	var args []Expr
+	//
+	switch e := e.(type) {
+	case *Add:
+		args = e.Args
+	case *Sub:
+		args = e.Args
+	case *Mul:
+		args = e.Args
+	case *Bitwise:
+		args = e.Args
+	case *Exp:
+		args = []Expr{e.Arg}
+	case *Normalise:
+		args = []Expr{e.Arg}
+	}
+	//
+	size := uint(1)
+	//
+	for _, arg := range args {
+		size += exprSize(arg)
+	}
+	//
+	return size
+}
+
+// Apply a given function to each of a given set of expressions, producing a new
+// set of expressions.
+func mapExprs(args []Expr, fn func(Expr) Expr) []Expr {
+	nargs := make([]Expr, len(args))
+	//
+	for i, arg := range args {
+		nargs[i] = fn(arg)
+	}
+	//
+	return nargs
+}
+
+// ============================================================================
+// Common Subexpression Elimination
+// ============================================================================
+
+// Eliminate common subexpressions using a given table of those subexpressions
+// encountered already (e.g. in other constraints of the same module).  The
+// operands of commutative operators are first sorted to ensure equivalent
+// subexpressions are identified, after which any subexpression already in the
+// table is replaced by its existing representation.
+func eliminateCommonSubexpressions(e Expr, table map[string]Expr) Expr {
+	switch e := e.(type) {
+	case *Add:
+		return internExpr(&Add{sortByKey(mapExprs(e.Args, cse(table)))}, table)
+	case *Sub:
+		return internExpr(&Sub{mapExprs(e.Args, cse(table))}, table)
+	case *Mul:
+		return internExpr(&Mul{sortByKey(mapExprs(e.Args, cse(table)))}, table)
+	case *Exp:
+		return internExpr(&Exp{eliminateCommonSubexpressions(e.Arg, table), e.Pow}, table)
+	case *Normalise:
+		return internExpr(&Normalise{eliminateCommonSubexpressions(e.Arg, table)}, table)
+	case *Bitwise:
+		return internExpr(&Bitwise{e.Op, e.Width, mapExprs(e.Args, cse(table))}, table)
+	default:
+		return internExpr(e, table)
+	}
+}
+
+func cse(table map[string]Expr) func(Expr) Expr {
+	return func(e Expr) Expr {
+		return eliminateCommonSubexpressions(e, table)
+	}
+}
+
+// Return the existing representation of a given expression (if one exists),
+// otherwise record this as the representation.
+func internExpr(e Expr, table map[string]Expr) Expr {
+	key := exprKey(e)
+	//
+	if existing, ok := table[key]; ok {
+		return existing
+	}
+	//
+	table[key] = e
+	//
+	return e
+}
+
+// Sort a given set of (commutative) operands by key, where constants are placed
+// first by convention.
+func sortByKey(args []Expr) []Expr {
+	slices.SortStableFunc(args, func(l Expr, r Expr) int {
+		_, lconst := l.(*Constant)
+		_, rconst := r.(*Constant)
+		//
+		if lconst != rconst && lconst {
+			return -1
+		} else if lconst != rconst {
+			return 1
+		}
+		//
+		return strings.Compare(exprKey(l), exprKey(r))
+	})
+	//
+	return args
+}
+
+// Determine a key which uniquely identifies a given expression.  This differs
+// from its Lisp representation, since that is not guaranteed to be unique (e.g.
+// columns with the same name in different modules).
+func exprKey(e Expr) string {
+	switch e := e.(type) {
+	case *Add:
+		return naryKey("+", e.Args)
+	case *Sub:
+		return naryKey("-", e.Args)
+	case *Mul:
+		return naryKey("*", e.Args)
+	case *Exp:
+		return fmt.Sprintf("(^ %s %d)", exprKey(e.Arg), e.Pow)
+	case *Normalise:
+		return fmt.Sprintf("(~ %s)", exprKey(e.Arg))
+	case *Bitwise:
+		return naryKey(fmt.Sprintf("%s:u%d", e.Op, e.Width), e.Args)
+	case *Constant:
+		return e.Value.String()
+	case *ColumnAccess:
+		return fmt.Sprintf("#%d@%d", e.Column, e.Shift)
+	}
+	// Should be unreachable
+	panic(fmt.Sprintf("unknown expression: %v", e))
+}
+
+func naryKey(op string, args []Expr) string {
+	var builder strings.Builder
+	//
+	builder.WriteString("(")
+	builder.WriteString(op)
+	//
+	for _, arg := range args {
+		builder.WriteString(" ")
+		builder.WriteString(exprKey(arg))
+	}
+	//
+	builder.WriteString(")")
+	//
+	return builder.String()
+}
@@ -37,6 +37,9 @@ type Permutation = *assignment.SortedPermutation
 // Interleaving captures the notion of an interleaving at the MIR level.
 type Interleaving = *assignment.Interleaving
 
+// ComputedColumn captures the notion of a computed column at the MIR level.
+type ComputedColumn = *assignment.ComputedColumn[Expr]
+
 // Schema for MIR traces
 type Schema struct {
 	// The modules of the schema
@@ -106,35 +109,48 @@ func (p *Schema) AddAssignment(c schema.Assignment) uint {
 
 // AddLookupConstraint appends a new lookup constraint.
 func (p *Schema) AddLookupConstraint(handle string, source trace.Context, target trace.Context,
-	sources []Expr, targets []Expr) {
+	sources []Expr, targets []Expr, sourceSelector util.Option[Expr],
+	targetSelector util.Option[Expr]) LookupConstraint {
 	if len(targets) != len(sources) {
 		panic("differeng number of target / source lookup columns")
 	}
 	// TODO: sanity source columns are in the same module, and likewise target
 	// columns (though they don't have to be in the same column together).
-	p.constraints = append(p.constraints,
-		constraint.NewLookupConstraint(handle, source, target, sources, targets))
+	lookup := constraint.NewLookupConstraint(handle, source, target, sources, targets, sourceSelector, targetSelector)
+	p.constraints = append(p.constraints, lookup)
+
+	return lookup
 }
 
 // AddVanishingConstraint appends a new vanishing constraint.
-func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, domain *int, expr Expr) {
+func (p *Schema) AddVanishingConstraint(handle string, context trace.Context, domain *constraint.Domain,
+	expr Expr) VanishingConstraint {
 	if context.Module() >= uint(len(p.modules)) {
 		panic(fmt.Sprintf("invalid module index (%d)", context.Module()))
 	}
 
-	p.constraints = append(p.constraints,
-		constraint.NewVanishingConstraint(handle, context, domain, constraint.ZeroTest[Expr]{Expr: expr}))
+	vanishing := constraint.NewVanishingConstraint(handle, context, domain, constraint.ZeroTest[Expr]{Expr: expr})
+	p.constraints = append(p.constraints, vanishing)
+
+	return vanishing
 }
 
 // AddRangeConstraint appends a new range constraint.
-func (p *Schema) AddRangeConstraint(handle string, context trace.Context, expr Expr, bound fr.Element) {
-	p.constraints = append(p.constraints, constraint.NewRangeConstraint(handle, context, expr, bound))
+func (p *Schema) AddRangeConstraint(handle string, context trace.Context, expr Expr,
+	bound fr.Element) RangeConstraint {
+	rangeConstraint := constraint.NewRangeConstraint(handle, context, expr, bound)
+	p.constraints = append(p.constraints, rangeConstraint)
+
+	return rangeConstraint
 }
 
 // AddPropertyAssertion appends a new property assertion.
-func (p *Schema) AddPropertyAssertion(handle string, context trace.Context, expr Expr) {
+func (p *Schema) AddPropertyAssertion(handle string, context trace.Context, expr Expr) PropertyAssertion {
 	test := constraint.ZeroTest[Expr]{Expr: expr}
-	p.assertions = append(p.assertions, schema.NewPropertyAssertion(handle, context, test))
+	assertion := schema.NewPropertyAssertion(handle, context, test)
+	p.assertions = append(p.assertions, assertion)
+
+	return assertion
 }
 
 // ============================================================================
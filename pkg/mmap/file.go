@@ -49,3 +49,62 @@ func NewFile(path string, minimumSizeBytes int) (*File, error) {
 		SectorCount:     sectorCount,
 	}, nil
 }
+
+// OpenFile opens an existing file as a read-only memory-mapped file.  Unlike
+// NewFile, the file is neither created nor resized, and any attempt to write to
+// it will fail.  The underlying file descriptor is closed before returning,
+// regardless of whether or not this succeeds.
+func OpenFile(path string) (*File, error) {
+	fd, err := unix.Open(path, unix.O_RDONLY, 0)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "failed to open file %#v", path)
+	}
+
+	file, err := mapFile(fd, path)
+	// The mapping (if any) remains valid after the file descriptor is closed.
+	if cerr := unix.Close(fd); err != nil {
+		return nil, err
+	} else if cerr != nil {
+		// Ignore error from unmapping, since we are already failing.
+		_ = file.Close()
+		return nil, pkgErrors.Wrapf(cerr, "failed to close file %#v", path)
+	}
+
+	return file, nil
+}
+
+// Map the entire contents of an open file descriptor into memory.
+func mapFile(fd int, path string) (*File, error) {
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return nil, pkgErrors.Wrapf(err, "failed to obtain size of file %#v", path)
+	}
+
+	sectorSizeBytes := int(stat.Blksize)
+	sectorCount := (stat.Size + int64(stat.Blksize) - 1) / int64(stat.Blksize)
+	// Memory mapping an empty file is not permitted.
+	bd := &BlockDevice{fd, []byte{}}
+
+	if stat.Size > 0 {
+		var err error
+		if bd, err = NewBlockDevice(fd, int(stat.Size)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &File{
+		BlockDevice:     bd,
+		SectorSizeBytes: sectorSizeBytes,
+		SectorCount:     sectorCount,
+	}, nil
+}
+
+// Close unmaps this file.  Any data obtained from the underlying block device
+// must not be accessed after the file is closed.
+func (f *File) Close() error {
+	if len(f.BlockDevice.Data) == 0 {
+		return nil
+	}
+
+	return unix.Munmap(f.BlockDevice.Data)
+}
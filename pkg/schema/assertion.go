@@ -16,6 +16,8 @@ type AssertionFailure struct {
 	constraint Testable
 	// Row on which the constraint failed
 	row uint
+	// Origin of the failing assertion
+	source *Source
 }
 
 // Handle returns the handle of this constraint
@@ -45,6 +47,12 @@ func (p *AssertionFailure) RequiredCells(trace tr.Trace) *util.AnySortedSet[tr.C
 	return p.constraint.RequiredCells(int(p.row), trace)
 }
 
+// Source returns the declaration from which the failing assertion originated
+// (or nil if this is unknown).
+func (p *AssertionFailure) Source() *Source {
+	return p.source
+}
+
 func (p *AssertionFailure) String() string {
 	return p.Message()
 }
@@ -69,11 +77,13 @@ type PropertyAssertion[T Testable] struct {
 	// on a given trace --- we are not restricted to expressions
 	// which can be arithmetised.
 	property T
+	// Origin of this assertion (if known)
+	source *Source
 }
 
 // NewPropertyAssertion constructs a new property assertion!
 func NewPropertyAssertion[T Testable](handle string, ctx tr.Context, property T) *PropertyAssertion[T] {
-	return &PropertyAssertion[T]{handle, ctx, property}
+	return &PropertyAssertion[T]{handle, ctx, property, nil}
 }
 
 // Handle returns the handle associated with this constraint.
@@ -90,6 +100,17 @@ func (p *PropertyAssertion[T]) Context() tr.Context {
 	return p.context
 }
 
+// Source returns the declaration from which this assertion originated (or nil
+// if this is unknown).
+func (p *PropertyAssertion[T]) Source() *Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this assertion originated.
+func (p *PropertyAssertion[T]) SetSource(source *Source) {
+	p.source = source
+}
+
 // Property returns the handle associated with this constraint.
 //
 //nolint:revive
@@ -97,23 +118,31 @@ func (p *PropertyAssertion[T]) Property() T {
 	return p.property
 }
 
+// RequiredColumns returns the set of columns on which this assertion depends.
+//
+//nolint:revive
+func (p *PropertyAssertion[T]) RequiredColumns() *util.SortedSet[uint] {
+	return p.property.RequiredColumns()
+}
+
 // Accepts checks whether a vanishing constraint evaluates to zero on every row
-// of a table. If so, return nil otherwise return an error.
+// of a table. If so, return nil otherwise return (up to limit) failures.
 //
 //nolint:revive
-func (p *PropertyAssertion[T]) Accepts(tr tr.Trace) Failure {
+func (p *PropertyAssertion[T]) Accepts(tr tr.Trace, limit uint) []Failure {
+	var failures []Failure
 	// Determine height of enclosing module
 	height := tr.Height(p.context)
 	// Iterate every row in the module
-	for k := uint(0); k < height; k++ {
+	for k := uint(0); k < height && uint(len(failures)) < limit; k++ {
 		// Check whether property holds (or was undefined)
 		if !p.property.TestAt(int(k), tr) {
 			// Evaluation failure
-			return &AssertionFailure{p.handle, p.property, k}
+			failures = append(failures, &AssertionFailure{p.handle, p.property, k, p.source})
 		}
 	}
-	// All good
-	return nil
+	// Done
+	return failures
 }
 
 // Lisp converts this constraint into an S-Expression.
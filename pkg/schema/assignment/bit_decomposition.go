@@ -0,0 +1,150 @@
+package assignment
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// BitDecomposition is part of the constraints for bitwise operations (e.g.
+// bitwise and) which are implemented using a decomposition of their arguments
+// into individual bits.  This is analogous to a byte decomposition, except that
+// each target column holds a single bit.
+type BitDecomposition struct {
+	// The source column being decomposed
+	source uint
+	// Target columns needed for decomposition
+	targets []sc.Column
+}
+
+// NewBitDecomposition creates a new bit decomposition of a given source column
+// into a given number of bits.
+func NewBitDecomposition(prefix string, context trace.Context, source uint, width uint) *BitDecomposition {
+	if width == 0 {
+		panic("zero bit decomposition encountered")
+	}
+	// Define type of bits
+	U1 := sc.NewUintType(1)
+	// Construct target names
+	targets := make([]sc.Column, width)
+
+	for i := uint(0); i < width; i++ {
+		name := fmt.Sprintf("%s:%d", prefix, i)
+		targets[i] = sc.NewColumn(context, name, U1)
+	}
+	// Done
+	return &BitDecomposition{source, targets}
+}
+
+// Source returns the column being decomposed by this bit decomposition.
+func (p *BitDecomposition) Source() uint {
+	return p.source
+}
+
+// ============================================================================
+// Declaration Interface
+// ============================================================================
+
+// Context returns the evaluation context for this declaration.
+func (p *BitDecomposition) Context() trace.Context {
+	return p.targets[0].Context()
+}
+
+// Columns returns the columns declared by this bit decomposition (in the order
+// of declaration).
+func (p *BitDecomposition) Columns() util.Iterator[sc.Column] {
+	return util.NewArrayIterator[sc.Column](p.targets)
+}
+
+// IsComputed Determines whether or not this declaration is computed.
+func (p *BitDecomposition) IsComputed() bool {
+	return true
+}
+
+// ============================================================================
+// Assignment Interface
+// ============================================================================
+
+// ComputeColumns computes the values of columns defined by this assignment.
+// This requires computing the value of each bit column in the decomposition.
+func (p *BitDecomposition) ComputeColumns(tr trace.Trace) ([]trace.ArrayColumn, error) {
+	// Calculate how many bits required.
+	n := len(p.targets)
+	// Identify source column
+	source := tr.Column(p.source)
+	// Determine height of column
+	height := tr.Height(source.Context())
+	// Determine padding values
+	padding := decomposeIntoBits(source.Padding(), n)
+	// Construct bit column data
+	cols := make([]trace.ArrayColumn, n)
+	// Initialise columns
+	for i := 0; i < n; i++ {
+		ith := p.targets[i]
+		// Construct a bit array for ith bit
+		data := util.NewFrArray(height, 1)
+		// Construct a bit column for ith bit
+		cols[i] = trace.NewArrayColumn(ith.Context(), ith.Name(), data, padding[i])
+	}
+	// Decompose each row of each column
+	for i := uint(0); i < height; i = i + 1 {
+		ith := decomposeIntoBits(source.Get(int(i)), n)
+		for j := 0; j < n; j++ {
+			cols[j].Data().Set(i, ith[j])
+		}
+	}
+	// Done
+	return cols, nil
+}
+
+// RequiredSpillage returns the minimum amount of spillage required to ensure
+// valid traces are accepted in the presence of arbitrary padding.
+func (p *BitDecomposition) RequiredSpillage() uint {
+	return uint(0)
+}
+
+// Dependencies returns the set of columns that this assignment depends upon.
+// That can include both input columns, as well as other computed columns.
+func (p *BitDecomposition) Dependencies() []uint {
+	return []uint{p.source}
+}
+
+// Decompose a given element into n bits in little endian form.  For example,
+// decomposing 6 into 3 bits gives [0,1,1].
+func decomposeIntoBits(val fr.Element, n int) []fr.Element {
+	var bi big.Int
+	// Construct return array
+	elements := make([]fr.Element, n)
+	// Convert value into a big integer
+	val.BigInt(&bi)
+	// Convert each bit into a field element
+	for i := 0; i < n; i++ {
+		elements[i] = fr.NewElement(uint64(bi.Bit(i)))
+	}
+	// Done
+	return elements
+}
+
+// ============================================================================
+// Lispify Interface
+// ============================================================================
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (p *BitDecomposition) Lisp(schema sc.Schema) sexp.SExp {
+	targets := sexp.EmptyList()
+	for _, t := range p.targets {
+		targets.Append(sexp.NewSymbol(t.QualifiedName(schema)))
+	}
+
+	return sexp.NewList(
+		[]sexp.SExp{sexp.NewSymbol("decompose-bits"),
+			targets,
+			sexp.NewSymbol(sc.QualifiedName(schema, p.source)),
+		})
+}
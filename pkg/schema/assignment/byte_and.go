@@ -0,0 +1,139 @@
+package assignment
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ByteAnd is part of the constraints for bitwise operations (e.g. bitwise and),
+// and computes the bitwise conjunction of corresponding bytes from two byte
+// decompositions.  The resulting bytes are not constrained by this assignment;
+// rather, each triple of bytes is looked up in a table of byte conjunctions
+// (see ByteAndTable).
+type ByteAnd struct {
+	// Bytes of the left-hand operand (least significant first).
+	lhs []uint
+	// Bytes of the right-hand operand (least significant first).
+	rhs []uint
+	// Bytes of the result (least significant first).
+	targets []sc.Column
+}
+
+// NewByteAnd creates a new conjunction of the bytes of two operands, where the
+// ith target column holds the conjunction of the ith byte of either operand.
+func NewByteAnd(prefix string, context trace.Context, lhs []uint, rhs []uint) *ByteAnd {
+	if len(lhs) == 0 || len(lhs) != len(rhs) {
+		panic("invalid byte conjunction encountered")
+	}
+	// Define type of bytes
+	U8 := sc.NewUintType(8)
+	// Construct target names
+	targets := make([]sc.Column, len(lhs))
+
+	for i := range lhs {
+		name := fmt.Sprintf("%s:%d", prefix, i)
+		targets[i] = sc.NewColumn(context, name, U8)
+	}
+	// Done
+	return &ByteAnd{lhs, rhs, targets}
+}
+
+// Lhs returns the bytes of the left-hand operand of this conjunction.
+func (p *ByteAnd) Lhs() []uint {
+	return p.lhs
+}
+
+// Rhs returns the bytes of the right-hand operand of this conjunction.
+func (p *ByteAnd) Rhs() []uint {
+	return p.rhs
+}
+
+// ============================================================================
+// Declaration Interface
+// ============================================================================
+
+// Context returns the evaluation context for this declaration.
+func (p *ByteAnd) Context() trace.Context {
+	return p.targets[0].Context()
+}
+
+// Columns returns the columns declared by this byte conjunction (in the order
+// of declaration).
+func (p *ByteAnd) Columns() util.Iterator[sc.Column] {
+	return util.NewArrayIterator[sc.Column](p.targets)
+}
+
+// IsComputed Determines whether or not this declaration is computed.
+func (p *ByteAnd) IsComputed() bool {
+	return true
+}
+
+// ============================================================================
+// Assignment Interface
+// ============================================================================
+
+// ComputeColumns computes the values of columns defined by this assignment.
+// This requires computing the conjunction of each pair of bytes on every row.
+func (p *ByteAnd) ComputeColumns(tr trace.Trace) ([]trace.ArrayColumn, error) {
+	// Determine height of columns
+	height := tr.Height(p.Context())
+	// Construct byte column data
+	cols := make([]trace.ArrayColumn, len(p.targets))
+	//
+	for i, ith := range p.targets {
+		lhs := tr.Column(p.lhs[i])
+		rhs := tr.Column(p.rhs[i])
+		data := util.NewFrArray(height, 8)
+		// Conjoin each row
+		for j := uint(0); j < height; j++ {
+			data.Set(j, andBytes(lhs.Get(int(j)), rhs.Get(int(j))))
+		}
+		//
+		padding := andBytes(lhs.Padding(), rhs.Padding())
+		cols[i] = trace.NewArrayColumn(ith.Context(), ith.Name(), data, padding)
+	}
+	// Done
+	return cols, nil
+}
+
+// RequiredSpillage returns the minimum amount of spillage required to ensure
+// valid traces are accepted in the presence of arbitrary padding.
+func (p *ByteAnd) RequiredSpillage() uint {
+	return uint(0)
+}
+
+// Dependencies returns the set of columns that this assignment depends upon.
+// That can include both input columns, as well as other computed columns.
+func (p *ByteAnd) Dependencies() []uint {
+	return append(append([]uint{}, p.lhs...), p.rhs...)
+}
+
+// Compute the bitwise conjunction of two bytes.
+func andBytes(lhs fr.Element, rhs fr.Element) fr.Element {
+	return fr.NewElement(lhs.Uint64() & rhs.Uint64() & 0xff)
+}
+
+// ============================================================================
+// Lispify Interface
+// ============================================================================
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (p *ByteAnd) Lisp(schema sc.Schema) sexp.SExp {
+	targets := sexp.EmptyList()
+	lhs := sexp.EmptyList()
+	rhs := sexp.EmptyList()
+	//
+	for i, t := range p.targets {
+		targets.Append(sexp.NewSymbol(t.QualifiedName(schema)))
+		lhs.Append(sexp.NewSymbol(sc.QualifiedName(schema, p.lhs[i])))
+		rhs.Append(sexp.NewSymbol(sc.QualifiedName(schema, p.rhs[i])))
+	}
+	//
+	return sexp.NewList([]sexp.SExp{sexp.NewSymbol("and-bytes"), targets, lhs, rhs})
+}
@@ -0,0 +1,167 @@
+package assignment
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ByteAndTable is a table of byte conjunctions, consisting of three columns
+// holding bytes x and y, along with their conjunction x & y.  This is the
+// target of lookups constraining byte conjunctions (see ByteAnd).  Rather than
+// every possible pair of bytes, the table contains only those pairs actually
+// being conjoined in a given trace (which are taken from a given set of source
+// columns).  Hence, this table does not constrain its own values.
+type ByteAndTable struct {
+	// Left-hand bytes being conjoined.
+	lhs []uint
+	// Right-hand bytes being conjoined.
+	rhs []uint
+	// Target columns of the table.
+	targets []sc.Column
+}
+
+// NewByteAndTable creates a new table of byte conjunctions for those pairs of
+// bytes held in the given source columns.
+func NewByteAndTable(context trace.Context, lhs []uint, rhs []uint) *ByteAndTable {
+	if len(lhs) != len(rhs) {
+		panic("invalid byte conjunction table encountered")
+	}
+	// Define type of bytes
+	U8 := sc.NewUintType(8)
+	// Construct targets
+	targets := []sc.Column{
+		sc.NewColumn(context, "lhs", U8),
+		sc.NewColumn(context, "rhs", U8),
+		sc.NewColumn(context, "and", U8),
+	}
+	// Done
+	return &ByteAndTable{lhs, rhs, targets}
+}
+
+// ============================================================================
+// Declaration Interface
+// ============================================================================
+
+// Context returns the evaluation context for this declaration.
+func (p *ByteAndTable) Context() trace.Context {
+	return p.targets[0].Context()
+}
+
+// Columns returns the columns declared by this table (in the order of
+// declaration).
+func (p *ByteAndTable) Columns() util.Iterator[sc.Column] {
+	return util.NewArrayIterator[sc.Column](p.targets)
+}
+
+// IsComputed Determines whether or not this declaration is computed.
+func (p *ByteAndTable) IsComputed() bool {
+	return true
+}
+
+// ============================================================================
+// Assignment Interface
+// ============================================================================
+
+// ComputeColumns computes the values of columns defined by this assignment.
+// This requires determining every distinct pair of bytes being conjoined
+// (including those arising from padding), which are then ordered to ensure the
+// table is deterministic.
+func (p *ByteAndTable) ComputeColumns(tr trace.Trace) ([]trace.ArrayColumn, error) {
+	var (
+		pairs  [256 * 256]bool
+		height uint
+	)
+	// Mark every pair of bytes being conjoined
+	for i := range p.lhs {
+		lhs := tr.Column(p.lhs[i])
+		rhs := tr.Column(p.rhs[i])
+		//
+		for j := 0; j < int(tr.Height(lhs.Context())); j++ {
+			height += markBytePair(&pairs, lhs.Get(j), rhs.Get(j))
+		}
+		//
+		height += markBytePair(&pairs, lhs.Padding(), rhs.Padding())
+	}
+	// Construct table
+	data := make([]util.FrArray, 3)
+	//
+	for i := range data {
+		data[i] = util.NewFrArray(height, 8)
+	}
+	//
+	for pair, row := 0, uint(0); pair < len(pairs); pair++ {
+		if pairs[pair] {
+			lhs, rhs := uint64(pair/256), uint64(pair%256)
+			data[0].Set(row, fr.NewElement(lhs))
+			data[1].Set(row, fr.NewElement(rhs))
+			data[2].Set(row, fr.NewElement(lhs&rhs))
+			row++
+		}
+	}
+	// Padding is always valid
+	var zero fr.Element
+	//
+	return []trace.ArrayColumn{
+		trace.NewArrayColumn(p.targets[0].Context(), p.targets[0].Name(), data[0], zero),
+		trace.NewArrayColumn(p.targets[1].Context(), p.targets[1].Name(), data[1], zero),
+		trace.NewArrayColumn(p.targets[2].Context(), p.targets[2].Name(), data[2], zero),
+	}, nil
+}
+
+// Mark a given pair of bytes as being present, returning one if this pair was
+// not already marked (and zero otherwise).  Values which are not bytes are
+// ignored, since they cannot be in the table.
+func markBytePair(pairs *[256 * 256]bool, lhs fr.Element, rhs fr.Element) uint {
+	if !lhs.IsUint64() || !rhs.IsUint64() || lhs.Uint64() > 255 || rhs.Uint64() > 255 {
+		return 0
+	}
+	//
+	pair := lhs.Uint64()*256 + rhs.Uint64()
+	//
+	if pairs[pair] {
+		return 0
+	}
+	//
+	pairs[pair] = true
+	//
+	return 1
+}
+
+// RequiredSpillage returns the minimum amount of spillage required to ensure
+// valid traces are accepted in the presence of arbitrary padding.
+func (p *ByteAndTable) RequiredSpillage() uint {
+	return uint(0)
+}
+
+// Dependencies returns the set of columns that this assignment depends upon.
+// That can include both input columns, as well as other computed columns.
+func (p *ByteAndTable) Dependencies() []uint {
+	return append(append([]uint{}, p.lhs...), p.rhs...)
+}
+
+// ============================================================================
+// Lispify Interface
+// ============================================================================
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (p *ByteAndTable) Lisp(schema sc.Schema) sexp.SExp {
+	targets := sexp.EmptyList()
+	sources := sexp.EmptyList()
+	//
+	for _, t := range p.targets {
+		targets.Append(sexp.NewSymbol(t.QualifiedName(schema)))
+	}
+	//
+	for i := range p.lhs {
+		sources.Append(sexp.NewList([]sexp.SExp{
+			sexp.NewSymbol(sc.QualifiedName(schema, p.lhs[i])),
+			sexp.NewSymbol(sc.QualifiedName(schema, p.rhs[i])),
+		}))
+	}
+	//
+	return sexp.NewList([]sexp.SExp{sexp.NewSymbol("and-table"), targets, sources})
+}
@@ -37,6 +37,11 @@ func NewByteDecomposition(prefix string, context trace.Context, source uint, wid
 	return &ByteDecomposition{source, targets}
 }
 
+// Source returns the column being decomposed by this byte decomposition.
+func (p *ByteDecomposition) Source() uint {
+	return p.source
+}
+
 // ============================================================================
 // Declaration Interface
 // ============================================================================
@@ -18,15 +18,18 @@ type ComputedColumn[E sc.Evaluable] struct {
 	// The computation which accepts a given trace and computes
 	// the value of this column at a given row.
 	expr E
+	// Declaration from which this computed column originated (or nil if this
+	// is unknown).
+	source *sc.Source
 }
 
-// NewComputedColumn constructs a new computed column with a given name and
-// determining expression.  More specifically, that expression is used to
+// NewComputedColumn constructs a new computed column with a given name, type
+// and determining expression.  More specifically, that expression is used to
 // compute the values for this column during trace expansion.
-func NewComputedColumn[E sc.Evaluable](context trace.Context, name string, expr E) *ComputedColumn[E] {
-	column := sc.NewColumn(context, name, &sc.FieldType{})
-	// FIXME: Determine computed columns type?
-	return &ComputedColumn[E]{column, expr}
+func NewComputedColumn[E sc.Evaluable](context trace.Context, name string, datatype sc.Type,
+	expr E) *ComputedColumn[E] {
+	column := sc.NewColumn(context, name, datatype)
+	return &ComputedColumn[E]{column, expr, nil}
 }
 
 // Name returns the name of this computed column.
@@ -34,6 +37,22 @@ func (p *ComputedColumn[E]) Name() string {
 	return p.target.Name()
 }
 
+// Expr returns the expression used to compute the values of this column.
+func (p *ComputedColumn[E]) Expr() E {
+	return p.expr
+}
+
+// Source returns the declaration from which this computed column originated
+// (or nil if this is unknown).
+func (p *ComputedColumn[E]) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this computed column originated.
+func (p *ComputedColumn[E]) SetSource(source *sc.Source) {
+	p.source = source
+}
+
 // ============================================================================
 // Declaration Interface
 // ============================================================================
@@ -45,7 +64,6 @@ func (p *ComputedColumn[E]) Context() trace.Context {
 
 // Columns returns the columns declared by this computed column.
 func (p *ComputedColumn[E]) Columns() util.Iterator[sc.Column] {
-	// TODO: figure out appropriate type for computed column
 	return util.NewUnitIterator[sc.Column](p.target)
 }
 
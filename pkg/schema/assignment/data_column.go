@@ -19,11 +19,14 @@ type DataColumn struct {
 	// true for the input columns for any valid trace and, furthermore, every
 	// computed column should have values of this type.
 	datatype sc.Type
+	// Declaration from which this data column originated (or nil if this is
+	// unknown).
+	source *sc.Source
 }
 
 // NewDataColumn constructs a new data column with a given name.
 func NewDataColumn(context trace.Context, name string, base sc.Type) *DataColumn {
-	return &DataColumn{context, name, base}
+	return &DataColumn{context, name, base, nil}
 }
 
 // Context returns the evaluation context for this column.
@@ -46,6 +49,17 @@ func (p *DataColumn) Type() sc.Type {
 	return p.datatype
 }
 
+// Source returns the declaration from which this data column originated (or
+// nil if this is unknown).
+func (p *DataColumn) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this data column originated.
+func (p *DataColumn) SetSource(source *sc.Source) {
+	p.source = source
+}
+
 // ============================================================================
 // Declaration Interface
 // ============================================================================
@@ -18,6 +18,9 @@ type Interleaving struct {
 	target sc.Column
 	// The source columns
 	sources []uint
+	// Declaration from which this interleaving originated (or nil if this is
+	// unknown).
+	source *sc.Source
 }
 
 // NewInterleaving constructs a new interleaving assignment.
@@ -29,7 +32,7 @@ func NewInterleaving(context tr.Context, name string, sources []uint, datatype s
 	// Fixme: determine interleaving type
 	target := sc.NewColumn(context, name, datatype)
 
-	return &Interleaving{target, sources}
+	return &Interleaving{target, sources, nil}
 }
 
 // Module returns the module which encloses this interleaving.
@@ -43,6 +46,17 @@ func (p *Interleaving) Sources() []uint {
 	return p.sources
 }
 
+// Source returns the declaration from which this interleaving originated (or
+// nil if this is unknown).
+func (p *Interleaving) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this interleaving originated.
+func (p *Interleaving) SetSource(source *sc.Source) {
+	p.source = source
+}
+
 // ============================================================================
 // Declaration Interface
 // ============================================================================
@@ -13,7 +13,9 @@ import (
 // LexicographicSort provides the necessary computation for filling out columns
 // added to enforce lexicographic sorting constraints between one or more source
 // columns.  Specifically, a delta column is required along with one selector
-// column (binary) for each source column.
+// column (binary) for each source column.  When sorting is guarded by a
+// selector column, rows whose preceding row is unselected are left unset (i.e.
+// since there is no ordering to enforce).
 type LexicographicSort struct {
 	// Context in which source and target columns to be located.  All target and
 	// source columns should be contained within this.
@@ -25,11 +27,14 @@ type LexicographicSort struct {
 	sources  []uint
 	signs    []bool
 	bitwidth uint
+	// Selector determining which rows are sorted (if present).
+	selector util.Option[uint]
 }
 
-// NewLexicographicSort constructs a new LexicographicSorting assignment.
+// NewLexicographicSort constructs a new LexicographicSorting assignment, which
+// is optionally guarded by a selector column.
 func NewLexicographicSort(prefix string, context tr.Context,
-	sources []uint, signs []bool, bitwidth uint) *LexicographicSort {
+	sources []uint, signs []bool, bitwidth uint, selector util.Option[uint]) *LexicographicSort {
 	//
 	targets := make([]sc.Column, len(sources)+1)
 	// Create delta column
@@ -40,7 +45,28 @@ func NewLexicographicSort(prefix string, context tr.Context,
 		targets[1+i] = sc.NewColumn(context, ithName, sc.NewUintType(1))
 	}
 
-	return &LexicographicSort{context, targets, sources, signs, bitwidth}
+	return &LexicographicSort{context, targets, sources, signs, bitwidth, selector}
+}
+
+// Sources returns the columns being sorted by this assignment.
+func (p *LexicographicSort) Sources() []uint {
+	return p.sources
+}
+
+// Signs returns the sorting direction for each of the columns being sorted.
+func (p *LexicographicSort) Signs() []bool {
+	return p.signs
+}
+
+// BitWidth returns the bitwidth of the delta column for this assignment.
+func (p *LexicographicSort) BitWidth() uint {
+	return p.bitwidth
+}
+
+// Selector returns the column which determines which rows are sorted (if
+// present).
+func (p *LexicographicSort) Selector() util.Option[uint] {
+	return p.selector
 }
 
 // ============================================================================
@@ -101,7 +127,9 @@ func (p *LexicographicSort) ComputeColumns(trace tr.Trace) ([]tr.ArrayColumn, er
 	}
 
 	for i := uint(0); i < nrows; i++ {
-		set := false
+		// Rows following an unselected row are not sorted, and hence are
+		// treated as though a winner was already decided.
+		set := !p.isSortedAt(int(i), trace)
 		// Initialise delta to zero
 		delta.Set(i, zero)
 		// Decide which row is the winner (if any)
@@ -132,9 +160,24 @@ func (p *LexicographicSort) ComputeColumns(trace tr.Trace) ([]tr.ArrayColumn, er
 	return cols, nil
 }
 
+// Determine whether a given row is sorted with respect to its preceding row,
+// which is the case unless the preceding row is unselected.
+func (p *LexicographicSort) isSortedAt(k int, trace tr.Trace) bool {
+	if p.selector.HasValue() {
+		prev := trace.Column(p.selector.Unwrap()).Get(k - 1)
+		return !prev.IsZero()
+	}
+	//
+	return true
+}
+
 // Dependencies returns the set of columns that this assignment depends upon.
 // That can include both input columns, as well as other computed columns.
 func (p *LexicographicSort) Dependencies() []uint {
+	if p.selector.HasValue() {
+		return append(append([]uint{}, p.sources...), p.selector.Unwrap())
+	}
+	//
 	return p.sources
 }
 
@@ -164,9 +207,19 @@ func (p *LexicographicSort) Lisp(schema sc.Schema) sexp.SExp {
 		sources.Append(sexp.NewSymbol(ith))
 	}
 
+	// Without selector
+	if !p.selector.HasValue() {
+		return sexp.NewList([]sexp.SExp{
+			sexp.NewSymbol("lexicographic-order"),
+			targets,
+			sources,
+		})
+	}
+	// With selector
 	return sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("lexicographic-order"),
 		targets,
+		sexp.NewSymbol(sc.QualifiedName(schema, p.selector.Unwrap())),
 		sources,
 	})
 }
@@ -0,0 +1,238 @@
+package assignment
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// LookupMultiplicity generates a new column which counts, for each row of the
+// target columns of a lookup, how many rows of the source columns match it.
+// This is required for lookups to be proven using a log-derivative argument.
+// When the same tuple occurs on more than one (selected) target row, all
+// matching source rows are counted against the first such row, and the
+// remainder are zero.  For example, consider a lookup from X=[1,2,1] into
+// Y=[1,2,2].  Then, the multiplicity column M has the values M=[2,1,0].
+type LookupMultiplicity struct {
+	// The new (multiplicity) column, which resides in the target module.
+	target sc.Column
+	// Source columns of the lookup
+	sources []uint
+	// Target columns of the lookup
+	targets []uint
+	// Selector determining which source rows participate (if present).
+	sourceSelector util.Option[uint]
+	// Selector determining which target rows participate (if present).
+	targetSelector util.Option[uint]
+}
+
+// NewLookupMultiplicity constructs a new multiplicity column for a lookup from
+// a given set of source columns into a given set of target columns, where
+// either side is optionally guarded by a selector column.
+func NewLookupMultiplicity(context trace.Context, name string, sources []uint, targets []uint,
+	sourceSelector util.Option[uint], targetSelector util.Option[uint]) *LookupMultiplicity {
+	if len(targets) != len(sources) {
+		panic("differeng number of target / source lookup columns")
+	}
+	//
+	column := sc.NewColumn(context, name, &sc.FieldType{})
+	//
+	return &LookupMultiplicity{column, sources, targets, sourceSelector, targetSelector}
+}
+
+// Sources returns the source columns of the lookup being counted.
+func (p *LookupMultiplicity) Sources() []uint {
+	return p.sources
+}
+
+// Targets returns the target columns of the lookup being counted.
+func (p *LookupMultiplicity) Targets() []uint {
+	return p.targets
+}
+
+// SourceSelector returns the column which determines which source rows
+// participate in the lookup (if present).
+func (p *LookupMultiplicity) SourceSelector() util.Option[uint] {
+	return p.sourceSelector
+}
+
+// TargetSelector returns the column which determines which target rows
+// participate in the lookup (if present).
+func (p *LookupMultiplicity) TargetSelector() util.Option[uint] {
+	return p.targetSelector
+}
+
+// ============================================================================
+// Declaration Interface
+// ============================================================================
+
+// Context returns the evaluation context for this multiplicity column.
+func (p *LookupMultiplicity) Context() trace.Context {
+	return p.target.Context()
+}
+
+// Columns returns the column declared by this multiplicity column.
+func (p *LookupMultiplicity) Columns() util.Iterator[sc.Column] {
+	return util.NewUnitIterator(p.target)
+}
+
+// IsComputed Determines whether or not this declaration is computed (which it
+// is).
+func (p *LookupMultiplicity) IsComputed() bool {
+	return true
+}
+
+// ============================================================================
+// Assignment Interface
+// ============================================================================
+
+// RequiredSpillage returns the minimum amount of spillage required to ensure
+// valid traces are accepted in the presence of arbitrary padding.
+func (p *LookupMultiplicity) RequiredSpillage() uint {
+	return uint(0)
+}
+
+// ComputeColumns computes the values of columns defined by this assignment.
+// This requires counting the number of (selected) source rows matching each
+// (selected) target row.
+func (p *LookupMultiplicity) ComputeColumns(tr trace.Trace) ([]trace.ArrayColumn, error) {
+	var (
+		one        = fr.One()
+		tgt_ctx    = p.target.Context()
+		tgt_height = tr.Height(tgt_ctx)
+		// Determine source context from first source column
+		src_ctx    = tr.Column(p.sources[0]).Context()
+		src_height = tr.Height(src_ctx)
+		// Maps each target tuple to the first row on which it occurs.
+		rows = make(map[string]uint, tgt_height)
+		// Construct empty array
+		data = util.NewFrArray(tgt_height, 256)
+	)
+	// Identify first (selected) row of each target tuple
+	for i := uint(0); i < tgt_height; i++ {
+		if selectedAt(int(i), p.targetSelector, tr) {
+			key := tupleAt(int(i), p.targets, tr)
+			if _, ok := rows[key]; !ok {
+				rows[key] = i
+			}
+		}
+	}
+	// Count (selected) source rows against matching target rows.  Observe that
+	// source rows without a matching target row are ignored here, since the
+	// lookup itself will fail for them.
+	for i := uint(0); i < src_height; i++ {
+		if selectedAt(int(i), p.sourceSelector, tr) {
+			if row, ok := rows[tupleAt(int(i), p.sources, tr)]; ok {
+				count := data.Get(row)
+				data.Set(row, *count.Add(&count, &one))
+			}
+		}
+	}
+	// Padding rows present at this point (e.g. spillage) have already been
+	// counted above.  However, any padding added subsequently (e.g. by a
+	// prover) can only be accounted for when padding rows in the source match
+	// padding rows in the target, in which case each target padding row
+	// accounts for a fixed number of source padding rows (as determined by
+	// their length multipliers).  Otherwise, multiplicities must be recomputed
+	// after padding (see IsPaddingSensitive).
+	padding := fr.NewElement(0)
+	src_mul, tgt_mul := src_ctx.LengthMultiplier(), tgt_ctx.LengthMultiplier()
+	//
+	if selectedAt(-1, p.sourceSelector, tr) && selectedAt(-1, p.targetSelector, tr) &&
+		tupleAt(-1, p.sources, tr) == tupleAt(-1, p.targets, tr) && src_mul%tgt_mul == 0 {
+		padding = fr.NewElement(uint64(src_mul / tgt_mul))
+	}
+	//
+	col := trace.NewArrayColumn(p.target.Context(), p.target.Name(), data, padding)
+	//
+	return []trace.ArrayColumn{col}, nil
+}
+
+// IsPaddingSensitive determines whether or not this multiplicity must be
+// recomputed after padding.  This is always the case, since padding rows added
+// to the source must be counted against the matching target row (if any),
+// which is not necessarily a padding row.
+func (p *LookupMultiplicity) IsPaddingSensitive() bool {
+	return true
+}
+
+// Dependencies returns the set of columns that this assignment depends upon.
+// That can include both input columns, as well as other computed columns.
+func (p *LookupMultiplicity) Dependencies() []uint {
+	deps := append(append([]uint{}, p.sources...), p.targets...)
+	//
+	if p.sourceSelector.HasValue() {
+		deps = append(deps, p.sourceSelector.Unwrap())
+	}
+	//
+	if p.targetSelector.HasValue() {
+		deps = append(deps, p.targetSelector.Unwrap())
+	}
+	//
+	return deps
+}
+
+// Determine whether a given row is selected by an (optional) selector column.
+// Every row is selected when there is no selector.
+func selectedAt(k int, selector util.Option[uint], tr trace.Trace) bool {
+	if selector.HasValue() {
+		val := tr.Column(selector.Unwrap()).Get(k)
+		return !val.IsZero()
+	}
+	//
+	return true
+}
+
+// Construct a key representing the values of a given set of columns on a given
+// row.
+func tupleAt(k int, columns []uint, tr trace.Trace) string {
+	bytes := make([]byte, 0, 32*len(columns))
+	//
+	for _, c := range columns {
+		ith := tr.Column(c).Get(k)
+		ith_bytes := ith.Bytes()
+		bytes = append(bytes, ith_bytes[:]...)
+	}
+	//
+	return string(bytes)
+}
+
+// ============================================================================
+// Lispify Interface
+// ============================================================================
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+func (p *LookupMultiplicity) Lisp(schema sc.Schema) sexp.SExp {
+	target := sexp.NewSymbol(p.target.QualifiedName(schema))
+	sources := sexp.EmptyList()
+	targets := sexp.EmptyList()
+	// Convert source & target columns
+	for i := range p.sources {
+		sources.Append(sexp.NewSymbol(sc.QualifiedName(schema, p.sources[i])))
+		targets.Append(sexp.NewSymbol(sc.QualifiedName(schema, p.targets[i])))
+	}
+	// Without selectors
+	if !p.sourceSelector.HasValue() && !p.targetSelector.HasValue() {
+		return sexp.NewList([]sexp.SExp{sexp.NewSymbol("multiplicity"), target, targets, sources})
+	}
+	// With selectors, where an absent selector selects every row.
+	return sexp.NewList([]sexp.SExp{
+		sexp.NewSymbol("multiplicity"),
+		target,
+		selectorLisp(p.targetSelector, schema),
+		targets,
+		selectorLisp(p.sourceSelector, schema),
+		sources,
+	})
+}
+
+func selectorLisp(selector util.Option[uint], schema sc.Schema) sexp.SExp {
+	if selector.HasValue() {
+		return sexp.NewSymbol(sc.QualifiedName(schema, selector.Unwrap()))
+	}
+	//
+	return sexp.NewSymbol("1")
+}
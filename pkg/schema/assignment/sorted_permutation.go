@@ -2,7 +2,9 @@ package assignment
 
 import (
 	"fmt"
+	"math"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -10,7 +12,12 @@ import (
 )
 
 // SortedPermutation declares one or more columns as sorted permutations of
-// existing columns.
+// existing columns.  The source columns may reside in a different module from
+// the target columns and, furthermore, may be filtered by a selector column
+// such that only selected source rows are permuted.  In such case, an
+// additional target selector column is declared which identifies the target
+// rows arising from selected source rows (i.e. all rows other than padding
+// rows when the source selector is unset on its padding row).
 type SortedPermutation struct {
 	// Context where this data column is located.
 	context tr.Context
@@ -20,6 +27,12 @@ type SortedPermutation struct {
 	signs []bool
 	// The existing columns
 	sources []uint
+	// Selector determining which source rows are permuted (if present).
+	selector util.Option[uint]
+	// Selector identifying which target rows are permuted (if present).
+	targetSelector util.Option[sc.Column]
+	// Origin of this sorted permutation (if known)
+	source *sc.Source
 }
 
 // NewSortedPermutation creates a new sorted permutation
@@ -35,7 +48,24 @@ func NewSortedPermutation(context tr.Context, targets []sc.Column,
 		}
 	}
 
-	return &SortedPermutation{context, targets, signs, sources}
+	return &SortedPermutation{context, targets, signs, sources, util.None[uint](), util.None[sc.Column](), nil}
+}
+
+// NewFilteredSortedPermutation creates a new sorted permutation of only those
+// source rows selected by a given selector column, where a given target
+// selector column is declared to identify the resulting target rows.
+func NewFilteredSortedPermutation(context tr.Context, targets []sc.Column, signs []bool, sources []uint,
+	selector uint, targetSelector sc.Column) *SortedPermutation {
+	p := NewSortedPermutation(context, targets, signs, sources)
+	// Check target selector
+	if targetSelector.Context() != context {
+		panic("inconsistent evaluation contexts")
+	}
+	//
+	p.selector = util.Some(selector)
+	p.targetSelector = util.Some(targetSelector)
+	//
+	return p
 }
 
 // Module returns the module which encloses this sorted permutation.
@@ -43,12 +73,35 @@ func (p *SortedPermutation) Module() uint {
 	return p.context.Module()
 }
 
+// Source returns the declaration from which this sorted permutation originated (or nil
+// if this is unknown).
+func (p *SortedPermutation) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this sorted permutation originated.
+func (p *SortedPermutation) SetSource(source *sc.Source) {
+	p.source = source
+}
+
 // Sources returns the columns used by this sorted permutation to define the new
 // (sorted) columns.
 func (p *SortedPermutation) Sources() []uint {
 	return p.sources
 }
 
+// Selector returns the column which determines which source rows are permuted
+// (if present).
+func (p *SortedPermutation) Selector() util.Option[uint] {
+	return p.selector
+}
+
+// TargetSelector returns the column declared by this sorted permutation to
+// identify which target rows arise from selected source rows (if present).
+func (p *SortedPermutation) TargetSelector() util.Option[sc.Column] {
+	return p.targetSelector
+}
+
 // Signs returns the sorting direction for each column defined by this sorted permutation.
 func (p *SortedPermutation) Signs() []bool {
 	return p.signs
@@ -71,8 +124,13 @@ func (p *SortedPermutation) Context() tr.Context {
 }
 
 // Columns returns the columns declared by this sorted permutation (in the order
-// of declaration).
+// of declaration).  The target selector (if present) is declared last.
 func (p *SortedPermutation) Columns() util.Iterator[sc.Column] {
+	if p.targetSelector.HasValue() {
+		columns := append(append([]sc.Column{}, p.targets...), p.targetSelector.Unwrap())
+		return util.NewArrayIterator(columns)
+	}
+	//
 	return util.NewArrayIterator(p.targets)
 }
 
@@ -92,8 +150,11 @@ func (p *SortedPermutation) RequiredSpillage() uint {
 }
 
 // ComputeColumns computes the values of columns defined by this assignment.
-// This requires copying the data in the source columns, and sorting that data
-// according to the permutation criteria.
+// This requires copying the data in the (selected rows of the) source columns,
+// and sorting that data according to the permutation criteria.  When the
+// target module has no height of its own, its height is determined by the
+// number of rows permuted.  Otherwise, the number of rows permuted must match
+// its height.
 func (p *SortedPermutation) ComputeColumns(trace tr.Trace) ([]tr.ArrayColumn, error) {
 	data := make([]util.FrArray, len(p.sources))
 	// Construct target columns
@@ -101,19 +162,46 @@ func (p *SortedPermutation) ComputeColumns(trace tr.Trace) ([]tr.ArrayColumn, er
 		src := p.sources[i]
 		// Read column data
 		src_data := trace.Column(src).Data()
-		// Clone it to initialise permutation.
-		data[i] = src_data.Clone()
+		// Clone (selected rows) to initialise permutation.
+		if p.selector.HasValue() {
+			data[i] = filterRows(src_data, trace.Column(p.selector.Unwrap()).Data())
+		} else {
+			data[i] = src_data.Clone()
+		}
+	}
+	// Sanity check height of target module (if known)
+	height := trace.Modules().Nth(p.context.Module()).Height()
+	//
+	if nrows := data[0].Len(); height != math.MaxUint && nrows != height*p.context.LengthMultiplier() {
+		mod := trace.Modules().Nth(p.context.Module()).Name()
+		return nil, fmt.Errorf("permutation has %d rows, but module %s has %d", nrows, mod,
+			height*p.context.LengthMultiplier())
 	}
 	// Sort target columns
 	util.PermutationSort(data, p.signs)
 	// Physically construct the columns
 	cols := make([]tr.ArrayColumn, len(p.sources))
 	//
-	for i, iter := 0, p.Columns(); iter.HasNext(); i++ {
-		ith := iter.Next()
-		dstColName := ith.Name()
+	for i, ith := range p.targets {
 		srcCol := trace.Column(p.sources[i])
-		cols[i] = tr.NewArrayColumn(ith.Context(), dstColName, data[i], srcCol.Padding())
+		cols[i] = tr.NewArrayColumn(ith.Context(), ith.Name(), data[i], srcCol.Padding())
+	}
+	// Construct target selector (if applicable).  This is set on every row,
+	// except padding rows which only match selected source padding rows.
+	if p.targetSelector.HasValue() {
+		one, padding := fr.One(), fr.NewElement(0)
+		selector := p.targetSelector.Unwrap()
+		tgt_data := util.NewFrArray(data[0].Len(), 1)
+		//
+		for i := uint(0); i < tgt_data.Len(); i++ {
+			tgt_data.Set(i, one)
+		}
+		//
+		if src_padding := trace.Column(p.selector.Unwrap()).Padding(); !src_padding.IsZero() {
+			padding = one
+		}
+		//
+		cols = append(cols, tr.NewArrayColumn(selector.Context(), selector.Name(), tgt_data, padding))
 	}
 	//
 	return cols, nil
@@ -122,9 +210,33 @@ func (p *SortedPermutation) ComputeColumns(trace tr.Trace) ([]tr.ArrayColumn, er
 // Dependencies returns the set of columns that this assignment depends upon.
 // That can include both input columns, as well as other computed columns.
 func (p *SortedPermutation) Dependencies() []uint {
+	if p.selector.HasValue() {
+		return append(append([]uint{}, p.sources...), p.selector.Unwrap())
+	}
+	//
 	return p.sources
 }
 
+// Extract those rows of a given array which are selected by a given selector
+// array (i.e. for which the selector is non-zero).
+func filterRows(data util.FrArray, selector util.FrArray) util.FrArray {
+	var rows []uint
+	//
+	for i := uint(0); i < selector.Len(); i++ {
+		if ith := selector.Get(i); !ith.IsZero() {
+			rows = append(rows, i)
+		}
+	}
+	//
+	filtered := util.NewFrArray(uint(len(rows)), data.BitWidth())
+	//
+	for i, row := range rows {
+		filtered.Set(uint(i), data.Get(row))
+	}
+	//
+	return filtered
+}
+
 // ============================================================================
 // Lispify Interface
 // ============================================================================
@@ -151,9 +263,20 @@ func (p *SortedPermutation) Lisp(schema sc.Schema) sexp.SExp {
 		sources.Append(sexp.NewSymbol(ith))
 	}
 
+	// Without selectors
+	if !p.selector.HasValue() {
+		return sexp.NewList([]sexp.SExp{
+			sexp.NewSymbol("sort"),
+			targets,
+			sources,
+		})
+	}
+	// With selectors
 	return sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("sort"),
+		sexp.NewSymbol(p.targetSelector.Unwrap().QualifiedName(schema)),
 		targets,
+		sexp.NewSymbol(sc.QualifiedName(schema, p.selector.Unwrap())),
 		sources,
 	})
 }
@@ -33,35 +33,45 @@ type TraceBuilder struct {
 	parallel bool
 	// Specify the maximum size of any dispatched batch.
 	batchSize uint
+	// Cache from which previously computed columns can be reused during trace
+	// expansion (or nil if none).
+	cache *Cache
 }
 
 // NewTraceBuilder constructs a default trace builder.  The idea is that this
 // could then be customized as needed following the builder pattern.
 func NewTraceBuilder(schema Schema) TraceBuilder {
-	return TraceBuilder{schema, true, 0, true, math.MaxUint}
+	return TraceBuilder{schema, true, 0, true, math.MaxUint, nil}
 }
 
 // Expand updates a given builder configuration to perform trace expansion (or
 // not).
 func (tb TraceBuilder) Expand(flag bool) TraceBuilder {
-	return TraceBuilder{tb.schema, flag, tb.padding, tb.parallel, tb.batchSize}
+	return TraceBuilder{tb.schema, flag, tb.padding, tb.parallel, tb.batchSize, tb.cache}
 }
 
 // Padding updates a given builder configuration to use a given amount of padding
 func (tb TraceBuilder) Padding(padding uint) TraceBuilder {
-	return TraceBuilder{tb.schema, tb.expand, padding, tb.parallel, tb.batchSize}
+	return TraceBuilder{tb.schema, tb.expand, padding, tb.parallel, tb.batchSize, tb.cache}
 }
 
 // Parallel updates a given builder configuration to allow trace expansion to be
 // performed concurrently (or not).
 func (tb TraceBuilder) Parallel(parallel bool) TraceBuilder {
-	return TraceBuilder{tb.schema, tb.expand, tb.padding, parallel, tb.batchSize}
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, parallel, tb.batchSize, tb.cache}
 }
 
 // BatchSize sets the maximum number of batches to run in parallel during trace
 // expansion.
 func (tb TraceBuilder) BatchSize(batchSize uint) TraceBuilder {
-	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, batchSize}
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, batchSize, tb.cache}
+}
+
+// Cache updates a given builder configuration to reuse columns computed during
+// previous trace expansions (where possible), and to record those computed
+// during this expansion.
+func (tb TraceBuilder) Cache(cache *Cache) TraceBuilder {
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, tb.batchSize, cache}
 }
 
 // Build takes the given builder configuration, along with a given set of input
@@ -78,10 +88,10 @@ func (tb TraceBuilder) Build(columns []trace.RawColumn) (trace.Trace, []error) {
 		// Expand trace
 		if tb.parallel {
 			// Run (parallel) trace expansion
-			if err := parallelTraceExpansion(tb.batchSize, tb.schema, tr); err != nil {
+			if err := parallelTraceExpansion(tb.batchSize, tb.cache, tb.schema, tr); err != nil {
 				return nil, append(errs, err)
 			}
-		} else if err := sequentialTraceExpansion(tb.schema, tr); err != nil {
+		} else if err := sequentialTraceExpansion(tb.cache, tb.schema, tr); err != nil {
 			// Expansion errors are fatal as well
 			return nil, append(errs, err)
 		}
@@ -90,6 +100,12 @@ func (tb TraceBuilder) Build(columns []trace.RawColumn) (trace.Trace, []error) {
 	if tb.padding > 0 {
 		padColumns(tr, tb.padding)
 	}
+	// Recompute columns which cannot be padded (e.g. lookup multiplicities)
+	if tb.padding > 0 && tb.expand {
+		if err := recomputePaddedColumns(tb.schema, tr); err != nil {
+			return nil, append(errs, err)
+		}
+	}
 
 	return tr, errs
 }
@@ -170,7 +186,6 @@ func (tb TraceBuilder) initialiseTraceColumns() ([]trace.ArrayColumn, map[column
 // Fill columns in the corresponding trace from the given input columns
 func fillTraceColumns(modmap map[string]uint, colmap map[columnKey]uint,
 	cols []trace.RawColumn, tr *trace.ArrayTrace) []error {
-	var zero fr.Element = fr.NewElement(0)
 	// Errs contains the set of filling errors which are accumulated
 	var errs []error
 	// Assign data from each input column given
@@ -189,7 +204,7 @@ func fillTraceColumns(modmap map[string]uint, colmap map[columnKey]uint,
 				errs = append(errs, fmt.Errorf("duplicate column '%s' in trace", c.QualifiedName()))
 			} else {
 				// Assign data
-				tr.FillColumn(cid, c.Data, zero)
+				tr.FillColumn(cid, c.Data, c.Padding)
 			}
 		}
 	}
@@ -226,13 +241,18 @@ func validateTraceColumns(schema Schema, tr *trace.ArrayTrace) (error, []error)
 	return nil, warnings
 }
 
-// applySpillage pads each module with its given level of spillage
+// applySpillage pads each module with its given level of spillage.  Modules
+// whose height is not yet known (i.e. because they contain only computed
+// columns) are skipped, since their height is determined during trace expansion
+// from modules which have already been padded.
 func applySpillage(tr *trace.ArrayTrace, schema Schema) {
 	n := tr.Modules().Count()
 	// Iterate over modules
 	for i := uint(0); i < n; i++ {
-		spillage := RequiredSpillage(i, schema)
-		tr.Pad(i, spillage)
+		if tr.Modules().Nth(i).Height() != math.MaxUint {
+			spillage := RequiredSpillage(i, schema)
+			tr.Pad(i, spillage)
+		}
 	}
 }
 
@@ -245,10 +265,38 @@ func padColumns(tr *trace.ArrayTrace, padding uint) {
 	}
 }
 
+// recomputePaddedColumns recomputes the columns of any padding sensitive
+// assignment (see PaddingSensitiveAssignment) in a given trace, which has been
+// padded after expansion.
+func recomputePaddedColumns(schema Schema, trace *tr.ArrayTrace) error {
+	// Column identifiers for computed columns start immediately following the
+	// designated input columns.
+	cid := schema.InputColumns().Count()
+	//
+	for i := schema.Assignments(); i.HasNext(); {
+		ith := i.Next()
+		//
+		if a, ok := ith.(PaddingSensitiveAssignment); ok && a.IsPaddingSensitive() {
+			cols, err := ith.ComputeColumns(trace)
+			if err != nil {
+				return err
+			}
+			//
+			for j, col := range cols {
+				trace.RefillColumn(cid+uint(j), col.Data(), col.Padding())
+			}
+		}
+		// Advance column id past this assignment
+		cid += ith.Columns().Count()
+	}
+	//
+	return nil
+}
+
 // sequentialTraceExpansion expands a given trace according to a given schema.
 // More specifically, that means computing the actual values for any
 // assignments.  This is done using a straightforward sequential algorithm.
-func sequentialTraceExpansion(schema Schema, trace *tr.ArrayTrace) error {
+func sequentialTraceExpansion(cache *Cache, schema Schema, trace *tr.ArrayTrace) error {
 	var err error
 	// Column identifiers for computed columns start immediately following the
 	// designated input columns.
@@ -259,7 +307,7 @@ func sequentialTraceExpansion(schema Schema, trace *tr.ArrayTrace) error {
 		// Get ith assignment
 		ith := i.Next()
 		// Compute ith assignment(s)
-		if cols, err = ith.ComputeColumns(trace); err != nil {
+		if cols, err = computeColumns(ith, cache, schema, trace); err != nil {
 			return err
 		}
 		// Fill all computed columns
@@ -276,7 +324,7 @@ func sequentialTraceExpansion(schema Schema, trace *tr.ArrayTrace) error {
 // is for two reasons: firstly, the latter would require locks that would slow
 // down evaluation performance; secondly, the vast majority of jobs are run in
 // the very first wave.
-func parallelTraceExpansion(batchsize uint, schema Schema, trace *tr.ArrayTrace) error {
+func parallelTraceExpansion(batchsize uint, cache *Cache, schema Schema, trace *tr.ArrayTrace) error {
 	batch := 0
 	// Construct a communication channel for errors.
 	ch := make(chan columnBatch, 1024)
@@ -288,7 +336,7 @@ func parallelTraceExpansion(batchsize uint, schema Schema, trace *tr.ArrayTrace)
 	for ntodo > 0 {
 		stats := util.NewPerfStats()
 		// Dispatch next batch of assignments.
-		n := dispatchReadyAssignments(batchsize, ninputs, schema, trace, ch)
+		n := dispatchReadyAssignments(batchsize, ninputs, cache, schema, trace, ch)
 		//
 		batches := make([]columnBatch, n)
 		// Collect all the results
@@ -320,7 +368,7 @@ func parallelTraceExpansion(batchsize uint, schema Schema, trace *tr.ArrayTrace)
 // results being fed back into the shared channel.  This returns the number of
 // jobs which have been dispatched (i.e. so the caller knows how many results to
 // expect).
-func dispatchReadyAssignments(batchsize uint, ninputs uint, schema Schema,
+func dispatchReadyAssignments(batchsize uint, ninputs uint, cache *Cache, schema Schema,
 	trace *tr.ArrayTrace, ch chan columnBatch) uint {
 	count := uint(0)
 	//
@@ -331,7 +379,7 @@ func dispatchReadyAssignments(batchsize uint, ninputs uint, schema Schema,
 		if trace.Column(cid).Data() == nil && isReady(ith, trace) {
 			// Dispatch!
 			go func(index uint) {
-				cols, err := ith.ComputeColumns(trace)
+				cols, err := computeColumns(ith, cache, schema, trace)
 				// Send outcome back
 				ch <- columnBatch{index, cols, err}
 			}(cid)
@@ -345,6 +393,17 @@ func dispatchReadyAssignments(batchsize uint, ninputs uint, schema Schema,
 	return count
 }
 
+// Compute the columns of a given assignment, reusing those of a previous trace
+// expansion when a cache is given (i.e. is not nil).
+func computeColumns(assignment Assignment, cache *Cache, schema Schema, trace *tr.ArrayTrace) ([]tr.ArrayColumn,
+	error) {
+	if cache != nil {
+		return cache.computeColumns(assignment, schema, trace)
+	}
+	//
+	return assignment.ComputeColumns(trace)
+}
+
 // Check whether all dependencies for this assignment are available (that is,
 // have their data already).
 func isReady(assignment Assignment, trace *tr.ArrayTrace) bool {
@@ -0,0 +1,296 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/lt"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// Cache is a persistent (i.e. on-disk) store of results obtained when checking
+// previous traces.  This is useful when repeatedly checking traces which are
+// nearly identical, since work whose inputs have not changed can be skipped.
+// Specifically, the cache records two kinds of result: firstly, the columns
+// computed by an assignment during trace expansion; secondly, those constraints
+// which were found to hold.  In both cases, results are keyed by a hash of the
+// element in question (i.e. its textual representation) along with the
+// contents of the columns on which it depends.  Thus, a result is only reused
+// when none of its input columns have changed.  Observe that constraints which
+// failed are not recorded and, hence, are always rechecked.  This ensures
+// failures are always reported in full.
+type Cache struct {
+	// Directory in which cache entries are stored.
+	dir string
+	// Protects the hashes map, since columns are hashed concurrently.
+	mux sync.Mutex
+	// Hashes of column contents, indexed by column.  This saves rehashing
+	// columns which are dependencies of many assignments or constraints.
+	hashes map[uint]columnHash
+}
+
+// The hash of a given column's contents.  The column's data is retained in
+// order to determine whether or not the hash remains valid, since column data
+// is replaced (rather than updated in place) when padding is applied.
+// Likewise, the same cache may be used with traces built from different
+// schemas (i.e. at different IR levels).
+type columnHash struct {
+	data    util.FrArray
+	padding fr.Element
+	hash    []byte
+}
+
+// NewCache constructs a cache which stores its entries within the given
+// directory, creating it if it does not already exist.
+func NewCache(dir string) (*Cache, error) {
+	for _, sub := range []string{"columns", "constraints"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, err
+		}
+	}
+	//
+	return &Cache{dir: dir, hashes: make(map[uint]columnHash)}, nil
+}
+
+// Accepts determines whether a given schema accepts a given trace, as for
+// AcceptsWith.  However, constraints which held on a previously checked trace are
+// not checked again if none of the columns on which they depend have changed.
+func (p *Cache) Accepts(batchsize uint, workers uint, limit uint, schema Schema, trace tr.Trace) []Failure {
+	return checkConstraints("Constraint", p, batchsize, workers, limit, schema, schema.Constraints(), trace)
+}
+
+// Asserts determines whether a given schema asserts a given trace, as for
+// AssertsWith.  As for Accepts, assertions which held on a previously checked trace
+// are not checked again if none of the columns on which they depend have
+// changed.
+func (p *Cache) Asserts(batchsize uint, workers uint, limit uint, schema Schema, trace tr.Trace) []Failure {
+	return checkConstraints("Assertion", p, batchsize, workers, limit, schema, schema.Assertions(), trace)
+}
+
+// Check a given constraint, unless it is known to hold already.  If the
+// constraint is found to hold then this is recorded for the future.
+func (p *Cache) checkConstraint(constraint Constraint, schema Schema, trace tr.Trace,
+	check func() []Failure) []Failure {
+	var key string
+	// Constraints which do not depend on any columns are not cached, since
+	// they are cheap to check anyway.
+	if constraint.RequiredColumns().Iter().Count() == 0 {
+		return check()
+	}
+	//
+	key = p.constraintKey(constraint, schema, trace)
+	// Check whether known to hold
+	if _, err := os.Stat(p.path("constraints", key)); err == nil {
+		return nil
+	}
+	// Check the constraint
+	failures := check()
+	// Record when it holds.  Errors here are ignored, since they simply mean
+	// the result is not reused.
+	if len(failures) == 0 {
+		_ = p.write("constraints", key, nil)
+	}
+	//
+	return failures
+}
+
+// Compute the columns of a given assignment, reusing those computed during a
+// previous expansion if none of its dependencies have changed.
+func (p *Cache) computeColumns(assignment Assignment, schema Schema, trace tr.Trace) ([]tr.ArrayColumn, error) {
+	key := p.assignmentKey(assignment, schema, trace)
+	// Attempt to reuse previously computed columns
+	if cols, ok := p.readColumns(key, assignment); ok {
+		return cols, nil
+	}
+	// Compute columns
+	cols, err := assignment.ComputeColumns(trace)
+	// Record computed columns.  As before, errors here are ignored.
+	if err == nil {
+		_ = p.writeColumns(key, cols, trace)
+	}
+	//
+	return cols, err
+}
+
+// Read the columns stored under a given key (if they exist), checking they
+// match those declared by the given assignment.
+func (p *Cache) readColumns(key string, assignment Assignment) ([]tr.ArrayColumn, bool) {
+	bytes, err := os.ReadFile(p.path("columns", key))
+	if err != nil {
+		return nil, false
+	}
+	//
+	raw, err := lt.FromBytes(bytes)
+	if err != nil || uint(len(raw)) != assignment.Columns().Count() {
+		return nil, false
+	}
+	//
+	cols := make([]tr.ArrayColumn, len(raw))
+	//
+	for i, iter := 0, assignment.Columns(); iter.HasNext(); i++ {
+		ith := iter.Next()
+		// Sanity check column matches
+		if ith.Name() != raw[i].Name {
+			return nil, false
+		}
+		//
+		cols[i] = tr.NewArrayColumn(ith.Context(), ith.Name(), raw[i].Data, raw[i].Padding)
+	}
+	//
+	return cols, true
+}
+
+// Write a given set of computed columns under a given key.
+func (p *Cache) writeColumns(key string, cols []tr.ArrayColumn, trace tr.Trace) error {
+	raw := make([]tr.RawColumn, len(cols))
+	//
+	for i, col := range cols {
+		mod := trace.Modules().Nth(col.Context().Module())
+		raw[i] = tr.RawColumn{Module: mod.Name(), Name: col.Name(), Data: col.Data(), Padding: col.Padding()}
+	}
+	//
+	bytes, err := lt.ToBytesV2(raw, nil)
+	if err != nil {
+		return err
+	}
+	//
+	return p.write("columns", key, bytes)
+}
+
+// Write a given cache entry.  To ensure concurrent (or interrupted) checks
+// never observe partially written entries, entries are first written to a
+// temporary file which is then renamed.
+func (p *Cache) write(kind string, key string, bytes []byte) error {
+	file, err := os.CreateTemp(filepath.Join(p.dir, kind), "tmp-*")
+	if err != nil {
+		return err
+	}
+	//
+	_, err = file.Write(bytes)
+	// Always close the file
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	//
+	if err == nil {
+		err = os.Rename(file.Name(), p.path(kind, key))
+	}
+	//
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	//
+	return err
+}
+
+// Determine the file for a given cache entry.
+func (p *Cache) path(kind string, key string) string {
+	return filepath.Join(p.dir, kind, key)
+}
+
+// Determine the key for a given assignment.  Since computed columns may depend
+// upon the height of their enclosing module (e.g. when they depend on no other
+// columns), this is included as well.
+func (p *Cache) assignmentKey(assignment Assignment, schema Schema, trace tr.Trace) string {
+	hasher := sha256.New()
+	//
+	writeString(hasher, "assignment")
+	writeString(hasher, assignment.Lisp(schema).String(false))
+	writeUint(hasher, trace.Height(assignment.Context()))
+	//
+	for _, cid := range assignment.Dependencies() {
+		p.writeColumn(hasher, cid, trace)
+	}
+	//
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Determine the key for a given constraint.  Since constraints may depend upon
+// the height of a module (e.g. for local constraints), the heights of all
+// modules are included as well.
+func (p *Cache) constraintKey(constraint Constraint, schema Schema, trace tr.Trace) string {
+	hasher := sha256.New()
+	//
+	writeString(hasher, "constraint")
+	writeString(hasher, constraint.Lisp(schema).String(false))
+	//
+	for iter := trace.Modules(); iter.HasNext(); {
+		writeUint(hasher, iter.Next().Height())
+	}
+	//
+	for iter := constraint.RequiredColumns().Iter(); iter.HasNext(); {
+		p.writeColumn(hasher, iter.Next(), trace)
+	}
+	//
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Write the identity and contents of a given column into a given hash.
+func (p *Cache) writeColumn(hasher hash.Hash, cid uint, trace tr.Trace) {
+	col := trace.Column(cid)
+	mod := trace.Modules().Nth(col.Context().Module())
+	//
+	writeString(hasher, tr.QualifiedColumnName(mod.Name(), col.Name()))
+	hasher.Write(p.columnHash(cid, trace))
+}
+
+// Determine the hash of a given column's contents (including its padding).
+// Hashes are remembered, such that each column is hashed at most once.
+func (p *Cache) columnHash(cid uint, trace tr.Trace) []byte {
+	col := trace.Column(cid)
+	data, padding := col.Data(), col.Padding()
+	// Sanity check data available
+	if data == nil {
+		panic(fmt.Sprintf("column %s has no data", col.Name()))
+	}
+	// Check whether already hashed
+	p.mux.Lock()
+	entry, ok := p.hashes[cid]
+	p.mux.Unlock()
+	//
+	if ok && entry.data == data && entry.padding == padding {
+		return entry.hash
+	}
+	// Hash the column
+	hasher := sha256.New()
+	//
+	writeUint(hasher, data.Len())
+	writeElement(hasher, padding)
+	//
+	for i := uint(0); i < data.Len(); i++ {
+		writeElement(hasher, data.Get(i))
+	}
+	//
+	entry = columnHash{data, padding, hasher.Sum(nil)}
+	// Remember the hash
+	p.mux.Lock()
+	p.hashes[cid] = entry
+	p.mux.Unlock()
+	//
+	return entry.hash
+}
+
+func writeString(hasher hash.Hash, str string) {
+	writeUint(hasher, uint(len(str)))
+	hasher.Write([]byte(str))
+}
+
+func writeUint(hasher hash.Hash, n uint) {
+	var bytes [8]byte
+	//
+	binary.BigEndian.PutUint64(bytes[:], uint64(n))
+	hasher.Write(bytes[:])
+}
+
+func writeElement(hasher hash.Hash, element fr.Element) {
+	bytes := element.Bytes()
+	hasher.Write(bytes[:])
+}
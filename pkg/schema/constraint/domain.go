@@ -0,0 +1,178 @@
+package constraint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Domain identifies the rows of a trace on which a (local) vanishing constraint
+// must hold.  A domain is made up from one or more windows, each of which
+// identifies a (possibly strided) range of rows.  Row indices can be negative,
+// in which case they are counted backwards from the end of the trace (e.g. -1
+// identifies the last row).  For example, the domain "{0 -1}" identifies the
+// first and last rows of a trace, whilst "{0..-1:4}" identifies every fourth
+// row starting from the first.
+type Domain struct {
+	windows []Window
+}
+
+// Window identifies the rows from a given start row up to (and including) a
+// given end row, at intervals of a given step.
+type Window struct {
+	// First row in this window.
+	Start int
+	// Last row in this window (which is only included if it is a whole number
+	// of steps from the first row).
+	End int
+	// Interval between successive rows in this window, which must be non-zero.
+	Step uint
+}
+
+// NewDomain constructs a domain consisting of the given windows.
+func NewDomain(windows ...Window) *Domain {
+	return &Domain{windows}
+}
+
+// NewRowDomain constructs a domain consisting of exactly one row.
+func NewRowDomain(row int) *Domain {
+	return NewDomain(Window{row, row, 1})
+}
+
+// Windows returns the windows which make up this domain.
+func (p *Domain) Windows() []Window {
+	return p.windows
+}
+
+// Row determines whether this domain consists of exactly one row and, if so,
+// returns it.
+func (p *Domain) Row() (int, bool) {
+	if len(p.windows) == 1 && p.windows[0].Start == p.windows[0].End {
+		return p.windows[0].Start, true
+	}
+	//
+	return 0, false
+}
+
+// Contains determines whether or not a given row is in this domain, for a
+// trace of the given height.
+func (p *Domain) Contains(row uint, height uint) bool {
+	for _, w := range p.windows {
+		if w.Contains(row, height) {
+			return true
+		}
+	}
+	//
+	return false
+}
+
+// Rows determines the rows of a trace with the given height which are in this
+// domain.  These are returned in order, and without duplicates.
+func (p *Domain) Rows(height uint) []uint {
+	return p.RowsWithin(0, height, height)
+}
+
+// RowsWithin determines the rows in the range [start,end) of a trace with the
+// given height which are in this domain.  These are returned in order, and
+// without duplicates.  Observe that only rows within the range are visited,
+// hence the cost is proportional to their number (rather than the height).
+func (p *Domain) RowsWithin(start uint, end uint, height uint) []uint {
+	var rows []uint
+	// Optimise the common case of a single window, since no merging is needed.
+	if len(p.windows) == 1 {
+		return p.windows[0].RowsWithin(start, end, height)
+	}
+	//
+	for _, w := range p.windows {
+		rows = append(rows, w.RowsWithin(start, end, height)...)
+	}
+	// Merge rows from overlapping windows
+	slices.Sort(rows)
+	//
+	return slices.Compact(rows)
+}
+
+func (p *Domain) String() string {
+	var builder strings.Builder
+	//
+	builder.WriteString("{")
+	//
+	for i, w := range p.windows {
+		if i != 0 {
+			builder.WriteString(" ")
+		}
+		//
+		builder.WriteString(w.String())
+	}
+	//
+	builder.WriteString("}")
+	//
+	return builder.String()
+}
+
+// Contains determines whether or not a given row is in this window, for a
+// trace of the given height.
+func (p Window) Contains(row uint, height uint) bool {
+	start, end, ok := p.bounds(height)
+	//
+	return ok && start <= row && row <= end && (row-start)%p.Step == 0
+}
+
+// Rows determines the rows of a trace with the given height which are in this
+// window (in order).
+func (p Window) Rows(height uint) []uint {
+	return p.RowsWithin(0, height, height)
+}
+
+// RowsWithin determines the rows in the range [start,end) of a trace with the
+// given height which are in this window (in order).
+func (p Window) RowsWithin(start uint, end uint, height uint) []uint {
+	var rows []uint
+	//
+	if first, last, ok := p.bounds(height); ok && start < end {
+		// Skip rows before the range, whilst preserving the step.
+		if first < start {
+			first += ((start - first + p.Step - 1) / p.Step) * p.Step
+		}
+		//
+		for row := first; row <= min(last, end-1); row += p.Step {
+			rows = append(rows, row)
+		}
+	}
+	//
+	return rows
+}
+
+// Determine the first and last rows of this window in a trace of the given
+// height, or false if the window does not contain any rows of the trace.
+// Observe that a window extending beyond either end of the trace is truncated.
+func (p Window) bounds(height uint) (uint, uint, bool) {
+	start := resolveRow(p.Start, height)
+	end := min(resolveRow(p.End, height), int(height)-1)
+	// Skip rows before the start of the trace, whilst preserving the step.
+	if step := int(p.Step); start < 0 {
+		start += ((step - 1 - start) / step) * step
+	}
+	//
+	return uint(start), uint(end), start >= 0 && start <= end
+}
+
+func (p Window) String() string {
+	if p.Start == p.End {
+		return fmt.Sprintf("%d", p.Start)
+	} else if p.Step == 1 {
+		return fmt.Sprintf("%d..%d", p.Start, p.End)
+	}
+	//
+	return fmt.Sprintf("%d..%d:%d", p.Start, p.End, p.Step)
+}
+
+// Resolve a (possibly negative) row index within a trace of the given height.
+// The result is negative if the row lies before the start of the trace.
+func resolveRow(row int, height uint) int {
+	if row < 0 {
+		return row + int(height)
+	}
+	//
+	return row
+}
@@ -0,0 +1,283 @@
+package constraint
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/schema"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// MultiplicityFailure provides structural information about a log-derivative
+// lookup whose multiplicity is incorrect for some target row.
+type MultiplicityFailure struct {
+	// Handle of the failing constraint
+	handle string
+	// Target expressions (and multiplicity) of the failing constraint
+	targets []sc.Evaluable
+	// Target row whose multiplicity is incorrect
+	row uint
+	// Origin of the failing constraint
+	source *sc.Source
+}
+
+// Handle returns the handle of the failing constraint.
+func (p *MultiplicityFailure) Handle() string {
+	return p.handle
+}
+
+// Row identifies the target row whose multiplicity is incorrect.
+func (p *MultiplicityFailure) Row() uint {
+	return p.row
+}
+
+// Message provides a suitable error message
+func (p *MultiplicityFailure) Message() string {
+	return fmt.Sprintf("lookup \"%s\" has incorrect multiplicity (row %d)", p.handle, p.row)
+}
+
+// RequiredCells identifies the cells required to evaluate the target
+// expressions and multiplicity at the failing row.
+func (p *MultiplicityFailure) RequiredCells(tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	res := util.NewAnySortedSet[trace.CellRef]()
+	//
+	for _, e := range p.targets {
+		res.InsertSorted(e.RequiredCells(int(p.row), tr))
+	}
+	//
+	return res
+}
+
+// Source returns the declaration from which the failing constraint originated
+// (or nil if this is unknown).
+func (p *MultiplicityFailure) Source() *sc.Source {
+	return p.source
+}
+
+func (p *MultiplicityFailure) String() string {
+	return p.Message()
+}
+
+// LogDerivativeLookupConstraint is a lookup constraint which additionally
+// includes a multiplicity expression on the target side.  This is the form of
+// lookup required by provers which use a log-derivative (i.e. "logUp")
+// argument.  Specifically, writing s_i for the i'th (selected) source tuple,
+// t_j for the j'th (selected) target tuple and m_j for the j'th multiplicity,
+// the constraint holds when the following holds for a random challenge α:
+//
+// Σ_i 1/(α - s_i) = Σ_j m_j/(α - t_j)
+//
+// Equivalently, for every tuple, the number of source rows on which it occurs
+// must equal the sum of multiplicities of the target rows on which it occurs.
+// This implies the source tuples are a subset of the target tuples (as for a
+// normal lookup) but, unlike a normal lookup, depends upon the multiplicities
+// being correct.
+type LogDerivativeLookupConstraint[E schema.Evaluable] struct {
+	handle string
+	// Evaluation context for source columns.
+	source trace.Context
+	// Evaluation context for target columns.
+	target trace.Context
+	// Source rows represent the subset of rows.
+	sources []E
+	// Target rows represent the set of rows.
+	targets []E
+	// Selector determining which source rows participate (if present).
+	sourceSelector util.Option[E]
+	// Selector determining which target rows participate (if present).
+	targetSelector util.Option[E]
+	// Number of times each target row is matched by a source row.  This is
+	// evaluated in the target context.
+	multiplicity E
+	// Origin of this constraint (if known).  Observe that this is distinct
+	// from the source context above.
+	origin *sc.Source
+}
+
+// NewLogDerivativeLookupConstraint creates a new log-derivative lookup
+// constraint with a given handle and multiplicity, where the source and target
+// sides are optionally guarded by selectors.
+func NewLogDerivativeLookupConstraint[E schema.Evaluable](handle string, source trace.Context,
+	target trace.Context, sources []E, targets []E, sourceSelector util.Option[E],
+	targetSelector util.Option[E], multiplicity E) *LogDerivativeLookupConstraint[E] {
+	if len(targets) != len(sources) {
+		panic("differeng number of target / source lookup columns")
+	}
+
+	return &LogDerivativeLookupConstraint[E]{handle, source, target, sources, targets, sourceSelector,
+		targetSelector, multiplicity, nil}
+}
+
+// Handle returns the handle for this lookup constraint which is simply an
+// identifier useful when debugging (i.e. to know which lookup failed, etc).
+//
+//nolint:revive
+func (p *LogDerivativeLookupConstraint[E]) Handle() string {
+	return p.handle
+}
+
+// Source returns the declaration from which this constraint originated (or nil
+// if this is unknown).
+func (p *LogDerivativeLookupConstraint[E]) Source() *sc.Source {
+	return p.origin
+}
+
+// SetSource sets the declaration from which this constraint originated.
+func (p *LogDerivativeLookupConstraint[E]) SetSource(source *sc.Source) {
+	p.origin = source
+}
+
+// SourceContext returns the contezt in which all source expressions are
+// evaluated.
+func (p *LogDerivativeLookupConstraint[E]) SourceContext() trace.Context {
+	return p.source
+}
+
+// TargetContext returns the contezt in which all target expressions (including
+// the multiplicity) are evaluated.
+func (p *LogDerivativeLookupConstraint[E]) TargetContext() trace.Context {
+	return p.target
+}
+
+// Sources returns the source expressions which are used to lookup into the
+// target expressions.
+func (p *LogDerivativeLookupConstraint[E]) Sources() []E {
+	return p.sources
+}
+
+// Targets returns the target expressions which are used to lookup into the
+// target expressions.
+func (p *LogDerivativeLookupConstraint[E]) Targets() []E {
+	return p.targets
+}
+
+// SourceSelector returns the selector which determines which source rows
+// participate in the lookup (if present).
+func (p *LogDerivativeLookupConstraint[E]) SourceSelector() util.Option[E] {
+	return p.sourceSelector
+}
+
+// TargetSelector returns the selector which determines which target rows
+// participate in the lookup (if present).
+func (p *LogDerivativeLookupConstraint[E]) TargetSelector() util.Option[E] {
+	return p.targetSelector
+}
+
+// Multiplicity returns the expression determining how many times each target
+// row is matched by a source row.
+func (p *LogDerivativeLookupConstraint[E]) Multiplicity() E {
+	return p.multiplicity
+}
+
+// RequiredColumns returns the set of columns on which this constraint depends,
+// including those of the selectors (if present) and the multiplicity.
+//
+//nolint:revive
+func (p *LogDerivativeLookupConstraint[E]) RequiredColumns() *util.SortedSet[uint] {
+	columns := requiredColumnsOf(p.sources, p.targets, p.sourceSelector, p.targetSelector)
+	columns.InsertSorted(p.multiplicity.RequiredColumns())
+	//
+	return columns
+}
+
+// Accepts checks whether the (selected) source tuples are accounted for exactly
+// by the multiplicities of the (selected) target tuples.  A lookup failure is
+// reported for each source row whose tuple does not occur in the targets, and a
+// multiplicity failure for the first target row of any tuple whose
+// multiplicities do not match its occurrences in the sources (up to limit).
+//
+//nolint:revive
+func (p *LogDerivativeLookupConstraint[E]) Accepts(tr trace.Trace, limit uint) []schema.Failure {
+	var (
+		failures []schema.Failure
+		// Determine height of enclosing module for source columns
+		src_height = tr.Height(p.source)
+		tgt_height = tr.Height(p.target)
+		// Maps each target tuple to its current balance (i.e. the sum of its
+		// multiplicities less its occurrences in the sources).
+		balances = make(map[string]fr.Element, tgt_height)
+	)
+	// Sum multiplicities of all (selected) target rows
+	for i := 0; i < int(tgt_height); i++ {
+		if isSelected(i, p.targetSelector, tr) {
+			key := string(evalExprsAt(i, p.targets, tr))
+			balance := balances[key]
+			multiplicity := p.multiplicity.EvalAt(i, tr)
+			balances[key] = *balance.Add(&balance, &multiplicity)
+		}
+	}
+	// Subtract occurrences of all (selected) source rows
+	for i := 0; i < int(src_height) && uint(len(failures)) < limit; i++ {
+		if !isSelected(i, p.sourceSelector, tr) {
+			continue
+		}
+		//
+		key := string(evalExprsAt(i, p.sources, tr))
+		//
+		if balance, ok := balances[key]; !ok {
+			sources := evaluables(p.sources)
+			// Include selector, since this determines the row participates.
+			if p.sourceSelector.HasValue() {
+				sources = append(sources, p.sourceSelector.Unwrap())
+			}
+			//
+			failures = append(failures, &LookupFailure{p.handle, sources, uint(i), p.origin})
+		} else {
+			one := fr.One()
+			balances[key] = *balance.Sub(&balance, &one)
+		}
+	}
+	// Check all balances are zero.  Each unbalanced tuple is reported at the
+	// first target row on which it occurs.
+	for i := 0; i < int(tgt_height) && uint(len(failures)) < limit; i++ {
+		if !isSelected(i, p.targetSelector, tr) {
+			continue
+		}
+		//
+		key := string(evalExprsAt(i, p.targets, tr))
+		//
+		if balance := balances[key]; !balance.IsZero() {
+			targets := append(evaluables(p.targets), p.multiplicity)
+			failures = append(failures, &MultiplicityFailure{p.handle, targets, uint(i), p.origin})
+			// Prevent this tuple being reported again
+			delete(balances, key)
+		}
+	}
+	//
+	return failures
+}
+
+// Lisp converts this schema element into a simple S-Expression, for example
+// so it can be printed.
+//
+//nolint:revive
+func (p *LogDerivativeLookupConstraint[E]) Lisp(schema sc.Schema) sexp.SExp {
+	sources := sexp.EmptyList()
+	targets := sexp.EmptyList()
+	// Iterate source expressions
+	for i := 0; i < len(p.sources); i++ {
+		sources.Append(p.sources[i].Lisp(schema))
+	}
+	// Iterate target expressions
+	for i := 0; i < len(p.targets); i++ {
+		targets.Append(p.targets[i].Lisp(schema))
+	}
+	// Without selectors
+	if !p.sourceSelector.HasValue() && !p.targetSelector.HasValue() {
+		return sexp.NewList([]sexp.SExp{sexp.NewSymbol("logup"), sexp.NewSymbol(p.handle),
+			p.multiplicity.Lisp(schema), targets, sources})
+	}
+	// With selectors, where an absent selector selects every row.
+	return sexp.NewList([]sexp.SExp{
+		sexp.NewSymbol("logup"),
+		sexp.NewSymbol(p.handle),
+		p.multiplicity.Lisp(schema),
+		selectorLisp(p.targetSelector, schema),
+		targets,
+		selectorLisp(p.sourceSelector, schema),
+		sources,
+	})
+}
@@ -13,16 +13,51 @@ import (
 
 // LookupFailure provides structural information about a failing lookup constraint.
 type LookupFailure struct {
-	msg string
+	// Handle of the failing constraint
+	handle string
+	// Source expressions of the failing constraint
+	sources []sc.Evaluable
+	// Source row which was not found in the target columns
+	row uint
+	// Origin of the failing constraint
+	source *sc.Source
+}
+
+// Handle returns the handle of the failing constraint.
+func (p *LookupFailure) Handle() string {
+	return p.handle
+}
+
+// Row identifies the source row which was not found.
+func (p *LookupFailure) Row() uint {
+	return p.row
 }
 
 // Message provides a suitable error message
 func (p *LookupFailure) Message() string {
-	return p.msg
+	return fmt.Sprintf("lookup \"%s\" failed (row %d)", p.handle, p.row)
+}
+
+// RequiredCells identifies the cells required to evaluate the source
+// expressions at the failing row.
+func (p *LookupFailure) RequiredCells(tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	res := util.NewAnySortedSet[trace.CellRef]()
+	//
+	for _, e := range p.sources {
+		res.InsertSorted(e.RequiredCells(int(p.row), tr))
+	}
+	//
+	return res
+}
+
+// Source returns the declaration from which the failing constraint originated
+// (or nil if this is unknown).
+func (p *LookupFailure) Source() *sc.Source {
+	return p.source
 }
 
 func (p *LookupFailure) String() string {
-	return p.msg
+	return p.Message()
 }
 
 // LookupConstraint (sometimes also called an inclusion constraint) constrains
@@ -33,6 +68,11 @@ func (p *LookupFailure) String() string {
 // same module, and likewise for target modules.  However, the source columns
 // can be in a different module from the target columns.
 //
+// Either side of a lookup can optionally be guarded by a selector.  In such
+// case, only those rows on which the selector is non-zero participate in the
+// lookup.  That is, only source rows with a non-zero source selector must be
+// matched, and only target rows with a non-zero target selector can be matched.
+//
 // Lookup constraints are typically used to "connect" modules together.  We can
 // think of them (in some ways) as being a little like function calls.  In this
 // analogy, the source module is making a "function call" into the target
@@ -50,16 +90,25 @@ type LookupConstraint[E schema.Evaluable] struct {
 	sources []E
 	// Target rows represent the set of rows.
 	targets []E
+	// Selector determining which source rows participate (if present).
+	sourceSelector util.Option[E]
+	// Selector determining which target rows participate (if present).
+	targetSelector util.Option[E]
+	// Origin of this constraint (if known).  Observe that this is distinct
+	// from the source context above.
+	origin *sc.Source
 }
 
-// NewLookupConstraint creates a new lookup constraint with a given handle.
+// NewLookupConstraint creates a new lookup constraint with a given handle,
+// where the source and target sides are optionally guarded by selectors.
 func NewLookupConstraint[E schema.Evaluable](handle string, source trace.Context,
-	target trace.Context, sources []E, targets []E) *LookupConstraint[E] {
+	target trace.Context, sources []E, targets []E, sourceSelector util.Option[E],
+	targetSelector util.Option[E]) *LookupConstraint[E] {
 	if len(targets) != len(sources) {
 		panic("differeng number of target / source lookup columns")
 	}
 
-	return &LookupConstraint[E]{handle, source, target, sources, targets}
+	return &LookupConstraint[E]{handle, source, target, sources, targets, sourceSelector, targetSelector, nil}
 }
 
 // Handle returns the handle for this lookup constraint which is simply an
@@ -70,6 +119,17 @@ func (p *LookupConstraint[E]) Handle() string {
 	return p.handle
 }
 
+// Source returns the declaration from which this constraint originated (or nil
+// if this is unknown).
+func (p *LookupConstraint[E]) Source() *sc.Source {
+	return p.origin
+}
+
+// SetSource sets the declaration from which this constraint originated.
+func (p *LookupConstraint[E]) SetSource(source *sc.Source) {
+	p.origin = source
+}
+
 // SourceContext returns the contezt in which all target expressions are evaluated.
 func (p *LookupConstraint[E]) SourceContext() trace.Context {
 	return p.source
@@ -92,31 +152,86 @@ func (p *LookupConstraint[E]) Targets() []E {
 	return p.targets
 }
 
+// SourceSelector returns the selector which determines which source rows
+// participate in the lookup (if present).
+func (p *LookupConstraint[E]) SourceSelector() util.Option[E] {
+	return p.sourceSelector
+}
+
+// TargetSelector returns the selector which determines which target rows
+// participate in the lookup (if present).
+func (p *LookupConstraint[E]) TargetSelector() util.Option[E] {
+	return p.targetSelector
+}
+
+// RequiredColumns returns the set of columns on which this constraint depends,
+// including those of the selectors (if present).
+//
+//nolint:revive
+func (p *LookupConstraint[E]) RequiredColumns() *util.SortedSet[uint] {
+	return requiredColumnsOf(p.sources, p.targets, p.sourceSelector, p.targetSelector)
+}
+
 // Accepts checks whether a lookup constraint into the target columns holds for
-// all rows of the source columns.
+// all (selected) rows of the source columns.  If not, a failure is reported for each
+// source row not found (up to limit).
 //
 //nolint:revive
-func (p *LookupConstraint[E]) Accepts(tr trace.Trace) schema.Failure {
+func (p *LookupConstraint[E]) Accepts(tr trace.Trace, limit uint) []schema.Failure {
+	var failures []schema.Failure
 	// Determine height of enclosing module for source columns
 	src_height := tr.Height(p.source)
 	tgt_height := tr.Height(p.target)
 	//
 	rows := util.NewHashSet[util.BytesKey](tgt_height)
-	// Add all target columns to the set
+	// Add all (selected) target columns to the set
 	for i := 0; i < int(tgt_height); i++ {
-		ith_bytes := evalExprsAt(i, p.targets, tr)
-		rows.Insert(util.NewBytesKey(ith_bytes))
+		if isSelected(i, p.targetSelector, tr) {
+			ith_bytes := evalExprsAt(i, p.targets, tr)
+			rows.Insert(util.NewBytesKey(ith_bytes))
+		}
 	}
-	// Check all source columns are contained
-	for i := 0; i < int(src_height); i++ {
+	// Check all (selected) source columns are contained
+	for i := 0; i < int(src_height) && uint(len(failures)) < limit; i++ {
+		if !isSelected(i, p.sourceSelector, tr) {
+			continue
+		}
+		//
 		ith_bytes := evalExprsAt(i, p.sources, tr)
 		// Check whether contained.
 		if !rows.Contains(util.NewBytesKey(ith_bytes)) {
-			return &LookupFailure{fmt.Sprintf("lookup \"%s\" failed (row %d)", p.handle, i)}
+			sources := evaluables(p.sources)
+			// Include selector, since this determines the row participates.
+			if p.sourceSelector.HasValue() {
+				sources = append(sources, p.sourceSelector.Unwrap())
+			}
+			//
+			failures = append(failures, &LookupFailure{p.handle, sources, uint(i), p.origin})
 		}
 	}
 	//
-	return nil
+	return failures
+}
+
+// Convert an array of expressions into an array of evaluables.
+func evaluables[E schema.Evaluable](exprs []E) []sc.Evaluable {
+	evaluables := make([]sc.Evaluable, len(exprs))
+	for i, e := range exprs {
+		evaluables[i] = e
+	}
+	//
+	return evaluables
+}
+
+// Determine whether a given row is selected by an (optional) selector.  Every
+// row is selected when there is no selector.
+func isSelected[E schema.Evaluable](k int, selector util.Option[E], tr trace.Trace) bool {
+	if selector.HasValue() {
+		val := selector.Unwrap().EvalAt(k, tr)
+		return !val.IsZero()
+	}
+	//
+	return true
 }
 
 func evalExprsAt[E schema.Evaluable](k int, sources []E, tr trace.Trace) []byte {
@@ -154,11 +269,48 @@ func (p *LookupConstraint[E]) Lisp(schema sc.Schema) sexp.SExp {
 	for i := 0; i < len(p.targets); i++ {
 		targets.Append(p.targets[i].Lisp(schema))
 	}
-	// Done
+	// Without selectors
+	if !p.sourceSelector.HasValue() && !p.targetSelector.HasValue() {
+		return sexp.NewList([]sexp.SExp{sexp.NewSymbol("lookup"), sexp.NewSymbol(p.handle), targets, sources})
+	}
+	// With selectors, where an absent selector selects every row.
 	return sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("lookup"),
 		sexp.NewSymbol(p.handle),
+		selectorLisp(p.targetSelector, schema),
 		targets,
+		selectorLisp(p.sourceSelector, schema),
 		sources,
 	})
 }
+
+func selectorLisp[E schema.Evaluable](selector util.Option[E], schema sc.Schema) sexp.SExp {
+	if selector.HasValue() {
+		return selector.Unwrap().Lisp(schema)
+	}
+	//
+	return sexp.NewSymbol("1")
+}
+
+// Determine the set of columns on which a given set of source and target
+// expressions depend, including those of the selectors (if present).
+func requiredColumnsOf[E schema.Evaluable](sources []E, targets []E, sourceSelector util.Option[E],
+	targetSelector util.Option[E]) *util.SortedSet[uint] {
+	columns := util.NewSortedSet[uint]()
+	//
+	for _, e := range sources {
+		columns.InsertSorted(e.RequiredColumns())
+	}
+	//
+	for _, e := range targets {
+		columns.InsertSorted(e.RequiredColumns())
+	}
+	//
+	for _, selector := range []util.Option[E]{sourceSelector, targetSelector} {
+		if selector.HasValue() {
+			columns.InsertSorted(selector.Unwrap().RequiredColumns())
+		}
+	}
+	//
+	return columns
+}
@@ -12,7 +12,24 @@ import (
 
 // PermutationFailure provides structural information about a failing permutation constraint.
 type PermutationFailure struct {
-	msg string
+	// Handle of the failing constraint
+	handle string
+	msg    string
+	// Target columns of the failing constraint
+	targets []uint
+	// Origin of the failing constraint
+	source *sc.Source
+}
+
+// Handle returns the handle of the failing permutation constraint.  Since
+// permutations are not named, this is formed from its target columns.
+func (p *PermutationFailure) Handle() string {
+	return p.handle
+}
+
+// Targets returns the target columns of the failing permutation constraint.
+func (p *PermutationFailure) Targets() []uint {
+	return p.targets
 }
 
 // Message provides a suitable error message
@@ -20,26 +37,53 @@ func (p *PermutationFailure) Message() string {
 	return p.msg
 }
 
+// Source returns the declaration from which the failing constraint originated
+// (or nil if this is unknown).
+func (p *PermutationFailure) Source() *sc.Source {
+	return p.source
+}
+
 func (p *PermutationFailure) String() string {
 	return p.msg
 }
 
 // PermutationConstraint declares a constraint that one (or more) columns are a permutation
-// of another.
+// of another.  The source and target columns need not reside in the same
+// module and, furthermore, either side can be guarded by a selector column such
+// that only the selected rows of each are permuted.
 type PermutationConstraint struct {
 	// The target columns
 	targets []uint
 	// The source columns
 	sources []uint
+	// Selector determining which source rows participate (if present).
+	sourceSelector util.Option[uint]
+	// Selector determining which target rows participate (if present).
+	targetSelector util.Option[uint]
+	// Origin of this constraint (if known)
+	source *sc.Source
 }
 
-// NewPermutationConstraint creates a new permutation
-func NewPermutationConstraint(targets []uint, sources []uint) *PermutationConstraint {
+// NewPermutationConstraint creates a new permutation, where the source and
+// target sides are optionally guarded by selectors.
+func NewPermutationConstraint(targets []uint, sources []uint, sourceSelector util.Option[uint],
+	targetSelector util.Option[uint]) *PermutationConstraint {
 	if len(targets) != len(sources) {
 		panic("differeng number of target / source permutation columns")
 	}
 
-	return &PermutationConstraint{targets, sources}
+	return &PermutationConstraint{targets, sources, sourceSelector, targetSelector, nil}
+}
+
+// Source returns the declaration from which this constraint originated (or nil
+// if this is unknown).
+func (p *PermutationConstraint) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this constraint originated.
+func (p *PermutationConstraint) SetSource(source *sc.Source) {
+	p.source = source
 }
 
 // RequiredSpillage returns the minimum amount of spillage required to ensure
@@ -48,12 +92,39 @@ func (p *PermutationConstraint) RequiredSpillage() uint {
 	return uint(0)
 }
 
+// RequiredColumns returns the set of columns on which this constraint depends,
+// including the selectors (if present).
+//
+//nolint:revive
+func (p *PermutationConstraint) RequiredColumns() *util.SortedSet[uint] {
+	columns := util.NewSortedSet[uint]()
+	//
+	for _, cid := range p.targets {
+		columns.Insert(cid)
+	}
+	//
+	for _, cid := range p.sources {
+		columns.Insert(cid)
+	}
+	//
+	for _, selector := range []util.Option[uint]{p.sourceSelector, p.targetSelector} {
+		if selector.HasValue() {
+			columns.Insert(selector.Unwrap())
+		}
+	}
+	//
+	return columns
+}
+
 // Accepts checks whether a permutation holds between the source and
-// target columns.
-func (p *PermutationConstraint) Accepts(tr trace.Trace) schema.Failure {
-	// Slice out data
-	src := sliceColumns(p.sources, tr)
-	dst := sliceColumns(p.targets, tr)
+// target columns.  Since this is a property of the columns as a whole, at most
+// one failure is reported.
+//
+//nolint:revive
+func (p *PermutationConstraint) Accepts(tr trace.Trace, limit uint) []schema.Failure {
+	// Slice out (selected) data
+	src := sliceColumns(p.sources, p.sourceSelector, tr)
+	dst := sliceColumns(p.targets, p.targetSelector, tr)
 	// Sanity check whether column exists
 	if util.ArePermutationOf(dst, src) {
 		// Success
@@ -66,7 +137,7 @@ func (p *PermutationConstraint) Accepts(tr trace.Trace) schema.Failure {
 	msg := fmt.Sprintf("Target columns (%s) not permutation of source columns (%s)",
 		dst_names, src_names)
 	// Done
-	return &PermutationFailure{msg}
+	return []schema.Failure{&PermutationFailure{dst_names, msg, p.targets, p.source}}
 }
 
 // Lisp converts this schema element into a simple S-Expression, for example
@@ -85,9 +156,20 @@ func (p *PermutationConstraint) Lisp(schema sc.Schema) sexp.SExp {
 		sources.Append(sexp.NewSymbol(source.QualifiedName(schema)))
 	}
 
+	// Without selectors
+	if !p.sourceSelector.HasValue() && !p.targetSelector.HasValue() {
+		return sexp.NewList([]sexp.SExp{
+			sexp.NewSymbol("permutation"),
+			targets,
+			sources,
+		})
+	}
+	// With selectors, where an absent selector selects every row.
 	return sexp.NewList([]sexp.SExp{
 		sexp.NewSymbol("permutation"),
+		columnSelectorLisp(p.targetSelector, schema),
 		targets,
+		columnSelectorLisp(p.sourceSelector, schema),
 		sources,
 	})
 }
@@ -104,7 +186,19 @@ func (p *PermutationConstraint) Sources() []uint {
 	return p.sources
 }
 
-func sliceColumns(columns []uint, tr trace.Trace) []util.FrArray {
+// SourceSelector returns the column which determines which source rows
+// participate in the permutation (if present).
+func (p *PermutationConstraint) SourceSelector() util.Option[uint] {
+	return p.sourceSelector
+}
+
+// TargetSelector returns the column which determines which target rows
+// participate in the permutation (if present).
+func (p *PermutationConstraint) TargetSelector() util.Option[uint] {
+	return p.targetSelector
+}
+
+func sliceColumns(columns []uint, selector util.Option[uint], tr trace.Trace) []util.FrArray {
 	// Allocate return array
 	cols := make([]util.FrArray, len(columns))
 	// Slice out the data
@@ -113,6 +207,35 @@ func sliceColumns(columns []uint, tr trace.Trace) []util.FrArray {
 		// Copy over
 		cols[i] = nth.Data()
 	}
+	// Filter out unselected rows (if applicable)
+	if selector.HasValue() {
+		var (
+			sel  = tr.Column(selector.Unwrap()).Data()
+			rows []uint
+		)
+		//
+		for k := uint(0); k < sel.Len(); k++ {
+			if kth := sel.Get(k); !kth.IsZero() {
+				rows = append(rows, k)
+			}
+		}
+		//
+		for i, col := range cols {
+			cols[i] = util.NewFrArray(uint(len(rows)), col.BitWidth())
+			//
+			for j, row := range rows {
+				cols[i].Set(uint(j), col.Get(row))
+			}
+		}
+	}
 	// Done
 	return cols
 }
+
+func columnSelectorLisp(selector util.Option[uint], schema sc.Schema) sexp.SExp {
+	if selector.HasValue() {
+		return sexp.NewSymbol(sc.QualifiedName(schema, selector.Unwrap()))
+	}
+	//
+	return sexp.NewSymbol("1")
+}
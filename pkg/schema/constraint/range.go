@@ -8,6 +8,7 @@ import (
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // RangeFailure provides structural information about a failing type constraint.
@@ -18,6 +19,18 @@ type RangeFailure struct {
 	expr sc.Evaluable
 	// Row on which the constraint failed
 	row uint
+	// Origin of the failing constraint
+	source *sc.Source
+}
+
+// Handle returns the handle of the failing constraint.
+func (p *RangeFailure) Handle() string {
+	return p.handle
+}
+
+// Row identifies the row on which this constraint failed.
+func (p *RangeFailure) Row() uint {
+	return p.row
 }
 
 // Message provides a suitable error message
@@ -26,6 +39,18 @@ func (p *RangeFailure) Message() string {
 	return fmt.Sprintf("expression \"%s\" out-of-bounds (row %d)", p.handle, p.row)
 }
 
+// RequiredCells identifies the cells required to evaluate the failing
+// expression at the failing row.
+func (p *RangeFailure) RequiredCells(tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	return p.expr.RequiredCells(int(p.row), tr)
+}
+
+// Source returns the declaration from which the failing constraint originated
+// (or nil if this is unknown).
+func (p *RangeFailure) Source() *sc.Source {
+	return p.source
+}
+
 func (p *RangeFailure) String() string {
 	return p.Message()
 }
@@ -48,12 +73,14 @@ type RangeConstraint[E sc.Evaluable] struct {
 	// an fr.Element is used here to store the bound simply to make the
 	// necessary comparison against table data more direct.
 	bound fr.Element
+	// Origin of this constraint (if known)
+	source *sc.Source
 }
 
 // NewRangeConstraint constructs a new Range constraint!
 func NewRangeConstraint[E sc.Evaluable](handle string, context trace.Context,
 	expr E, bound fr.Element) *RangeConstraint[E] {
-	return &RangeConstraint[E]{handle, context, expr, bound}
+	return &RangeConstraint[E]{handle, context, expr, bound, nil}
 }
 
 // Handle returns a unique identifier for this constraint.
@@ -70,6 +97,17 @@ func (p *RangeConstraint[E]) Context() trace.Context {
 	return p.context
 }
 
+// Source returns the declaration from which this constraint originated (or nil
+// if this is unknown).
+func (p *RangeConstraint[E]) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this constraint originated.
+func (p *RangeConstraint[E]) SetSource(source *sc.Source) {
+	p.source = source
+}
+
 // Target returns the target expression for this constraint.
 func (p *RangeConstraint[E]) Target() E {
 	return p.expr
@@ -88,25 +126,33 @@ func (p *RangeConstraint[E]) BoundedAtMost(bound uint) bool {
 	return p.bound.Cmp(&n) <= 0
 }
 
+// RequiredColumns returns the set of columns on which this constraint depends.
+//
+//nolint:revive
+func (p *RangeConstraint[E]) RequiredColumns() *util.SortedSet[uint] {
+	return p.expr.RequiredColumns()
+}
+
 // Accepts checks whether a range constraint holds on every row of a table. If so, return
-// nil otherwise return an error.
+// nil otherwise return (up to limit) failures.
 //
 //nolint:revive
-func (p *RangeConstraint[E]) Accepts(tr trace.Trace) schema.Failure {
+func (p *RangeConstraint[E]) Accepts(tr trace.Trace, limit uint) []schema.Failure {
+	var failures []schema.Failure
 	// Determine height of enclosing module
 	height := tr.Height(p.context)
 	// Iterate every row
-	for k := 0; k < int(height); k++ {
+	for k := 0; k < int(height) && uint(len(failures)) < limit; k++ {
 		// Get the value on the kth row
 		kth := p.expr.EvalAt(k, tr)
 		// Perform the range check
 		if kth.Cmp(&p.bound) >= 0 {
 			// Evaluation failure
-			return &RangeFailure{p.handle, p.expr, uint(k)}
+			failures = append(failures, &RangeFailure{p.handle, p.expr, uint(k), p.source})
 		}
 	}
-	// All good
-	return nil
+	// Done
+	return failures
 }
 
 // Lisp converts this schema element into a simple S-Expression, for example so
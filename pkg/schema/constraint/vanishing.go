@@ -2,6 +2,7 @@ package constraint
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/consensys/go-corset/pkg/schema"
 	sc "github.com/consensys/go-corset/pkg/schema"
@@ -24,6 +25,17 @@ func (p ZeroTest[E]) TestAt(row int, tr tr.Trace) bool {
 	return val.IsZero()
 }
 
+// Explain the evaluation of this zero test at a given row in a given trace,
+// provided the underlying expression is itself explainable.  Otherwise, nil is
+// returned.
+func (p ZeroTest[E]) Explain(row int, tr tr.Trace, schema sc.Schema) *sc.Explanation {
+	if e, ok := any(p.Expr).(sc.Explainable); ok {
+		return e.Explain(row, tr, schema)
+	}
+	//
+	return nil
+}
+
 // Bounds determines the bounds for this zero test.
 func (p ZeroTest[E]) Bounds() util.Bounds {
 	return p.Expr.Bounds()
@@ -69,6 +81,8 @@ type VanishingFailure struct {
 	constraint sc.Testable
 	// Row on which the constraint failed
 	row uint
+	// Origin of the failing constraint
+	source *sc.Source
 }
 
 // Handle returns the handle of this constraint
@@ -98,6 +112,12 @@ func (p *VanishingFailure) RequiredCells(trace tr.Trace) *util.AnySortedSet[tr.C
 	return p.constraint.RequiredCells(int(p.row), trace)
 }
 
+// Source returns the declaration from which the failing constraint originated
+// (or nil if this is unknown).
+func (p *VanishingFailure) Source() *sc.Source {
+	return p.source
+}
+
 func (p *VanishingFailure) String() string {
 	return p.Message()
 }
@@ -116,18 +136,20 @@ type VanishingConstraint[T sc.Testable] struct {
 	// constrained expression itself.
 	context tr.Context
 	// Indicates (when nil) a global constraint that applies to all rows.
-	// Otherwise, indicates a local constraint which applies to the specific row
-	// given here.
-	domain *int
+	// Otherwise, indicates a local constraint which applies only to the rows
+	// in the given domain.
+	domain *Domain
 	// The actual constraint itself (e.g. an expression which
 	// should evaluate to zero, etc)
 	constraint T
+	// Origin of this constraint (if known)
+	source *sc.Source
 }
 
 // NewVanishingConstraint constructs a new vanishing constraint!
 func NewVanishingConstraint[T sc.Testable](handle string, context tr.Context,
-	domain *int, constraint T) *VanishingConstraint[T] {
-	return &VanishingConstraint[T]{handle, context, domain, constraint}
+	domain *Domain, constraint T) *VanishingConstraint[T] {
+	return &VanishingConstraint[T]{handle, context, domain, constraint, nil}
 }
 
 // Handle returns the handle associated with this constraint.
@@ -142,10 +164,21 @@ func (p *VanishingConstraint[T]) Constraint() T {
 	return p.constraint
 }
 
+// Source returns the declaration from which this constraint originated (or nil
+// if this is unknown).
+func (p *VanishingConstraint[T]) Source() *sc.Source {
+	return p.source
+}
+
+// SetSource sets the declaration from which this constraint originated.
+func (p *VanishingConstraint[T]) SetSource(source *sc.Source) {
+	p.source = source
+}
+
 // Domain returns the domain of this constraint.  If the domain is nil, then
 // this is a global constraint.  Otherwise this signals a local constraint which
-// applies to a specific row (e.g. the first or last).
-func (p *VanishingConstraint[T]) Domain() *int {
+// applies to specific rows (e.g. the first or last).
+func (p *VanishingConstraint[T]) Domain() *Domain {
 	return p.domain
 }
 
@@ -157,48 +190,139 @@ func (p *VanishingConstraint[T]) Context() tr.Context {
 	return p.context
 }
 
+// RequiredColumns returns the set of columns on which this constraint depends.
+//
+//nolint:revive
+func (p *VanishingConstraint[T]) RequiredColumns() *util.SortedSet[uint] {
+	return p.constraint.RequiredColumns()
+}
+
 // Accepts checks whether a vanishing constraint evaluates to zero on every row
-// of a table.  If so, return nil otherwise return an error.
+// of a table.  If so, return nil otherwise return (up to limit) failures.
 //
 //nolint:revive
-func (p *VanishingConstraint[T]) Accepts(tr tr.Trace) schema.Failure {
-	if p.domain == nil {
-		// Global Constraint
-		return HoldsGlobally(p.handle, p.context, p.constraint, tr)
+func (p *VanishingConstraint[T]) Accepts(tr tr.Trace, limit uint) []schema.Failure {
+	row, ok := 0, false
+	//
+	if p.domain != nil {
+		row, ok = p.domain.Row()
+	}
+	//
+	if !ok {
+		// Global constraint, or local constraint applying to multiple rows.
+		return p.attribute(HoldsGlobally(limit, p.handle, p.context, p.domain, p.constraint, tr))
+	}
+	// Check specific row
+	if err := HoldsLocally(p.localRow(row, tr), p.handle, p.constraint, tr); err != nil {
+		return p.attribute([]schema.Failure{err})
+	}
+	// Success
+	return nil
+}
+
+// Rows returns the number of rows over which this constraint is checked, which
+// is the height of its enclosing module.
+//
+//nolint:revive
+func (p *VanishingConstraint[T]) Rows(tr tr.Trace) uint {
+	return tr.Height(p.context)
+}
+
+// AcceptsRange checks whether a vanishing constraint evaluates to zero on every
+// row of a table within the range [start,end).  If so, return nil otherwise
+// return (up to limit) failures in ascending order of row.
+//
+//nolint:revive
+func (p *VanishingConstraint[T]) AcceptsRange(tr tr.Trace, start uint, end uint, limit uint) []schema.Failure {
+	row, ok := 0, false
+	//
+	if p.domain != nil {
+		row, ok = p.domain.Row()
+	}
+	//
+	if !ok {
+		return p.attribute(HoldsWithin(start, end, limit, p.handle, p.context, p.domain, p.constraint, tr))
+	} else if k := p.localRow(row, tr); k < start || k >= end {
+		// Local constraint outside of range
+		return nil
 	}
-	// Local constraint
-	var start uint
-	// Handle negative domains
-	if *p.domain < 0 {
+	// Local constraint within range
+	return p.Accepts(tr, limit)
+}
+
+// Determine the actual row of a local constraint, where negative rows are
+// calculated from the end of the trace.
+func (p *VanishingConstraint[T]) localRow(row int, tr tr.Trace) uint {
+	if row < 0 {
 		// Determine height of enclosing module
 		height := tr.Height(p.context)
 		// Negative rows calculated from end of trace.
-		start = height + uint(*p.domain)
-	} else {
-		start = uint(*p.domain)
+		return height + uint(row)
 	}
-	// Check specific row
-	return HoldsLocally(start, p.handle, p.constraint, tr)
+	//
+	return uint(row)
+}
+
+// Attribute failures of this constraint to its originating declaration.
+func (p *VanishingConstraint[T]) attribute(failures []schema.Failure) []schema.Failure {
+	for _, f := range failures {
+		f.(*VanishingFailure).source = p.source
+	}
+	//
+	return failures
 }
 
 // HoldsGlobally checks whether a given expression vanishes (i.e. evaluates to
-// zero) for all rows of a trace.  If not, report an appropriate error.
-func HoldsGlobally[T sc.Testable](handle string, ctx tr.Context, constraint T, tr tr.Trace) schema.Failure {
+// zero) for all rows of a trace, or only those rows in a given domain (when
+// this is not nil).  If not, report a failure for each row on which it does not
+// hold, up to a given limit.  As for global constraints, rows of the domain on
+// which the constraint is undefined (i.e. because they are out-of-bounds) are
+// ignored.
+func HoldsGlobally[T sc.Testable](limit uint, handle string, ctx tr.Context, domain *Domain, constraint T,
+	tr tr.Trace) []schema.Failure {
+	return HoldsWithin(0, math.MaxUint, limit, handle, ctx, domain, constraint, tr)
+}
+
+// HoldsWithin checks whether a given expression vanishes for all rows of a
+// trace in the range [start,end), or only those rows of the range in a given
+// domain (when this is not nil).  This allows the rows of a large module to be
+// split into disjoint ranges which are checked in parallel.  Failures are
+// reported in ascending order of row, up to a given limit.
+func HoldsWithin[T sc.Testable](start uint, end uint, limit uint, handle string, ctx tr.Context, domain *Domain,
+	constraint T, tr tr.Trace) []schema.Failure {
+	var failures []schema.Failure
 	// Determine height of enclosing module
 	height := tr.Height(ctx)
 	// Determine well-definedness bounds for this constraint
 	bounds := constraint.Bounds()
 	// Sanity check enough rows
-	if bounds.End < height {
+	if bounds.End >= height {
+		return nil
+	}
+	// Restrict range to in-bounds rows
+	start = max(start, bounds.Start)
+	end = min(end, height-bounds.End)
+	//
+	if domain == nil {
 		// Check all in-bounds values
-		for k := bounds.Start; k < (height - bounds.End); k++ {
+		for k := start; k < end && uint(len(failures)) < limit; k++ {
 			if err := HoldsLocally(k, handle, constraint, tr); err != nil {
-				return err
+				failures = append(failures, err)
 			}
 		}
+		//
+		return failures
 	}
-	// Success
-	return nil
+	// Check only in-bounds values within the domain
+	for _, k := range domain.RowsWithin(start, end, height) {
+		if uint(len(failures)) >= limit {
+			break
+		} else if err := HoldsLocally(k, handle, constraint, tr); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	// Done
+	return failures
 }
 
 // HoldsLocally checks whether a given constraint holds (e.g. vanishes) on a
@@ -207,7 +331,7 @@ func HoldsLocally[T sc.Testable](k uint, handle string, constraint T, tr tr.Trac
 	// Check whether it holds or not
 	if !constraint.TestAt(int(k), tr) {
 		// Evaluation failure
-		return &VanishingFailure{handle, constraint, k}
+		return &VanishingFailure{handle, constraint, k, nil}
 	}
 	// Success
 	return nil
@@ -227,12 +351,12 @@ func (p *VanishingConstraint[T]) Lisp(schema sc.Schema) sexp.SExp {
 	// Handle attributes
 	if p.domain == nil {
 		// Skip
-	} else if *p.domain == 0 {
+	} else if row, ok := p.domain.Row(); ok && row == 0 {
 		name = fmt.Sprintf("%s:first", name)
-	} else if *p.domain == -1 {
+	} else if ok && row == -1 {
 		name = fmt.Sprintf("%s:last", name)
 	} else {
-		panic(fmt.Sprintf("domain value %d not supported for local constraint", p.domain))
+		name = fmt.Sprintf("%s:%s", name, p.domain)
 	}
 	// Determine multiplier
 	multiplier := fmt.Sprintf("x%d", p.context.LengthMultiplier())
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	tr "github.com/consensys/go-corset/pkg/trace"
+)
+
+// MAX_EXPLANATION_LABEL determines the maximum number of characters used to
+// show an expression within an explanation.  Longer expressions are truncated,
+// since their subexpressions are shown separately anyway.
+const MAX_EXPLANATION_LABEL = 64
+
+// Explanation is an annotated evaluation tree describing how a given expression
+// was evaluated at a given row of a trace.  Each node records the value(s)
+// produced by the corresponding (sub)expression, along with any additional
+// information (e.g. which branch of a conditional was taken).  This is useful
+// for understanding why a given constraint failed.
+type Explanation struct {
+	// Printable representation of the expression being explained.
+	label string
+	// Values produced by evaluating the expression.  Observe that expressions
+	// at the HIR level can evaluate to zero or more values.
+	values []fr.Element
+	// Additional information about how the expression was evaluated (e.g.
+	// that it was not evaluated at all).
+	note string
+	// Explanations for the subexpressions of this expression.
+	children []*Explanation
+}
+
+// NewExplanation constructs an explanation for an expression which evaluated
+// to the given values, and whose subexpressions are explained by the given
+// children.
+func NewExplanation(label string, values []fr.Element, children ...*Explanation) *Explanation {
+	return &Explanation{label, values, "", children}
+}
+
+// Label returns the printable representation of the expression being
+// explained.
+func (p *Explanation) Label() string {
+	return p.label
+}
+
+// Values returns the values to which the expression being explained evaluated.
+func (p *Explanation) Values() []fr.Element {
+	return p.values
+}
+
+// Note returns any additional information about how the expression was
+// evaluated.
+func (p *Explanation) Note() string {
+	return p.note
+}
+
+// Children returns the explanations for the subexpressions of the expression
+// being explained.
+func (p *Explanation) Children() []*Explanation {
+	return p.children
+}
+
+// Annotate this explanation with additional information about how the
+// expression was evaluated.
+func (p *Explanation) Annotate(note string) *Explanation {
+	p.note = note
+	return p
+}
+
+func (p *Explanation) String() string {
+	var builder strings.Builder
+	//
+	p.write(&builder, "", "")
+	//
+	return builder.String()
+}
+
+// Write this explanation as a tree, where the first line is prefixed by first
+// and all subsequent lines by rest.
+func (p *Explanation) write(builder *strings.Builder, first string, rest string) {
+	label := p.label
+	// Truncate long expressions
+	if len(label) > MAX_EXPLANATION_LABEL {
+		label = label[:MAX_EXPLANATION_LABEL-3] + "..."
+	}
+	//
+	builder.WriteString(first)
+	builder.WriteString(label)
+	// Values are omitted for expressions which were not evaluated.
+	if len(p.values) == 1 {
+		builder.WriteString(" = ")
+		builder.WriteString(p.values[0].String())
+	} else if len(p.values) > 1 {
+		vals := make([]string, len(p.values))
+		//
+		for i, v := range p.values {
+			vals[i] = v.String()
+		}
+		//
+		builder.WriteString(" = {")
+		builder.WriteString(strings.Join(vals, ", "))
+		builder.WriteString("}")
+	}
+	//
+	if p.note != "" {
+		builder.WriteString(" [")
+		builder.WriteString(p.note)
+		builder.WriteString("]")
+	}
+	//
+	builder.WriteString("\n")
+	//
+	for i, child := range p.children {
+		if i+1 == len(p.children) {
+			child.write(builder, rest+"└── ", rest+"    ")
+		} else {
+			child.write(builder, rest+"├── ", rest+"│   ")
+		}
+	}
+}
+
+// Explainable captures something (e.g. a constraint) whose evaluation at a
+// given row of a trace can be explained.
+type Explainable interface {
+	// Explain the evaluation of this element at a given row of a given trace.
+	// The schema is used to determine the names of any columns involved.
+	Explain(int, tr.Trace, Schema) *Explanation
+}
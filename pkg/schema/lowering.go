@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// LoweringError describes a schema element which could not be lowered from one
+// IR level into the next.  For example, a vanishing constraint which reduces to
+// a non-zero constant can never hold and, hence, cannot be lowered.  Likewise,
+// a schema read from a binary file may contain malformed elements.  Lowering
+// errors identify the element in question (i.e. its handle and, where known,
+// its source) along with the IR level at which the error arose.
+type LoweringError struct {
+	// IR level being lowered into when the error arose (e.g. "MIR").
+	level string
+	// Handle of the element which could not be lowered.
+	handle string
+	// Source of the element which could not be lowered, or nil if unknown.
+	source *Source
+	// Describes what went wrong.
+	msg string
+}
+
+// NewLoweringError constructs a lowering error for the element with a given
+// handle (and source) which arose whilst lowering into a given IR level.
+func NewLoweringError(level string, handle string, source *Source, msg string) LoweringError {
+	return LoweringError{level, handle, source, msg}
+}
+
+// Level returns the IR level being lowered into when this error arose.
+func (p *LoweringError) Level() string {
+	return p.level
+}
+
+// Handle returns the handle of the element which could not be lowered.
+func (p *LoweringError) Handle() string {
+	return p.handle
+}
+
+// Source returns the source of the element which could not be lowered, which
+// identifies its span within the originating source file.  This is nil for
+// elements which did not originate from a source file.
+func (p *LoweringError) Source() *Source {
+	return p.source
+}
+
+// Message returns a description of what went wrong.
+func (p *LoweringError) Message() string {
+	return p.msg
+}
+
+func (p *LoweringError) Error() string {
+	if p.source != nil {
+		return fmt.Sprintf("%s: %s (%s): %s", p.source, p.handle, p.level, p.msg)
+	}
+	//
+	return fmt.Sprintf("%s (%s): %s", p.handle, p.level, p.msg)
+}
+
+// LowerElement lowers a given schema element into a given IR level using a
+// given function, and reports any failure as a lowering error attributed to
+// that element.
+func LowerElement(level string, element any, schema Schema, lower func() error) *LoweringError {
+	if e := lower(); e != nil {
+		r := NewLoweringError(level, HandleOf(element, schema), SourceOf(element), e.Error())
+		return &r
+	}
+	//
+	return nil
+}
+
+// HandleOf determines a suitable handle for identifying a given schema element
+// (e.g. in an error message).  For elements which have no handle of their own
+// (e.g. assignments), this is derived from the columns they declare or, failing
+// that, their textual representation.
+func HandleOf(element any, schema Schema) string {
+	if e, ok := element.(interface{ Handle() string }); ok {
+		return e.Handle()
+	} else if e, ok := element.(Declaration); ok && e.Columns().HasNext() {
+		return e.Columns().Next().QualifiedName(schema)
+	} else if e, ok := element.(Lispifiable); ok {
+		return e.Lisp(schema).String(false)
+	}
+	//
+	return fmt.Sprintf("%v", element)
+}
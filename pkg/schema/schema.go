@@ -83,11 +83,47 @@ type Assignment interface {
 	Dependencies() []uint
 }
 
+// PaddingSensitiveAssignment is an assignment whose columns cannot be padded in
+// the usual way (i.e. by repeating their padding value), since their values on
+// existing rows depend upon the padding of other columns.  For example, a
+// lookup multiplicity counts the source rows matching each target row,
+// including any source padding rows.  Hence, such columns are recomputed after
+// padding is applied.
+type PaddingSensitiveAssignment interface {
+	Assignment
+	// IsPaddingSensitive determines whether or not the columns of this
+	// assignment must be recomputed after padding.
+	IsPaddingSensitive() bool
+}
+
 // Constraint represents an element which can "accept" a trace, or either reject
 // with an error (or eventually perhaps report a warning).
 type Constraint interface {
 	Lispifiable
-	Accepts(tr.Trace) Failure
+	// Accepts checks whether this constraint holds on a given trace, returning
+	// the failures found (if any).  At most the given number of failures is
+	// returned, where a limit of one corresponds to reporting only the first
+	// failure found.
+	Accepts(tr.Trace, uint) []Failure
+	// RequiredColumns returns the set of columns on which this constraint
+	// depends.  That is, columns whose values may be accessed when checking
+	// this constraint on a given trace.
+	RequiredColumns() *util.SortedSet[uint]
+}
+
+// ShardedConstraint is a constraint whose rows can be checked independently of
+// each other.  Such constraints can be checked by splitting their rows into
+// disjoint ranges (i.e. shards), and checking each range in parallel.
+type ShardedConstraint interface {
+	Constraint
+	// Rows returns the number of rows in the given trace over which this
+	// constraint is checked.
+	Rows(tr.Trace) uint
+	// AcceptsRange checks whether this constraint holds on those rows of a
+	// given trace within the range [start,end), returning the failures found
+	// (if any) in ascending order of row.  As for Accepts, at most the given
+	// number of failures is returned.
+	AcceptsRange(trace tr.Trace, start uint, end uint, limit uint) []Failure
 }
 
 // Failure embodies structured information about a failing constraint.
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"sync"
 
 	tr "github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/util"
@@ -68,37 +69,61 @@ func ContextOfColumns(cols []uint, schema Schema) tr.Context {
 	return ctx
 }
 
+// SHARD_HEIGHT determines the number of rows in each shard when the rows of a
+// constraint are split across workers.  Constraints with fewer rows than two
+// shards are never split, since the overhead of doing so outweighs any gain.
+const SHARD_HEIGHT = 1024
+
 // Accepts determines whether this schema will accept a given trace.  That is,
 // whether or not the given trace adheres to the schema constraints.  A trace
 // can fail to adhere to the schema for a variety of reasons, such as having a
 // constraint which does not hold.  Observe that this does not check assertions
-// within the schema hold.
+// within the schema hold.  At most one failure is reported for any given
+// constraint.
 //
 //nolint:revive
 func Accepts(batchsize uint, schema Schema, trace tr.Trace) []Failure {
-	errors := make([]Failure, 0)
-	// Initialise batch number (for debugging purposes)
-	batch := uint(0)
-	// Process constraints in batches
-	for iter := schema.Constraints(); iter.HasNext(); {
-		errs := processConstraintBatch("Constraint", batch, batchsize, iter, trace)
-		errors = append(errors, errs...)
-		// Increment batch number
-		batch++
-	}
-	// Success
-	return errors
+	return AcceptsWith(batchsize, 1, 1, schema, trace)
+}
+
+// AcceptsWith determines whether this schema will accept a given trace, as for
+// Accepts.  However, the number of workers determines how many go-routines may
+// be used to check the rows of any given constraint, whilst the limit
+// determines the maximum number of failures reported for any given constraint.
+// Failures are reported in a deterministic order, irrespective of how many
+// workers are used.
+//
+//nolint:revive
+func AcceptsWith(batchsize uint, workers uint, limit uint, schema Schema, trace tr.Trace) []Failure {
+	return checkConstraints("Constraint", nil, batchsize, workers, limit, schema, schema.Constraints(), trace)
 }
 
 // Asserts determines whether or not this schema will "assert" a given trace.
 // That is, whether or not the given trace adheres to the schema assertions.
 func Asserts(batchsize uint, schema Schema, trace tr.Trace) []Failure {
+	return AssertsWith(batchsize, 1, 1, schema, trace)
+}
+
+// AssertsWith determines whether or not this schema will "assert" a given
+// trace, as for Asserts.  As for AcceptsWith, the number of workers determines
+// how many go-routines may be used to check any given assertion, whilst the
+// limit determines the maximum number of failures reported for any given
+// assertion.
+func AssertsWith(batchsize uint, workers uint, limit uint, schema Schema, trace tr.Trace) []Failure {
+	return checkConstraints("Assertion", nil, batchsize, workers, limit, schema, schema.Assertions(), trace)
+}
+
+// Check a given set of constraints (or assertions) in batches, whilst
+// recording all failures.  When a cache is given (i.e. is not nil), constraints
+// which are known to hold already are skipped.
+func checkConstraints(logtitle string, cache *Cache, batchsize uint, workers uint, limit uint, schema Schema,
+	iter util.Iterator[Constraint], trace tr.Trace) []Failure {
 	errors := make([]Failure, 0)
 	// Initialise batch number (for debugging purposes)
 	batch := uint(0)
-	// Process assertions in batches
-	for iter := schema.Assertions(); iter.HasNext(); {
-		errs := processConstraintBatch("Assertion", batch, batchsize, iter, trace)
+	// Process constraints in batches
+	for iter.HasNext() {
+		errs := processConstraintBatch(logtitle, batch, cache, batchsize, workers, limit, schema, iter, trace)
 		errors = append(errors, errs...)
 		// Increment batch number
 		batch++
@@ -107,29 +132,46 @@ func Asserts(batchsize uint, schema Schema, trace tr.Trace) []Failure {
 	return errors
 }
 
-// Process a given set of constraints in a single batch whilst recording all constraint failures.
-func processConstraintBatch(logtitle string, batch uint, batchsize uint, iter util.Iterator[Constraint],
-	trace tr.Trace) []Failure {
-	n := uint(0)
-	c := make(chan Failure, 1024)
+// Process a given set of constraints in a single batch whilst recording all
+// constraint failures.  Failures are recorded in the order constraints are
+// given, rather than the order in which they complete.
+func processConstraintBatch(logtitle string, batch uint, cache *Cache, batchsize uint, workers uint, limit uint,
+	schema Schema, iter util.Iterator[Constraint], trace tr.Trace) []Failure {
+	var (
+		constraints []Constraint
+		wg          sync.WaitGroup
+	)
+	//
 	errors := make([]Failure, 0)
 	stats := util.NewPerfStats()
-	// Launch at most 100 go-routines.
-	for ; n < batchsize && iter.HasNext(); n++ {
-		// Get ith constraint
-		ith := iter.Next()
+	// Collect constraints for this batch
+	for n := uint(0); n < batchsize && iter.HasNext(); n++ {
+		constraints = append(constraints, iter.Next())
+	}
+	// Each checker writes its outcome into its own slot
+	outcomes := make([][]Failure, len(constraints))
+	//
+	for i, ith := range constraints {
+		wg.Add(1)
 		// Launch checker for constraint
 		go func() {
-			// Send outcome back
-			c <- ith.Accepts(trace)
+			defer wg.Done()
+			check := func() []Failure {
+				return checkConstraint(ith, workers, limit, trace)
+			}
+			//
+			if cache != nil {
+				outcomes[i] = cache.checkConstraint(ith, schema, trace, check)
+			} else {
+				outcomes[i] = check()
+			}
 		}()
 	}
+	// Wait for all checkers to finish
+	wg.Wait()
 	//
-	for i := uint(0); i < n; i++ {
-		// Read from channel
-		if e := <-c; e != nil {
-			errors = append(errors, e)
-		}
+	for _, outcome := range outcomes {
+		errors = append(errors, outcome...)
 	}
 	// Log stats about this batch
 	stats.Log(fmt.Sprintf("%s batch %d", logtitle, batch))
@@ -137,6 +179,86 @@ func processConstraintBatch(logtitle string, batch uint, batchsize uint, iter ut
 	return errors
 }
 
+// Check a given constraint, splitting its rows into disjoint shards which are
+// checked by separate workers where this is possible.  Workers check shards in
+// order of row, and no further shards are checked once the shards below them
+// have already given enough failures to reach the limit.  The failures of each
+// shard are then merged in order of shard, such that the failures reported are
+// exactly those reported by checking sequentially (i.e. lowest row first).
+func checkConstraint(constraint Constraint, workers uint, limit uint, trace tr.Trace) []Failure {
+	var (
+		height  uint
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		sharded ShardedConstraint
+		ok      bool
+		// Index of next shard to check
+		next uint
+		// Number of leading shards which are completed, and the number of
+		// failures found amongst them.
+		completed, found uint
+	)
+	// Determine height of constraint (if it can be sharded)
+	if sharded, ok = constraint.(ShardedConstraint); ok && workers > 1 {
+		height = sharded.Rows(trace)
+	}
+	// Check whether sharding is worthwhile
+	if height < 2*SHARD_HEIGHT {
+		return constraint.Accepts(trace, limit)
+	}
+	//
+	shards := (height + SHARD_HEIGHT - 1) / SHARD_HEIGHT
+	outcomes := make([][]Failure, shards)
+	done := make([]bool, shards)
+	//
+	for w := uint(0); w < min(workers, shards); w++ {
+		wg.Add(1)
+		// Launch worker
+		go func() {
+			defer wg.Done()
+			//
+			for {
+				mutex.Lock()
+				// Check whether any shards remain to be checked, and whether
+				// they are needed at all.
+				if next == shards || found >= limit {
+					mutex.Unlock()
+					return
+				}
+				//
+				i := next
+				next++
+				mutex.Unlock()
+				// Check shard
+				start := i * SHARD_HEIGHT
+				outcome := sharded.AcceptsRange(trace, start, min(height, start+SHARD_HEIGHT), limit)
+				//
+				mutex.Lock()
+				outcomes[i], done[i] = outcome, true
+				// Advance completed shards
+				for ; completed < shards && done[completed]; completed++ {
+					found += uint(len(outcomes[completed]))
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	// Wait for all workers to finish
+	wg.Wait()
+	// Merge failures in order of row
+	var failures []Failure
+	//
+	for _, outcome := range outcomes {
+		failures = append(failures, outcome...)
+		// Check whether limit reached
+		if uint(len(failures)) >= limit {
+			return failures[:limit]
+		}
+	}
+	//
+	return failures
+}
+
 // ColumnIndexOf returns the column index of the column with the given name, or
 // returns false if no matching column exists.
 func ColumnIndexOf(schema Schema, module uint, name string) (uint, bool) {
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// Source identifies the declaration in some source file from which a given
+// schema element (e.g. a constraint) originated.  Since a single declaration can
+// give rise to several elements (e.g. when a constraint containing a list is
+// lowered), this also identifies the specific sub-expression within the
+// declaration responsible.  Elements which are generated during lowering (e.g.
+// by gadgets) are attributed to the element being lowered at the time.
+type Source struct {
+	// Source file containing the originating declaration.
+	file *sexp.SourceFile
+	// Span of the originating declaration.
+	declaration sexp.Span
+	// Span of the originating sub-expression.  This is enclosed within the
+	// span of the declaration, but may be the same.
+	expr sexp.Span
+}
+
+// NewSource constructs a new source for a given declaration in a given file.
+func NewSource(file *sexp.SourceFile, declaration sexp.Span) *Source {
+	return &Source{file, declaration, declaration}
+}
+
+// File returns the source file containing the originating declaration.
+func (p *Source) File() *sexp.SourceFile {
+	return p.file
+}
+
+// Declaration returns the span of the originating declaration.
+func (p *Source) Declaration() sexp.Span {
+	return p.declaration
+}
+
+// Expr returns the span of the originating sub-expression within the
+// declaration.
+func (p *Source) Expr() sexp.Span {
+	return p.expr
+}
+
+// Narrow this source to a given sub-expression within the originating
+// declaration.
+func (p *Source) Narrow(expr sexp.Span) *Source {
+	return &Source{p.file, p.declaration, expr}
+}
+
+// Line returns the line (counting from 1) on which the originating
+// sub-expression starts.
+func (p *Source) Line() int {
+	line := p.file.FindFirstEnclosingLine(p.expr)
+	return line.Number()
+}
+
+func (p *Source) String() string {
+	return fmt.Sprintf("%s:%d", p.file.Filename(), p.Line())
+}
+
+// Sourced captures schema elements which can be traced back to the source
+// declaration from which they originated.  Elements which did not originate
+// from a source file (e.g. because they were read from a binary file) have no
+// source.
+type Sourced interface {
+	// Source returns the origin of this element, or nil if this is unknown.
+	Source() *Source
+	// SetSource sets the origin of this element.
+	SetSource(*Source)
+}
+
+// AttributeFrom attributes every element of a given iterator from a given index
+// onwards to a given source, unless that element already has a source.  This is
+// used when lowering one element (e.g. a constraint) into several, such that
+// every element arising can be traced back to the original declaration.
+func AttributeFrom[T any](elements util.Iterator[T], start uint, source *Source) {
+	if source == nil {
+		return
+	}
+	//
+	for i, n := start, elements.Count(); i < n; i++ {
+		if s, ok := any(elements.Nth(i)).(Sourced); ok && s.Source() == nil {
+			s.SetSource(source)
+		}
+	}
+}
+
+// SourceOf determines the source of a given schema element, or returns nil if
+// the element has no source.
+func SourceOf(element any) *Source {
+	if s, ok := element.(Sourced); ok {
+		return s.Source()
+	}
+	//
+	return nil
+}
@@ -134,6 +134,14 @@ func (s *SourceFile) FindFirstEnclosingLine(span Span) Line {
 	return Line{s.contents, Span{start, len(s.contents)}, num}
 }
 
+// LineAndColumn determines the line and column (both counting from 1) of a
+// given position within this source file.
+func (s *SourceFile) LineAndColumn(index int) (int, int) {
+	line := s.FindFirstEnclosingLine(Span{index, index})
+	//
+	return line.Number(), index - line.Start() + 1
+}
+
 // SyntaxError is a structured error which retains the index into the original
 // string where an error occurred, along with an error message.
 type SyntaxError struct {
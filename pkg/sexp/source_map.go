@@ -79,6 +79,19 @@ func (p *SourceMaps[T]) SyntaxErrors(node T, msg string) []SyntaxError {
 	return []SyntaxError{*err}
 }
 
+// Lookup the span for a given node, along with the source file in which it is
+// contained.  If the node is not present in any of the source maps, then false
+// is returned.
+func (p *SourceMaps[T]) Lookup(node T) (*SourceFile, Span, bool) {
+	for _, m := range p.maps {
+		if m.Has(node) {
+			return &m.srcfile, m.Get(node), true
+		}
+	}
+	//
+	return nil, Span{}, false
+}
+
 // Join a given source map into this set of source maps.  The effect of this is
 // that nodes recorded in the given source map can be accessed from this set.
 func (p *SourceMaps[T]) Join(srcmap *SourceMap[T]) {
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/json"
+)
+
+func Test_Cache_01(t *testing.T) {
+	CheckCache(t, "permute_03", `{"ST": [1,1,1], "X": [3,1,2]}`, `{"ST": [1,1,1], "X": [3,1,4]}`,
+		`{"ST": [1,1,1,1], "X": [3,1,2,4]}`)
+}
+
+func Test_Cache_02(t *testing.T) {
+	CheckCache(t, "lookup_01", `{"X": [1,2,3], "Y": [1,2,3]}`, `{"X": [1,2,4], "Y": [1,2,3]}`,
+		`{"X": [1,2,3], "Y": [3,2,1]}`)
+}
+
+func Test_Cache_03(t *testing.T) {
+	CheckCache(t, "basic_01", `{"X": [0,0,0]}`, `{"X": [0,1,0]}`, `{"X": [0,0,0,0]}`)
+}
+
+// CheckCache checks that, at every IR level, repeatedly checking a sequence of
+// traces using a cache gives exactly the same expanded traces and failures as
+// checking them without.  Each trace is checked twice in a row, such that the
+// second check reuses results from the first.
+func CheckCache(t *testing.T, test string, inputs ...string) {
+	hirSchema := compileTestFile(t, test)
+	mirSchema := lowerToMir(t, hirSchema)
+	schemas := []sc.Schema{hirSchema, mirSchema, lowerToAir(t, mirSchema, mir.LoweringConfig{})}
+	//
+	for i, schema := range schemas {
+		cache, err := sc.NewCache(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		//
+		for _, input := range append(inputs, inputs...) {
+			expected := buildTestTrace(t, schema, input)
+			expectedFailures := failureMessages(sc.AcceptsWith(100, 1, math.MaxUint, schema, expected))
+			//
+			for n := 0; n < 2; n++ {
+				actual := buildCachedTrace(t, schema, cache, input)
+				actualFailures := failureMessages(cache.Accepts(100, 1, math.MaxUint, schema, actual))
+				//
+				if !equalTraces(actual, expected) {
+					t.Errorf("%s (IR %d) expanded %s incorrectly with cache", test, i, input)
+				} else if !slices.Equal(actualFailures, expectedFailures) {
+					t.Errorf("%s (IR %d) failed %s with %v, but expected %v", test, i, input, actualFailures,
+						expectedFailures)
+				}
+			}
+		}
+	}
+}
+
+// Build a trace for a given schema from a JSON string, using a given cache
+// during trace expansion.
+func buildCachedTrace(t *testing.T, schema sc.Schema, cache *sc.Cache, input string) trace.Trace {
+	columns, err := json.FromBytes([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	tr, errs := sc.NewTraceBuilder(schema).Cache(cache).Build(columns)
+	if len(errs) > 0 {
+		t.Fatalf("Error building trace: %v\n", errs)
+	}
+	//
+	return tr
+}
+
+// Check whether two traces have identical columns (including padding).
+func equalTraces(left trace.Trace, right trace.Trace) bool {
+	if left.Width() != right.Width() {
+		return false
+	}
+	//
+	for i := uint(0); i < left.Width(); i++ {
+		lhs, rhs := left.Column(i), right.Column(i)
+		//
+		if lhs.Name() != rhs.Name() || lhs.Padding() != rhs.Padding() || lhs.Data().Len() != rhs.Data().Len() {
+			return false
+		}
+		//
+		for j := uint(0); j < lhs.Data().Len(); j++ {
+			if lhs.Data().Get(j) != rhs.Data().Get(j) {
+				return false
+			}
+		}
+	}
+	//
+	return true
+}
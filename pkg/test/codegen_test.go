@@ -0,0 +1,86 @@
+package test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/codegen"
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+func Test_GenerateGo_01(t *testing.T) {
+	CheckGenerateGo(t, false, "type_01")
+}
+
+func Test_GenerateGo_02(t *testing.T) {
+	CheckGenerateGo(t, false, "module_03")
+}
+
+func Test_GenerateGo_03(t *testing.T) {
+	CheckGenerateGo(t, true, "memory")
+}
+
+func Test_GenerateGo_04(t *testing.T) {
+	CheckGenerateGo(t, true, "wcp")
+}
+
+// CheckGenerateGo checks that valid Go code is generated for a given test, that
+// this includes a setter for every input column and, furthermore, that the
+// generated code type checks.
+func CheckGenerateGo(t *testing.T, stdlib bool, test string) {
+	filename := fmt.Sprintf("%s.lisp", test)
+	// Read constraints file
+	bytes, err := os.ReadFile(fmt.Sprintf("%s/%s", TestDir, filename))
+	// Check test file read ok
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Parse terms into an HIR schema
+	schema, errs := corset.CompileSourceFile(stdlib, false, sexp.NewSourceFile(filename, bytes))
+	// Check terms parsed ok
+	if len(errs) > 0 {
+		t.Fatalf("Error parsing %s: %v\n", filename, errs)
+	}
+	// Generate code (which is checked for syntax errors during formatting)
+	source, err := codegen.GenerateGo("trace", schema)
+	if err != nil {
+		t.Fatalf("Error generating code for %s: %s\n", filename, err)
+	}
+	// Check every input column has a setter
+	if n, m := schema.InputColumns().Count(), strings.Count(source, "Module) Set"); n != uint(m) {
+		t.Errorf("Generated %d setters for %s, but expected %d\n", m, filename, n)
+	}
+	// Type check generated code
+	checkGeneratedGo(t, filename, source)
+}
+
+// Importer used to type check generated code.  This imports packages from
+// source (rather than from compiled export data) and is shared between tests,
+// such that each imported package is only type checked once.
+var codegenImporter = importer.ForCompiler(token.NewFileSet(), "source", nil)
+
+// Parse and type check generated code.  Since the generated code imports
+// packages from this module, these are resolved relative to the current
+// directory.
+func checkGeneratedGo(t *testing.T, filename string, source string) {
+	fset := token.NewFileSet()
+	// Parse generated code
+	file, err := parser.ParseFile(fset, "trace.go", source, 0)
+	if err != nil {
+		t.Fatalf("Error parsing code generated for %s: %s\n", filename, err)
+	}
+	// Type check generated code
+	config := types.Config{Importer: codegenImporter}
+	//
+	if _, err := config.Check("trace", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("Error type checking code generated for %s: %s\n", filename, err)
+	}
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+func Test_Degree_01(t *testing.T) {
+	CheckDegree(t, 0, 3, "(* X Y Z)")
+}
+
+func Test_Degree_02(t *testing.T) {
+	CheckDegree(t, 0, 1, "(+ X (- Y 1) (* 2 Z))")
+}
+
+func Test_Degree_03(t *testing.T) {
+	CheckDegree(t, 0, 4, "(* X Y (+ Z (* X Y)))")
+}
+
+func Test_Degree_04(t *testing.T) {
+	CheckDegree(t, 2, 2, "(* X Y Z)")
+}
+
+func Test_Degree_05(t *testing.T) {
+	CheckDegree(t, 3, 3, "(* X Y Z (shift X 1) (- Y 1) Z)")
+}
+
+func Test_Degree_06(t *testing.T) {
+	CheckDegree(t, 3, 3, "(* X Y (+ Z (* X Y)))")
+}
+
+func Test_Degree_07(t *testing.T) {
+	CheckDegree(t, 2, 2, "(* Z (~ (+ X Y)))")
+}
+
+func Test_Degree_08(t *testing.T) {
+	CheckDegree(t, 2, 2, "(* Z (shift X 1) (shift Y 1) (- Y 1))")
+}
+
+func Test_Degree_09(t *testing.T) {
+	CheckDegree(t, 2, 2, "(* (shift X -1) Y (+ (shift X -1) (shift Y 1)))")
+}
+
+// Reducing this constraint would change the rows on which it is checked,
+// hence it cannot be bounded.
+func Test_Degree_10(t *testing.T) {
+	CheckDegreeInvalid(t, 2, "(* (+ (shift X -1) (shift Y 1)) (+ (shift X -1) (shift Y 1)) Z)")
+}
+
+func Test_Degree_11(t *testing.T) {
+	CheckDegree(t, 3, 3, "(* (+ (shift X -1) (shift Y 1)) (+ (shift X -1) (shift Y 1)) Z)")
+}
+
+// CheckDegree checks that lowering a given constraint to AIR, using a given
+// maximum degree (where 0 means unbounded), produces vanishing constraints
+// whose maximum degree is as expected.
+func CheckDegree(t *testing.T, maxDegree uint, expected uint, input string) {
+	hirSchema := compileDegreeTest(t, input)
+	schema := lowerToAir(t, lowerToMir(t, hirSchema), mir.LoweringConfig{MaxDegree: maxDegree})
+	actual := uint(0)
+	//
+	for iter := schema.Constraints(); iter.HasNext(); {
+		if vc, ok := iter.Next().(air.VanishingConstraint); ok {
+			actual = max(actual, vc.Constraint().Expr.Degree())
+		}
+	}
+	//
+	if actual != expected {
+		t.Errorf("lowering %s gave degree %d, but expected %d", input, actual, expected)
+	}
+}
+
+// CheckDegreeInvalid checks that lowering a given constraint to AIR fails, since
+// it cannot be bounded to a given maximum degree.
+func CheckDegreeInvalid(t *testing.T, maxDegree uint, input string) {
+	hirSchema := compileDegreeTest(t, input)
+	//
+	if _, errs := lowerToMir(t, hirSchema).LowerToAirWith(mir.LoweringConfig{MaxDegree: maxDegree}); len(errs) == 0 {
+		t.Errorf("lowering %s with maximum degree %d should have failed", input, maxDegree)
+	}
+}
+
+// Compile a single constraint over columns X, Y and Z.
+func compileDegreeTest(t *testing.T, input string) *hir.Schema {
+	src := "(defpurefun ((vanishes! :@loob) x) x)\n(defcolumns X Y Z)\n" +
+		"(defconstraint test () (vanishes! " + input + "))"
+	// Compile source
+	hirSchema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile("test.lisp", []byte(src)))
+	if len(errs) > 0 {
+		t.Fatalf("Error compiling %s: %v\n", input, errs)
+	}
+	//
+	return hirSchema
+}
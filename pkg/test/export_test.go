@@ -0,0 +1,37 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/air/export"
+)
+
+func Test_ExportPolynomial_01(t *testing.T) {
+	// (X0 + Y0) * ... * (X7 + Y7) has exactly 256 terms
+	CheckExportPolynomial(t, 8, true)
+}
+
+func Test_ExportPolynomial_02(t *testing.T) {
+	// (X0 + Y0) * ... * (X8 + Y8) has 512 terms
+	CheckExportPolynomial(t, 9, false)
+}
+
+// CheckExportPolynomial checks whether a product of n sums over distinct
+// columns can be converted into a polynomial, which should only be possible
+// when the number of terms (i.e. 2^n) does not exceed the maximum.
+func CheckExportPolynomial(t *testing.T, n uint, expected bool) {
+	args := make([]air.Expr, n)
+	//
+	for i := range args {
+		args[i] = air.NewColumnAccess(uint(2*i), 0).Add(air.NewColumnAccess(uint(2*i+1), 0))
+	}
+	//
+	poly, ok := export.NewPolynomial(&air.Mul{Args: args})
+	//
+	if ok != expected {
+		t.Errorf("conversion of product of %d sums gave %t, but expected %t", n, ok, expected)
+	} else if ok && len(poly) != 1<<n {
+		t.Errorf("conversion of product of %d sums gave %d terms, but expected %d", n, len(poly), 1<<n)
+	}
+}
@@ -0,0 +1,397 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/json"
+)
+
+func Test_CollectAll_01(t *testing.T) {
+	CheckCollectAll(t, "basic_01", `{"X": [0,1,0,2,3]}`, "heartbeat", 2, 4, 5)
+}
+
+func Test_CollectAll_02(t *testing.T) {
+	CheckCollectAll(t, "lookup_01", `{"X": [1,2,3,4,5], "Y": [1,3,1,1,1]}`, "test", 2, 4, 5)
+}
+
+func Test_CollectAll_03(t *testing.T) {
+	CheckCollectAll(t, "basic_01", `{"X": [0,0,0]}`, "heartbeat")
+}
+
+// CheckCollectAll checks that, at every IR level, the failures reported for a
+// given constraint on a given trace are exactly those expected when collecting
+// all failures.  Likewise, that the number of failures reported is correctly
+// limited otherwise.  Observe that rows are offset by the spillage row added
+// when the trace is built.
+func CheckCollectAll(t *testing.T, test string, input string, handle string, rows ...uint) {
+	filename := fmt.Sprintf("%s.lisp", test)
+	// Read constraints file
+	bytes, err := os.ReadFile(fmt.Sprintf("%s/%s", TestDir, filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Parse terms into an HIR schema
+	hirSchema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile(filename, bytes))
+	if len(errs) > 0 {
+		t.Fatalf("Error parsing %s: %v\n", filename, errs)
+	}
+	//
+	columns, err := json.FromBytes([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	mirSchema := lowerToMir(t, hirSchema)
+	schemas := []sc.Schema{hirSchema, mirSchema, lowerToAir(t, mirSchema, mir.LoweringConfig{})}
+	//
+	for i, schema := range schemas {
+		trace, errs := sc.NewTraceBuilder(schema).Build(columns)
+		if len(errs) > 0 {
+			t.Fatalf("Error building trace for %s: %v\n", filename, errs)
+		}
+		// Check all failures reported
+		actual := failingRows(handle, sc.AcceptsWith(100, 1, math.MaxUint, schema, trace))
+		//
+		if !slices.Equal(actual, rows) {
+			t.Errorf("%s (IR %d) failed on rows %v, but expected %v", test, i, actual, rows)
+		}
+		// Check failures limited
+		for limit := uint(1); limit <= 2; limit++ {
+			expected := rows[:min(limit, uint(len(rows)))]
+			//
+			actual = failingRows(handle, sc.AcceptsWith(100, 1, limit, schema, trace))
+			//
+			if !slices.Equal(actual, expected) {
+				t.Errorf("%s (IR %d, limit %d) failed on rows %v, but expected %v", test, i, limit, actual, expected)
+			}
+		}
+	}
+}
+
+// Determine the rows on which a given constraint failed.
+func failingRows(handle string, failures []sc.Failure) []uint {
+	rows := make([]uint, 0)
+	//
+	for _, failure := range failures {
+		switch f := failure.(type) {
+		case *constraint.VanishingFailure:
+			if f.Handle() == handle {
+				rows = append(rows, f.Row())
+			}
+		case *constraint.LookupFailure:
+			if f.Handle() == handle {
+				rows = append(rows, f.Row())
+			}
+		}
+	}
+	//
+	slices.Sort(rows)
+	//
+	return rows
+}
+
+func Test_Locate_01(t *testing.T) {
+	CheckLocate(t, "basic_01", "heartbeat", 2, 1)
+	CheckLocate(t, "basic_01", "X", 1, 13)
+}
+
+func Test_Locate_02(t *testing.T) {
+	CheckLocate(t, "lookup_01", "test", 2, 1)
+	CheckLocate(t, "lookup_01", "Y", 1, 15)
+}
+
+func Test_Locate_03(t *testing.T) {
+	CheckLocate(t, "module_01", "heartbeat", 3, 1)
+	CheckLocate(t, "module_01", "test:X", 2, 13)
+}
+
+func Test_FailureCells_01(t *testing.T) {
+	schema := compileTestFile(t, "lookup_01")
+	trace := buildTestTrace(t, schema, `{"X": [1,2,3,4,5], "Y": [1,3,1,1,1]}`)
+	//
+	for _, failure := range sc.AcceptsWith(100, 1, math.MaxUint, schema, trace) {
+		f := failure.(*constraint.LookupFailure)
+		cells := f.RequiredCells(trace).ToArray()
+		// Lookup sources are evaluated only at the failing row
+		if len(cells) != 1 || trace.Column(cells[0].Column).Name() != "X" || cells[0].Row != int(f.Row()) {
+			t.Errorf("unexpected cells %v for failure %s", cells, f.Message())
+		}
+	}
+}
+
+// CheckLocate checks that the source of a given constraint (identified by its
+// handle) or column (identified by its qualified name) starts at the expected
+// line and column of its source file.
+func CheckLocate(t *testing.T, test string, name string, line int, column int) {
+	var src *sc.Source
+	//
+	schema := compileTestFile(t, test)
+	// Check constraints
+	for iter := schema.Constraints(); iter.HasNext() && src == nil; {
+		if c := iter.Next(); sc.HandleOf(c, schema) == name {
+			src = sc.SourceOf(c)
+		}
+	}
+	// Check columns
+	for cid, iter := uint(0), schema.Columns(); iter.HasNext() && src == nil; cid++ {
+		if iter.Next().QualifiedName(schema) == name {
+			src = sc.SourceOf(schema.Declaration(cid))
+		}
+	}
+	//
+	if src == nil {
+		t.Fatalf("%s not located", name)
+	}
+	//
+	span := src.Declaration()
+	//
+	if l, c := src.File().LineAndColumn(span.Start()); l != line || c != column {
+		t.Errorf("%s located at %d:%d, but expected %d:%d", name, l, c, line, column)
+	}
+}
+
+// Compile a given test file into an HIR schema.
+func compileTestFile(t *testing.T, test string) *hir.Schema {
+	filename := fmt.Sprintf("%s.lisp", test)
+	// Read constraints file
+	bytes, err := os.ReadFile(fmt.Sprintf("%s/%s", TestDir, filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Parse terms into an HIR schema
+	schema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile(filename, bytes))
+	if len(errs) > 0 {
+		t.Fatalf("Error parsing %s: %v\n", filename, errs)
+	}
+	//
+	return schema
+}
+
+// Build a trace for a given schema from a JSON string.
+func buildTestTrace(t *testing.T, schema sc.Schema, input string) trace.Trace {
+	columns, err := json.FromBytes([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	tr, errs := sc.NewTraceBuilder(schema).Build(columns)
+	if len(errs) > 0 {
+		t.Fatalf("Error building trace: %v\n", errs)
+	}
+	//
+	return tr
+}
+
+func Test_Explain_01(t *testing.T) {
+	CheckExplain(t, "if_02", `{"A": [0,1], "B": [3,0], "C": [0,5]}`, 1,
+		"(if A B C) = 3\n├── A = 0 [condition]\n├── B = 3 [true branch]\n"+
+			"└── C [false branch, not taken]\n")
+}
+
+func Test_Explain_02(t *testing.T) {
+	CheckExplain(t, "if_02", `{"A": [0,1], "B": [3,0], "C": [0,5]}`, 2,
+		"(if A B C) = 5\n├── A = 1 [condition]\n├── B [true branch, not taken]\n"+
+			"└── C = 5 [false branch]\n")
+}
+
+func Test_Explain_03(t *testing.T) {
+	CheckExplain(t, "basic_01", `{"X": [0,1,0,2,3]}`, 2, "X = 1\n")
+}
+
+// CheckExplain checks the explanation of a failing constraint at the HIR level
+// on a given row, and that every failure at the HIR and MIR levels has an
+// explanation which evaluates to a non-zero value.
+func CheckExplain(t *testing.T, test string, input string, row uint, expected string) {
+	hirSchema := compileTestFile(t, test)
+	schemas := []sc.Schema{hirSchema, lowerToMir(t, hirSchema)}
+	found := false
+	//
+	for i, schema := range schemas {
+		trace := buildTestTrace(t, schema, input)
+		//
+		for _, failure := range sc.AcceptsWith(100, 1, math.MaxUint, schema, trace) {
+			f := failure.(*constraint.VanishingFailure)
+			explanation := f.Constraint().(sc.Explainable).Explain(int(f.Row()), trace, schema)
+			//
+			if explanation == nil {
+				t.Fatalf("%s (IR %d) has no explanation for %s", test, i, f.Message())
+			} else if !slices.ContainsFunc(explanation.Values(), func(v fr.Element) bool { return !v.IsZero() }) {
+				t.Errorf("%s (IR %d) explanation of %s does not fail:\n%s", test, i, f.Message(), explanation)
+			} else if i == 0 && f.Row() == row {
+				found = true
+				//
+				if actual := explanation.String(); actual != expected {
+					t.Errorf("%s explained as:\n%s\nbut expected:\n%s", test, actual, expected)
+				}
+			}
+		}
+	}
+	//
+	if !found {
+		t.Errorf("%s did not fail on row %d", test, row)
+	}
+}
+
+func Test_Multiplicity_01(t *testing.T) {
+	// Row zero is the spillage row, whose source (0) matches the target (0).
+	CheckMultiplicity(t, "lookup_01", `{"X": [1,1,2], "Y": [1,2,3]}`, 1, 2, 1, 0)
+}
+
+func Test_Multiplicity_02(t *testing.T) {
+	// Duplicate targets are counted only once, against the first occurrence.
+	CheckMultiplicity(t, "lookup_01", `{"X": [2,2,2], "Y": [2,1,2]}`, 1, 3, 0, 0)
+}
+
+func Test_Multiplicity_03(t *testing.T) {
+	// Unselected rows are not counted.
+	CheckMultiplicity(t, "lookup_09", `{"F": [1,0,1], "A": [2,3,2], "B": [2,3,4]}`, 0, 2, 0, 0)
+}
+
+func Test_Multiplicity_04(t *testing.T) {
+	CheckMultiplicityFailure(t, `{"X": [1,1,2], "Y": [1,2,3], "(multiplicity test)": [2,1,0]}`)
+	CheckMultiplicityFailure(t, `{"X": [1,1,2], "Y": [1,2,3], "(multiplicity test)": [1,1,0]}`, 0)
+	CheckMultiplicityFailure(t, `{"X": [1,1,2], "Y": [1,2,3], "(multiplicity test)": [2,1,1]}`, 2)
+	CheckMultiplicityFailure(t, `{"X": [1,1,2], "Y": [1,2,3], "(multiplicity test)": [3,0,1]}`, 0, 1, 2)
+}
+
+// CheckMultiplicity checks the multiplicity column computed for the lookup of
+// a given test, when lowered to a log-derivative lookup at the AIR level.
+func CheckMultiplicity(t *testing.T, test string, input string, expected ...uint64) {
+	hirSchema := compileTestFile(t, test)
+	schema := lowerToAir(t, lowerToMir(t, hirSchema), mir.LoweringConfig{LogDerivativeLookups: true})
+	trace := buildTestTrace(t, schema, input)
+	// Sanity check trace is accepted
+	if failures := sc.AcceptsWith(100, 1, math.MaxUint, schema, trace); len(failures) > 0 {
+		t.Errorf("%s rejected incorrectly: %v", test, failures)
+	}
+	//
+	column, ok := sc.ColumnIndexOf(schema, 0, "(multiplicity test)")
+	if !ok {
+		t.Fatalf("%s has no multiplicity column", test)
+	}
+	//
+	actual := make([]uint64, trace.Height(trace.Column(column).Context()))
+	for i := range actual {
+		val := trace.Column(column).Get(i)
+		actual[i] = val.Uint64()
+	}
+	//
+	if !slices.Equal(actual, expected) {
+		t.Errorf("%s has multiplicities %v, but expected %v", test, actual, expected)
+	}
+}
+
+// CheckMultiplicityFailure checks that the multiplicities given in an
+// (expanded) trace for the log-derivative lookup of lookup_01 are rejected on
+// exactly the expected target rows.
+func CheckMultiplicityFailure(t *testing.T, input string, rows ...uint) {
+	hirSchema := compileTestFile(t, "lookup_01")
+	schema := lowerToAir(t, lowerToMir(t, hirSchema), mir.LoweringConfig{LogDerivativeLookups: true})
+	//
+	columns, err := json.FromBytes([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	trace, errs := sc.NewTraceBuilder(schema).Expand(false).Build(columns)
+	if len(errs) > 0 {
+		t.Fatalf("Error building trace: %v\n", errs)
+	}
+	//
+	actual := make([]uint, 0)
+	//
+	for _, failure := range sc.AcceptsWith(100, 1, math.MaxUint, schema, trace) {
+		actual = append(actual, failure.(*constraint.MultiplicityFailure).Row())
+	}
+	//
+	if !slices.Equal(actual, rows) {
+		t.Errorf("%s failed on rows %v, but expected %v", input, actual, rows)
+	}
+}
+
+func Test_Sharded_01(t *testing.T) {
+	CheckSharded(t, "basic_01", fmt.Sprintf(`{"X": %s}`, shardedColumn(10000, 3, 1500, 4099, 9999)))
+}
+
+func Test_Sharded_02(t *testing.T) {
+	CheckSharded(t, "shift_01", fmt.Sprintf(`{"X": %s, "ST": %s}`,
+		shardedColumn(5000, 0, 1249, 1250, 2500, 4999), shardedColumn(5000, 0, 1248, 1249, 2499, 4998)))
+}
+
+func Test_Sharded_03(t *testing.T) {
+	CheckSharded(t, "domain_04", fmt.Sprintf(`{"X": %s}`, shardedColumn(5000, 0, 2500, 4997, 4998, 4999)))
+}
+
+func Test_Sharded_04(t *testing.T) {
+	CheckSharded(t, "domain_06", fmt.Sprintf(`{"X": %s}`, shardedColumn(5000, 4992, 4993, 4996, 4997, 4999)))
+}
+
+// CheckSharded checks that, at every IR level, the failures reported when the
+// rows of each constraint are split across several workers are exactly those
+// (and in exactly the same order) as when they are checked sequentially.
+func CheckSharded(t *testing.T, test string, input string) {
+	hirSchema := compileTestFile(t, test)
+	mirSchema := lowerToMir(t, hirSchema)
+	schemas := []sc.Schema{hirSchema, mirSchema, lowerToAir(t, mirSchema, mir.LoweringConfig{})}
+	//
+	for i, schema := range schemas {
+		trace := buildTestTrace(t, schema, input)
+		//
+		for _, limit := range []uint{1, 2, math.MaxUint} {
+			expected := failureMessages(sc.AcceptsWith(100, 1, limit, schema, trace))
+			// Sanity check failures arise
+			if len(expected) == 0 {
+				t.Errorf("%s (IR %d, limit %d) expected failures", test, i, limit)
+			}
+			//
+			for _, workers := range []uint{2, 3, 8} {
+				actual := failureMessages(sc.AcceptsWith(100, workers, limit, schema, trace))
+				//
+				if !slices.Equal(actual, expected) {
+					t.Errorf("%s (IR %d, limit %d, workers %d) failed with %v, but expected %v", test, i, limit,
+						workers, actual, expected)
+				}
+			}
+		}
+	}
+}
+
+// Construct a JSON column of the given height, which is zero everywhere
+// except on the given rows.
+func shardedColumn(height uint, rows ...uint) string {
+	values := make([]string, height)
+	//
+	for i := range values {
+		if slices.Contains(rows, uint(i)) {
+			values[i] = "1"
+		} else {
+			values[i] = "0"
+		}
+	}
+	//
+	return fmt.Sprintf("[%s]", strings.Join(values, ","))
+}
+
+// Determine the messages of the given failures, in the order reported.
+func failureMessages(failures []sc.Failure) []string {
+	messages := make([]string, len(failures))
+	//
+	for i, failure := range failures {
+		messages[i] = failure.Message()
+	}
+	//
+	return messages
+}
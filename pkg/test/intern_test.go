@@ -0,0 +1,62 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+func Test_Intern_01(t *testing.T) {
+	CheckInterning(t, 1, 0, "(* Z (~ X))", "(* (- Z 1) (~ X))")
+}
+
+func Test_Intern_02(t *testing.T) {
+	CheckInterning(t, 1, 1, "(* Z (~ (+ X Y)))", "(* (- Z 1) (~ (+ Y X)))", "(* Z (~ (+ X Y)))")
+}
+
+func Test_Intern_03(t *testing.T) {
+	CheckInterning(t, 2, 0, "(* Z (~ (- X Y)))", "(* Z (~ (- Y X)))")
+}
+
+func Test_Intern_04(t *testing.T) {
+	CheckInterning(t, 2, 1, "(* Z (~ (* X Y)))", "(* Z (~ (* Y X)))", "(* Z (~ (+ X (shift Y -1))))")
+}
+
+// CheckInterning checks that lowering a given set of constraints to AIR
+// introduces the expected number of inverse columns, and reuses them the
+// expected number of times.  Furthermore, it checks the resulting AIR schema
+// still accepts (and rejects) the same traces as the HIR schema.
+func CheckInterning(t *testing.T, columns uint, saved uint, constraints ...string) {
+	src := "(defpurefun ((vanishes! :@loob) x) x)\n(defcolumns X Y Z)\n"
+	//
+	for _, c := range constraints {
+		src += "(defconstraint test () (vanishes! " + c + "))\n"
+	}
+	// Compile source
+	hirSchema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile("test.lisp", []byte(src)))
+	if len(errs) > 0 {
+		t.Fatalf("Error compiling %v: %v\n", constraints, errs)
+	}
+	//
+	airSchema := lowerToAir(t, lowerToMir(t, hirSchema), mir.LoweringConfig{})
+	// Count computed columns
+	if n := airSchema.Assignments().Count(); n != columns {
+		t.Errorf("lowering %v introduced %d inverse columns, but expected %d", constraints, n, columns)
+	} else if airSchema.SavedColumns() != saved {
+		t.Errorf("lowering %v saved %d inverse columns, but expected %d", constraints, airSchema.SavedColumns(), saved)
+	}
+	// Check traces accepted (or rejected) consistently
+	for _, input := range []string{`{"X": [0,1,2], "Y": [0,0,1], "Z": [0,0,0]}`,
+		`{"X": [0,1,2], "Y": [0,0,1], "Z": [1,0,0]}`, `{"X": [1,1,2], "Y": [0,2,1], "Z": [1,1,0]}`} {
+		expected := len(sc.AcceptsWith(100, 1, math.MaxUint, hirSchema, buildTestTrace(t, hirSchema, input))) == 0
+		actual := len(sc.AcceptsWith(100, 1, math.MaxUint, airSchema, buildTestTrace(t, airSchema, input))) == 0
+		//
+		if actual != expected {
+			t.Errorf("lowering %v changed whether %s is accepted", constraints, input)
+		}
+	}
+}
@@ -225,6 +225,22 @@ func Test_Invalid_Norm_01(t *testing.T) {
 	CheckInvalid(t, "norm_invalid_01")
 }
 
+// ===================================================================
+// Domain Tests
+// ===================================================================
+
+func Test_Invalid_Domain_01(t *testing.T) {
+	CheckInvalid(t, "domain_invalid_01")
+}
+
+func Test_Invalid_Domain_02(t *testing.T) {
+	CheckInvalid(t, "domain_invalid_02")
+}
+
+func Test_Invalid_Domain_03(t *testing.T) {
+	CheckInvalid(t, "domain_invalid_03")
+}
+
 // ===================================================================
 // If-Zero
 // ===================================================================
@@ -336,6 +352,34 @@ func Test_Invalid_Permute_08(t *testing.T) {
 	CheckInvalid(t, "permute_invalid_08")
 }
 
+func Test_Invalid_Permute_09(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_09")
+}
+
+func Test_Invalid_Permute_10(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_10")
+}
+
+func Test_Invalid_Permute_11(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_11")
+}
+
+func Test_Invalid_Permute_12(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_12")
+}
+
+func Test_Invalid_Permute_13(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_13")
+}
+
+func Test_Invalid_Permute_14(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_14")
+}
+
+func Test_Invalid_Permute_15(t *testing.T) {
+	CheckInvalid(t, "permute_invalid_15")
+}
+
 // ===================================================================
 // Lookups
 // ===================================================================
@@ -370,6 +414,18 @@ func Test_Invalid_Lookup_08(t *testing.T) {
 func Test_Invalid_Lookup_09(t *testing.T) {
 	CheckInvalid(t, "lookup_invalid_09")
 }
+func Test_Invalid_Lookup_10(t *testing.T) {
+	CheckInvalid(t, "lookup_invalid_10")
+}
+func Test_Invalid_Lookup_11(t *testing.T) {
+	CheckInvalid(t, "lookup_invalid_11")
+}
+func Test_Invalid_Lookup_12(t *testing.T) {
+	CheckInvalid(t, "lookup_invalid_12")
+}
+func Test_Invalid_Lookup_13(t *testing.T) {
+	CheckInvalid(t, "lookup_invalid_13")
+}
 
 // ===================================================================
 // Interleavings
@@ -423,6 +479,142 @@ func Test_Invalid_Interleave_12(t *testing.T) {
 	CheckInvalid(t, "interleave_invalid_12")
 }
 
+// ===================================================================
+// Computed Columns
+// ===================================================================
+
+func Test_Invalid_Computed_01(t *testing.T) {
+	CheckInvalid(t, "computed_invalid_01")
+}
+
+func Test_Invalid_Computed_02(t *testing.T) {
+	CheckInvalid(t, "computed_invalid_02")
+}
+
+func Test_Invalid_Computed_03(t *testing.T) {
+	CheckInvalid(t, "computed_invalid_03")
+}
+
+func Test_Invalid_Computed_04(t *testing.T) {
+	CheckInvalid(t, "computed_invalid_04")
+}
+
+func Test_Invalid_Computed_05(t *testing.T) {
+	CheckInvalid(t, "computed_invalid_05")
+}
+
+func Test_Invalid_Computed_06(t *testing.T) {
+	CheckInvalid(t, "computed_invalid_06")
+}
+
+// ===================================================================
+// Bitwise
+// ===================================================================
+
+func Test_Invalid_Bitwise_01(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_01")
+}
+
+func Test_Invalid_Bitwise_02(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_02")
+}
+
+func Test_Invalid_Bitwise_03(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_03")
+}
+
+func Test_Invalid_Bitwise_04(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_04")
+}
+
+func Test_Invalid_Bitwise_05(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_05")
+}
+
+func Test_Invalid_Bitwise_06(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_06")
+}
+
+func Test_Invalid_Bitwise_07(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_07")
+}
+
+func Test_Invalid_Bitwise_08(t *testing.T) {
+	CheckInvalid(t, "bitwise_invalid_08")
+}
+
+// ===================================================================
+// Let
+// ===================================================================
+
+func Test_Invalid_Let_01(t *testing.T) {
+	CheckInvalid(t, "let_invalid_01")
+}
+
+func Test_Invalid_Let_02(t *testing.T) {
+	CheckInvalid(t, "let_invalid_02")
+}
+
+func Test_Invalid_Let_03(t *testing.T) {
+	CheckInvalid(t, "let_invalid_03")
+}
+
+func Test_Invalid_Let_04(t *testing.T) {
+	CheckInvalid(t, "let_invalid_04")
+}
+
+func Test_Invalid_Let_05(t *testing.T) {
+	CheckInvalid(t, "let_invalid_05")
+}
+
+func Test_Invalid_Let_06(t *testing.T) {
+	CheckInvalid(t, "let_invalid_06")
+}
+
+func Test_Invalid_Let_07(t *testing.T) {
+	CheckInvalid(t, "let_invalid_07")
+}
+
+// ===================================================================
+// Match
+// ===================================================================
+
+func Test_Invalid_Match_01(t *testing.T) {
+	CheckInvalid(t, "match_invalid_01")
+}
+
+func Test_Invalid_Match_02(t *testing.T) {
+	CheckInvalid(t, "match_invalid_02")
+}
+
+func Test_Invalid_Match_03(t *testing.T) {
+	CheckInvalid(t, "match_invalid_03")
+}
+
+func Test_Invalid_Match_04(t *testing.T) {
+	CheckInvalid(t, "match_invalid_04")
+}
+
+func Test_Invalid_Match_05(t *testing.T) {
+	CheckInvalid(t, "match_invalid_05")
+}
+
+func Test_Invalid_Match_06(t *testing.T) {
+	CheckInvalid(t, "match_invalid_06")
+}
+
+func Test_Invalid_Match_07(t *testing.T) {
+	CheckInvalid(t, "match_invalid_07")
+}
+
+func Test_Invalid_Match_08(t *testing.T) {
+	CheckInvalid(t, "match_invalid_08")
+}
+
+func Test_Invalid_Match_09(t *testing.T) {
+	CheckInvalid(t, "match_invalid_09")
+}
+
 // ===================================================================
 // Functions
 // ===================================================================
@@ -578,6 +770,34 @@ func Test_Invalid_Debug_02(t *testing.T) {
 	CheckInvalid(t, "debug_invalid_02")
 }
 
+// ===================================================================
+// Perspectives
+// ===================================================================
+
+func Test_Invalid_Perspective_01(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_01")
+}
+
+func Test_Invalid_Perspective_02(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_02")
+}
+
+func Test_Invalid_Perspective_03(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_03")
+}
+
+func Test_Invalid_Perspective_04(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_04")
+}
+
+func Test_Invalid_Perspective_05(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_05")
+}
+
+func Test_Invalid_Perspective_06(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_06")
+}
+
 // ===================================================================
 // Test Helpers
 // ===================================================================
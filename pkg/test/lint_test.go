@@ -0,0 +1,145 @@
+package test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/binfile"
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+func Test_Lint_01(t *testing.T) {
+	CheckLint(t, "(defconstraint c1 () (vanishes! (+ X Y)))")
+}
+
+func Test_Lint_02(t *testing.T) {
+	CheckLint(t, "(defconstraint c1 () (vanishes! X))", "Y: column is never used")
+}
+
+func Test_Lint_03(t *testing.T) {
+	CheckLint(t, "(defcomputedcolumn (Z) (+ Y 1))\n(defconstraint c1 () (vanishes! X))",
+		"Y: column is never constrained", "Z: column is never used")
+}
+
+func Test_Lint_04(t *testing.T) {
+	CheckLint(t, "(defcomputedcolumn (Z) (+ Y 1))\n(defconstraint c1 () (vanishes! (* X Z)))")
+}
+
+func Test_Lint_05(t *testing.T) {
+	CheckLint(t, "(defcolumns (P :i16))\n(defpermutation (A) ((+ P)))\n(defconstraint c1 () (vanishes! (+ X Y)))",
+		"A: column is never used")
+}
+
+func Test_Lint_06(t *testing.T) {
+	CheckLint(t, "(defconstraint c1 () (vanishes! X))\n(defconstraint c2 (:domain {0}) (vanishes! Y))",
+		"c2: constraint applies only to padding rows")
+}
+
+func Test_Lint_07(t *testing.T) {
+	CheckLint(t, "(defconstraint c1 (:domain {-1}) (vanishes! (+ X Y)))")
+}
+
+func Test_Lint_08(t *testing.T) {
+	CheckLint(t, "(defcolumns (B :byte@prove))\n(defconstraint c1 () (vanishes! (+ X Y B)))")
+}
+
+func Test_Lint_09(t *testing.T) {
+	CheckLint(t, "(defcolumns (B :byte@prove))", "X: column is never used", "Y: column is never used")
+}
+
+func Test_Lint_10(t *testing.T) {
+	CheckLintBinfile(t, `{"columns": {"_cols": [{"handle": "m.A", "register": 0, "must_prove": true}],
+		"registers": [{"handle": "m.A", "magma": {"m": {"Integer": 8}}, "length_multiplier": 1}]}}`)
+}
+
+func Test_Lint_11(t *testing.T) {
+	CheckLintBinfile(t, `{"columns": {"_cols": [{"handle": "m.A", "register": 0, "must_prove": true}],
+		"registers": [{"handle": "m.A", "magma": {"m": "Native"}, "length_multiplier": 1}]}}`,
+		"m.A: column is never used", "m.A: proven column has no range constraint")
+}
+
+func Test_Lint_12(t *testing.T) {
+	CheckProvenColumns(t, "(defcolumns (B :byte@prove) C)\n(defcomputedcolumn (D :i8@prove) (* X Y))", "B", "D")
+}
+
+func Test_Lint_13(t *testing.T) {
+	CheckProvenColumns(t, "(defcolumns (P :binary@bool))\n(defperspective p P ((A :byte@prove) (B :byte)))", "p/A")
+}
+
+// CheckProvenColumns checks that compiling a given set of constraints (over
+// columns X and Y) records exactly the expected columns as requiring their
+// types to be proven, and that this is retained when the schema is compiled
+// into a binary file.
+func CheckProvenColumns(t *testing.T, constraints string, expected ...string) {
+	src := "(defcolumns X Y)\n" + constraints
+	// Compile source
+	schema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile("test.lisp", []byte(src)))
+	if len(errs) > 0 {
+		t.Fatalf("Error compiling %s: %v\n", constraints, errs)
+	}
+	// Round trip through binary file
+	bytes, err := hir.ToBytes(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	binSchema, err := hir.FromBytes(bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	for _, s := range []*hir.Schema{schema, binSchema} {
+		var actual []string
+		//
+		for _, cid := range s.ProvenColumns() {
+			actual = append(actual, s.Columns().Nth(cid).QualifiedName(s))
+		}
+		//
+		if !slices.Equal(actual, expected) {
+			t.Errorf("proven columns for %s were %v, but expected %v", constraints, actual, expected)
+		}
+	}
+}
+
+// CheckLint checks that linting a given set of constraints (over columns X and
+// Y) gives exactly the expected warnings, in any order.
+func CheckLint(t *testing.T, constraints string, expected ...string) {
+	src := "(defpurefun ((vanishes! :@loob) x) x)\n(defcolumns X Y)\n" + constraints
+	srcfile := sexp.NewSourceFile("test.lisp", []byte(src))
+	// Compile source
+	schema, errs := corset.CompileSourceFile(false, false, srcfile)
+	if len(errs) > 0 {
+		t.Fatalf("Error compiling %s: %v\n", constraints, errs)
+	}
+	//
+	checkLintWarnings(t, constraints, hir.Lint(schema), expected)
+}
+
+// CheckLintBinfile checks that linting a given binary (JSON) file gives exactly
+// the expected warnings, in any order.
+func CheckLintBinfile(t *testing.T, input string, expected ...string) {
+	schema, err := binfile.HirSchemaFromJson([]byte(input))
+	if err != nil {
+		t.Fatalf("Error reading %s: %v\n", input, err)
+	}
+	//
+	checkLintWarnings(t, input, hir.Lint(schema), expected)
+}
+
+func checkLintWarnings(t *testing.T, input string, warnings []hir.LintWarning, expected []string) {
+	var actual []string
+	//
+	for _, w := range warnings {
+		actual = append(actual, w.String())
+	}
+	//
+	slices.Sort(actual)
+	expected = slices.Clone(expected)
+	slices.Sort(expected)
+	//
+	if !slices.Equal(actual, expected) {
+		t.Errorf("linting %s gave %v, but expected %v", input, actual, expected)
+	}
+}
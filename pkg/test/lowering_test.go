@@ -0,0 +1,104 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/binfile"
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+func Test_Lowering_01(t *testing.T) {
+	CheckLoweringErrors(t, "(defconstraint c1 () (vanishes! (+ 1 (* 0 X))))", "c1:3")
+}
+
+func Test_Lowering_02(t *testing.T) {
+	CheckLoweringErrors(t, "(defconstraint c1 () (vanishes! X))\n(defconstraint c2 () (vanishes! (+ 2 (* X 0))))",
+		"c2:4")
+}
+
+func Test_Lowering_03(t *testing.T) {
+	CheckLoweringErrors(t, "(defconstraint c1 () (vanishes! (* 0 X)))\n"+
+		"(defconstraint c2 () (vanishes! (- 1 (* X 0))))\n"+
+		"(defconstraint c3 () (vanishes! (+ (* X 0) 1)))", "c2:4", "c3:5")
+}
+
+func Test_Lowering_04(t *testing.T) {
+	CheckBinfileErrors(t, `{"constraints": [{"Lookup": {"handle": "l1", "included": [], "including": []}}]}`)
+}
+
+func Test_Lowering_05(t *testing.T) {
+	CheckBinfileErrors(t, `{"constraints": [{}]}`, "unknown")
+}
+
+func Test_Lowering_06(t *testing.T) {
+	CheckBinfileErrors(t, `{"columns": {"_cols": [{"Handle": "m.X", "Register": 0}], "registers": [{"handle": "X"}]}}`,
+		"X")
+}
+
+func Test_Lowering_07(t *testing.T) {
+	CheckBinfileErrors(t, `{"columns": {"_cols": [{"Handle": "m.X", "Register": 1}], "registers": []}}`, "m.X")
+}
+
+func Test_Lowering_08(t *testing.T) {
+	CheckBinfileErrors(t,
+		`{"constraints": [{"Vanishes": {"handle": "c1", "expr": {"_e": {"Column": {"handle": "#0"}}}}}]}`, "c1")
+}
+
+// CheckLoweringErrors checks that lowering a given set of constraints to AIR
+// fails with exactly the expected errors, each of which is identified by the
+// handle of the failing constraint and the line on which it is declared.
+func CheckLoweringErrors(t *testing.T, constraints string, expected ...string) {
+	src := "(defpurefun ((vanishes! :@loob) x) x)\n(defcolumns X)\n" + constraints
+	// Compile source
+	hirSchema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile("test.lisp", []byte(src)))
+	if len(errs) > 0 {
+		t.Fatalf("Error compiling %s: %v\n", constraints, errs)
+	}
+	//
+	airSchema, lerrs := lowerToMir(t, hirSchema).LowerToAirWith(mir.LoweringConfig{})
+	//
+	if airSchema != nil {
+		t.Errorf("lowering %s succeeded unexpectedly", constraints)
+	} else if len(lerrs) != len(expected) {
+		t.Fatalf("lowering %s gave %d errors, but expected %d", constraints, len(lerrs), len(expected))
+	}
+	//
+	for i, err := range lerrs {
+		if actual := loweringErrorId(err); err.Level() != "AIR" || actual != expected[i] {
+			t.Errorf("lowering %s gave error %s at %s, but expected %s at AIR", constraints, actual, err.Level(),
+				expected[i])
+		}
+	}
+}
+
+// CheckBinfileErrors checks that reading a given (malformed) binary file gives
+// an error, rather than crashing.  Furthermore, the error must identify the
+// elements with the expected handles.
+func CheckBinfileErrors(t *testing.T, input string, expected ...string) {
+	schema, err := binfile.HirSchemaFromJson([]byte(input))
+	//
+	if err == nil || schema != nil {
+		t.Fatalf("reading %s succeeded unexpectedly", input)
+	}
+	//
+	for _, handle := range expected {
+		if !strings.Contains(err.Error(), handle+" (HIR)") {
+			t.Errorf("reading %s gave error \"%s\", but expected error for %s", input, err.Error(), handle)
+		}
+	}
+}
+
+// Identify a lowering error by its handle and the line on which the failing
+// element is declared.
+func loweringErrorId(err sc.LoweringError) string {
+	if err.Source() == nil {
+		return err.Handle()
+	}
+	//
+	return fmt.Sprintf("%s:%d", err.Handle(), err.Source().Line())
+}
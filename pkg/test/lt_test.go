@@ -0,0 +1,208 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/lt"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+func Test_MmapReader_01(t *testing.T) {
+	CheckMmapReader(t, "basic_01")
+}
+
+func Test_MmapReader_02(t *testing.T) {
+	CheckMmapReader(t, "module_03")
+}
+
+func Test_MmapReader_03(t *testing.T) {
+	CheckMmapReader(t, "type_01")
+}
+
+func Test_MmapReader_04(t *testing.T) {
+	CheckMmapReader(t, "bit_decomposition")
+}
+
+func Test_LtV2_01(t *testing.T) {
+	CheckLtV2(t, "basic_01")
+}
+
+func Test_LtV2_02(t *testing.T) {
+	CheckLtV2(t, "module_03")
+}
+
+func Test_LtV2_03(t *testing.T) {
+	CheckLtV2(t, "type_01")
+}
+
+func Test_LtV2_04(t *testing.T) {
+	// Columns of varying bitwidth, including nonzero padding.
+	var columns []trace.RawColumn
+	//
+	for i, bitwidth := range []uint{1, 8, 12, 32, 64, 100, 256} {
+		data := util.NewFrArray(5, bitwidth)
+		for j := uint(0); j < data.Len(); j++ {
+			data.Set(j, fr.NewElement(uint64(j%2)))
+		}
+		//
+		column := trace.RawColumn{Module: "m", Name: fmt.Sprintf("c%d", i), Data: data, Padding: fr.One()}
+		columns = append(columns, column)
+	}
+	//
+	checkLtV2(t, "bitwidths", columns)
+}
+
+// CheckLtV2 checks that the traces of a given test can be written using
+// version 2 of the LT format, and read back both directly and through a memory
+// map.  Furthermore, it checks that any corruption of the data is detected.
+func CheckLtV2(t *testing.T, test string) {
+	for i, tr := range ReadTracesFile(fmt.Sprintf("%s.accepts", test)) {
+		checkLtV2(t, fmt.Sprintf("%s_%d", test, i), tr)
+	}
+}
+
+func checkLtV2(t *testing.T, name string, columns []trace.RawColumn) {
+	metadata := map[string]lt.Metadata{"": {"name": name}, "other": {}}
+	bytes, err := lt.ToBytesV2(columns, metadata)
+	//
+	if err != nil {
+		t.Fatal(err)
+	} else if !lt.IsVersion2(bytes) {
+		t.Fatalf("%s not written in version 2 format", name)
+	}
+	// Read directly
+	actual, err := lt.FromBytes(bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	checkMmapColumns(t, name, columns, actual)
+	// Read through memory map
+	filename := filepath.Join(t.TempDir(), name+".lt")
+	if err := os.WriteFile(filename, bytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	//
+	file, err := lt.OpenFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	} else if file.Header().MajorVersion != 2 || file.Header().Metadata[""]["name"] != name {
+		t.Errorf("%s has incorrect header (%v)", name, file.Header())
+	} else if err := file.Verify(); err != nil {
+		t.Errorf("%s failed verification (%s)", name, err)
+	}
+	//
+	checkMmapColumns(t, name, columns, file.Columns())
+	//
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Check truncation is detected
+	if _, err := lt.FromBytes(bytes[:len(bytes)-1]); err == nil && len(columns) > 0 {
+		t.Errorf("%s truncated without error", name)
+	}
+	// Check corruption is detected (assuming there is data to corrupt)
+	if corrupted := slices.Clone(bytes); totalLength(columns) > 0 {
+		corrupted[len(corrupted)-1] ^= 1
+		//
+		if _, err := lt.FromBytes(corrupted); err == nil {
+			t.Errorf("%s corrupted without error", name)
+		}
+		// Likewise, when read through a memory map
+		if err := os.WriteFile(filename, corrupted, 0644); err != nil {
+			t.Fatal(err)
+		} else if file, err := lt.OpenFile(filename); err != nil {
+			t.Fatal(err)
+		} else {
+			// Checksums are only verified on request
+			if err := file.Verify(); err == nil {
+				t.Errorf("%s corrupted without error (memory mapped)", name)
+			}
+			//
+			file.Close()
+		}
+	}
+}
+
+func totalLength(columns []trace.RawColumn) uint {
+	n := uint(0)
+	for _, col := range columns {
+		n += col.Data.Len()
+	}
+	//
+	return n
+}
+
+// CheckMmapReader checks that reading the traces of a given test through a
+// memory map produces exactly the same columns as reading them directly.
+func CheckMmapReader(t *testing.T, test string) {
+	traces := ReadTracesFile(fmt.Sprintf("%s.accepts", test))
+	//
+	for i, tr := range traces {
+		bytes, err := lt.ToBytes(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//
+		expected, err := lt.FromBytes(bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write trace file
+		filename := filepath.Join(t.TempDir(), fmt.Sprintf("%s_%d.lt", test, i))
+		if err := os.WriteFile(filename, bytes, 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Open trace file
+		file, err := lt.OpenFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//
+		checkMmapColumns(t, filename, expected, file.Columns())
+		//
+		if err := file.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkMmapColumns(t *testing.T, filename string, expected []trace.RawColumn, actual []trace.RawColumn) {
+	if len(expected) != len(actual) {
+		t.Fatalf("%s has %d columns, but expected %d", filename, len(actual), len(expected))
+	}
+	//
+	for i := range expected {
+		if expected[i].Module != actual[i].Module || expected[i].Name != actual[i].Name ||
+			expected[i].Padding != actual[i].Padding {
+			t.Fatalf("%s has column %s, but expected %s", filename, actual[i].QualifiedName(),
+				expected[i].QualifiedName())
+		}
+		// Check padding is applied consistently.
+		one := fr.One()
+		expectedData := expected[i].Data.PadFront(2, one).PadFront(1, fr.NewElement(0))
+		actualData := actual[i].Data.PadFront(2, one).PadFront(1, fr.NewElement(0))
+		//
+		checkMmapArray(t, expected[i].QualifiedName(), expected[i].Data, actual[i].Data)
+		checkMmapArray(t, expected[i].QualifiedName(), expectedData, actualData)
+	}
+}
+
+func checkMmapArray(t *testing.T, name string, expected util.FrArray, actual util.FrArray) {
+	if expected.Len() != actual.Len() || expected.BitWidth() != actual.BitWidth() {
+		t.Fatalf("column %s has length %d (u%d), but expected %d (u%d)", name, actual.Len(), actual.BitWidth(),
+			expected.Len(), expected.BitWidth())
+	}
+	//
+	for j := uint(0); j < expected.Len(); j++ {
+		if e, a := expected.Get(j), actual.Get(j); e != a {
+			t.Errorf("row %d of column %s is %s, but expected %s", j, name, a.String(), e.String())
+		}
+	}
+}
@@ -73,3 +73,32 @@ func Ignored_TestNewBlockDeviceFromFile(t *testing.T) {
 	require.Equal(t, 0, n)
 	require.Error(t, err, "page fault occurred while reading from memory map")
 }
+
+func Test_MmapOpenFile_01(t *testing.T) {
+	// Memory mapping a directory fails, after it has been opened.
+	dir := t.TempDir()
+	before := countOpenFiles(t)
+	//
+	for i := 0; i < 16; i++ {
+		_, err := mmap.OpenFile(dir)
+		require.Error(t, err)
+	}
+	// Check file descriptors were not leaked.
+	require.Equal(t, before, countOpenFiles(t))
+}
+
+func Test_MmapOpenFile_02(t *testing.T) {
+	// Opening a missing file fails.
+	_, err := mmap.OpenFile(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+// Determine the number of file descriptors currently open in this process.
+func countOpenFiles(t *testing.T) int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("cannot determine open file descriptors")
+	}
+	//
+	return len(entries)
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/mir"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+func Test_Optimise_01(t *testing.T) {
+	CheckOptimise(t, 1, "(* 1 (+ X 0) (~ (+ Y Z)))", "(* X (~ (+ Y Z)))")
+}
+
+func Test_Optimise_02(t *testing.T) {
+	CheckOptimise(t, 1, "(- (^ X 1) (^ Y 0))", "(- X 1)")
+}
+
+func Test_Optimise_03(t *testing.T) {
+	CheckOptimise(t, 1, "(* X (+ 1 2) (- 3 3))", "0")
+}
+
+func Test_Optimise_04(t *testing.T) {
+	CheckOptimise(t, 2, "(- (* 2 X) (* X 3) (+ X X))", "(- 0 (* 3 X))")
+}
+
+func Test_Optimise_05(t *testing.T) {
+	CheckOptimise(t, 2, "(* (+ X Y) (- X Y))", "(- (^ X 2) (^ Y 2))")
+}
+
+func Test_Optimise_06(t *testing.T) {
+	// Normal form not used when larger than the original
+	CheckOptimise(t, 2, "(* (+ X 1) (+ Y 1) (+ Z 1))", "(* (+ 1 X) (+ 1 Y) (+ 1 Z))")
+}
+
+func Test_Optimise_07(t *testing.T) {
+	// Common subexpressions share the same ordering of operands
+	CheckOptimise(t, 1, "(- (~ (+ Z Y)) (~ (+ Y Z)))", "(- (~ (+ Y Z)) (~ (+ Y Z)))")
+}
+
+func Test_Optimise_08(t *testing.T) {
+	// Bounds of the original constraint are preserved
+	CheckOptimise(t, 2, "(+ X (- (shift Y 1) (shift Y 1)))", "(+ X (- (shift Y 1) (shift Y 1)))")
+}
+
+func Test_Optimise_09(t *testing.T) {
+	CheckOptimise(t, 0, "(* 1 (+ X 0))", "(* 1 (+ X 0))")
+}
+
+// CheckOptimise checks that a given constraint over the columns X, Y and Z is
+// optimised at the MIR level into the expected form at a given optimisation
+// level.
+func CheckOptimise(t *testing.T, level uint, input string, expected string) {
+	src := "(defpurefun ((vanishes! :@loob) x) x)\n(defcolumns X Y Z)\n" +
+		"(defconstraint test () (vanishes! " + input + "))"
+	// Compile source
+	hirSchema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile("test.lisp", []byte(src)))
+	if len(errs) > 0 {
+		t.Fatalf("Error compiling %s: %v\n", input, errs)
+	}
+	//
+	schema := lowerToMir(t, hirSchema).Optimise(mir.OptimisationLevel(level))
+	//
+	for iter := schema.Constraints(); iter.HasNext(); {
+		c := iter.Next().(mir.VanishingConstraint)
+		//
+		if actual := c.Constraint().Expr.Lisp(schema).String(true); actual != expected {
+			t.Errorf("optimised %s into %s, but expected %s", input, actual, expected)
+		}
+	}
+}
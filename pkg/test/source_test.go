@@ -0,0 +1,86 @@
+package test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/mir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+func Test_Source_01(t *testing.T) {
+	CheckSources(t, "basic_01")
+}
+
+func Test_Source_02(t *testing.T) {
+	CheckSources(t, "type_01")
+}
+
+func Test_Source_03(t *testing.T) {
+	CheckSources(t, "lookup_01")
+}
+
+func Test_Source_04(t *testing.T) {
+	CheckSources(t, "permute_03")
+}
+
+func Test_Source_05(t *testing.T) {
+	CheckSources(t, "norm_01")
+}
+
+func Test_Source_06(t *testing.T) {
+	CheckSources(t, "property_01")
+}
+
+func Test_Source_07(t *testing.T) {
+	CheckSources(t, "guard_02")
+}
+
+func Test_SubExprSource_01(t *testing.T) {
+	CheckSubExprSources(t, "block_01", 6, 7)
+}
+
+// CheckSources checks that every constraint and assertion, at every IR level,
+// can be traced back to its originating declaration.  This includes
+// constraints generated by gadgets during lowering.
+func CheckSources(t *testing.T, test string) {
+	hirSchema := compileTestFile(t, test)
+	mirSchema := lowerToMir(t, hirSchema)
+	schemas := []sc.Schema{hirSchema, mirSchema, lowerToAir(t, mirSchema, mir.LoweringConfig{})}
+	//
+	for i, schema := range schemas {
+		elements := schema.Constraints().Append(schema.Assertions())
+		//
+		for _, c := range elements.Collect() {
+			if src := sc.SourceOf(c); src == nil {
+				t.Errorf("%s (IR %d) constraint %s has no source", test, i, c.Lisp(schema).String(false))
+			} else if src.File().Filename() != test+".lisp" {
+				t.Errorf("%s (IR %d) constraint attributed to %s", test, i, src.File().Filename())
+			}
+		}
+	}
+}
+
+// CheckSubExprSources checks that constraints arising from the elements of a
+// list (at the MIR and AIR levels) are attributed to those elements, by
+// comparing the lines on which they start.
+func CheckSubExprSources(t *testing.T, test string, lines ...int) {
+	hirSchema := compileTestFile(t, test)
+	mirSchema := lowerToMir(t, hirSchema)
+	schemas := []sc.Schema{mirSchema, lowerToAir(t, mirSchema, mir.LoweringConfig{})}
+	//
+	for i, schema := range schemas {
+		actual := util.NewSortedSet[int]()
+		//
+		for _, c := range schema.Constraints().Collect() {
+			if src := sc.SourceOf(c); src != nil {
+				actual.Insert(src.Line())
+			}
+		}
+		//
+		if !slices.Equal(*actual, lines) {
+			t.Errorf("%s (IR %d) constraints attributed to lines %v, but expected %v", test, i+1, *actual, lines)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"bufio"
+	encoding "encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,8 +10,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/air/export"
 	"github.com/consensys/go-corset/pkg/corset"
 	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/trace"
@@ -158,6 +163,22 @@ func Test_Domain_03(t *testing.T) {
 	Check(t, false, "domain_03")
 }
 
+func Test_Domain_04(t *testing.T) {
+	Check(t, false, "domain_04")
+}
+
+func Test_Domain_05(t *testing.T) {
+	Check(t, false, "domain_05")
+}
+
+func Test_Domain_06(t *testing.T) {
+	Check(t, false, "domain_06")
+}
+
+func Test_Domain_07(t *testing.T) {
+	Check(t, false, "domain_07")
+}
+
 // ===================================================================
 // Block Tests
 // ===================================================================
@@ -530,6 +551,18 @@ func Test_Permute_09(t *testing.T) {
 	Check(t, false, "permute_09")
 }
 
+func Test_Permute_10(t *testing.T) {
+	Check(t, true, "permute_10")
+}
+
+func Test_Permute_11(t *testing.T) {
+	Check(t, false, "permute_11")
+}
+
+func Test_Permute_12(t *testing.T) {
+	Check(t, false, "permute_12")
+}
+
 // ===================================================================
 // Lookups
 // ===================================================================
@@ -566,6 +599,14 @@ func Test_Lookup_08(t *testing.T) {
 	Check(t, false, "lookup_08")
 }
 
+func Test_Lookup_09(t *testing.T) {
+	Check(t, false, "lookup_09")
+}
+
+func Test_Lookup_10(t *testing.T) {
+	Check(t, false, "lookup_10")
+}
+
 // ===================================================================
 // Interleaving
 // ===================================================================
@@ -586,6 +627,110 @@ func Test_Interleave_04(t *testing.T) {
 	Check(t, false, "interleave_04")
 }
 
+// ===================================================================
+// Computed Columns
+// ===================================================================
+
+func Test_Computed_01(t *testing.T) {
+	Check(t, false, "computed_01")
+}
+
+func Test_Computed_02(t *testing.T) {
+	Check(t, false, "computed_02")
+}
+
+func Test_Computed_03(t *testing.T) {
+	Check(t, false, "computed_03")
+}
+
+// ===================================================================
+// Bitwise
+// ===================================================================
+
+func Test_Bitwise_01(t *testing.T) {
+	Check(t, false, "bitwise_01")
+}
+
+func Test_Bitwise_02(t *testing.T) {
+	Check(t, false, "bitwise_02")
+}
+
+func Test_Bitwise_03(t *testing.T) {
+	Check(t, false, "bitwise_03")
+}
+
+func Test_Bitwise_04(t *testing.T) {
+	Check(t, false, "bitwise_04")
+}
+
+func Test_Bitwise_05(t *testing.T) {
+	Check(t, false, "bitwise_05")
+}
+
+func Test_Bitwise_06(t *testing.T) {
+	Check(t, false, "bitwise_06")
+}
+
+func Test_Bitwise_07(t *testing.T) {
+	Check(t, false, "bitwise_07")
+}
+
+func Test_Bitwise_08(t *testing.T) {
+	Check(t, false, "bitwise_08")
+}
+
+func Test_Bitwise_09(t *testing.T) {
+	Check(t, false, "bitwise_09")
+}
+
+func Test_Bitwise_10(t *testing.T) {
+	Check(t, false, "bitwise_10")
+}
+
+func Test_Bitwise_11(t *testing.T) {
+	Check(t, false, "bitwise_11")
+}
+
+// ===================================================================
+// Let
+// ===================================================================
+
+func Test_Let_01(t *testing.T) {
+	Check(t, false, "let_01")
+}
+
+func Test_Let_02(t *testing.T) {
+	Check(t, false, "let_02")
+}
+
+func Test_Let_03(t *testing.T) {
+	Check(t, false, "let_03")
+}
+
+func Test_Let_04(t *testing.T) {
+	Check(t, false, "let_04")
+}
+
+func Test_Let_05(t *testing.T) {
+	Check(t, false, "let_05")
+}
+
+// ===================================================================
+// Match
+// ===================================================================
+
+func Test_Match_01(t *testing.T) {
+	Check(t, false, "match_01")
+}
+
+func Test_Match_02(t *testing.T) {
+	Check(t, false, "match_02")
+}
+
+func Test_Match_03(t *testing.T) {
+	Check(t, false, "match_03")
+}
+
 // ===================================================================
 // Functions
 // ===================================================================
@@ -714,6 +859,22 @@ func Test_Debug_01(t *testing.T) {
 	Check(t, false, "debug_01")
 }
 
+// ===================================================================
+// Perspectives
+// ===================================================================
+
+func Test_Perspective_01(t *testing.T) {
+	Check(t, false, "perspective_01")
+}
+
+func Test_Perspective_02(t *testing.T) {
+	Check(t, false, "perspective_02")
+}
+
+func Test_Perspective_03(t *testing.T) {
+	Check(t, false, "perspective_03")
+}
+
 // ===================================================================
 // Complex Tests
 // ===================================================================
@@ -795,6 +956,8 @@ func Check(t *testing.T, stdlib bool, test string) {
 	if len(errs) > 0 {
 		t.Fatalf("Error parsing %s: %v\n", filename, errs)
 	}
+	// Check schema survives compilation into a binary file
+	checkBinaryFile(t, filename, schema)
 	// Check valid traces are accepted
 	accepts_file := fmt.Sprintf("%s.%s", test, "accepts")
 	accepts := ReadTracesFile(accepts_file)
@@ -819,6 +982,24 @@ func Check(t *testing.T, stdlib bool, test string) {
 	}
 }
 
+// Check a given schema can be written out as a binary file, and that reading it
+// back produces the same schema.
+func checkBinaryFile(t *testing.T, filename string, schema *hir.Schema) {
+	bytes, err := hir.ToBytes(schema)
+	if err != nil {
+		t.Fatalf("Error compiling %s: %s\n", filename, err)
+	}
+	// Read binary file back
+	compiled, err := hir.FromBytes(bytes)
+	if err != nil {
+		t.Fatalf("Error reading compiled %s: %s\n", filename, err)
+	}
+	// Check nothing was lost
+	if rbytes, _ := hir.ToBytes(compiled); string(bytes) != string(rbytes) {
+		t.Errorf("Compiled %s differs from original\n", filename)
+	}
+}
+
 // Check a given set of tests have an expected outcome (i.e. are
 // either accepted or rejected) by a given set of constraints.
 func CheckTraces(t *testing.T, test string, expected bool, expand bool,
@@ -826,15 +1007,24 @@ func CheckTraces(t *testing.T, test string, expected bool, expand bool,
 	for i, tr := range traces {
 		if tr != nil {
 			// Lower HIR => MIR
-			mirSchema := hirSchema.LowerToMir()
+			mirSchema := lowerToMir(t, hirSchema)
 			// Lower MIR => AIR
-			airSchema := mirSchema.LowerToAir()
+			airSchema := lowerToAir(t, mirSchema, mir.LoweringConfig{})
+			// Lower MIR => AIR (using log-derivative lookups)
+			logupSchema := lowerToAir(t, mirSchema, mir.LoweringConfig{LogDerivativeLookups: true})
+			// Optimise MIR, then lower MIR => AIR
+			optSchema := lowerToAir(t, mirSchema.Optimise(mir.OptimisationLevel(2)), mir.LoweringConfig{})
+			// Lower MIR => AIR (with constraints of degree at most 2)
+			degreeSchema := lowerToAir(t, mirSchema, mir.LoweringConfig{MaxDegree: 2})
 			// Align trace with schema, and check whether expanded or not.
 			for padding := uint(0); padding <= MAX_PADDING; padding++ {
 				// Construct trace identifiers
 				hirID := traceId{"HIR", test, expected, i + 1, padding}
 				mirID := traceId{"MIR", test, expected, i + 1, padding}
 				airID := traceId{"AIR", test, expected, i + 1, padding}
+				logupID := traceId{"AIR/logup", test, expected, i + 1, padding}
+				optID := traceId{"AIR/opt", test, expected, i + 1, padding}
+				degreeID := traceId{"AIR/degree", test, expected, i + 1, padding}
 				//
 				if expand {
 					// Only HIR / MIR constraints for traces which must be
@@ -844,11 +1034,51 @@ func CheckTraces(t *testing.T, test string, expected bool, expand bool,
 				}
 				// Always check AIR constraints
 				checkTrace(t, tr, expand, airID, airSchema)
+				// Check exported AIR constraints agree with originals
+				if padding == 0 {
+					checkExport(t, tr, expand, airID, airSchema)
+				}
+				// Check log-derivative lookups agree with originals.  Since
+				// multiplicities are computed columns, this only makes sense
+				// for traces which are expanded.
+				if expand {
+					checkTrace(t, tr, expand, logupID, logupSchema)
+				}
+				// Likewise, check optimised constraints agree with originals.
+				// Since optimisation can change which computed columns are
+				// needed, this only makes sense for traces which are expanded.
+				// The same holds for degree bounding, which introduces
+				// intermediate computed columns.
+				if padding == 0 && expand {
+					checkTrace(t, tr, expand, optID, optSchema)
+					checkTrace(t, tr, expand, degreeID, degreeSchema)
+				}
 			}
 		}
 	}
 }
 
+// Lower a given HIR schema to the MIR level, failing if this is not possible.
+func lowerToMir(t *testing.T, schema *hir.Schema) *mir.Schema {
+	mirSchema, errs := schema.LowerToMir()
+	if len(errs) > 0 {
+		t.Fatalf("Error lowering to MIR: %v\n", errs)
+	}
+	//
+	return mirSchema
+}
+
+// Lower a given MIR schema to the AIR level using a given configuration,
+// failing if this is not possible.
+func lowerToAir(t *testing.T, schema *mir.Schema, cfg mir.LoweringConfig) *air.Schema {
+	airSchema, errs := schema.LowerToAirWith(cfg)
+	if len(errs) > 0 {
+		t.Fatalf("Error lowering to AIR: %v\n", errs)
+	}
+	//
+	return airSchema
+}
+
 func checkTrace(t *testing.T, inputs []trace.RawColumn, expand bool, id traceId, schema sc.Schema) {
 	// Construct the trace
 	tr, errs := sc.NewTraceBuilder(schema).Expand(expand).Padding(id.padding).Parallel(true).Build(inputs)
@@ -877,6 +1107,113 @@ func checkTrace(t *testing.T, inputs []trace.RawColumn, expand bool, id traceId,
 	}
 }
 
+// Check the polynomials (or expressions) of an exported AIR schema evaluate to
+// the same values as the original constraints on every row of a given trace.
+func checkExport(t *testing.T, inputs []trace.RawColumn, expand bool, id traceId, schema *air.Schema) {
+	tr, errs := sc.NewTraceBuilder(schema).Expand(expand).Padding(id.padding).Build(inputs)
+	// Ignore traces which cannot be built, since these are reported elsewhere.
+	if len(errs) > 0 {
+		return
+	}
+	//
+	var doc export.Document
+	// Check document as seen by (non-Go) consumers, where absent fields cannot
+	// be distinguished from zero.
+	if err := encoding.Unmarshal([]byte(export.ToJsonString(schema)), &doc); err != nil {
+		t.Fatalf("Exported schema is malformed (%s, %s): %s", id.ir, id.test, err)
+	}
+	// Check selectors (and only selectors) are exported as fixed columns
+	for _, a := range doc.Assignments {
+		for _, target := range a.Targets {
+			if doc.Columns[target].Fixed != (a.Kind == "selector") {
+				t.Errorf("Exported column %s has wrong kind (%s, %s)", doc.Columns[target].Name, id.ir, id.test)
+			}
+		}
+	}
+	//
+	for i, index := schema.Constraints(), 0; i.HasNext(); index++ {
+		ith := i.Next()
+		exported := doc.Constraints[index]
+		//
+		if rc, ok := ith.(air.RangeConstraint); ok {
+			if exported.Column == nil || *exported.Column != rc.Target().Column {
+				t.Errorf("Exported constraint %s has wrong column (%s, %s)", rc.Handle(), id.ir, id.test)
+			}
+		} else if vc, ok := ith.(air.VanishingConstraint); ok {
+			for k := 0; k < int(tr.Height(vc.Context())); k++ {
+				expected := vc.Constraint().Expr.EvalAt(k, tr)
+				actual := evalPolynomial(exported.Polynomial, k, tr)
+				//
+				if exported.Expr != nil {
+					actual = evalExportedExpr(exported.Expr, k, tr)
+				}
+				//
+				if actual != expected {
+					t.Errorf("Exported constraint %s differs (%s, %s, line %d, row %d)", vc.Handle(), id.ir,
+						id.test, id.line, k)
+				}
+			}
+		}
+	}
+}
+
+func evalPolynomial(poly export.Polynomial, k int, tr trace.Trace) fr.Element {
+	var sum fr.Element
+	//
+	for _, term := range poly {
+		var val fr.Element
+		//
+		if _, err := val.SetString(term.Coefficient); err != nil {
+			panic(err)
+		}
+		//
+		for _, f := range term.Factors {
+			for i := uint(0); i < f.Power; i++ {
+				ith := tr.Column(f.Column).Get(k + f.Shift)
+				val.Mul(&val, &ith)
+			}
+		}
+		//
+		sum.Add(&sum, &val)
+	}
+	//
+	return sum
+}
+
+// Evaluate an exported constraint expression, which uses only column accesses,
+// constants, additions, subtractions and multiplications.
+func evalExportedExpr(e *export.Expr, k int, tr trace.Trace) fr.Element {
+	var val fr.Element
+	//
+	switch e.Op {
+	case "col":
+		return tr.Column(*e.Col).Get(k + e.Shift)
+	case "const":
+		if _, err := val.SetString(e.Val); err != nil {
+			panic(err)
+		}
+	default:
+		val = evalExportedExpr(e.Args[0], k, tr)
+		//
+		for _, arg := range e.Args[1:] {
+			ith := evalExportedExpr(arg, k, tr)
+			//
+			switch e.Op {
+			case "add":
+				val.Add(&val, &ith)
+			case "sub":
+				val.Sub(&val, &ith)
+			case "mul":
+				val.Mul(&val, &ith)
+			default:
+				panic(fmt.Sprintf("unknown exported operator %s", e.Op))
+			}
+		}
+	}
+	//
+	return val
+}
+
 // A trace identifier uniquely identifies a specific trace within a given test.
 // This is used to provide debug information about a trace failure.
 // Specifically, so the user knows which line in which file caused the problem.
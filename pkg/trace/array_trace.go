@@ -72,6 +72,23 @@ func (p *ArrayTrace) FillColumn(cid uint, data util.FrArray, padding fr.Element)
 	col.fill(data, padding)
 }
 
+// RefillColumn replaces the data and padding for the given column.  This will
+// panic if the data is not already set, or the height of the column would
+// change.
+func (p *ArrayTrace) RefillColumn(cid uint, data util.FrArray, padding fr.Element) {
+	// Find column to refill
+	col := &p.columns[cid]
+	// Sanity check column has already been filled
+	if col.data == nil {
+		panic(fmt.Sprintf("column %s has not been filled", col.name))
+	} else if data.Len() != col.data.Len() {
+		panic(fmt.Sprintf("column %s has invalid height (%d but expected %d)", col.name, data.Len(), col.data.Len()))
+	}
+	// Refill the column
+	col.data = data
+	col.padding = padding
+}
+
 // Pad pads a given module with a given number of padding rows.
 func (p *ArrayTrace) Pad(module uint, n uint) {
 	p.modules[module].height += n
@@ -0,0 +1,236 @@
+package lt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/mmap"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// File represents an LT trace file which has been memory-mapped, rather than
+// read into memory.  Columns are decoded lazily, such that only the elements of
+// a column which are actually accessed are ever decoded.  For the same reason,
+// column checksums (if present) are not verified when the file is opened, since
+// this requires reading the entire file.  Instead, they can be verified
+// explicitly (see Verify).  Observe that the columns of a file cannot be used
+// after it is closed.
+type File struct {
+	file    *mmap.File
+	header  Header
+	headers []columnHeader
+	columns []trace.RawColumn
+}
+
+// OpenFile opens a given LT trace file using a memory map, or produces an error
+// if the file is malformed (e.g. truncated) in some way.
+func OpenFile(filename string) (*File, error) {
+	file, err := mmap.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	//
+	data := file.BlockDevice.Data
+	header, headers, err := readHeaders(data)
+	//
+	if err != nil {
+		// Don't leak the memory map
+		file.Close()
+		return nil, err
+	}
+	//
+	columns := make([]trace.RawColumn, len(headers))
+	//
+	for i, ith := range headers {
+		bytes := data[ith.offset : ith.offset+ith.width*ith.length]
+		elements := &lazyArray{ith.width, ith.bitwidth, bytes, 0, fr.Element{}, nil}
+		columns[i] = trace.RawColumn{Module: ith.module, Name: ith.name, Data: elements, Padding: ith.padding}
+	}
+	//
+	return &File{file, header, headers, columns}, nil
+}
+
+// Verify the checksum of every column in this file (for those versions which
+// have them), producing an error for the first which does not match.  Observe
+// that this requires reading the entire file.
+func (p *File) Verify() error {
+	data := p.file.BlockDevice.Data
+	//
+	for _, ith := range p.headers {
+		if err := ith.verify(p.header, data[ith.offset:ith.offset+ith.width*ith.length]); err != nil {
+			return err
+		}
+	}
+	//
+	return nil
+}
+
+// Header returns the file-level information for this file, such as its version
+// and any module metadata.
+func (p *File) Header() Header {
+	return p.header
+}
+
+// Columns returns the (lazily decoded) columns of this file.
+func (p *File) Columns() []trace.RawColumn {
+	return p.columns
+}
+
+// Close this file, after which its columns must no longer be accessed.
+func (p *File) Close() error {
+	return p.file.Close()
+}
+
+// ============================================================================
+// Lazy Array
+// ============================================================================
+
+// A lazyArray decodes elements on demand from the raw bytes of a given column.
+// The array can be padded without decoding anything, since the padding is held
+// separately.  However, updating the array requires decoding it entirely, after
+// which it is no longer lazy.
+type lazyArray struct {
+	// Number of bytes per element
+	width uint
+	// Number of bits per element
+	bitwidth uint
+	// Raw bytes of the column
+	bytes []byte
+	// Number of padding elements at the front of this array
+	npadding uint
+	// Padding value
+	padding fr.Element
+	// Decoded array (if this array has been updated)
+	decoded util.FrArray
+}
+
+// Len returns the number of elements in this array.
+func (p *lazyArray) Len() uint {
+	if p.decoded != nil {
+		return p.decoded.Len()
+	}
+	//
+	return p.npadding + uint(len(p.bytes))/p.width
+}
+
+// BitWidth returns the number of bits required to store an element of this
+// array.
+func (p *lazyArray) BitWidth() uint {
+	if p.decoded != nil {
+		return p.decoded.BitWidth()
+	}
+	//
+	return p.bitwidth
+}
+
+// Get decodes the element at the given index in this array.
+func (p *lazyArray) Get(index uint) fr.Element {
+	var element fr.Element
+	//
+	if p.decoded != nil {
+		return p.decoded.Get(index)
+	} else if index < p.npadding {
+		return p.padding
+	} else if index >= p.Len() {
+		panic(fmt.Sprintf("index %d out-of-bounds (%d)", index, p.Len()))
+	}
+	offset := (index - p.npadding) * p.width
+	bytes := p.bytes[offset : offset+p.width]
+	//
+	switch p.width {
+	case 1:
+		element.SetUint64(uint64(bytes[0]))
+	case 2:
+		element.SetUint64(uint64(binary.BigEndian.Uint16(bytes)))
+	case 4:
+		element.SetUint64(uint64(binary.BigEndian.Uint32(bytes)))
+	case 8:
+		element.SetUint64(binary.BigEndian.Uint64(bytes))
+	default:
+		element.SetBytes(bytes)
+	}
+	//
+	return element
+}
+
+// Set the element at the given index in this array.  This requires decoding the
+// entire array first.
+func (p *lazyArray) Set(index uint, element fr.Element) {
+	if p.decoded == nil {
+		p.decoded = p.decode()
+	}
+	//
+	p.decoded.Set(index, element)
+}
+
+// Clone this array.  Since the underlying bytes are never modified, these can
+// be safely shared.
+func (p *lazyArray) Clone() util.Array[fr.Element] {
+	if p.decoded != nil {
+		return p.decoded.Clone()
+	}
+	//
+	return &lazyArray{p.width, p.bitwidth, p.bytes, p.npadding, p.padding, nil}
+}
+
+// PadFront this array with n copies of the given padding value.  When the
+// padding value matches any existing padding, this does not require decoding
+// the array.
+func (p *lazyArray) PadFront(n uint, padding fr.Element) util.Array[fr.Element] {
+	if p.decoded != nil {
+		return p.decoded.PadFront(n, padding)
+	} else if p.npadding == 0 || p.padding == padding {
+		return &lazyArray{p.width, p.bitwidth, p.bytes, p.npadding + n, padding, nil}
+	}
+	//
+	return p.decode().PadFront(n, padding)
+}
+
+// Write out the contents of this array.
+func (p *lazyArray) Write(w io.Writer) error {
+	for i := uint(0); i < p.Len(); i++ {
+		ith := p.Get(i)
+		// Read exactly 32 bytes
+		bytes := ith.Bytes()
+		// Write them out
+		if _, err := w.Write(bytes[:]); err != nil {
+			return err
+		}
+	}
+	//
+	return nil
+}
+
+func (p *lazyArray) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("[")
+
+	for i := uint(0); i < p.Len(); i++ {
+		if i != 0 {
+			sb.WriteString(",")
+		}
+
+		ith := p.Get(i)
+		sb.WriteString(ith.String())
+	}
+
+	sb.WriteString("]")
+
+	return sb.String()
+}
+
+// Decode this array in its entirety.
+func (p *lazyArray) decode() util.FrArray {
+	data := util.NewFrArray(p.Len(), p.BitWidth())
+	//
+	for i := uint(0); i < p.Len(); i++ {
+		data.Set(i, p.Get(i))
+	}
+	//
+	return data
+}
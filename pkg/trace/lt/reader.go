@@ -3,6 +3,10 @@ package lt
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
 	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
@@ -12,9 +16,104 @@ import (
 
 // FromBytes parses a byte array representing a given LT trace file into an
 // columns, or produces an error if the original file was malformed in some way.
+// Both version 1 and version 2 files are supported, where the checksum of every
+// column is verified for the latter.
 func FromBytes(data []byte) ([]trace.RawColumn, error) {
-	// Construct new bytes.Reader
-	buf := bytes.NewReader(data)
+	header, headers, err := readHeaders(data)
+	// Check for errors
+	if err != nil {
+		return nil, err
+	}
+	//
+	ncols := len(headers)
+	columns := make([]trace.RawColumn, ncols)
+	c := make(chan util.Pair[uint, error], ncols)
+	// Dispatch go-routines
+	for i := uint(0); i < uint(ncols); i++ {
+		ith := headers[i]
+		// Dispatch go-routine
+		go func(i uint) {
+			var (
+				bytes = data[ith.offset : ith.offset+ith.width*ith.length]
+				err   = ith.verify(header, bytes)
+			)
+			// Read column data
+			if err == nil {
+				elements := readColumnData(ith, bytes)
+				columns[i] = trace.RawColumn{Module: ith.module, Name: ith.name, Data: elements, Padding: ith.padding}
+			}
+			// Package result
+			c <- util.NewPair(i, err)
+		}(i)
+	}
+	// Collect results
+	for i := uint(0); i < uint(ncols); i++ {
+		// Read packaged result from channel
+		if res := <-c; res.Right != nil {
+			err = res.Right
+		}
+	}
+	// Done
+	if err != nil {
+		return nil, err
+	}
+	//
+	return columns, nil
+}
+
+// Header holds the file-level information of an LT trace file.
+type Header struct {
+	// Major version of the file format used.
+	MajorVersion uint16
+	// Minor version of the file format used.
+	MinorVersion uint16
+	// Metadata associated with each module (version 2 onwards).
+	Metadata map[string]Metadata
+}
+
+// Metadata is a set of key-value pairs describing a given module.
+type Metadata map[string]string
+
+// Read the headers of this trace file, irrespective of which version it is.
+// This also checks that there is sufficient data for every column.
+func readHeaders(data []byte) (Header, []columnHeader, error) {
+	var (
+		header  Header
+		headers []columnHeader
+		err     error
+		// Construct new bytes.Reader
+		buf = bytes.NewReader(data)
+	)
+	//
+	if IsVersion2(data) {
+		header, headers, err = readV2Headers(buf)
+	} else {
+		header = Header{1, 0, nil}
+		headers, err = readV1Headers(buf)
+	}
+	//
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return header, nil, errors.New("insufficient data in trace file")
+	} else if err != nil {
+		return header, nil, err
+	}
+	// Determine start of column data
+	offset := uint(len(data) - buf.Len())
+	// Sanity check enough data
+	for i := range headers {
+		headers[i].offset = offset
+		offset += headers[i].width * headers[i].length
+	}
+	//
+	if offset > uint(len(data)) {
+		return header, nil, errors.New("insufficient data in trace file")
+	}
+	//
+	return header, headers, nil
+}
+
+// Read the meta-data for all columns in a version 1 trace file.
+func readV1Headers(buf *bytes.Reader) ([]columnHeader, error) {
 	// Read Number of BytesColumns
 	var ncols uint32
 	if err := binary.Read(buf, binary.BigEndian, &ncols); err != nil {
@@ -22,10 +121,9 @@ func FromBytes(data []byte) ([]trace.RawColumn, error) {
 	}
 	// Construct empty environment
 	headers := make([]columnHeader, ncols)
-	columns := make([]trace.RawColumn, ncols)
 	// Read column headers
 	for i := uint32(0); i < ncols; i++ {
-		header, err := readColumnHeader(buf)
+		header, err := readV1ColumnHeader(buf)
 		// Read column
 		if err != nil {
 			// Handle error
@@ -34,55 +132,97 @@ func FromBytes(data []byte) ([]trace.RawColumn, error) {
 		// Assign header
 		headers[i] = header
 	}
-	// Determine byte slices
-	offset := uint(len(data) - buf.Len())
-	c := make(chan util.Pair[uint, util.Array[fr.Element]], ncols)
-	// Dispatch go-routines
-	for i := uint(0); i < uint(ncols); i++ {
-		ith := headers[i]
-		// Calculate length (in bytes) of this column
-		nbytes := ith.width * ith.length
-		// Dispatch go-routine
-		go func(i uint, offset uint) {
-			// Read column data
-			elements := readColumnData(ith, data[offset:offset+nbytes])
-			// Package result
-			c <- util.NewPair(i, elements)
-		}(i, offset)
-		// Update byte offset
-		offset += nbytes
+	//
+	return headers, nil
+}
+
+// Read the meta-data for all modules and columns in a version 2 trace file.
+func readV2Headers(buf *bytes.Reader) (Header, []columnHeader, error) {
+	var (
+		header Header
+		magic  [len(MAGIC)]byte
+		nmods  uint32
+		ncols  uint32
+	)
+	// Read identifier and version
+	if err := binary.Read(buf, binary.BigEndian, &magic); err != nil {
+		return header, nil, err
+	} else if err := binary.Read(buf, binary.BigEndian, &header.MajorVersion); err != nil {
+		return header, nil, err
+	} else if err := binary.Read(buf, binary.BigEndian, &header.MinorVersion); err != nil {
+		return header, nil, err
+	} else if header.MajorVersion != MAJOR_VERSION {
+		return header, nil, fmt.Errorf("unsupported trace file version (v%d.%d)", header.MajorVersion,
+			header.MinorVersion)
 	}
-	// Collect results
-	for i := uint(0); i < uint(ncols); i++ {
-		// Read packaged result from channel
-		res := <-c
-		// Split qualified column name
-		mod, col := splitQualifiedColumnName(headers[res.Left].name)
-		// Construct appropriate slice
-		columns[res.Left] = trace.RawColumn{Module: mod, Name: col, Data: res.Right}
+	// Read modules
+	if err := binary.Read(buf, binary.BigEndian, &nmods); err != nil {
+		return header, nil, err
 	}
-	// Done
-	return columns, nil
+	//
+	modules := make([]string, nmods)
+	header.Metadata = make(map[string]Metadata)
+	//
+	for i := range modules {
+		name, metadata, err := readModuleHeader(buf)
+		if err != nil {
+			return header, nil, err
+		}
+		//
+		modules[i] = name
+		header.Metadata[name] = metadata
+	}
+	// Read columns
+	if err := binary.Read(buf, binary.BigEndian, &ncols); err != nil {
+		return header, nil, err
+	}
+	//
+	headers := make([]columnHeader, ncols)
+	//
+	for i := range headers {
+		ith, err := readV2ColumnHeader(buf, modules)
+		if err != nil {
+			return header, nil, err
+		}
+		//
+		headers[i] = ith
+	}
+	//
+	return header, headers, nil
 }
 
 type columnHeader struct {
+	module string
 	name   string
 	length uint
-	width  uint
+	// Number of bytes per element
+	width uint
+	// Number of bits per element
+	bitwidth uint
+	// Value to use when padding this column
+	padding fr.Element
+	// Checksum of column data (version 2 onwards)
+	checksum uint32
+	// Byte offset of column data within the file
+	offset uint
 }
 
-// Read the meta-data for a specific column in this trace file.
-func readColumnHeader(buf *bytes.Reader) (columnHeader, error) {
-	var header columnHeader
-	// Qualified column name length
-	var nameLen uint16
-	// Read column name length
-	if err := binary.Read(buf, binary.BigEndian, &nameLen); err != nil {
-		return header, err
+// Verify the checksum for this column's data (for those versions which have
+// one).
+func (p *columnHeader) verify(header Header, bytes []byte) error {
+	if header.MajorVersion >= 2 && crc32.ChecksumIEEE(bytes) != p.checksum {
+		return fmt.Errorf("checksum mismatch for column %s", trace.QualifiedColumnName(p.module, p.name))
 	}
-	// Read column name bytes
-	name := make([]byte, nameLen)
-	if _, err := buf.Read(name); err != nil {
+	//
+	return nil
+}
+
+// Read the meta-data for a specific column in a version 1 trace file.
+func readV1ColumnHeader(buf *bytes.Reader) (columnHeader, error) {
+	var header columnHeader
+	// Read column name
+	name, err := readString16(buf)
+	if err != nil {
 		return header, err
 	}
 
@@ -99,15 +239,111 @@ func readColumnHeader(buf *bytes.Reader) (columnHeader, error) {
 	}
 	// Height is length
 	header.length = uint(length)
-	header.name = string(name)
+	header.module, header.name = splitQualifiedColumnName(name)
 	header.width = uint(bytesPerElement)
+	header.bitwidth = header.width * 8
 	// Add new column
 	return header, nil
 }
 
+// Read the name and metadata of a module in a version 2 trace file.
+func readModuleHeader(buf *bytes.Reader) (string, Metadata, error) {
+	var nkeys uint16
+	// Read module name
+	name, err := readString16(buf)
+	if err != nil {
+		return "", nil, err
+	} else if err := binary.Read(buf, binary.BigEndian, &nkeys); err != nil {
+		return "", nil, err
+	}
+	// Read key-value pairs
+	metadata := make(Metadata)
+	//
+	for i := uint16(0); i < nkeys; i++ {
+		key, err := readString16(buf)
+		if err != nil {
+			return "", nil, err
+		}
+		//
+		value, err := readString16(buf)
+		if err != nil {
+			return "", nil, err
+		}
+		//
+		metadata[key] = value
+	}
+	//
+	return name, metadata, nil
+}
+
+// Read the meta-data for a specific column in a version 2 trace file.
+func readV2ColumnHeader(buf *bytes.Reader, modules []string) (columnHeader, error) {
+	var (
+		header   columnHeader
+		module   uint32
+		bitwidth uint16
+		width    uint8
+		length   uint32
+	)
+	//
+	if err := binary.Read(buf, binary.BigEndian, &module); err != nil {
+		return header, err
+	} else if module >= uint32(len(modules)) {
+		return header, fmt.Errorf("invalid module index %d in trace file", module)
+	}
+	//
+	name, err := readString16(buf)
+	if err != nil {
+		return header, err
+	} else if err := binary.Read(buf, binary.BigEndian, &bitwidth); err != nil {
+		return header, err
+	} else if err := binary.Read(buf, binary.BigEndian, &width); err != nil {
+		return header, err
+	} else if err := binary.Read(buf, binary.BigEndian, &length); err != nil {
+		return header, err
+	} else if uint(width) != byteWidth(uint(bitwidth)) {
+		return header, fmt.Errorf("inconsistent width for column %s in trace file", name)
+	}
+	// Read padding value (which has the same width as other elements)
+	padding := make([]byte, width)
+	if _, err := io.ReadFull(buf, padding); err != nil {
+		return header, err
+	}
+	//
+	header.padding.SetBytes(padding)
+	//
+	if err := binary.Read(buf, binary.BigEndian, &header.checksum); err != nil {
+		return header, err
+	}
+	//
+	header.module = modules[module]
+	header.name = name
+	header.length = uint(length)
+	header.width = uint(width)
+	header.bitwidth = uint(bitwidth)
+	//
+	return header, nil
+}
+
+// Read a string whose length is given by a preceding 16bit value.
+func readString16(buf *bytes.Reader) (string, error) {
+	var n uint16
+	// Read string length
+	if err := binary.Read(buf, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	// Read string bytes
+	bytes := make([]byte, n)
+	if _, err := io.ReadFull(buf, bytes); err != nil {
+		return "", err
+	}
+	//
+	return string(bytes), nil
+}
+
 func readColumnData(header columnHeader, bytes []byte) util.FrArray {
 	// Construct array
-	data := util.NewFrArray(header.length, header.width*8)
+	data := util.NewFrArray(header.length, header.bitwidth)
 	// Handle special cases
 	switch header.width {
 	case 1:
@@ -3,12 +3,27 @@ package lt
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"hash/crc32"
 	"io"
 	"log"
+	"math"
+	"slices"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/trace"
 )
 
+// MAGIC identifies an LT trace file of version 2 (or later).  Version 1 files
+// have no such identifier, and instead begin with their column count.
+const MAGIC = "corsetlt"
+
+// MAJOR_VERSION is the latest major version of the LT format.
+const MAJOR_VERSION = 2
+
+// MINOR_VERSION is the latest minor version of the LT format.
+const MINOR_VERSION = 0
+
 // ToBytes writes a given trace file as an array of bytes.
 func ToBytes(columns []trace.RawColumn) ([]byte, error) {
 	buf, err := ToBytesBuffer(columns)
@@ -54,10 +69,7 @@ func WriteBytes(columns []trace.RawColumn, buf io.Writer) error {
 			log.Fatal(err)
 		}
 		// Determine number of bytes required to hold element of this column.
-		byteWidth := data.BitWidth() / 8
-		if data.BitWidth()%8 != 0 {
-			byteWidth++
-		}
+		byteWidth := byteWidth(data.BitWidth())
 		// Write bytes per element
 		if err := binary.Write(buf, binary.BigEndian, uint8(byteWidth)); err != nil {
 			log.Fatal(err)
@@ -77,3 +89,204 @@ func WriteBytes(columns []trace.RawColumn, buf io.Writer) error {
 	// Done
 	return nil
 }
+
+// IsVersion2 checks whether a given trace file is in version 2 of the LT
+// format (or later).
+func IsVersion2(data []byte) bool {
+	return len(data) >= len(MAGIC) && string(data[:len(MAGIC)]) == MAGIC
+}
+
+// ToBytesV2 writes a given trace file as an array of bytes using version 2 of
+// the LT format, whilst including any metadata given for its modules.
+func ToBytesV2(columns []trace.RawColumn, metadata map[string]Metadata) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteBytesV2(columns, metadata, &buf); err != nil {
+		return nil, err
+	}
+	//
+	return buf.Bytes(), nil
+}
+
+// WriteBytesV2 writes a given trace file to an io.Writer using version 2 of the
+// LT format.  This consists of a header (identifier, version and module
+// metadata) followed by the column headers and, finally, the column data.  Each
+// column header includes the column's bitwidth, padding value and a checksum of
+// its data.
+func WriteBytesV2(columns []trace.RawColumn, metadata map[string]Metadata, buf io.Writer) error {
+	var (
+		modules []string
+		// Index of each module
+		modmap = make(map[string]uint32)
+		// Encoded data for each column
+		data = make([][]byte, len(columns))
+	)
+	// Allocate modules in order of appearance
+	for _, col := range columns {
+		if _, ok := modmap[col.Module]; !ok {
+			modmap[col.Module] = uint32(len(modules))
+			modules = append(modules, col.Module)
+		}
+	}
+	// Include any modules with metadata but no columns
+	for _, mod := range sortedKeys(metadata) {
+		if _, ok := modmap[mod]; !ok {
+			modmap[mod] = uint32(len(modules))
+			modules = append(modules, mod)
+		}
+	}
+	// Encode column data (since checksums are required for the headers)
+	for i, col := range columns {
+		bytes, err := encodeColumnData(col)
+		if err != nil {
+			return err
+		}
+		//
+		data[i] = bytes
+	}
+	// Write identifier and version
+	if err := writeAll(buf, []byte(MAGIC), uint16(MAJOR_VERSION), uint16(MINOR_VERSION)); err != nil {
+		return err
+	}
+	// Write module headers
+	if err := binary.Write(buf, binary.BigEndian, uint32(len(modules))); err != nil {
+		return err
+	}
+	//
+	for _, mod := range modules {
+		if err := writeModuleHeader(buf, mod, metadata[mod]); err != nil {
+			return err
+		}
+	}
+	// Write column headers
+	if err := binary.Write(buf, binary.BigEndian, uint32(len(columns))); err != nil {
+		return err
+	}
+	//
+	for i, col := range columns {
+		if err := writeColumnHeader(buf, modmap[col.Module], col, data[i]); err != nil {
+			return err
+		}
+	}
+	// Write column data
+	for _, bytes := range data {
+		if _, err := buf.Write(bytes); err != nil {
+			return err
+		}
+	}
+	// Done
+	return nil
+}
+
+func writeModuleHeader(buf io.Writer, name string, metadata Metadata) error {
+	keys := sortedKeys(metadata)
+	//
+	if len(keys) > math.MaxUint16 {
+		return fmt.Errorf("too much metadata for module %s", name)
+	} else if err := writeString16(buf, name); err != nil {
+		return err
+	} else if err := binary.Write(buf, binary.BigEndian, uint16(len(keys))); err != nil {
+		return err
+	}
+	//
+	for _, key := range keys {
+		if err := writeString16(buf, key); err != nil {
+			return err
+		} else if err := writeString16(buf, metadata[key]); err != nil {
+			return err
+		}
+	}
+	//
+	return nil
+}
+
+func writeColumnHeader(buf io.Writer, module uint32, col trace.RawColumn, data []byte) error {
+	var (
+		bitwidth = col.Data.BitWidth()
+		width    = byteWidth(bitwidth)
+	)
+	//
+	padding, ok := encodeElement(col.Padding, width)
+	//
+	if bitwidth > math.MaxUint16 || col.Data.Len() > math.MaxUint32 {
+		return fmt.Errorf("column %s too large for trace file", col.QualifiedName())
+	} else if !ok {
+		return fmt.Errorf("padding for column %s exceeds its bitwidth", col.QualifiedName())
+	} else if err := binary.Write(buf, binary.BigEndian, module); err != nil {
+		return err
+	} else if err := writeString16(buf, col.Name); err != nil {
+		return err
+	}
+	//
+	return writeAll(buf, uint16(bitwidth), uint8(width), uint32(col.Data.Len()), padding, crc32.ChecksumIEEE(data))
+}
+
+// Encode the data for a given column, such that each element occupies the
+// minimal number of bytes required for the column's bitwidth.
+func encodeColumnData(col trace.RawColumn) ([]byte, error) {
+	var (
+		width = byteWidth(col.Data.BitWidth())
+		data  = make([]byte, 0, width*col.Data.Len())
+	)
+	//
+	for i := uint(0); i < col.Data.Len(); i++ {
+		bytes, ok := encodeElement(col.Data.Get(i), width)
+		//
+		if !ok {
+			return nil, fmt.Errorf("row %d of column %s exceeds its bitwidth", i, col.QualifiedName())
+		}
+		//
+		data = append(data, bytes...)
+	}
+	//
+	return data, nil
+}
+
+// Encode a given element using a given number of bytes, or fail if the element
+// does not fit.
+func encodeElement(element fr.Element, width uint) ([]byte, bool) {
+	bytes := element.Bytes()
+	n := uint(len(bytes)) - width
+	// Check leading bytes are all zero
+	for _, b := range bytes[:n] {
+		if b != 0 {
+			return nil, false
+		}
+	}
+	//
+	return bytes[n:], true
+}
+
+func writeString16(buf io.Writer, str string) error {
+	if len(str) > math.MaxUint16 {
+		return fmt.Errorf("string too long for trace file (%d bytes)", len(str))
+	}
+	//
+	return writeAll(buf, uint16(len(str)), []byte(str))
+}
+
+// Write a sequence of (fixed-size) values in big-endian order.
+func writeAll(buf io.Writer, values ...any) error {
+	for _, v := range values {
+		if err := binary.Write(buf, binary.BigEndian, v); err != nil {
+			return err
+		}
+	}
+	//
+	return nil
+}
+
+// Determine number of bytes required to hold an element of a given bitwidth.
+func byteWidth(bitwidth uint) uint {
+	return (bitwidth + 7) / 8
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	//
+	slices.Sort(keys)
+	//
+	return keys
+}
@@ -45,6 +45,9 @@ type RawColumn struct {
 	Name string
 	// Data held in the column
 	Data util.FrArray
+	// Value to use when padding the column (which is zero unless otherwise
+	// specified by the trace file).
+	Padding fr.Element
 }
 
 // QualifiedName returns the fully qualified name of this column.
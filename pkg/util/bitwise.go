@@ -0,0 +1,116 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+)
+
+// BitwiseOp identifies a bitwise operation over unsigned integers of a given
+// bitwidth (e.g. bitwise and, shift left, etc).
+type BitwiseOp uint8
+
+const (
+	// BAND represents bitwise conjunction over one or more arguments.
+	BAND BitwiseOp = iota
+	// BOR represents bitwise disjunction over one or more arguments.
+	BOR
+	// BXOR represents bitwise exclusive-or over one or more arguments.
+	BXOR
+	// BNOT represents bitwise negation of exactly one argument.
+	BNOT
+	// SHL represents shifting the first argument left by the second (with any
+	// bits shifted beyond the bitwidth being discarded).
+	SHL
+	// SHR represents shifting the first argument right by the second.
+	SHR
+)
+
+// String returns the name of this operation, as used in the concrete syntax.
+func (op BitwiseOp) String() string {
+	switch op {
+	case BAND:
+		return "band"
+	case BOR:
+		return "bor"
+	case BXOR:
+		return "bxor"
+	case BNOT:
+		return "bnot"
+	case SHL:
+		return "shl"
+	case SHR:
+		return "shr"
+	default:
+		panic(fmt.Sprintf("unknown bitwise operation (%d)", op))
+	}
+}
+
+// IsShift determines whether this operation is a shift.  For such operations,
+// the second argument is the shift amount, rather than an operand.
+func (op BitwiseOp) IsShift() bool {
+	return op == SHL || op == SHR
+}
+
+// EvalBitwise evaluates a given bitwise operation over one or more field
+// elements, each of which is treated as an unsigned integer of the given
+// bitwidth.  Observe that the result is always truncated to fit within that
+// bitwidth, and likewise for the arguments.
+func EvalBitwise(op BitwiseOp, width uint, args []fr.Element) fr.Element {
+	var (
+		result fr.Element
+		mask   = big.NewInt(1)
+		vals   = make([]*big.Int, len(args))
+	)
+	// Construct mask of the form 2^width - 1
+	mask.Lsh(mask, width)
+	mask.Sub(mask, big.NewInt(1))
+	// Convert arguments (noting shift amounts are not truncated)
+	for i := range args {
+		vals[i] = args[i].BigInt(new(big.Int))
+		//
+		if i == 0 || !op.IsShift() {
+			vals[i].And(vals[i], mask)
+		}
+	}
+	// Apply operation
+	val := vals[0]
+	//
+	switch op {
+	case BAND:
+		for _, v := range vals[1:] {
+			val.And(val, v)
+		}
+	case BOR:
+		for _, v := range vals[1:] {
+			val.Or(val, v)
+		}
+	case BXOR:
+		for _, v := range vals[1:] {
+			val.Xor(val, v)
+		}
+	case BNOT:
+		val.Xor(val, mask)
+	case SHL:
+		val.Lsh(val, shiftAmount(vals[1], width))
+	case SHR:
+		val.Rsh(val, shiftAmount(vals[1], width))
+	}
+	// Truncate result
+	val.And(val, mask)
+	//
+	result.SetBigInt(val)
+	//
+	return result
+}
+
+// Determine the amount to shift by which, since any shift beyond the bitwidth
+// produces zero, is capped at the bitwidth.
+func shiftAmount(amount *big.Int, width uint) uint {
+	if !amount.IsUint64() || amount.Uint64() > uint64(width) {
+		return width
+	}
+	//
+	return uint(amount.Uint64())
+}
@@ -0,0 +1,35 @@
+package util
+
+// Option represents a value of a given type which may (or may not) be present.
+// This is useful for types which have no natural representation of absence
+// (e.g. type parameters).
+type Option[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some constructs an option holding a given value.
+func Some[T any](value T) Option[T] {
+	return Option[T]{value, true}
+}
+
+// None constructs an option holding no value.
+func None[T any]() Option[T] {
+	var empty T
+	return Option[T]{empty, false}
+}
+
+// HasValue determines whether or not this option holds a value.
+func (p Option[T]) HasValue() bool {
+	return p.ok
+}
+
+// Unwrap returns the value held by this option, or panics if it holds no
+// value.
+func (p Option[T]) Unwrap() T {
+	if !p.ok {
+		panic("option holds no value")
+	}
+	//
+	return p.value
+}
@@ -19,6 +19,8 @@ import (
 func ArePermutationOf[T Array[fr.Element]](dst []T, src []T) bool {
 	if len(dst) != len(src) {
 		return false
+	} else if len(dst) > 0 && dst[0].Len() != src[0].Len() {
+		return false
 	}
 	// Determine geometry
 	ncols := len(dst)
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i4) (Y :i4) (Z :i4))
+(defconstraint c1 () (vanishes! (- Z (band X Y))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i3) (Y :i3) (W :i3) (Z :i3))
+(defconstraint c1 () (vanishes! (- Z (bxor X Y W))))
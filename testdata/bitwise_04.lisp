@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (ST :binary@bool) (X :i8) (Z :i8))
+;; padding rows do not satisfy Z == ~X, hence the guard.
+(defconstraint c1 () (if ST (vanishes! (- Z (bnot X)))))
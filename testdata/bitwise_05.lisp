@@ -0,0 +1,6 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defconst THREE 3)
+(defcolumns (X :i8) (Y :i8) (Z :i8))
+(defconstraint c1 () (vanishes! (- Y (shl X THREE))))
+(defconstraint c2 () (vanishes! (- Z (shr X (- THREE 1)))))
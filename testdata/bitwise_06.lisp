@@ -0,0 +1,6 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i8) (Y :i8) (Z :i8))
+(defcomputedcolumn (R :i8) (band X Y))
+(defconstraint c1 () (vanishes! (- R Z)))
+(defconstraint c2 () (vanishes! (- R (band X Y))))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defunalias or bor)
+
+(defcolumns (X :i4) (Y :i4) (W :i4) (Z :i4))
+(defconstraint c1 () (vanishes! (- Z (reduce or (begin X Y W)))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i4) (Y :i8) (Z :i8))
+(defconstraint c1 () (vanishes! (- Z (bxor X Y))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i12) (Y :i12) (Z :i12))
+(defconstraint c1 () (vanishes! (- Z (bxor X (shift Y 1)))))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (G :i8) (X :i12) (Y :i12) (Z :i12))
+;; Operands are only required to fit within 12 bits when guard is active.
+(defconstraint c1 (:guard G) (vanishes! (- Z (band X Y))))
@@ -0,0 +1,6 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (P :binary@prove))
+(defperspective p P ((X :i12) (Y :i12) (Z :i12)))
+;; Operands are only required to fit within 12 bits when perspective is active.
+(defconstraint c1 (:perspective p) (vanishes! (- Z (bor X Y))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X (Y :i8) (Z :i8))
+(defconstraint c1 () (vanishes! (- Z (band X Y))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i8) (Y :i8) (Z :i8))
+(defconstraint c1 () (vanishes! (- Z (bnot X Y))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i257) (Y :i257) (Z :i257))
+(defconstraint c1 () (vanishes! (- Z (bor X Y))))
@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :i256) (Y :i8) (Z :i256))
+(defconstraint c1 () (vanishes! (- Z (band X Y))))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X Y)
+(defcomputedcolumn (Z :i16) (* 2 X))
+(defconstraint c1 () (vanishes! (- Z Y)))
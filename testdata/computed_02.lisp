@@ -0,0 +1,6 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (A :@loob) X Y)
+;; Z = X when A == 0, otherwise Z = 2*X
+(defcomputedcolumn (Z :i32) (if A X (* 2 X)))
+(defconstraint c1 () (vanishes! (- Z Y)))
@@ -0,0 +1,7 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X)
+;; Y must fit within a byte
+(defcomputedcolumn (Y :i8@prove) (* X X))
+(defcomputedcolumn Z (+ Y 1))
+(defconstraint c1 () (vanishes! (- Z Y 1)))
@@ -0,0 +1,2 @@
+(defcolumns X Y)
+(defcomputedcolumn Z (begin X Y))
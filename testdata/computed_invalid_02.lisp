@@ -0,0 +1,2 @@
+(defcolumns (A :@loob) X)
+(defcomputedcolumn Z (if A X))
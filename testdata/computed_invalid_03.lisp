@@ -0,0 +1,2 @@
+(defcolumns X)
+(defcomputedcolumn Z (+ X Y))
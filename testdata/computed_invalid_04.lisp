@@ -0,0 +1,2 @@
+(defcolumns X)
+(defcomputedcolumn (Z :i8 :array [2]) X)
@@ -0,0 +1,2 @@
+(defcolumns X Y)
+(defcomputedcolumn X (+ Y 1))
@@ -0,0 +1,6 @@
+(module m1)
+(defcolumns X)
+
+(module m2)
+(defcolumns Y)
+(defcomputedcolumn Z (+ Y m1.X))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X)
+;; X[-3] == X[-2] == X[-1] == 0
+(defconstraint c1 (:domain {-3 -2 -1}) (vanishes! X))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X)
+;; X[k] == 0 for the last three rows
+(defconstraint c1 (:domain {-3..-1}) (vanishes! X))
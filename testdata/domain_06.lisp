@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X)
+;; X[k] == 0 for every other row, counting back from the last
+(defconstraint c1 (:domain {-7..-1:2}) (vanishes! X))
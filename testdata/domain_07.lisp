@@ -0,0 +1,10 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns X Y)
+;; X[k] == X[k-1] for the last two rows
+(defconstraint c1 (:domain {-2..-1}) (vanishes! (- X (shift X -1))))
+;; Y[-1] == 1
+(defconstraint c2 (:domain {-1}) (vanishes! (- Y 1)))
+;; Y[-4] == 0, and Y[-3] == Y[-2] == 2
+(defconstraint c3 (:domain {-4}) (vanishes! Y))
+(defconstraint c4 (:domain {-3 -2}) (vanishes! (- Y 2)))
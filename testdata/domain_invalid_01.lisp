@@ -0,0 +1,2 @@
+(defcolumns (X :@loob))
+(defconstraint c1 (:domain {}) X)
@@ -0,0 +1,9 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B)
+;; bindings are sequential, and may shadow earlier bindings
+(defconstraint c1 ()
+  (let ((x (* 2 A))
+        (y (+ x 1))
+        (x (- y 1)))
+    (vanishes! (- x B))))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defpurefun (double x) (let ((y x)) (+ y y)))
+
+(defcolumns A B)
+(defconstraint c1 () (let ((z (double A))) (vanishes! (- B z))))
@@ -0,0 +1,6 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B (C :i16))
+(defcomputedcolumn (D :i16) (let ((x (+ A B))) (* x 2)))
+(defconstraint c1 () (let ((x D) (y (+ A B))) (vanishes! (- x y y))))
+(defconstraint c2 () (let ((x (begin A B))) (vanishes! (- x C))))
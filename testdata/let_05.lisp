@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defpurefun (triple x) (let ((y 3)) (* x y)))
+
+(defcolumns A B C)
+(defconstraint c1 () (vanishes! (- C (triple (let ((y A) (x B)) (+ y x))))))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B)
+;; bindings are sequential, hence y is not visible here
+(defconstraint c1 () (let ((x y) (y A)) (vanishes! (- x B))))
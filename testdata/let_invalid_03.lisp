@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B)
+(defconstraint c1 () (let () (vanishes! (- A B))))
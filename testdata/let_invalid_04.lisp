@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B)
+(defconstraint c1 () (let ((x A B)) (vanishes! (- x B))))
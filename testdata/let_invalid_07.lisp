@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B)
+(defconstraint c1 () (begin (let ((x A)) (vanishes! (- x B))) (vanishes! x)))
@@ -0,0 +1,3 @@
+(defcolumns (F :binary) A B)
+;; Only rows where F is set are looked up
+(deflookup test (B) (A) :source-selector F)
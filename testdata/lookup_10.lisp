@@ -0,0 +1,8 @@
+(module m1)
+(defcolumns (F :binary) X)
+
+(module m2)
+(defcolumns (G :binary) Y)
+;; Only rows of m1 where F is set are looked up, and only amongst those rows of
+;; m2 where G is set.
+(deflookup test (Y) (m1.X) :source-selector m1.F :target-selector G)
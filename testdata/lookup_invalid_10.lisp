@@ -0,0 +1,2 @@
+(defcolumns A B (F :@loob))
+(deflookup test (B) (A) :source-selector F)
@@ -0,0 +1,6 @@
+(module m1)
+(defcolumns A F)
+
+(module m2)
+(defcolumns B F)
+(deflookup test (B) (m1.A) :source-selector F)
@@ -0,0 +1,2 @@
+(defcolumns A B F)
+(deflookup test (B) (A) :source-selector)
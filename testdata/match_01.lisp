@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defpurefun ((eq! :@loob) x y) (- x y))
+(defconst ADD 1 MUL 2)
+(defcolumns (OP :i2) A B C)
+(defconstraint c1 () (match OP (ADD (eq! C (+ A B))) (MUL (eq! C (* A B))) (else (vanishes! C))))
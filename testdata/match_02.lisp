@@ -0,0 +1,8 @@
+(defpurefun ((eq! :@loob) x y) (- x y))
+
+(defcolumns (OP :i8) A B)
+(defconstraint c1 ()
+  (match OP
+    (1 (eq! B A))
+    (2 (eq! B (* 2 A)))
+    (3 (begin (eq! B (* 3 A)) (eq! A 1)))))
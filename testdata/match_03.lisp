@@ -0,0 +1,5 @@
+(defpurefun ((eq! :@loob) x y) (- x y))
+
+(defcolumns (SEL :binary) A B C)
+(defcomputedcolumn (D :i32) (match SEL (0 A) (else (+ A B))))
+(defconstraint c1 () (eq! C D))
@@ -0,0 +1,5 @@
+(defpurefun ((eq! :@loob) x y) (- x y))
+
+(defconst ONE 1 TWO 2)
+(defcolumns (OP :i8) A B)
+(defconstraint c1 () (match OP (ONE (eq! A B)) ((- TWO 1) (eq! A 0))))
@@ -0,0 +1,4 @@
+(defpurefun ((eq! :@loob) x y) (- x y))
+
+(defcolumns (OP :i2) A B)
+(defconstraint c1 () (match OP (1 (eq! A B)) (4 (eq! A 0))))
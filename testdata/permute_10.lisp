@@ -0,0 +1,11 @@
+(module mem)
+(defcolumns (RW :binary@prove) (ADDR :i8@prove) (VAL :i8@prove))
+
+(module ram)
+;; Only rows of mem where RW is set are sorted, and SEL identifies the resulting
+;; rows (i.e. all except padding rows).
+(defpermutation (ADDR' VAL') ((+ mem.ADDR) (+ mem.VAL)) :source-selector mem.RW :target-selector SEL)
+;; Consecutive rows with the same address hold the same value.
+(defconstraint consistency ()
+  (if-zero (- ADDR' (shift ADDR' -1))
+           (vanishes! (* (shift SEL -1) (- VAL' (shift VAL' -1))))))
@@ -0,0 +1,12 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(module m1)
+(defcolumns
+  (ST :i16@prove)
+  (X :i16@prove)
+  (Y :i16@prove))
+
+(module m2)
+(defpermutation (ST' A B) ((+ m1.ST) (- m1.X) (- m1.Y)))
+(defconstraint diag_ab ()
+  (vanishes! (* ST' (- (shift A 1) B))))
@@ -0,0 +1,10 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(module m1)
+(defcolumns (S :binary@prove) (X :i16@prove))
+
+(module m2)
+(defpermutation (Y) ((+ m1.X)) :source-selector m1.S :target-selector T)
+;; The selected values of X are consecutive.
+(defconstraint consecutive ()
+  (vanishes! (* (shift T -1) (- Y (+ 1 (shift Y -1))))))
@@ -0,0 +1,4 @@
+(module m1)
+(defcolumns (S :binary) (X :i16))
+(module m2)
+(defpermutation (Y) ((+ m1.X)) :source-selector m1.S)
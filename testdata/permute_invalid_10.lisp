@@ -0,0 +1,6 @@
+(module m1)
+(defcolumns (X :i16))
+(module m2)
+(defcolumns (Y :i16))
+(module m3)
+(defpermutation (A B) ((+ m1.X) (+ m2.Y)))
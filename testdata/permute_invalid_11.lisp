@@ -0,0 +1,5 @@
+(module m1)
+(defcolumns (X :i16))
+(module m2)
+(defcolumns (Z :i16))
+(defpermutation (Y) ((+ m1.X)))
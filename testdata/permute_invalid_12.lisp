@@ -0,0 +1,5 @@
+(module m1)
+(defconst ONE 1)
+(defcolumns (X :i16))
+(module m2)
+(defpermutation (Y) ((+ m1.X)) :source-selector m1.ONE :target-selector T)
@@ -0,0 +1,2 @@
+(defcolumns (S :binary) (X :i16))
+(defpermutation (Y) ((+ X)) :source-selector S :target-selector T)
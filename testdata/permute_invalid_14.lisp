@@ -0,0 +1,6 @@
+(module m1)
+(defcolumns (X :i16))
+(module m2)
+(defcolumns (S :binary))
+(module m3)
+(defpermutation (Y) ((+ m1.X)) :source-selector m2.S :target-selector T)
@@ -0,0 +1,3 @@
+(module m1)
+(defcolumns (X :i16@prove))
+(defpermutation (Z) ((+ m2.X)))
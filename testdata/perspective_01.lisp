@@ -0,0 +1,10 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (PERSPECTIVE_A :binary@prove) (PERSPECTIVE_B :binary@prove))
+(defperspective a PERSPECTIVE_A ((X :i16) (Y :i16)))
+(defperspective b PERSPECTIVE_B ((Z :i16)))
+
+;; X == Y whenever perspective a is active
+(defconstraint c1 (:perspective a) (vanishes! (- X Y)))
+;; Z == 1 whenever perspective b is active
+(defconstraint c2 (:perspective b) (vanishes! (- Z 1)))
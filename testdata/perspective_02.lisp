@@ -0,0 +1,10 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(module m1)
+(defcolumns (SEL :binary@prove) A)
+(defperspective p SEL ((B :i8)))
+
+;; Outside the perspective, its columns are accessed by qualified name.
+(defconstraint c1 (:guard SEL) (vanishes! (- A p/B)))
+;; Inside the perspective, module columns remain accessible.
+(defconstraint c2 (:perspective p :guard A) (vanishes! (- B 1)))
@@ -0,0 +1,9 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (P :binary@prove) (Q :binary@prove))
+;; Registers are only shared between columns of the same type.
+(defperspective p P ((X :i8@prove) (Y :i16)))
+(defperspective q Q ((Z :i16) (W :i8@prove)))
+
+(defconstraint c1 (:perspective p) (vanishes! (- Y X)))
+(defconstraint c2 (:perspective q) (vanishes! (- Z W)))
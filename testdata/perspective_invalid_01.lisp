@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A (SEL :binary))
+(defperspective p SEL ((X :i8)))
+;; unknown perspective
+(defconstraint c1 (:perspective q) (vanishes! X))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A (SEL :binary))
+(defperspective p SEL ((X :i8)))
+;; perspective columns must be qualified outside their perspective
+(defconstraint c1 () (vanishes! X))
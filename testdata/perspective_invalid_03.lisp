@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A)
+;; loobean selector
+(defperspective p (vanishes! A) ((X :i8)))
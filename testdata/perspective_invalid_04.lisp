@@ -0,0 +1,2 @@
+(defcolumns A)
+(defperspective p A)
@@ -0,0 +1,2 @@
+(defcolumns (SEL :binary))
+(defperspective p SEL ((X :i8) (X :i16)))
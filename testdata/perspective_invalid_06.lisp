@@ -0,0 +1,4 @@
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns (SEL :binary) X)
+;; perspective name cannot clash with a column
+(defperspective X SEL ((Y :i8)))